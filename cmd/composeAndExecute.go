@@ -19,6 +19,7 @@ package cmd
 import (
 	"time"
 
+	"github.com/makerdao/vulcanizedb/libraries/shared/watcher"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -117,6 +118,17 @@ func init() {
 	rootCmd.AddCommand(composeAndExecuteCmd)
 	composeAndExecuteCmd.Flags().BoolVarP(&recheckHeadersArg, "recheck-headers", "r", false, "whether to re-check headers for watched events")
 	composeAndExecuteCmd.Flags().DurationVarP(&queueRecheckInterval, "queue-recheck-interval", "q", 5*time.Minute, "interval duration for rechecking queued storage diffs (ex: 5m30s)")
+	composeAndExecuteCmd.Flags().Int64Var(&storageFinalityDepth, "storage-finality-depth", watcher.DefaultFinalityDepth, "number of blocks below the most recent header after which queued storage diffs that don't match the header at their height are marked orphaned")
+	composeAndExecuteCmd.Flags().DurationVar(&pollingInterval, "polling-interval", defaultPollingInterval, "interval between executions of contract transformers, and between checks for new reorgs to deliver to transformers")
 	composeAndExecuteCmd.Flags().DurationVarP(&retryInterval, "retry-interval", "i", 7*time.Second, "interval duration between retries on execution error")
+	composeAndExecuteCmd.Flags().Int64VarP(&confirmations, "confirmations", "c", 0, "number of blocks a header must be below the chain head before its events are transformed")
+	composeAndExecuteCmd.Flags().BoolVar(&fastThenConfirm, "fast-then-confirm", false, "transform events for unconfirmed headers immediately, then re-check them once they have the required confirmations")
+	composeAndExecuteCmd.Flags().IntVar(&logBatchSize, "log-batch-size", 1000, "maximum number of persisted event logs to load and delegate to transformers at a time (0 loads all of them)")
+	composeAndExecuteCmd.Flags().Int64Var(&logRangeSize, "log-range-size", 0, "maximum number of contiguous headers to fetch event logs for in one query (0 fetches logs header by header)")
+	composeAndExecuteCmd.Flags().StringVar(&metricsAddress, "metrics-address", "", "address to serve Prometheus metrics on at /metrics, e.g. :9090 (metrics are not served if empty)")
+	composeAndExecuteCmd.Flags().StringVar(&healthAddress, "health-address", "", "address to serve /healthz and /readyz health checks on, e.g. :8080 (health checks are not served if empty)")
+	composeAndExecuteCmd.Flags().Int64Var(&maxHeaderLag, "max-header-lag", 0, "maximum number of blocks the most recent header may be behind the chain head before /readyz fails (0 does not check the lag)")
+	composeAndExecuteCmd.Flags().DurationVar(&maxLogStaleness, "max-log-staleness", 10*time.Minute, "maximum time since extracting or delegating event logs last succeeded before health checks fail (0 does not check it)")
+	composeAndExecuteCmd.Flags().DurationVar(&maxStorageStaleness, "max-storage-staleness", time.Hour, "maximum time since the last storage diff was received before health checks fail (0 does not check it)")
 	composeAndExecuteCmd.Flags().IntVarP(&maxUnexpectedErrors, "max-unexpected-errs", "m", 5, "maximum number of unexpected errors to allow (with retries) before exiting")
 }
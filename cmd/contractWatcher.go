@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/makerdao/vulcanizedb/pkg/config"
 	ft "github.com/makerdao/vulcanizedb/pkg/contract_watcher/full/transformer"
 	ht "github.com/makerdao/vulcanizedb/pkg/contract_watcher/header/transformer"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
 	"github.com/makerdao/vulcanizedb/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -89,11 +91,16 @@ var (
 )
 
 func contractWatcher() {
-	ticker := time.NewTicker(5 * time.Second)
+	ticker := time.NewTicker(contractPollingInterval)
 	defer ticker.Stop()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cancelOnSignal(cancel)
+
 	blockChain := getBlockChain()
 	db := utils.LoadPostgres(databaseConfig, blockChain.Node())
+	startHTTPServers(blockChain, &db)
 
 	var t st.ContractTransformer
 	con := config.ContractConfig{}
@@ -112,15 +119,25 @@ func contractWatcher() {
 		LogWithCommand.Fatal(fmt.Sprintf("Failed to initialize transformer, err: %v ", err))
 	}
 
-	for range ticker.C {
-		err = t.Execute()
-		if err != nil {
-			LogWithCommand.Error("Execution error for transformer: ", t.GetConfig().Name, err)
+	for ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+			start := time.Now()
+			err = t.Execute(ctx)
+			metrics.ObserveTransformerExecution(t.GetConfig().Name, start, err)
+			if err != nil && ctx.Err() == nil {
+				LogWithCommand.Error("Execution error for transformer: ", t.GetConfig().Name, err)
+			}
 		}
 	}
+	closeConnections(&db)
+	LogWithCommand.Info("contract watcher stopped")
 }
 
 func init() {
 	rootCmd.AddCommand(contractWatcherCmd)
 	contractWatcherCmd.Flags().StringVarP(&mode, "mode", "o", "header", "'header' or 'full' mode to work with either header synced or fully synced vDB (default is header)")
+	contractWatcherCmd.Flags().DurationVar(&contractPollingInterval, "polling-interval", 5*time.Second, "interval between executions of the contract transformer")
+	contractWatcherCmd.Flags().StringVar(&metricsAddress, "metrics-address", "", "address to serve Prometheus metrics on at /metrics, e.g. :9090 (metrics are not served if empty)")
 }
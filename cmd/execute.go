@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"context"
 	"plugin"
 	"sync"
 	"time"
@@ -82,7 +83,18 @@ func init() {
 	rootCmd.AddCommand(executeCmd)
 	executeCmd.Flags().BoolVarP(&recheckHeadersArg, "recheck-headers", "r", false, "whether to re-check headers for watched events")
 	executeCmd.Flags().DurationVarP(&queueRecheckInterval, "queue-recheck-interval", "q", 5*time.Minute, "interval duration for rechecking queued storage diffs (ex: 5m30s)")
+	executeCmd.Flags().Int64Var(&storageFinalityDepth, "storage-finality-depth", watcher.DefaultFinalityDepth, "number of blocks below the most recent header after which queued storage diffs that don't match the header at their height are marked orphaned")
+	executeCmd.Flags().DurationVar(&pollingInterval, "polling-interval", defaultPollingInterval, "interval between executions of contract transformers, and between checks for new reorgs to deliver to transformers")
 	executeCmd.Flags().DurationVarP(&retryInterval, "retry-interval", "i", 7*time.Second, "interval duration between retries on execution error")
+	executeCmd.Flags().Int64VarP(&confirmations, "confirmations", "c", 0, "number of blocks a header must be below the chain head before its events are transformed")
+	executeCmd.Flags().BoolVar(&fastThenConfirm, "fast-then-confirm", false, "transform events for unconfirmed headers immediately, then re-check them once they have the required confirmations")
+	executeCmd.Flags().IntVar(&logBatchSize, "log-batch-size", 1000, "maximum number of persisted event logs to load and delegate to transformers at a time (0 loads all of them)")
+	executeCmd.Flags().Int64Var(&logRangeSize, "log-range-size", 0, "maximum number of contiguous headers to fetch event logs for in one query (0 fetches logs header by header)")
+	executeCmd.Flags().StringVar(&metricsAddress, "metrics-address", "", "address to serve Prometheus metrics on at /metrics, e.g. :9090 (metrics are not served if empty)")
+	executeCmd.Flags().StringVar(&healthAddress, "health-address", "", "address to serve /healthz and /readyz health checks on, e.g. :8080 (health checks are not served if empty)")
+	executeCmd.Flags().Int64Var(&maxHeaderLag, "max-header-lag", 0, "maximum number of blocks the most recent header may be behind the chain head before /readyz fails (0 does not check the lag)")
+	executeCmd.Flags().DurationVar(&maxLogStaleness, "max-log-staleness", 10*time.Minute, "maximum time since extracting or delegating event logs last succeeded before health checks fail (0 does not check it)")
+	executeCmd.Flags().DurationVar(&maxStorageStaleness, "max-storage-staleness", time.Hour, "maximum time since the last storage diff was received before health checks fail (0 does not check it)")
 	executeCmd.Flags().IntVarP(&maxUnexpectedErrors, "max-unexpected-errs", "m", 5, "maximum number of unexpected errors to allow (with retries) before exiting")
 }
 
@@ -118,18 +130,36 @@ func executeTransformers() {
 	// Setup bc and db objects
 	blockChain := getBlockChain()
 	db := utils.LoadPostgres(databaseConfig, blockChain.Node())
+	startHTTPServers(blockChain, &db)
 
 	// Execute over transformer sets returned by the exporter
-	// Use WaitGroup to wait on both goroutines
+	// Cancel the watchers on SIGINT/SIGTERM or once any of them fails, letting the others finish their work in progress
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cancelOnSignal(cancel)
 	var wg sync.WaitGroup
+	watcherErrs := make(chan error, 4)
+	var reorgListeners []transformer.ReorgListener
+	runWatcher := func(watch func(context.Context) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := watch(ctx)
+			if err != nil {
+				watcherErrs <- err
+				cancel()
+			}
+		}()
+	}
+
 	if len(ethEventInitializers) > 0 {
-		ew := watcher.NewEventWatcher(&db, blockChain, maxUnexpectedErrors, retryInterval)
+		ew := watcher.NewEventWatcher(&db, blockChain, confirmations, fastThenConfirm, logRangeSize, logBatchSize, maxUnexpectedErrors, retryInterval)
 		addErr := ew.AddTransformers(ethEventInitializers)
 		if addErr != nil {
 			LogWithCommand.Fatalf("failed to add event transformer initializers to watcher: %s", addErr.Error())
 		}
-		wg.Add(1)
-		go watchEthEvents(&ew, &wg)
+		reorgListeners = append(reorgListeners, ew.ReorgListeners()...)
+		runWatcher(func(ctx context.Context) error { return watchEthEvents(ctx, &ew) })
 	}
 
 	if len(ethStorageInitializers) > 0 {
@@ -141,35 +171,50 @@ func executeTransformers() {
 			payloadChan := make(chan statediff.Payload)
 			storageFetcher := fetcher.NewGethRpcStorageFetcher(&stateDiffStreamer, payloadChan)
 			sw := watcher.NewStorageWatcher(storageFetcher, &db)
+			sw.FinalityDepth = storageFinalityDepth
 			sw.AddTransformers(ethStorageInitializers)
-			wg.Add(1)
-			go watchEthStorage(&sw, &wg)
+			reorgListeners = append(reorgListeners, sw.ReorgListeners()...)
+			runWatcher(func(ctx context.Context) error { return watchEthStorage(ctx, &sw) })
 		default:
 			logrus.Debug("fetching storage diffs from csv")
 			tailer := fs.FileTailer{Path: storageDiffsPath}
 			storageFetcher := fetcher.NewCsvTailStorageFetcher(tailer)
 			sw := watcher.NewStorageWatcher(storageFetcher, &db)
+			sw.FinalityDepth = storageFinalityDepth
 			sw.AddTransformers(ethStorageInitializers)
-			wg.Add(1)
-			go watchEthStorage(&sw, &wg)
+			reorgListeners = append(reorgListeners, sw.ReorgListeners()...)
+			runWatcher(func(ctx context.Context) error { return watchEthStorage(ctx, &sw) })
 		}
 	}
 
 	if len(ethContractInitializers) > 0 {
 		gw := watcher.NewContractWatcher(&db, blockChain)
 		gw.AddTransformers(ethContractInitializers)
-		wg.Add(1)
-		go watchEthContract(&gw, &wg)
+		reorgListeners = append(reorgListeners, gw.ReorgListeners()...)
+		runWatcher(func(ctx context.Context) error { return watchEthContract(ctx, &gw) })
+	}
+
+	if len(reorgListeners) > 0 {
+		rw := watcher.NewReorgWatcher(&db, pollingInterval)
+		rw.AddListeners(reorgListeners)
+		runWatcher(func(ctx context.Context) error { return watchReorgs(ctx, &rw) })
 	}
 	wg.Wait()
+	close(watcherErrs)
+
+	closeConnections(&db)
+	watcherErr, failed := <-watcherErrs
+	if failed {
+		LogWithCommand.Fatalf("watchers stopped after error: %s", watcherErr.Error())
+	}
+	LogWithCommand.Info("watchers stopped")
 }
 
 type Exporter interface {
 	Export() ([]transformer.EventTransformerInitializer, []transformer.StorageTransformerInitializer, []transformer.ContractTransformerInitializer)
 }
 
-func watchEthEvents(w *watcher.EventWatcher, wg *sync.WaitGroup) {
-	defer wg.Done()
+func watchEthEvents(ctx context.Context, w *watcher.EventWatcher) error {
 	// Execute over the EventTransformerInitializer set using the watcher
 	LogWithCommand.Info("executing event transformers")
 	var recheck constants.TransformerExecution
@@ -178,31 +223,55 @@ func watchEthEvents(w *watcher.EventWatcher, wg *sync.WaitGroup) {
 	} else {
 		recheck = constants.HeaderUnchecked
 	}
-	err := w.Execute(recheck)
+	err := w.Execute(ctx, recheck)
 	if err != nil {
-		LogWithCommand.Fatalf("error executing event watcher: %s", err.Error())
+		LogWithCommand.Errorf("error executing event watcher: %s", err.Error())
 	}
+	return err
 }
 
-func watchEthStorage(w watcher.IStorageWatcher, wg *sync.WaitGroup) {
-	defer wg.Done()
+func watchEthStorage(ctx context.Context, w watcher.IStorageWatcher) error {
 	// Execute over the StorageTransformerInitializer set using the storage watcher
 	LogWithCommand.Info("executing storage transformers")
-	ticker := time.NewTicker(pollingInterval)
-	defer ticker.Stop()
-	err := w.Execute(queueRecheckInterval)
+	err := w.Execute(ctx, queueRecheckInterval)
+	if err != nil {
+		LogWithCommand.Errorf("error executing storage watcher: %s", err.Error())
+	}
+	return err
+}
+
+func watchReorgs(ctx context.Context, w *watcher.ReorgWatcher) error {
+	// Deliver the reorgs headerSync records to the transformers reacting to them
+	LogWithCommand.Info("delivering reorgs to transformers")
+	err := w.Execute(ctx)
 	if err != nil {
-		LogWithCommand.Fatalf("error executing storage watcher: %s", err.Error())
+		LogWithCommand.Errorf("error executing reorg watcher: %s", err.Error())
 	}
+	return err
 }
 
-func watchEthContract(w *watcher.ContractWatcher, wg *sync.WaitGroup) {
-	defer wg.Done()
+// Stops with the last error once more than --max-unexpected-errs consecutive executions fail
+func watchEthContract(ctx context.Context, w *watcher.ContractWatcher) error {
 	// Execute over the ContractTransformerInitializer set using the contract watcher
 	LogWithCommand.Info("executing contract_watcher transformers")
 	ticker := time.NewTicker(pollingInterval)
 	defer ticker.Stop()
-	for range ticker.C {
-		w.Execute()
+	consecutiveErrCount := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			err := w.Execute(ctx)
+			if err == nil {
+				consecutiveErrCount = 0
+				continue
+			}
+			consecutiveErrCount++
+			LogWithCommand.Errorf("error executing contract watcher: %s", err.Error())
+			if consecutiveErrCount > maxUnexpectedErrors {
+				return err
+			}
+		}
 	}
 }
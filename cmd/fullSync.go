@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"context"
 	"time"
 
 	"github.com/makerdao/vulcanizedb/pkg/core"
@@ -57,6 +58,10 @@ Expects ethereum node to be running and requires a .toml config:
 func init() {
 	rootCmd.AddCommand(fullSyncCmd)
 	fullSyncCmd.Flags().Int64VarP(&startingBlockNumber, "starting-block-number", "s", 0, "Block number to start syncing from")
+	fullSyncCmd.Flags().DurationVar(&pollingInterval, "polling-interval", defaultPollingInterval, "interval between validating the blocks near the chain head")
+	fullSyncCmd.Flags().IntVar(&validationWindow, "validation-window", defaultValidationWindow, "number of blocks below the chain head that are validated on every poll")
+	fullSyncCmd.Flags().Int64Var(&finalityDepth, "finality-depth", 0, finalityDepthUsage)
+	fullSyncCmd.Flags().StringVar(&metricsAddress, "metrics-address", "", "address to serve Prometheus metrics on at /metrics, e.g. :9090 (metrics are not served if empty)")
 }
 
 func backFillAllBlocks(blockchain core.BlockChain, blockRepository datastore.BlockRepository, missingBlocksPopulated chan int, startingBlockNumber int64) {
@@ -67,12 +72,16 @@ func backFillAllBlocks(blockchain core.BlockChain, blockRepository datastore.Blo
 	missingBlocksPopulated <- populated
 }
 
+// Syncs blocks until SIGINT or SIGTERM, then waits for the backfill in progress before closing connections
 func fullSync() {
 	ticker := time.NewTicker(pollingInterval)
 	defer ticker.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cancelOnSignal(cancel)
 
 	blockChain := getBlockChain()
-	lastBlock, err := blockChain.LastBlock()
+	lastBlock, err := blockChain.LastBlock(context.Background())
 	if err != nil {
 		LogWithCommand.Error("fullSync: Error getting last block: ", err)
 	}
@@ -84,13 +93,15 @@ func fullSync() {
 	}
 
 	db := utils.LoadPostgres(databaseConfig, blockChain.Node())
+	startHTTPServers(blockChain, &db)
 	blockRepository := repositories.NewBlockRepository(&db)
-	validator := history.NewBlockValidator(blockChain, blockRepository, validationWindow)
+	validator := history.NewBlockValidator(blockChain, blockRepository, getValidationWindow())
 	missingBlocksPopulated := make(chan int)
 	go backFillAllBlocks(blockChain, blockRepository, missingBlocksPopulated, startingBlockNumber)
 
-	for {
+	for ctx.Err() == nil {
 		select {
+		case <-ctx.Done():
 		case <-ticker.C:
 			window, err := validator.ValidateBlocks()
 			if err != nil {
@@ -101,4 +112,8 @@ func fullSync() {
 			go backFillAllBlocks(blockChain, blockRepository, missingBlocksPopulated, startingBlockNumber)
 		}
 	}
+
+	<-missingBlocksPopulated
+	closeConnections(&db)
+	LogWithCommand.Info("full sync stopped")
 }
@@ -17,18 +17,25 @@
 package cmd
 
 import (
+	"context"
+	"sync/atomic"
 	"time"
 
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/makerdao/vulcanizedb/libraries/shared/streamer"
 	"github.com/makerdao/vulcanizedb/pkg/core"
-	"github.com/makerdao/vulcanizedb/pkg/datastore"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
 	"github.com/makerdao/vulcanizedb/pkg/eth"
+	"github.com/makerdao/vulcanizedb/pkg/health"
 	"github.com/makerdao/vulcanizedb/pkg/history"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
 	"github.com/makerdao/vulcanizedb/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
+const resubscribeInterval = 30 * time.Second
+
 // headerSyncCmd represents the headerSync command
 var headerSyncCmd = &cobra.Command{
 	Use:   "headerSync",
@@ -38,6 +45,15 @@ Postgres with block headers.
 
 ./vulcanizedb headerSync --starting-block-number 0 --config public.toml
 
+Headers near the chain head are checked against the node on every poll. If a
+chain reorganization is detected, orphaned headers are replaced and the reorg
+is recorded in public.reorgs. Use --max-reorg-depth to bound how far back the
+sync will walk looking for a common ancestor.
+
+With --subscribe-new-heads, headers are inserted as the node announces them
+instead of on every poll, and polling resumes whenever the subscription drops.
+Subscriptions require a WebSocket or IPC connection to the node.
+
 Expects ethereum node to be running and requires a .toml config:
 
   [database]
@@ -58,49 +74,140 @@ Expects ethereum node to be running and requires a .toml config:
 func init() {
 	rootCmd.AddCommand(headerSyncCmd)
 	headerSyncCmd.Flags().Int64VarP(&startingBlockNumber, "starting-block-number", "s", 0, "Block number to start syncing from")
+	headerSyncCmd.Flags().IntVar(&backfillWorkers, "backfill-workers", 4, "Number of concurrent workers fetching missing headers")
+	headerSyncCmd.Flags().Int64Var(&backfillBatchSize, "backfill-batch-size", eth.MAX_BATCH_SIZE, "Number of missing headers each backfill worker fetches per batch")
+	headerSyncCmd.Flags().DurationVar(&pollingInterval, "polling-interval", defaultPollingInterval, "interval between validating the headers near the chain head")
+	headerSyncCmd.Flags().IntVar(&validationWindow, "validation-window", defaultValidationWindow, "number of blocks below the chain head whose headers are validated on every poll")
+	headerSyncCmd.Flags().Int64Var(&finalityDepth, "finality-depth", 0, finalityDepthUsage)
+	headerSyncCmd.Flags().DurationVar(&backfillRetryInterval, "backfill-retry-interval", 3*time.Second, "interval between checks for missing headers once none are left to backfill")
+	headerSyncCmd.Flags().BoolVar(&subscribeNewHeads, "subscribe-new-heads", false, "sync headers from a newHeads subscription instead of polling, falling back to polling while the subscription is down (requires a WebSocket or IPC connection)")
+	headerSyncCmd.Flags().Int64Var(&maxReorgDepth, "max-reorg-depth", 100, "Maximum number of blocks to walk back from the head when resolving a chain reorganization")
+	headerSyncCmd.Flags().StringVar(&healthAddress, "health-address", "", "address to serve /healthz and /readyz health checks on, e.g. :8080 (health checks are not served if empty)")
+	headerSyncCmd.Flags().Int64Var(&maxHeaderLag, "max-header-lag", 0, "maximum number of blocks the most recent header may be behind the chain head before /readyz fails (0 does not check the lag)")
+	headerSyncCmd.Flags().DurationVar(&maxSyncStaleness, "max-sync-staleness", 5*time.Minute, "maximum time since validating recent headers last succeeded before health checks fail (0 does not check it)")
+	headerSyncCmd.Flags().StringVar(&metricsAddress, "metrics-address", "", "address to serve Prometheus metrics on at /metrics, e.g. :9090 (metrics are not served if empty)")
 }
 
-func backFillAllHeaders(blockchain core.BlockChain, headerRepository datastore.HeaderRepository, missingBlocksPopulated chan int, startingBlockNumber int64) {
-	populated, err := history.PopulateMissingHeaders(blockchain, headerRepository, startingBlockNumber)
+func backFillAllHeaders(ctx context.Context, backfiller history.HeaderBackfiller, repairer *history.HeaderLinkRepairer, missingBlocksPopulated chan int, startingBlockNumber int64) {
+	populated, err := backfiller.Backfill(ctx, startingBlockNumber)
 	if err != nil {
 		// TODO Lots of possible errors in the call stack above. If errors occur, we still put
 		// 0 in the channel, triggering another round
 		LogWithCommand.Error("backfillAllHeaders: Error populating headers: ", err)
 	}
-	missingBlocksPopulated <- populated
+	repaired, err := repairer.Repair(startingBlockNumber, populated > 0)
+	if err != nil {
+		LogWithCommand.Error("backfillAllHeaders: Error repairing broken header links: ", err)
+	}
+	missingBlocksPopulated <- populated + repaired
 }
 
+// Syncs headers until SIGINT or SIGTERM, then waits for the backfill in progress before closing connections
 func headerSync() {
 	ticker := time.NewTicker(pollingInterval)
 	defer ticker.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cancelOnSignal(cancel)
 	blockChain := getBlockChain()
 	validateArgs(blockChain)
 	db := utils.LoadPostgres(databaseConfig, blockChain.Node())
+	startHTTPServers(blockChain, &db)
 
 	headerRepository := repositories.NewHeaderRepository(&db)
-	validator := history.NewHeaderValidator(blockChain, headerRepository, validationWindow)
+	reorgRepository := repositories.NewReorgRepository(&db)
+	reorgHandler := history.NewReorgHandler(blockChain, headerRepository, reorgRepository, maxReorgDepth)
+	reorgHandler.AddHook(func(reorg core.Reorg) error {
+		metrics.ObserveReorg(reorg.Depth())
+		return nil
+	})
+	windowSize := getValidationWindow()
+	validator := history.NewHeaderValidator(blockChain, headerRepository, reorgHandler, windowSize)
+	backfiller := history.NewHeaderBackfiller(blockChain, headerRepository, backfillWorkers, backfillBatchSize)
+	// Headers are only replaced within the validation window or the max reorg depth of the head
+	recheckDepth := maxReorgDepth
+	if int64(windowSize) > recheckDepth {
+		recheckDepth = int64(windowSize)
+	}
+	repairer := history.NewHeaderLinkRepairer(blockChain, headerRepository, reorgHandler, recheckDepth)
 	missingBlocksPopulated := make(chan int)
-	go backFillAllHeaders(blockChain, headerRepository, missingBlocksPopulated, startingBlockNumber)
+	go backFillAllHeaders(ctx, backfiller, repairer, missingBlocksPopulated, startingBlockNumber)
+
+	var subscribed int32
+	var subscriberDone chan struct{}
+	if subscribeNewHeads {
+		headStreamer := streamer.NewHeadStreamer(blockChain.RpcClient())
+		subscriber := history.NewHeaderSubscriber(blockChain, headerRepository, headStreamer, validator)
+		subscriberDone = make(chan struct{})
+		go func() {
+			defer close(subscriberDone)
+			subscribeToNewHeads(ctx, subscriber, &subscribed)
+		}()
+	}
 
-	for {
+	backfilling := true
+	for ctx.Err() == nil {
 		select {
+		case <-ctx.Done():
 		case <-ticker.C:
+			if atomic.LoadInt32(&subscribed) == 1 {
+				continue
+			}
 			window, err := validator.ValidateHeaders()
 			if err != nil {
 				LogWithCommand.Error("headerSync: ValidateHeaders failed: ", err)
+			} else {
+				health.RecordProgress(health.ValidateHeaders)
 			}
 			LogWithCommand.Debug(window.GetString())
 		case n := <-missingBlocksPopulated:
+			backfilling = false
 			if n == 0 {
-				time.Sleep(3 * time.Second)
+				select {
+				case <-ctx.Done():
+					continue
+				case <-time.After(backfillRetryInterval):
+				}
 			}
-			go backFillAllHeaders(blockChain, headerRepository, missingBlocksPopulated, startingBlockNumber)
+			backfilling = true
+			go backFillAllHeaders(ctx, backfiller, repairer, missingBlocksPopulated, startingBlockNumber)
+		}
+	}
+
+	if backfilling {
+		<-missingBlocksPopulated
+	}
+	if subscriberDone != nil {
+		<-subscriberDone
+	}
+	closeConnections(&db)
+	LogWithCommand.Info("header sync stopped")
+}
+
+// Syncs headers from the node's newHeads subscription, resubscribing whenever it drops. Headers are validated on the
+// polling interval while there is no subscription.
+func subscribeToNewHeads(ctx context.Context, subscriber history.HeaderSubscriber, subscribed *int32) {
+	for ctx.Err() == nil {
+		atomic.StoreInt32(subscribed, 1)
+		err := subscriber.Subscribe(ctx)
+		atomic.StoreInt32(subscribed, 0)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == rpc.ErrNotificationsUnsupported {
+			LogWithCommand.Error("headerSync: node connection does not support subscriptions, polling for headers instead")
+			return
+		}
+		LogWithCommand.Warn("headerSync: newHeads subscription dropped, polling for headers until resubscribed: ", err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(resubscribeInterval):
 		}
 	}
 }
 
 func validateArgs(blockChain *eth.BlockChain) {
-	lastBlock, err := blockChain.LastBlock()
+	lastBlock, err := blockChain.LastBlock(context.Background())
 	if err != nil {
 		LogWithCommand.Error("validateArgs: Error getting last block: ", err)
 	}
@@ -17,43 +17,83 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/makerdao/vulcanizedb/pkg/config"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
 	"github.com/makerdao/vulcanizedb/pkg/eth"
+	"github.com/makerdao/vulcanizedb/pkg/eth/cache"
 	"github.com/makerdao/vulcanizedb/pkg/eth/client"
 	vRpc "github.com/makerdao/vulcanizedb/pkg/eth/converters/rpc"
+	"github.com/makerdao/vulcanizedb/pkg/eth/failover"
+	"github.com/makerdao/vulcanizedb/pkg/eth/middleware"
 	"github.com/makerdao/vulcanizedb/pkg/eth/node"
+	"github.com/makerdao/vulcanizedb/pkg/health"
+	"github.com/makerdao/vulcanizedb/pkg/history"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
+	"github.com/makerdao/vulcanizedb/pkg/metrics/collectors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	LogWithCommand       logrus.Entry
-	SubCommand           string
-	cfgFile              string
-	databaseConfig       config.Database
-	genConfig            config.Plugin
-	ipc                  string
-	maxUnexpectedErrors  int
-	queueRecheckInterval time.Duration
-	recheckHeadersArg    bool
-	retryInterval        time.Duration
-	startingBlockNumber  int64
-	storageDiffsPath     string
-	storageDiffsSource   string
+	LogWithCommand          logrus.Entry
+	SubCommand              string
+	backfillBatchSize       int64
+	backfillRetryInterval   time.Duration
+	backfillWorkers         int
+	cfgFile                 string
+	clientConfig            config.Client
+	clientHeaders           map[string]string
+	confirmations           int64
+	contractPollingInterval time.Duration
+	databaseConfig          config.Database
+	fastThenConfirm         bool
+	finalityDepth           int64
+	genConfig               config.Plugin
+	healthAddress           string
+	logBatchSize            int
+	logRangeSize            int64
+	maxHeaderLag            int64
+	maxLogStaleness         time.Duration
+	maxReorgDepth           int64
+	maxStorageStaleness     time.Duration
+	maxSyncStaleness        time.Duration
+	maxUnexpectedErrors     int
+	metricsAddress          string
+	pollingInterval         time.Duration
+	queueRecheckInterval    time.Duration
+	recheckHeadersArg       bool
+	retryInterval           time.Duration
+	rpcCache                *cache.Cache
+	rpcCacheConfig          cache.Config
+	rpcCacheEnabled         bool
+	rpcMiddlewareConfig     middleware.Config
+	startingBlockNumber     int64
+	storageDiffsPath        string
+	storageDiffsSource      string
+	storageFinalityDepth    int64
+	subscribeNewHeads       bool
+	validationWindow        int
 )
 
 const (
-	pollingInterval  = 7 * time.Second
-	validationWindow = 15
+	defaultPollingInterval  = 7 * time.Second
+	defaultValidationWindow = 15
+	finalityDepthUsage      = "number of blocks below the chain head after which blocks are final; overrides --validation-window with a window covering the finality depth and a margin for blocks finalized between polls"
 )
 
 var rootCmd = &cobra.Command{
@@ -78,7 +118,46 @@ func initFuncs(cmd *cobra.Command, args []string) {
 }
 
 func setViperConfigs() {
-	ipc = viper.GetString("client.ipcpath")
+	clientConfig = config.Client{
+		IPCPath: viper.GetString("client.ipcpath"),
+		RPCURL:  viper.GetString("client.rpcurl"),
+		RPCURLs: viper.GetStringSlice("client.rpcurls"),
+		Headers: viper.GetStringMapString("client.headers"),
+		Timeout: viper.GetDuration("client.timeout"),
+		TLS: config.TLS{
+			CAFile:             viper.GetString("client.tls.cafile"),
+			CertFile:           viper.GetString("client.tls.certfile"),
+			KeyFile:            viper.GetString("client.tls.keyfile"),
+			InsecureSkipVerify: viper.GetBool("client.tls.insecureskipverify"),
+		},
+		MaxHeadLag:          viper.GetInt64("client.maxheadlag"),
+		HealthCheckInterval: viper.GetDuration("client.healthcheckinterval"),
+	}
+	for key, value := range clientHeaders {
+		clientConfig.Headers[key] = value
+	}
+	rpcMiddlewareConfig = middleware.Config{
+		CallTimeout:     viper.GetDuration("rpc.calltimeout"),
+		CallTimeouts:    make(map[string]time.Duration),
+		MaxRetries:      viper.GetInt("rpc.maxretries"),
+		RetryBackoff:    viper.GetDuration("rpc.retrybackoff"),
+		MaxRetryBackoff: viper.GetDuration("rpc.maxretrybackoff"),
+		RateLimit:       viper.GetFloat64("rpc.ratelimit"),
+		RateLimitBurst:  viper.GetInt("rpc.ratelimitburst"),
+	}
+	for method, timeout := range viper.GetStringMapString("rpc.calltimeouts") {
+		duration, parseErr := time.ParseDuration(timeout)
+		if parseErr != nil {
+			logrus.Fatalf("invalid rpc.callTimeouts value for %s: %s", method, parseErr.Error())
+		}
+		rpcMiddlewareConfig.CallTimeouts[method] = duration
+	}
+	rpcCacheEnabled = viper.GetBool("cache.enabled")
+	rpcCacheConfig = cache.Config{
+		Size:          viper.GetInt("cache.size"),
+		Path:          viper.GetString("cache.path"),
+		Confirmations: viper.GetInt64("cache.confirmations"),
+	}
 	storageDiffsPath = viper.GetString("filesystem.storageDiffsPath")
 	storageDiffsSource = viper.GetString("storageDiffs.source")
 	databaseConfig = config.Database{
@@ -117,6 +196,22 @@ func init() {
 	rootCmd.PersistentFlags().String("database-user", "", "database user")
 	rootCmd.PersistentFlags().String("database-password", "", "database password")
 	rootCmd.PersistentFlags().String("client-ipcPath", "", "location of geth.ipc file")
+	rootCmd.PersistentFlags().String("client-rpcUrl", "", "http(s):// or ws(s):// URL of the node's RPC endpoint; takes precedence over client-ipcPath")
+	rootCmd.PersistentFlags().StringSlice("client-rpcUrls", nil, "URLs of multiple nodes to spread requests across, failing over between them; takes precedence over client-rpcUrl")
+	rootCmd.PersistentFlags().Int64("client-maxHeadLag", 5, "with client-rpcUrls, don't use nodes whose chain head is more than this many blocks behind the others")
+	rootCmd.PersistentFlags().Duration("client-healthCheckInterval", 15*time.Second, "with client-rpcUrls, how often to check the nodes' health")
+	rootCmd.PersistentFlags().Duration("client-timeout", 30*time.Second, "timeout for connecting to the node, and for each HTTP request (0 for none)")
+	rootCmd.PersistentFlags().StringToStringVar(&clientHeaders, "client-headers", nil, "headers to send with HTTP and WebSocket RPC requests, e.g. Authorization=\"Bearer <token>\"")
+	rootCmd.PersistentFlags().Duration("rpc-callTimeout", 0, "timeout for each attempt of an RPC call (0 for none); rpc.callTimeouts in the config file overrides it by method")
+	rootCmd.PersistentFlags().Int("rpc-maxRetries", 3, "number of times to retry RPC calls that fail with a transient error")
+	rootCmd.PersistentFlags().Duration("rpc-retryBackoff", time.Second, "time to wait before retrying a failed RPC call, doubled for each retry")
+	rootCmd.PersistentFlags().Duration("rpc-maxRetryBackoff", 30*time.Second, "maximum time to wait before retrying a failed RPC call")
+	rootCmd.PersistentFlags().Float64("rpc-rateLimit", 0, "maximum sustained RPC calls per second (0 for no limit)")
+	rootCmd.PersistentFlags().Int("rpc-rateLimitBurst", 10, "number of RPC calls that can be made at once before rpc-rateLimit applies")
+	rootCmd.PersistentFlags().Bool("cache-enabled", false, "cache RPC responses about blocks at least cache-confirmations behind the chain head")
+	rootCmd.PersistentFlags().Int("cache-size", 10000, "number of RPC responses to cache in memory")
+	rootCmd.PersistentFlags().String("cache-path", "", "directory to also cache RPC responses in on disk, so they're kept between runs")
+	rootCmd.PersistentFlags().Int64("cache-confirmations", 64, "how far behind the chain head a block has to be for responses about it to be cached")
 	rootCmd.PersistentFlags().String("filesystem-storageDiffsPath", "", "location of storage diffs csv file")
 	rootCmd.PersistentFlags().String("storageDiffs-source", "csv", "where to get the state diffs: csv or geth")
 	rootCmd.PersistentFlags().String("exporter-name", "exporter", "name of exporter plugin")
@@ -128,6 +223,21 @@ func init() {
 	viper.BindPFlag("database.user", rootCmd.PersistentFlags().Lookup("database-user"))
 	viper.BindPFlag("database.password", rootCmd.PersistentFlags().Lookup("database-password"))
 	viper.BindPFlag("client.ipcPath", rootCmd.PersistentFlags().Lookup("client-ipcPath"))
+	viper.BindPFlag("client.rpcUrl", rootCmd.PersistentFlags().Lookup("client-rpcUrl"))
+	viper.BindPFlag("client.rpcUrls", rootCmd.PersistentFlags().Lookup("client-rpcUrls"))
+	viper.BindPFlag("client.maxHeadLag", rootCmd.PersistentFlags().Lookup("client-maxHeadLag"))
+	viper.BindPFlag("client.healthCheckInterval", rootCmd.PersistentFlags().Lookup("client-healthCheckInterval"))
+	viper.BindPFlag("client.timeout", rootCmd.PersistentFlags().Lookup("client-timeout"))
+	viper.BindPFlag("rpc.callTimeout", rootCmd.PersistentFlags().Lookup("rpc-callTimeout"))
+	viper.BindPFlag("rpc.maxRetries", rootCmd.PersistentFlags().Lookup("rpc-maxRetries"))
+	viper.BindPFlag("rpc.retryBackoff", rootCmd.PersistentFlags().Lookup("rpc-retryBackoff"))
+	viper.BindPFlag("rpc.maxRetryBackoff", rootCmd.PersistentFlags().Lookup("rpc-maxRetryBackoff"))
+	viper.BindPFlag("rpc.rateLimit", rootCmd.PersistentFlags().Lookup("rpc-rateLimit"))
+	viper.BindPFlag("rpc.rateLimitBurst", rootCmd.PersistentFlags().Lookup("rpc-rateLimitBurst"))
+	viper.BindPFlag("cache.enabled", rootCmd.PersistentFlags().Lookup("cache-enabled"))
+	viper.BindPFlag("cache.size", rootCmd.PersistentFlags().Lookup("cache-size"))
+	viper.BindPFlag("cache.path", rootCmd.PersistentFlags().Lookup("cache-path"))
+	viper.BindPFlag("cache.confirmations", rootCmd.PersistentFlags().Lookup("cache-confirmations"))
 	viper.BindPFlag("filesystem.storageDiffsPath", rootCmd.PersistentFlags().Lookup("filesystem-storageDiffsPath"))
 	viper.BindPFlag("storageDiffs.source", rootCmd.PersistentFlags().Lookup("storageDiffs-source"))
 	viper.BindPFlag("exporter.fileName", rootCmd.PersistentFlags().Lookup("exporter-name"))
@@ -149,25 +259,168 @@ func initConfig() {
 }
 
 func getBlockChain() *eth.BlockChain {
-	rpcClient, ethClient := getClients()
-	vdbEthClient := client.NewEthClient(ethClient)
+	if endpoints := clientConfig.Endpoints(); len(endpoints) > 1 {
+		return getFailoverBlockChain(endpoints)
+	}
+	rawRpcClient, rawEthClient := getClients()
+	rpcClient, ethClient := applyRPCMiddleware(rawRpcClient, client.NewEthClient(rawEthClient))
 	vdbNode := node.MakeNode(rpcClient)
 	transactionConverter := vRpc.NewRpcTransactionConverter(ethClient)
-	return eth.NewBlockChain(vdbEthClient, rpcClient, vdbNode, transactionConverter)
+	return eth.NewBlockChain(ethClient, rpcClient, vdbNode, transactionConverter)
 }
 
-func getClients() (client.RpcClient, *ethclient.Client) {
-	rawRpcClient, err := rpc.Dial(ipc)
+// The validation window derived from --finality-depth if it's set, or --validation-window otherwise
+func getValidationWindow() int {
+	if finalityDepth > 0 {
+		return history.WindowSizeForFinalityDepth(finalityDepth)
+	}
+	return validationWindow
+}
 
+// Applies the timeouts, retries and rate limit configured under [rpc], and the response cache configured under [cache]
+func applyRPCMiddleware(rpcClient core.RpcClient, ethClient core.EthClient) (core.RpcClient, core.EthClient) {
+	policy := middleware.NewPolicy(rpcMiddlewareConfig)
+	rpcClient, ethClient = middleware.NewRpcClient(rpcClient, policy), middleware.NewEthClient(ethClient, policy)
+	if !rpcCacheEnabled {
+		return rpcClient, ethClient
+	}
+	var err error
+	rpcCache, err = cache.New(rpcCacheConfig, rpcClient)
 	if err != nil {
-		LogWithCommand.Fatal(err)
+		LogWithCommand.Fatalf("couldn't create RPC cache: %s", err.Error())
+	}
+	return cache.NewRpcClient(rpcClient, rpcCache), cache.NewEthClient(ethClient, rpcCache)
+}
+
+// Closes the RPC cache, if there is one, releasing the database it keeps responses in on disk
+func closeRPCCache() {
+	if rpcCache == nil {
+		return
+	}
+	if err := rpcCache.Close(); err != nil {
+		LogWithCommand.Errorf("error closing RPC cache: %s", err.Error())
+	}
+}
+
+// Cancels the command's work on SIGINT or SIGTERM, so that it can close the database and RPC cache once its work in
+// progress is finished. A second signal exits without waiting for work in progress.
+func cancelOnSignal(cancel context.CancelFunc) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-signals
+	LogWithCommand.Infof("received %s, stopping once work in progress is finished", sig)
+	cancel()
+	sig = <-signals
+	LogWithCommand.Fatalf("received %s again, exiting immediately", sig)
+}
+
+// Closes the database and the RPC cache once a command has stopped
+func closeConnections(db *postgres.DB) {
+	closeErr := db.Close()
+	if closeErr != nil {
+		LogWithCommand.Errorf("error closing database connection: %s", closeErr.Error())
+	}
+	closeRPCCache()
+}
+
+// Spreads requests across the nodes at --client-rpcUrls, failing over between them. The node recorded in eth_nodes is
+// read from the first one that can be reached, and nodes on a different chain are refused.
+func getFailoverBlockChain(urls []string) *eth.BlockChain {
+	var endpoints []failover.Endpoint
+	for _, url := range urls {
+		rpcClient, ethClient, err := dialEndpoint(url)
+		if err != nil {
+			LogWithCommand.Warnf("skipping RPC endpoint %s: %s", failover.NewEndpoint(url, nil, nil).Name, err.Error())
+			continue
+		}
+		endpoints = append(endpoints, failover.NewEndpoint(url, rpcClient, client.NewEthClient(ethClient)))
+	}
+	if len(endpoints) == 0 {
+		LogWithCommand.Fatal(failover.ErrNoEndpoints)
 	}
-	rpcClient := client.NewRpcClient(rawRpcClient, ipc)
-	ethClient := ethclient.NewClient(rawRpcClient)
 
+	failoverClient := failover.NewClient(endpoints, clientConfig.MaxHeadLag)
+	rpcClient, ethClient := applyRPCMiddleware(failoverClient, failoverClient)
+	vdbNode := node.MakeNode(rpcClient)
+	if clientConfig.HealthCheckInterval > 0 {
+		checkCtx, cancel := context.WithTimeout(context.Background(), clientConfig.HealthCheckInterval)
+		failoverClient.CheckHealth(checkCtx, vdbNode)
+		cancel()
+		go failoverClient.MonitorHealth(context.Background(), vdbNode, clientConfig.HealthCheckInterval)
+	} else {
+		failoverClient.CheckHealth(context.Background(), vdbNode)
+	}
+	transactionConverter := vRpc.NewRpcTransactionConverter(ethClient)
+	return eth.NewBlockChain(ethClient, rpcClient, vdbNode, transactionConverter)
+}
+
+func getClients() (client.RpcClient, *ethclient.Client) {
+	rpcClient, ethClient, err := dialEndpoint(clientConfig.Endpoints()[0])
+	if err != nil {
+		LogWithCommand.Fatal(err)
+	}
 	return rpcClient, ethClient
 }
 
+func dialEndpoint(endpoint string) (client.RpcClient, *ethclient.Client, error) {
+	tlsConfig, tlsErr := clientConfig.TLSConfig()
+	if tlsErr != nil {
+		return client.RpcClient{}, nil, tlsErr
+	}
+	rawRpcClient, err := client.Dial(context.Background(), endpoint, client.DialOptions{
+		Headers:   clientConfig.Headers,
+		Timeout:   clientConfig.Timeout,
+		TLSConfig: tlsConfig,
+	})
+	if err != nil {
+		return client.RpcClient{}, nil, err
+	}
+	return client.NewRpcClient(rawRpcClient, endpoint), ethclient.NewClient(rawRpcClient), nil
+}
+
+// Serves metrics at /metrics on --metrics-address, and health checks at /healthz and /readyz on --health-address, in
+// the background. Both are served by the same server if the addresses match, and neither is served if its address is
+// not set.
+func startHTTPServers(blockChain core.BlockChain, db *postgres.DB) {
+	muxes := make(map[string]*http.ServeMux)
+	getMux := func(address string) *http.ServeMux {
+		if _, ok := muxes[address]; !ok {
+			muxes[address] = http.NewServeMux()
+		}
+		return muxes[address]
+	}
+
+	if metricsAddress != "" {
+		collector := collectors.NewSyncStatusCollector(blockChain, repositories.NewSyncStatusRepository(db))
+		metricsHandler, metricsErr := metrics.Handler(collector)
+		if metricsErr != nil {
+			LogWithCommand.Fatalf("failed to register metrics: %s", metricsErr.Error())
+		}
+		getMux(metricsAddress).Handle("/metrics", metricsHandler)
+	}
+
+	if healthAddress != "" {
+		progressCheck := health.ProgressCheck(health.DefaultProgressTracker, map[string]time.Duration{
+			health.DelegateLogs:    maxLogStaleness,
+			health.ExtractLogs:     maxLogStaleness,
+			health.StorageDiffs:    maxStorageStaleness,
+			health.ValidateHeaders: maxSyncStaleness,
+		})
+		nodeCheck := health.NodeCheck(blockChain, repositories.NewHeaderRepository(db), maxHeaderLag)
+		mux := getMux(healthAddress)
+		mux.Handle("/healthz", health.Handler(progressCheck))
+		mux.Handle("/readyz", health.Handler(health.DatabaseCheck(db), nodeCheck, progressCheck))
+	}
+
+	for address, mux := range muxes {
+		go func(address string, mux *http.ServeMux) {
+			LogWithCommand.Infof("serving HTTP at %s", address)
+			serveErr := http.ListenAndServe(address, mux)
+			LogWithCommand.Errorf("HTTP server at %s stopped: %s", address, serveErr.Error())
+		}(address, mux)
+	}
+}
+
 func prepConfig() error {
 	LogWithCommand.Info("configuring plugin")
 	names := viper.GetStringSlice("exporter.transformerNames")
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE public.reorgs
+(
+    id              SERIAL PRIMARY KEY,
+    common_ancestor BIGINT        NOT NULL,
+    depth           INTEGER       NOT NULL,
+    old_hashes      VARCHAR(66)[] NOT NULL,
+    new_hashes      VARCHAR(66)[] NOT NULL,
+    eth_node_id     INTEGER       NOT NULL REFERENCES eth_nodes (id) ON DELETE CASCADE,
+    created         TIMESTAMP     NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX reorgs_common_ancestor ON public.reorgs (common_ancestor);
+
+COMMENT ON TABLE public.reorgs
+    IS E'@omit';
+
+-- +goose Down
+DROP INDEX reorgs_common_ancestor;
+DROP TABLE public.reorgs;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE public.headers
+    ADD COLUMN parent_hash VARCHAR(66);
+
+UPDATE public.headers
+SET parent_hash = raw ->> 'parentHash'
+WHERE raw IS NOT NULL;
+
+-- +goose Down
+ALTER TABLE public.headers
+    DROP COLUMN parent_hash;
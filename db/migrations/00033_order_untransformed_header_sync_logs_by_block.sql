@@ -0,0 +1,11 @@
+-- +goose Up
+DROP INDEX header_sync_logs_untransformed;
+CREATE INDEX header_sync_logs_untransformed
+    ON public.header_sync_logs (block_number, id)
+    WHERE transformed IS FALSE;
+
+-- +goose Down
+DROP INDEX header_sync_logs_untransformed;
+CREATE INDEX header_sync_logs_untransformed
+    ON public.header_sync_logs (transformed)
+    WHERE transformed IS FALSE;
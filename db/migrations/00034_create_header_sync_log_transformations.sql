@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE public.header_sync_log_transformations
+(
+    log_id           INTEGER   NOT NULL REFERENCES header_sync_logs (id) ON DELETE CASCADE,
+    transformer_name TEXT      NOT NULL,
+    created          TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (log_id, transformer_name)
+);
+
+CREATE INDEX header_sync_log_transformations_transformer_name
+    ON public.header_sync_log_transformations (transformer_name);
+
+COMMENT ON TABLE public.header_sync_log_transformations
+    IS E'@omit';
+
+-- +goose Down
+DROP INDEX header_sync_log_transformations_transformer_name;
+DROP TABLE public.header_sync_log_transformations;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE public.watched_logs
+    ADD COLUMN backfill_next_block   BIGINT,
+    ADD COLUMN backfill_ending_block BIGINT;
+
+-- +goose Down
+ALTER TABLE public.watched_logs
+    DROP COLUMN backfill_next_block,
+    DROP COLUMN backfill_ending_block;
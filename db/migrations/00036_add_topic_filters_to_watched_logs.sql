@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE public.watched_logs
+    ADD COLUMN topic_filters TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE public.watched_logs
+    DROP COLUMN topic_filters;
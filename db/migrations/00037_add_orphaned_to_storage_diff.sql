@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE public.storage_diff
+    ADD COLUMN orphaned BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE public.storage_diff
+    DROP COLUMN orphaned;
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE public.header_sync_log_transformers
+(
+    transformer_name TEXT PRIMARY KEY,
+    created          TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE public.header_sync_log_transformers
+    IS E'@omit';
+
+-- +goose Down
+DROP TABLE public.header_sync_log_transformers;
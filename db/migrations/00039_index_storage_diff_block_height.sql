@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE INDEX storage_diff_block_height
+    ON public.storage_diff (block_height)
+    WHERE orphaned IS FALSE;
+CREATE INDEX storage_diff_orphaned_block_height
+    ON public.storage_diff (block_height)
+    WHERE orphaned IS TRUE;
+
+-- +goose Down
+DROP INDEX storage_diff_orphaned_block_height;
+DROP INDEX storage_diff_block_height;
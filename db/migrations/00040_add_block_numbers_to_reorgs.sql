@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE public.reorgs
+    ADD COLUMN block_numbers BIGINT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE public.reorgs
+    DROP COLUMN block_numbers;
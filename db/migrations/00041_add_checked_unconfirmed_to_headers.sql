@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE public.headers
+    ADD COLUMN checked_unconfirmed BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX headers_checked_unconfirmed
+    ON public.headers (block_number)
+    WHERE checked_unconfirmed IS TRUE;
+
+-- +goose Down
+DROP INDEX headers_checked_unconfirmed;
+ALTER TABLE public.headers
+    DROP COLUMN checked_unconfirmed;
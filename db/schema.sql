@@ -352,6 +352,41 @@ CREATE SEQUENCE public.goose_db_version_id_seq
 ALTER SEQUENCE public.goose_db_version_id_seq OWNED BY public.goose_db_version.id;
 
 
+--
+-- Name: header_sync_log_transformations; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.header_sync_log_transformations (
+    log_id integer NOT NULL,
+    transformer_name text NOT NULL,
+    created timestamp without time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: TABLE header_sync_log_transformations; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON TABLE public.header_sync_log_transformations IS '@omit';
+
+
+--
+-- Name: header_sync_log_transformers; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.header_sync_log_transformers (
+    transformer_name text NOT NULL,
+    created timestamp without time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: TABLE header_sync_log_transformers; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON TABLE public.header_sync_log_transformers IS '@omit';
+
+
 --
 -- Name: header_sync_logs; Type: TABLE; Schema: public; Owner: -
 --
@@ -488,7 +523,9 @@ CREATE TABLE public.headers (
     raw jsonb,
     block_timestamp numeric,
     check_count integer DEFAULT 0 NOT NULL,
-    eth_node_id integer NOT NULL
+    eth_node_id integer NOT NULL,
+    parent_hash character varying(66),
+    checked_unconfirmed boolean DEFAULT false NOT NULL
 );
 
 
@@ -616,6 +653,49 @@ CREATE SEQUENCE public.queued_storage_id_seq
 ALTER SEQUENCE public.queued_storage_id_seq OWNED BY public.queued_storage.id;
 
 
+--
+-- Name: reorgs; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.reorgs (
+    id integer NOT NULL,
+    common_ancestor bigint NOT NULL,
+    depth integer NOT NULL,
+    old_hashes character varying(66)[] NOT NULL,
+    new_hashes character varying(66)[] NOT NULL,
+    eth_node_id integer NOT NULL,
+    created timestamp without time zone DEFAULT now() NOT NULL,
+    block_numbers bigint[] DEFAULT '{}'::bigint[] NOT NULL
+);
+
+
+--
+-- Name: TABLE reorgs; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON TABLE public.reorgs IS '@omit';
+
+
+--
+-- Name: reorgs_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.reorgs_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: reorgs_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.reorgs_id_seq OWNED BY public.reorgs.id;
+
+
 --
 -- Name: storage_diff; Type: TABLE; Schema: public; Owner: -
 --
@@ -626,7 +706,8 @@ CREATE TABLE public.storage_diff (
     block_hash bytea,
     hashed_address bytea,
     storage_key bytea,
-    storage_value bytea
+    storage_value bytea,
+    orphaned boolean DEFAULT false NOT NULL
 );
 
 
@@ -774,7 +855,10 @@ COMMENT ON VIEW public.watched_event_logs IS '@omit';
 CREATE TABLE public.watched_logs (
     id integer NOT NULL,
     contract_address character varying(42),
-    topic_zero character varying(66)
+    topic_zero character varying(66),
+    backfill_next_block bigint,
+    backfill_ending_block bigint,
+    topic_filters text DEFAULT ''::text NOT NULL
 );
 
 
@@ -903,6 +987,13 @@ ALTER TABLE ONLY public.log_filters ALTER COLUMN id SET DEFAULT nextval('public.
 ALTER TABLE ONLY public.queued_storage ALTER COLUMN id SET DEFAULT nextval('public.queued_storage_id_seq'::regclass);
 
 
+--
+-- Name: reorgs id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.reorgs ALTER COLUMN id SET DEFAULT nextval('public.reorgs_id_seq'::regclass);
+
+
 --
 -- Name: storage_diff id; Type: DEFAULT; Schema: public; Owner: -
 --
@@ -1019,6 +1110,22 @@ ALTER TABLE ONLY public.goose_db_version
     ADD CONSTRAINT goose_db_version_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: header_sync_log_transformations header_sync_log_transformations_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.header_sync_log_transformations
+    ADD CONSTRAINT header_sync_log_transformations_pkey PRIMARY KEY (log_id, transformer_name);
+
+
+--
+-- Name: header_sync_log_transformers header_sync_log_transformers_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.header_sync_log_transformers
+    ADD CONSTRAINT header_sync_log_transformers_pkey PRIMARY KEY (transformer_name);
+
+
 --
 -- Name: header_sync_logs header_sync_logs_header_id_tx_index_log_index_key; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -1115,6 +1222,14 @@ ALTER TABLE ONLY public.queued_storage
     ADD CONSTRAINT queued_storage_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: reorgs reorgs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.reorgs
+    ADD CONSTRAINT reorgs_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: storage_diff storage_diff_block_height_block_hash_hashed_address_storage_key; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -1199,6 +1314,13 @@ CREATE INDEX full_sync_receipts_block ON public.full_sync_receipts USING btree (
 CREATE INDEX full_sync_receipts_contract_address ON public.full_sync_receipts USING btree (contract_address_id);
 
 
+--
+-- Name: header_sync_log_transformations_transformer_name; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX header_sync_log_transformations_transformer_name ON public.header_sync_log_transformations USING btree (transformer_name);
+
+
 --
 -- Name: header_sync_logs_address; Type: INDEX; Schema: public; Owner: -
 --
@@ -1217,7 +1339,7 @@ CREATE INDEX header_sync_logs_transaction ON public.header_sync_logs USING btree
 -- Name: header_sync_logs_untransformed; Type: INDEX; Schema: public; Owner: -
 --
 
-CREATE INDEX header_sync_logs_untransformed ON public.header_sync_logs USING btree (transformed) WHERE (transformed IS FALSE);
+CREATE INDEX header_sync_logs_untransformed ON public.header_sync_logs USING btree (block_number, id) WHERE (transformed IS FALSE);
 
 
 --
@@ -1255,6 +1377,13 @@ CREATE INDEX headers_block_number ON public.headers USING btree (block_number);
 CREATE INDEX headers_check_count ON public.headers USING btree (check_count);
 
 
+--
+-- Name: headers_checked_unconfirmed; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX headers_checked_unconfirmed ON public.headers USING btree (block_number) WHERE (checked_unconfirmed IS TRUE);
+
+
 --
 -- Name: headers_eth_node; Type: INDEX; Schema: public; Owner: -
 --
@@ -1276,6 +1405,27 @@ CREATE INDEX node_id_index ON public.blocks USING btree (eth_node_id);
 CREATE INDEX number_index ON public.blocks USING btree (number);
 
 
+--
+-- Name: reorgs_common_ancestor; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX reorgs_common_ancestor ON public.reorgs USING btree (common_ancestor);
+
+
+--
+-- Name: storage_diff_block_height; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX storage_diff_block_height ON public.storage_diff USING btree (block_height) WHERE (orphaned IS FALSE);
+
+
+--
+-- Name: storage_diff_orphaned_block_height; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX storage_diff_orphaned_block_height ON public.storage_diff USING btree (block_height) WHERE (orphaned IS TRUE);
+
+
 --
 -- Name: tx_from_index; Type: INDEX; Schema: public; Owner: -
 --
@@ -1329,6 +1479,14 @@ ALTER TABLE ONLY public.full_sync_transactions
     ADD CONSTRAINT full_sync_transactions_block_id_fkey FOREIGN KEY (block_id) REFERENCES public.blocks(id) ON DELETE CASCADE;
 
 
+--
+-- Name: header_sync_log_transformations header_sync_log_transformations_log_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.header_sync_log_transformations
+    ADD CONSTRAINT header_sync_log_transformations_log_id_fkey FOREIGN KEY (log_id) REFERENCES public.header_sync_logs(id) ON DELETE CASCADE;
+
+
 --
 -- Name: header_sync_logs header_sync_logs_address_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -1417,6 +1575,14 @@ ALTER TABLE ONLY public.full_sync_logs
     ADD CONSTRAINT receipts_fk FOREIGN KEY (receipt_id) REFERENCES public.full_sync_receipts(id) ON DELETE CASCADE;
 
 
+--
+-- Name: reorgs reorgs_eth_node_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.reorgs
+    ADD CONSTRAINT reorgs_eth_node_id_fkey FOREIGN KEY (eth_node_id) REFERENCES public.eth_nodes(id) ON DELETE CASCADE;
+
+
 --
 -- Name: uncles uncles_block_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
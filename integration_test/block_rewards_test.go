@@ -17,6 +17,7 @@
 package integration
 
 import (
+	"context"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 	. "github.com/onsi/ginkgo"
@@ -40,7 +41,7 @@ var _ = Describe("Rewards calculations", func() {
 		node := node.MakeNode(rpcClient)
 		transactionConverter := vRpc.NewRpcTransactionConverter(ethClient)
 		blockChain := eth.NewBlockChain(blockChainClient, rpcClient, node, transactionConverter)
-		block, err := blockChain.GetBlockByNumber(1071819)
+		block, err := blockChain.GetBlockByNumber(context.Background(), 1071819)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(block.Reward).To(Equal("5313550000000000000"))
 	})
@@ -54,7 +55,7 @@ var _ = Describe("Rewards calculations", func() {
 		node := node.MakeNode(rpcClient)
 		transactionConverter := vRpc.NewRpcTransactionConverter(ethClient)
 		blockChain := eth.NewBlockChain(blockChainClient, rpcClient, node, transactionConverter)
-		block, err := blockChain.GetBlockByNumber(1071819)
+		block, err := blockChain.GetBlockByNumber(context.Background(), 1071819)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(block.UnclesReward).To(Equal("6875000000000000000"))
 	})
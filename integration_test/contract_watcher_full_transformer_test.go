@@ -17,6 +17,7 @@
 package integration
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"strings"
@@ -111,7 +112,7 @@ var _ = Describe("contractWatcher full transformer", func() {
 			err = t.Init()
 			Expect(err).ToNot(HaveOccurred())
 
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 
 			log := test_helpers.TransferLog{}
@@ -138,7 +139,7 @@ var _ = Describe("contractWatcher full transformer", func() {
 			c, ok := t.Contracts[tusdAddr]
 			Expect(ok).To(Equal(true))
 
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 
 			b, ok := c.EmittedAddrs[common.HexToAddress("0x000000000000000000000000000000000000Af21")]
@@ -172,7 +173,7 @@ var _ = Describe("contractWatcher full transformer", func() {
 			err = t.Init()
 			Expect(err).ToNot(HaveOccurred())
 
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 
 			res := test_helpers.BalanceOf{}
@@ -195,7 +196,7 @@ var _ = Describe("contractWatcher full transformer", func() {
 		It("Fails if initialization has not been done", func() {
 			t := transformer.NewTransformer(test_helpers.TusdConfig, blockChain, db)
 
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("transformer has no initialized contracts to work with"))
 		})
@@ -215,7 +216,7 @@ var _ = Describe("contractWatcher full transformer", func() {
 			err = t.Init()
 			Expect(err).ToNot(HaveOccurred())
 
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 
 			log := test_helpers.NewOwnerLog{}
@@ -242,7 +243,7 @@ var _ = Describe("contractWatcher full transformer", func() {
 			c, ok := t.Contracts[ensAddr]
 			Expect(ok).To(Equal(true))
 
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(c.EmittedHashes)).To(Equal(3))
 
@@ -269,7 +270,7 @@ var _ = Describe("contractWatcher full transformer", func() {
 			err = t.Init()
 			Expect(err).ToNot(HaveOccurred())
 
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 
 			res := test_helpers.Owner{}
@@ -299,7 +300,7 @@ var _ = Describe("contractWatcher full transformer", func() {
 			err = t.Init()
 			Expect(err).ToNot(HaveOccurred())
 
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 
 			log := test_helpers.HeaderSyncNewOwnerLog{}
@@ -321,7 +322,7 @@ var _ = Describe("contractWatcher full transformer", func() {
 			err = t.Init()
 			Expect(err).ToNot(HaveOccurred())
 
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 
 			res := test_helpers.Owner{}
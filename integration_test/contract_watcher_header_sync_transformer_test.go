@@ -17,6 +17,7 @@
 package integration
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -103,11 +104,11 @@ var _ = Describe("contractWatcher headerSync transformer", func() {
 
 	Describe("Execute- against TrueUSD contract", func() {
 		BeforeEach(func() {
-			header1, err := blockChain.GetHeaderByNumber(6791668)
+			header1, err := blockChain.GetHeaderByNumber(context.Background(), 6791668)
 			Expect(err).ToNot(HaveOccurred())
-			header2, err := blockChain.GetHeaderByNumber(6791669)
+			header2, err := blockChain.GetHeaderByNumber(context.Background(), 6791669)
 			Expect(err).ToNot(HaveOccurred())
-			header3, err := blockChain.GetHeaderByNumber(6791670)
+			header3, err := blockChain.GetHeaderByNumber(context.Background(), 6791670)
 			Expect(err).ToNot(HaveOccurred())
 			headerRepository.CreateOrUpdateHeader(header1)
 			headerID, err = headerRepository.CreateOrUpdateHeader(header2)
@@ -119,7 +120,7 @@ var _ = Describe("contractWatcher headerSync transformer", func() {
 			t := transformer.NewTransformer(test_helpers.TusdConfig, blockChain, db)
 			err = t.Init()
 			Expect(err).ToNot(HaveOccurred())
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 
 			log := test_helpers.HeaderSyncTransferLog{}
@@ -143,7 +144,7 @@ var _ = Describe("contractWatcher headerSync transformer", func() {
 			Expect(err).ToNot(HaveOccurred())
 			c, ok := t.Contracts[tusdAddr]
 			Expect(ok).To(Equal(true))
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(c.EmittedAddrs)).To(Equal(4))
 			Expect(len(c.EmittedHashes)).To(Equal(0))
@@ -186,7 +187,7 @@ var _ = Describe("contractWatcher headerSync transformer", func() {
 			t := transformer.NewTransformer(testConf, blockChain, db)
 			err = t.Init()
 			Expect(err).ToNot(HaveOccurred())
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 
 			res := test_helpers.BalanceOf{}
@@ -202,7 +203,7 @@ var _ = Describe("contractWatcher headerSync transformer", func() {
 
 		It("Fails if initialization has not been done", func() {
 			t := transformer.NewTransformer(test_helpers.TusdConfig, blockChain, db)
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("transformer has no initialized contracts"))
 		})
@@ -210,11 +211,11 @@ var _ = Describe("contractWatcher headerSync transformer", func() {
 
 	Describe("Execute- against ENS registry contract", func() {
 		BeforeEach(func() {
-			header1, err := blockChain.GetHeaderByNumber(6885695)
+			header1, err := blockChain.GetHeaderByNumber(context.Background(), 6885695)
 			Expect(err).ToNot(HaveOccurred())
-			header2, err := blockChain.GetHeaderByNumber(6885696)
+			header2, err := blockChain.GetHeaderByNumber(context.Background(), 6885696)
 			Expect(err).ToNot(HaveOccurred())
-			header3, err := blockChain.GetHeaderByNumber(6885697)
+			header3, err := blockChain.GetHeaderByNumber(context.Background(), 6885697)
 			Expect(err).ToNot(HaveOccurred())
 			headerRepository.CreateOrUpdateHeader(header1)
 			headerID, err = headerRepository.CreateOrUpdateHeader(header2)
@@ -226,7 +227,7 @@ var _ = Describe("contractWatcher headerSync transformer", func() {
 			t := transformer.NewTransformer(test_helpers.ENSConfig, blockChain, db)
 			err = t.Init()
 			Expect(err).ToNot(HaveOccurred())
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 			Expect(t.Start).To(Equal(int64(6885698)))
 
@@ -251,7 +252,7 @@ var _ = Describe("contractWatcher headerSync transformer", func() {
 			Expect(err).ToNot(HaveOccurred())
 			c, ok := t.Contracts[ensAddr]
 			Expect(ok).To(Equal(true))
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(c.EmittedHashes)).To(Equal(2))
 			Expect(len(c.EmittedAddrs)).To(Equal(0))
@@ -278,7 +279,7 @@ var _ = Describe("contractWatcher headerSync transformer", func() {
 			t := transformer.NewTransformer(testConf, blockChain, db)
 			err = t.Init()
 			Expect(err).ToNot(HaveOccurred())
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 
 			res := test_helpers.Owner{}
@@ -306,7 +307,7 @@ var _ = Describe("contractWatcher headerSync transformer", func() {
 			t := transformer.NewTransformer(testConf, blockChain, db)
 			err = t.Init()
 			Expect(err).ToNot(HaveOccurred())
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 
 			log := test_helpers.HeaderSyncNewOwnerLog{}
@@ -327,7 +328,7 @@ var _ = Describe("contractWatcher headerSync transformer", func() {
 			t := transformer.NewTransformer(testConf, blockChain, db)
 			err = t.Init()
 			Expect(err).ToNot(HaveOccurred())
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 
 			res := test_helpers.Owner{}
@@ -345,7 +346,7 @@ var _ = Describe("contractWatcher headerSync transformer", func() {
 	Describe("Execute- against both ENS and TrueUSD", func() {
 		BeforeEach(func() {
 			for i := 6885692; i <= 6885701; i++ {
-				header, err := blockChain.GetHeaderByNumber(int64(i))
+				header, err := blockChain.GetHeaderByNumber(context.Background(), int64(i))
 				Expect(err).ToNot(HaveOccurred())
 				_, err = headerRepository.CreateOrUpdateHeader(header)
 				Expect(err).ToNot(HaveOccurred())
@@ -356,7 +357,7 @@ var _ = Describe("contractWatcher headerSync transformer", func() {
 			t := transformer.NewTransformer(test_helpers.ENSandTusdConfig, blockChain, db)
 			err = t.Init()
 			Expect(err).ToNot(HaveOccurred())
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 			Expect(t.Start).To(Equal(int64(6885702)))
 
@@ -381,7 +382,7 @@ var _ = Describe("contractWatcher headerSync transformer", func() {
 			t := transformer.NewTransformer(test_helpers.ENSandTusdConfig, blockChain, db)
 			err = t.Init()
 			Expect(err).ToNot(HaveOccurred())
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 			Expect(t.Start).To(Equal(int64(6885702)))
 
@@ -427,7 +428,7 @@ var _ = Describe("contractWatcher headerSync transformer", func() {
 			Expect(ok).To(Equal(true))
 			tusd, ok := t.Contracts[tusdAddr]
 			Expect(ok).To(Equal(true))
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(ens.EmittedHashes)).To(Equal(2))
 			Expect(len(ens.EmittedAddrs)).To(Equal(0))
@@ -464,7 +465,7 @@ var _ = Describe("contractWatcher headerSync transformer", func() {
 			t := transformer.NewTransformer(testConf, blockChain, db)
 			err = t.Init()
 			Expect(err).ToNot(HaveOccurred())
-			err = t.Execute()
+			err = t.Execute(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 
 			owner := test_helpers.Owner{}
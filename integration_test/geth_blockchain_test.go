@@ -17,6 +17,7 @@
 package integration_test
 
 import (
+	"context"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -49,7 +50,7 @@ var _ = Describe("Reading from the Geth blockchain", func() {
 
 	It("reads two blocks", func(done Done) {
 		blocks := fakes.NewMockBlockRepository()
-		lastBlock, err := blockChain.LastBlock()
+		lastBlock, err := blockChain.LastBlock(context.Background())
 		Expect(err).NotTo(HaveOccurred())
 
 		queriedBlocks := []int64{lastBlock.Int64() - 5, lastBlock.Int64() - 6}
@@ -61,11 +62,11 @@ var _ = Describe("Reading from the Geth blockchain", func() {
 	}, 30)
 
 	It("retrieves the genesis block and first block", func(done Done) {
-		genesisBlock, err := blockChain.GetBlockByNumber(int64(0))
+		genesisBlock, err := blockChain.GetBlockByNumber(context.Background(), int64(0))
 		Expect(err).ToNot(HaveOccurred())
-		firstBlock, err := blockChain.GetBlockByNumber(int64(1))
+		firstBlock, err := blockChain.GetBlockByNumber(context.Background(), int64(1))
 		Expect(err).ToNot(HaveOccurred())
-		lastBlockNumber, err := blockChain.LastBlock()
+		lastBlockNumber, err := blockChain.LastBlock(context.Background())
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(genesisBlock.Number).To(Equal(int64(0)))
@@ -89,7 +90,7 @@ var _ = Describe("Reading from the Geth blockchain", func() {
 	It("retrieves transaction", func() {
 		// actual transaction: https://etherscan.io/tx/0x44d462f2a19ad267e276b234a62c542fc91c974d2e4754a325ca405f95440255
 		txHash := common.HexToHash("0x44d462f2a19ad267e276b234a62c542fc91c974d2e4754a325ca405f95440255")
-		transactions, err := blockChain.GetTransactions([]common.Hash{txHash})
+		transactions, err := blockChain.GetTransactions(context.Background(), []common.Hash{txHash})
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(len(transactions)).To(Equal(1))
@@ -127,7 +128,7 @@ var _ = Describe("Reading from the Geth blockchain", func() {
 			var blocks []core.Block
 			n := 10
 			for i := 5327459; i > 5327459-n; i-- {
-				block, err := blockChain.GetBlockByNumber(int64(i))
+				block, err := blockChain.GetBlockByNumber(context.Background(), int64(i))
 				Expect(err).ToNot(HaveOccurred())
 				blocks = append(blocks, block)
 			}
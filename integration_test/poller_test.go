@@ -17,6 +17,7 @@
 package integration_test
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -56,7 +57,7 @@ var _ = Describe("Poller", func() {
 				con.StartingBlock = 6707322
 				con.AddEmittedAddr(common.HexToAddress("0xfE9e8709d3215310075d67E3ed32A380CCf451C8"), common.HexToAddress("0x3f5CE5FBFe3E9af3971dD833D26bA9b5C936f0bE"))
 
-				err := contractPoller.PollContract(*con, 6707323)
+				err := contractPoller.PollContract(context.Background(), *con, 6707323)
 				Expect(err).ToNot(HaveOccurred())
 
 				scanStruct := test_helpers.BalanceOf{}
@@ -88,7 +89,7 @@ var _ = Describe("Poller", func() {
 				Expect(len(con.Methods)).To(Equal(1))
 				con.AddEmittedHash(common.HexToHash("0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"), common.HexToHash("0x7e74a86b6e146964fb965db04dc2590516da77f720bb6759337bf5632415fd86"))
 
-				err := contractPoller.PollContractAt(*con, 6885877)
+				err := contractPoller.PollContractAt(context.Background(), *con, 6885877)
 				Expect(err).ToNot(HaveOccurred())
 
 				scanStruct := test_helpers.Owner{}
@@ -110,7 +111,7 @@ var _ = Describe("Poller", func() {
 				con.StartingBlock = 6707322
 				con.AddEmittedAddr(common.HexToAddress("0xfE9e8709d3215310075d67E3ed32A380CCf451C8"), common.HexToAddress("0x3f5CE5FBFe3E9af3971dD833D26bA9b5C936f0bE"))
 
-				err := contractPoller.PollContract(*con, 6707323)
+				err := contractPoller.PollContract(context.Background(), *con, 6707323)
 				Expect(err).ToNot(HaveOccurred())
 
 				scanStruct := test_helpers.BalanceOf{}
@@ -123,7 +124,7 @@ var _ = Describe("Poller", func() {
 		Describe("FetchContractData", func() {
 			It("Calls a single contract method", func() {
 				var name = new(string)
-				err := contractPoller.FetchContractData(constants.TusdAbiString, constants.TusdContractAddress, "name", nil, &name, 6197514)
+				err := contractPoller.FetchContractData(context.Background(), constants.TusdAbiString, constants.TusdContractAddress, "name", nil, &name, 6197514)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(*name).To(Equal("TrueUSD"))
 			})
@@ -143,7 +144,7 @@ var _ = Describe("Poller", func() {
 				con.StartingBlock = 6707322
 				con.AddEmittedAddr(common.HexToAddress("0xfE9e8709d3215310075d67E3ed32A380CCf451C8"), common.HexToAddress("0x3f5CE5FBFe3E9af3971dD833D26bA9b5C936f0bE"))
 
-				err := contractPoller.PollContract(*con, 6707323)
+				err := contractPoller.PollContract(context.Background(), *con, 6707323)
 				Expect(err).ToNot(HaveOccurred())
 
 				scanStruct := test_helpers.BalanceOf{}
@@ -175,7 +176,7 @@ var _ = Describe("Poller", func() {
 				Expect(len(con.Methods)).To(Equal(1))
 				con.AddEmittedHash(common.HexToHash("0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"), common.HexToHash("0x7e74a86b6e146964fb965db04dc2590516da77f720bb6759337bf5632415fd86"))
 
-				err := contractPoller.PollContractAt(*con, 6885877)
+				err := contractPoller.PollContractAt(context.Background(), *con, 6885877)
 				Expect(err).ToNot(HaveOccurred())
 
 				scanStruct := test_helpers.Owner{}
@@ -197,7 +198,7 @@ var _ = Describe("Poller", func() {
 				con.StartingBlock = 6707322
 				con.AddEmittedAddr(common.HexToAddress("0xfE9e8709d3215310075d67E3ed32A380CCf451C8"), common.HexToAddress("0x3f5CE5FBFe3E9af3971dD833D26bA9b5C936f0bE"))
 
-				err := contractPoller.PollContract(*con, 6707323)
+				err := contractPoller.PollContract(context.Background(), *con, 6707323)
 				Expect(err).ToNot(HaveOccurred())
 
 				scanStruct := test_helpers.BalanceOf{}
@@ -213,7 +214,7 @@ var _ = Describe("Poller", func() {
 				con.EmittedAddrs = map[interface{}]bool{}
 				con.Piping = false
 				con.AddEmittedHash(common.HexToHash("0x495b6e6efdedb750aa519919b5cf282bdaa86067b82a2293a3ff5723527141e8"))
-				err := contractPoller.PollContract(*con, 6921968)
+				err := contractPoller.PollContract(context.Background(), *con, 6921968)
 				Expect(err).ToNot(HaveOccurred())
 
 				scanStruct := test_helpers.Resolver{}
@@ -228,7 +229,7 @@ var _ = Describe("Poller", func() {
 				contractPoller = poller.NewPoller(bc, db, types.HeaderSync)
 
 				con.Piping = true
-				err = contractPoller.PollContract(*con, 6921968)
+				err = contractPoller.PollContract(context.Background(), *con, 6921968)
 				Expect(err).ToNot(HaveOccurred())
 
 				err = db.QueryRowx(fmt.Sprintf("SELECT * FROM header_%s.resolver_method WHERE node_ = '0x495b6e6efdedb750aa519919b5cf282bdaa86067b82a2293a3ff5723527141e8' AND block = '6921967'", constants.EnsContractAddress)).StructScan(&scanStruct)
@@ -243,7 +244,7 @@ var _ = Describe("Poller", func() {
 		Describe("FetchContractData", func() {
 			It("Calls a single contract method", func() {
 				var name = new(string)
-				err := contractPoller.FetchContractData(constants.TusdAbiString, constants.TusdContractAddress, "name", nil, &name, 6197514)
+				err := contractPoller.FetchContractData(context.Background(), constants.TusdAbiString, constants.TusdContractAddress, "name", nil, &name, 6197514)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(*name).To(Equal("TrueUSD"))
 			})
@@ -29,16 +29,22 @@ type Chunker interface {
 }
 
 type LogChunker struct {
-	AddressToNames map[string][]string
-	NameToTopic0   map[string]common.Hash
+	AddressToNames      map[string][]string
+	NameToEndingBlock   map[string]int64
+	NameToStartingBlock map[string]int64
+	NameToTopic0        map[string]common.Hash
+	NameToTopicFilters  map[string][][]common.Hash
 }
 
 // Returns a new log chunker with initialised maps.
 // Needs to have configs added with `AddConfigs` to consider logs for the respective transformer.
 func NewLogChunker() *LogChunker {
 	return &LogChunker{
-		AddressToNames: map[string][]string{},
-		NameToTopic0:   map[string]common.Hash{},
+		AddressToNames:      map[string][]string{},
+		NameToEndingBlock:   map[string]int64{},
+		NameToStartingBlock: map[string]int64{},
+		NameToTopic0:        map[string]common.Hash{},
+		NameToTopicFilters:  map[string][][]common.Hash{},
 	}
 }
 
@@ -49,9 +55,17 @@ func (chunker *LogChunker) AddConfig(transformerConfig transformer.EventTransfor
 		chunker.AddressToNames[lowerCaseAddress] = append(chunker.AddressToNames[lowerCaseAddress], transformerConfig.TransformerName)
 		chunker.NameToTopic0[transformerConfig.TransformerName] = common.HexToHash(transformerConfig.Topic)
 	}
+	chunker.NameToTopicFilters[transformerConfig.TransformerName] = transformerConfig.TopicFilters()
+	chunker.NameToStartingBlock[transformerConfig.TransformerName] = transformerConfig.StartingBlockNumber
+	if transformerConfig.IsIndefinite() {
+		chunker.NameToEndingBlock[transformerConfig.TransformerName] = -1
+	} else {
+		chunker.NameToEndingBlock[transformerConfig.TransformerName] = transformerConfig.EndingBlockNumber
+	}
 }
 
-// Goes through a slice of logs, associating relevant logs (matching addresses and topic) with transformers
+// Goes through a slice of logs, associating relevant logs (matching addresses and topics, within the transformer's
+// block range) with transformers
 func (chunker *LogChunker) ChunkLogs(logs []core.HeaderSyncLog) map[string][]core.HeaderSyncLog {
 	chunks := map[string][]core.HeaderSyncLog{}
 	for _, log := range logs {
@@ -59,10 +73,39 @@ func (chunker *LogChunker) ChunkLogs(logs []core.HeaderSyncLog) map[string][]cor
 		relevantTransformers := chunker.AddressToNames[strings.ToLower(log.Log.Address.Hex())]
 
 		for _, t := range relevantTransformers {
-			if chunker.NameToTopic0[t] == log.Log.Topics[0] {
+			if chunker.NameToTopic0[t] == log.Log.Topics[0] && chunker.matchesTopicFilters(t, log.Log.Topics) &&
+				chunker.watchesBlock(t, int64(log.Log.BlockNumber)) {
 				chunks[t] = append(chunks[t], log)
 			}
 		}
 	}
 	return chunks
 }
+
+func (chunker *LogChunker) watchesBlock(transformerName string, blockNumber int64) bool {
+	endingBlock := chunker.NameToEndingBlock[transformerName]
+	return blockNumber >= chunker.NameToStartingBlock[transformerName] && (endingBlock == -1 || blockNumber <= endingBlock)
+}
+
+// Checks topic1 to topic3 against the transformer's filters; an empty filter matches any topic in its position
+func (chunker *LogChunker) matchesTopicFilters(transformerName string, topics []common.Hash) bool {
+	for i, filter := range chunker.NameToTopicFilters[transformerName] {
+		if len(filter) == 0 {
+			continue
+		}
+		position := i + 1
+		if len(topics) <= position || !containsHash(filter, topics[position]) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsHash(hashes []common.Hash, target common.Hash) bool {
+	for _, hash := range hashes {
+		if hash == target {
+			return true
+		}
+	}
+	return false
+}
@@ -106,6 +106,84 @@ var _ = Describe("Log chunker", func() {
 			Expect(chunks["TransformerB"]).To(BeEmpty())
 			Expect(chunks["TransformerC"]).To(ContainElement(log5))
 		})
+
+		It("only associates logs within the transformer's block range", func() {
+			configD := transformer.EventTransformerConfig{
+				TransformerName:     "TransformerD",
+				ContractAddresses:   []string{"0x000000000000000000000000000000000000000D"},
+				Topic:               "0xD",
+				StartingBlockNumber: 10,
+				EndingBlockNumber:   20,
+			}
+			chunker.AddConfig(configD)
+			logBeforeStart := newTransformerDLog(9)
+			logAtStart := newTransformerDLog(10)
+			logAtEnd := newTransformerDLog(20)
+			logAfterEnd := newTransformerDLog(21)
+
+			chunks := chunker.ChunkLogs([]core.HeaderSyncLog{logBeforeStart, logAtStart, logAtEnd, logAfterEnd})
+
+			Expect(chunks["TransformerD"]).To(Equal([]core.HeaderSyncLog{logAtStart, logAtEnd}))
+		})
+
+		It("associates logs indefinitely with transformers without an ending block", func() {
+			configD := transformer.EventTransformerConfig{
+				TransformerName:     "TransformerD",
+				ContractAddresses:   []string{"0x000000000000000000000000000000000000000D"},
+				Topic:               "0xD",
+				StartingBlockNumber: 10,
+				EndingBlockNumber:   -1,
+			}
+			chunker.AddConfig(configD)
+			log := newTransformerDLog(1000000)
+
+			chunks := chunker.ChunkLogs([]core.HeaderSyncLog{log})
+
+			Expect(chunks["TransformerD"]).To(Equal([]core.HeaderSyncLog{log}))
+		})
+
+		It("only associates logs matching the transformer's topic filters", func() {
+			configD := transformer.EventTransformerConfig{
+				TransformerName:   "TransformerD",
+				ContractAddresses: []string{"0x000000000000000000000000000000000000000D"},
+				Topic:             "0xD",
+				Topic1:            []string{"0x11", "0x12"},
+				Topic3:            []string{"0x31"},
+			}
+			chunker.AddConfig(configD)
+			matchingLog := newTransformerDLogWithTopics("0x12", "0x99", "0x31")
+			wrongTopic1Log := newTransformerDLogWithTopics("0x13", "0x99", "0x31")
+			wrongTopic3Log := newTransformerDLogWithTopics("0x11", "0x99", "0x32")
+			missingTopic3Log := newTransformerDLogWithTopics("0x11", "0x99")
+
+			chunks := chunker.ChunkLogs([]core.HeaderSyncLog{matchingLog, wrongTopic1Log, wrongTopic3Log, missingTopic3Log})
+
+			Expect(chunks["TransformerD"]).To(Equal([]core.HeaderSyncLog{matchingLog}))
+		})
+
+		It("routes logs sharing address and topic0 by their topic filters", func() {
+			configD := transformer.EventTransformerConfig{
+				TransformerName:   "TransformerD",
+				ContractAddresses: []string{"0x000000000000000000000000000000000000000D"},
+				Topic:             "0xD",
+				Topic1:            []string{"0x11"},
+			}
+			configE := transformer.EventTransformerConfig{
+				TransformerName:   "TransformerE",
+				ContractAddresses: []string{"0x000000000000000000000000000000000000000D"},
+				Topic:             "0xD",
+				Topic1:            []string{"0x12"},
+			}
+			chunker.AddConfig(configD)
+			chunker.AddConfig(configE)
+			logD := newTransformerDLogWithTopics("0x11")
+			logE := newTransformerDLogWithTopics("0x12")
+
+			chunks := chunker.ChunkLogs([]core.HeaderSyncLog{logD, logE})
+
+			Expect(chunks["TransformerD"]).To(Equal([]core.HeaderSyncLog{logD}))
+			Expect(chunks["TransformerE"]).To(Equal([]core.HeaderSyncLog{logE}))
+		})
 	})
 })
 
@@ -161,3 +239,21 @@ var (
 		},
 	}
 )
+
+func newTransformerDLog(blockNumber uint64) core.HeaderSyncLog {
+	return core.HeaderSyncLog{
+		Log: types.Log{
+			Address:     common.HexToAddress("0xD"),
+			Topics:      []common.Hash{common.HexToHash("0xD")},
+			BlockNumber: blockNumber,
+		},
+	}
+}
+
+func newTransformerDLogWithTopics(topics ...string) core.HeaderSyncLog {
+	log := newTransformerDLog(0)
+	for _, topic := range topics {
+		log.Log.Topics = append(log.Log.Topics, common.HexToHash(topic))
+	}
+	return log
+}
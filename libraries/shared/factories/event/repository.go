@@ -19,14 +19,14 @@ package event
 import (
 	"database/sql/driver"
 	"fmt"
-	"github.com/makerdao/vulcanizedb/utils"
 	"strings"
 
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
 	"github.com/sirupsen/logrus"
 )
 
-// SetLogTransformedQuery marks the log as transformed in the database
+// SetLogTransformedQuery marks the log as transformed in the database. PersistModels no longer runs it - logs are marked
+// transformed by the LogDelegator once every transformer they are relevant to has processed them.
 const SetLogTransformedQuery = `UPDATE public.header_sync_logs SET transformed = true WHERE id = $1`
 
 // ErrEmptyModelSlice is returned when PersistModel gets 0 InsertionModels
@@ -155,13 +155,6 @@ func PersistModels(models []InsertionModel, db *postgres.DB) error {
 			}
 			return execErr
 		}
-
-		_, logErr := tx.Exec(SetLogTransformedQuery, model.ColumnValues[LogFK])
-
-		if logErr != nil {
-			utils.RollbackAndLogFailure(tx, logErr, "header_sync_logs.transformed")
-			return logErr
-		}
 	}
 
 	return tx.Commit()
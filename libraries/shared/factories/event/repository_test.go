@@ -186,14 +186,14 @@ var _ = Describe("Repository", func() {
 			Expect(actualQuery).To(Equal(expectedQuery))
 		})
 
-		It("marks log transformed", func() {
+		It("leaves marking the log transformed to the log delegator", func() {
 			createErr := event.PersistModels([]event.InsertionModel{testModel}, db)
 			Expect(createErr).NotTo(HaveOccurred())
 
 			var logTransformed bool
 			getErr := db.Get(&logTransformed, `SELECT transformed FROM public.header_sync_logs WHERE id = $1`, logID)
 			Expect(getErr).NotTo(HaveOccurred())
-			Expect(logTransformed).To(BeTrue())
+			Expect(logTransformed).To(BeFalse())
 		})
 	})
 })
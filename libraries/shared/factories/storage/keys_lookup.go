@@ -27,13 +27,25 @@ type KeysLookup interface {
 	SetDB(db *postgres.DB)
 }
 
+// DynamicKeysLookup is a KeysLookup that maps the data slots of String and Bytes values once their length is observed
+type DynamicKeysLookup interface {
+	KeysLookup
+	GetDynamicLengthKeys() ([]common.Hash, error)
+	AddDynamicDataKeys(lengthKey common.Hash, numberOfDataSlots int)
+}
+
 type keysLookup struct {
-	loader   KeysLoader
-	mappings map[common.Hash]storage.ValueMetadata
+	loader            KeysLoader
+	mappings          map[common.Hash]storage.ValueMetadata
+	numberOfDataSlots map[common.Hash]int
 }
 
 func NewKeysLookup(loader KeysLoader) KeysLookup {
-	return &keysLookup{loader: loader, mappings: make(map[common.Hash]storage.ValueMetadata)}
+	return &keysLookup{
+		loader:            loader,
+		mappings:          make(map[common.Hash]storage.ValueMetadata),
+		numberOfDataSlots: make(map[common.Hash]int),
+	}
 }
 
 func (lookup *keysLookup) Lookup(key common.Hash) (storage.ValueMetadata, error) {
@@ -57,10 +69,40 @@ func (lookup *keysLookup) refreshMappings() error {
 	if err != nil {
 		return err
 	}
-	lookup.mappings = storage.AddHashedKeys(lookup.mappings)
+	lookup.mappings = storage.AddHashedKeys(storage.AddDynamicLengthKeys(lookup.mappings))
+	for lengthKey, numberOfDataSlots := range lookup.numberOfDataSlots {
+		lookup.mappings = storage.AddDynamicDataKeys(lookup.mappings, lengthKey, numberOfDataSlots)
+	}
 	return nil
 }
 
+// GetDynamicLengthKeys returns the keys of the length slots of the String and Bytes values
+func (lookup *keysLookup) GetDynamicLengthKeys() ([]common.Hash, error) {
+	if len(lookup.mappings) == 0 {
+		refreshErr := lookup.refreshMappings()
+		if refreshErr != nil {
+			return nil, refreshErr
+		}
+	}
+	var lengthKeys []common.Hash
+	for key, metadata := range lookup.mappings {
+		if metadata.Type.IsDynamic() && metadata.LengthKey == key {
+			lengthKeys = append(lengthKeys, key)
+		}
+	}
+	return lengthKeys, nil
+}
+
+// AddDynamicDataKeys maps the data slots of the value whose length is stored at lengthKey, once that length is observed
+// to take numberOfDataSlots. Data slots already mapped for a longer length stay mapped.
+func (lookup *keysLookup) AddDynamicDataKeys(lengthKey common.Hash, numberOfDataSlots int) {
+	if numberOfDataSlots <= lookup.numberOfDataSlots[lengthKey] {
+		return
+	}
+	lookup.numberOfDataSlots[lengthKey] = numberOfDataSlots
+	lookup.mappings = storage.AddDynamicDataKeys(lookup.mappings, lengthKey, numberOfDataSlots)
+}
+
 func (lookup *keysLookup) SetDB(db *postgres.DB) {
 	lookup.loader.SetDB(db)
 }
@@ -22,6 +22,7 @@ import (
 	storage_factory "github.com/makerdao/vulcanizedb/libraries/shared/factories/storage"
 	"github.com/makerdao/vulcanizedb/libraries/shared/mocks"
 	"github.com/makerdao/vulcanizedb/libraries/shared/storage"
+	"github.com/makerdao/vulcanizedb/libraries/shared/test_data"
 	"github.com/makerdao/vulcanizedb/pkg/fakes"
 	"github.com/makerdao/vulcanizedb/test_config"
 	. "github.com/onsi/ginkgo"
@@ -95,6 +96,42 @@ var _ = Describe("Storage keys lookup", func() {
 			Expect(metadata).To(Equal(fakeMetadata))
 		})
 
+		It("returns metadata for the raw and hashed data keys of dynamic values once their length is observed", func() {
+			stringMetadata := storage.GetValueMetadata("name", map[storage.Key]string{}, storage.String)
+			loader.StorageKeyMappings = map[common.Hash]storage.ValueMetadata{fakes.FakeHash: stringMetadata}
+			dataKey := storage.GetDynamicDataKeys(fakes.FakeHash, 2)[1]
+			hashedDataKey := crypto.Keccak256Hash(dataKey.Bytes())
+			_, notFoundErr := lookup.Lookup(dataKey)
+			Expect(notFoundErr).To(MatchError(storage.ErrKeyNotFound{Key: dataKey.Hex()}))
+
+			lookup.(storage_factory.DynamicKeysLookup).AddDynamicDataKeys(fakes.FakeHash, 2)
+			metadata, err := lookup.Lookup(dataKey)
+			Expect(err).NotTo(HaveOccurred())
+			hashedMetadata, hashedErr := lookup.Lookup(hashedDataKey)
+			Expect(hashedErr).NotTo(HaveOccurred())
+
+			expectedMetadata := stringMetadata
+			expectedMetadata.LengthKey = fakes.FakeHash
+			Expect(metadata).To(Equal(expectedMetadata))
+			Expect(hashedMetadata).To(Equal(expectedMetadata))
+		})
+
+		It("keeps the observed data keys of dynamic values when refreshing keys", func() {
+			stringMetadata := storage.GetValueMetadata("name", map[storage.Key]string{}, storage.String)
+			loader.StorageKeyMappings = map[common.Hash]storage.ValueMetadata{fakes.FakeHash: stringMetadata}
+			dataKey := storage.GetDynamicDataKeys(fakes.FakeHash, 1)[0]
+			_, err := lookup.Lookup(fakes.FakeHash)
+			Expect(err).NotTo(HaveOccurred())
+			lookup.(storage_factory.DynamicKeysLookup).AddDynamicDataKeys(fakes.FakeHash, 1)
+
+			_, refreshErr := lookup.Lookup(test_data.FakeHash())
+			Expect(refreshErr).To(HaveOccurred())
+			_, dataErr := lookup.Lookup(dataKey)
+
+			Expect(dataErr).NotTo(HaveOccurred())
+			Expect(loader.LoadMappingsCallCount).To(Equal(2))
+		})
+
 		It("returns key not found error if key not found", func() {
 			_, err := lookup.Lookup(fakes.FakeHash)
 
@@ -103,6 +140,29 @@ var _ = Describe("Storage keys lookup", func() {
 		})
 	})
 
+	Describe("GetDynamicLengthKeys", func() {
+		It("returns the length keys of dynamic values", func() {
+			stringMetadata := storage.GetValueMetadata("name", map[storage.Key]string{}, storage.String)
+			loader.StorageKeyMappings = map[common.Hash]storage.ValueMetadata{
+				fakes.FakeHash:          stringMetadata,
+				common.HexToHash("0x3"): fakeMetadata,
+			}
+
+			lengthKeys, err := lookup.(storage_factory.DynamicKeysLookup).GetDynamicLengthKeys()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lengthKeys).To(Equal([]common.Hash{fakes.FakeHash}))
+		})
+
+		It("returns error if loading keys fails", func() {
+			loader.LoadMappingsError = fakes.FakeError
+
+			_, err := lookup.(storage_factory.DynamicKeysLookup).GetDynamicLengthKeys()
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+	})
+
 	Describe("SetDB", func() {
 		It("sets the db on the loader", func() {
 			lookup.SetDB(test_config.NewTestDB(test_config.NewTestNode()))
@@ -0,0 +1,308 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/lib/pq"
+	sharedRepository "github.com/makerdao/vulcanizedb/libraries/shared/repository"
+	"github.com/makerdao/vulcanizedb/libraries/shared/storage"
+	"github.com/makerdao/vulcanizedb/libraries/shared/transformer"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+)
+
+// LengthColumn is the name of the key query column holding the length of a dynamic array
+const LengthColumn = "length"
+
+// LayoutConfig configures a storage transformer for a contract from its solc storage layout
+type LayoutConfig struct {
+	// Address of the contract
+	Address string `mapstructure:"address"`
+	// Path to the storageLayout JSON emitted by solc for the contract
+	StorageLayoutPath string `mapstructure:"storageLayoutPath"`
+	// Postgres schema holding a table per variable
+	Schema string `mapstructure:"schema"`
+	// Queries returning the known mapping keys and array lengths of each variable, by variable name
+	KeyQueries map[string]string `mapstructure:"keyQueries"`
+}
+
+// NewLayoutTransformer returns a storage transformer whose keys are derived from the contract's storage layout, and
+// whose values are written to a table per variable
+func NewLayoutTransformer(config LayoutConfig) (Transformer, error) {
+	raw, err := ioutil.ReadFile(config.StorageLayoutPath)
+	if err != nil {
+		return Transformer{}, err
+	}
+	layout, err := storage.ParseSolcStorageLayout(raw)
+	if err != nil {
+		return Transformer{}, err
+	}
+	return Transformer{
+		HashedAddress:     storage.HexToKeccak256Hash(common.HexToAddress(config.Address).Hex()),
+		StorageKeysLookup: NewKeysLookup(NewLayoutKeysLoader(layout, config.KeyQueries)),
+		Repository:        NewLayoutRepository(config.Schema),
+	}, nil
+}
+
+// NewLayoutTransformerInitializer is NewLayoutTransformer for plugins, which export transformer initializers
+func NewLayoutTransformerInitializer(config LayoutConfig) (transformer.StorageTransformerInitializer, error) {
+	layoutTransformer, err := NewLayoutTransformer(config)
+	if err != nil {
+		return nil, err
+	}
+	return layoutTransformer.NewTransformer, nil
+}
+
+type layoutKeysLoader struct {
+	db         *postgres.DB
+	layout     storage.SolcStorageLayout
+	keyQueries map[string]string
+}
+
+// NewLayoutKeysLoader derives the keys of the layout's variables, for the mapping keys and array lengths returned by
+// each variable's key query. A query's columns hold the keys of the variable's mappings, outermost first, and are
+// recorded in the metadata under the column's name. An array's length follows the keys of the mappings it's nested in,
+// in a column named LengthColumn.
+func NewLayoutKeysLoader(layout storage.SolcStorageLayout, keyQueries map[string]string) KeysLoader {
+	return &layoutKeysLoader{layout: layout, keyQueries: keyQueries}
+}
+
+func (loader *layoutKeysLoader) LoadMappings() (map[common.Hash]storage.ValueMetadata, error) {
+	known := make(map[string]storage.KnownValues)
+	for variable, query := range loader.keyQueries {
+		values, err := loader.getKnownValues(query)
+		if err != nil {
+			return nil, err
+		}
+		known[variable] = values
+	}
+	return loader.layout.DeriveKeys(known)
+}
+
+func (loader *layoutKeysLoader) SetDB(db *postgres.DB) {
+	loader.db = db
+}
+
+func (loader *layoutKeysLoader) getKnownValues(query string) (storage.KnownValues, error) {
+	var known storage.KnownValues
+	rows, err := loader.db.Queryx(query)
+	if err != nil {
+		return known, err
+	}
+	defer rows.Close()
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		return known, err
+	}
+	for _, column := range columns {
+		known.Keys = append(known.Keys, storage.Key(column.Name()))
+	}
+	for rows.Next() {
+		values, scanErr := rows.SliceScan()
+		if scanErr != nil {
+			return known, scanErr
+		}
+		row := make([]string, len(values))
+		for i, value := range values {
+			row[i] = toKnownValue(value, columns[i].DatabaseTypeName() == "BYTEA", columns[i].Name() == LengthColumn)
+		}
+		known.Rows = append(known.Rows, row)
+	}
+	return known, rows.Err()
+}
+
+// Keys are hex, so integer keys are converted from base 10, while lengths stay in base 10
+func toKnownValue(value interface{}, isBytes, isLength bool) string {
+	var number *big.Int
+	switch v := value.(type) {
+	case int64:
+		number = big.NewInt(v)
+	case []byte:
+		if isBytes {
+			return hexutil.Encode(v)
+		}
+		// numeric columns are returned as their text
+		if parsed, ok := new(big.Int).SetString(string(v), 10); ok {
+			number = parsed
+		} else {
+			return string(v)
+		}
+	case string:
+		if parsed, ok := new(big.Int).SetString(v, 10); ok {
+			number = parsed
+		} else {
+			return v
+		}
+	}
+	if number == nil {
+		return fmt.Sprint(value)
+	}
+	if isLength {
+		return number.String()
+	}
+	return hexutil.EncodeBig(number)
+}
+
+type layoutRepository struct {
+	db     *postgres.DB
+	schema string
+	tables map[string]bool
+}
+
+// NewLayoutRepository writes each variable's values to a table named after its metadata in the schema, creating the
+// schema and tables as needed. Tables have a text column for each of the variable's keys and for its value.
+func NewLayoutRepository(schema string) Repository {
+	return &layoutRepository{schema: schema, tables: make(map[string]bool)}
+}
+
+func (repository *layoutRepository) Create(diffID, headerID int64, metadata storage.ValueMetadata, value interface{}) error {
+	tx, err := repository.db.Beginx()
+	if err != nil {
+		return err
+	}
+	// tables are created in the transaction, so they're only recorded as existing once it commits
+	created := make(map[string]bool)
+	switch v := value.(type) {
+	case string:
+		err = repository.insert(tx, created, diffID, headerID, metadata.Name, metadata.Keys, v)
+	case map[int]string:
+		for position, item := range v {
+			table, keys := metadata.PackedNames[position], metadata.Keys
+			// elements of dynamic arrays packed into a slot are named by their index
+			if _, indexErr := strconv.Atoi(table); indexErr == nil {
+				table, keys = metadata.Name, withIndex(keys, table)
+			}
+			err = repository.insert(tx, created, diffID, headerID, table, keys, item)
+			if err != nil {
+				break
+			}
+		}
+	default:
+		err = fmt.Errorf("can't persist value of type %T for %s", value, metadata.Name)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	for tableID := range created {
+		repository.tables[tableID] = true
+	}
+	return nil
+}
+
+func (repository *layoutRepository) SetDB(db *postgres.DB) {
+	repository.db = db
+}
+
+// DeleteOrphanedValues deletes values created from orphaned diffs above the height from every table in the schema,
+// including those created by earlier runs
+func (repository *layoutRepository) DeleteOrphanedValues(afterBlockHeight int64) error {
+	var tables []string
+	err := repository.db.Select(&tables, `SELECT table_name FROM information_schema.tables WHERE table_schema = $1`,
+		repository.schema)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		_, deleteErr := sharedRepository.DeleteOrphanedStorageValues(repository.db,
+			pq.QuoteIdentifier(repository.schema)+"."+pq.QuoteIdentifier(table), afterBlockHeight)
+		if deleteErr != nil {
+			return deleteErr
+		}
+	}
+	return nil
+}
+
+func (repository *layoutRepository) insert(tx sqlExecer, created map[string]bool, diffID, headerID int64, table string, keys map[storage.Key]string, value string) error {
+	columns := getKeyColumns(keys)
+	tableID := pq.QuoteIdentifier(repository.schema) + "." + pq.QuoteIdentifier(strings.ToLower(table))
+	if !repository.tables[tableID] && !created[tableID] {
+		err := repository.createTable(tx, tableID, columns)
+		if err != nil {
+			return err
+		}
+		created[tableID] = true
+	}
+
+	names := []string{"diff_id", "header_id"}
+	placeholders := []string{"$1", "$2"}
+	args := []interface{}{diffID, headerID}
+	for _, column := range columns {
+		names = append(names, pq.QuoteIdentifier(strings.ToLower(column)))
+		placeholders = append(placeholders, "$"+strconv.Itoa(len(args)+1))
+		args = append(args, keys[storage.Key(column)])
+	}
+	names = append(names, "value")
+	placeholders = append(placeholders, "$"+strconv.Itoa(len(args)+1))
+	args = append(args, value)
+	_, err := tx.Exec(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT DO NOTHING", tableID,
+		strings.Join(names, ", "), strings.Join(placeholders, ", ")), args...)
+	return err
+}
+
+func (repository *layoutRepository) createTable(tx sqlExecer, tableID string, columns []string) error {
+	_, err := tx.Exec("CREATE SCHEMA IF NOT EXISTS " + pq.QuoteIdentifier(repository.schema))
+	if err != nil {
+		return err
+	}
+	unique := []string{"diff_id", "header_id"}
+	pgStr := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (id SERIAL PRIMARY KEY,
+		diff_id BIGINT NOT NULL REFERENCES public.storage_diff (id) ON DELETE CASCADE,
+		header_id INTEGER NOT NULL REFERENCES public.headers (id) ON DELETE CASCADE,`, tableID)
+	for _, column := range columns {
+		name := pq.QuoteIdentifier(strings.ToLower(column))
+		pgStr += fmt.Sprintf(" %s TEXT NOT NULL,", name)
+		unique = append(unique, name)
+	}
+	pgStr += fmt.Sprintf(" value TEXT NOT NULL, UNIQUE (%s))", strings.Join(unique, ", "))
+	_, err = tx.Exec(pgStr)
+	return err
+}
+
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func getKeyColumns(keys map[storage.Key]string) []string {
+	var columns []string
+	for key := range keys {
+		columns = append(columns, string(key))
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+func withIndex(keys map[storage.Key]string, index string) map[storage.Key]string {
+	result := map[storage.Key]string{storage.IndexKey: index}
+	for k, v := range keys {
+		result[k] = v
+	}
+	return result
+}
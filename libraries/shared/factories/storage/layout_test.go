@@ -0,0 +1,233 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/makerdao/vulcanizedb/libraries/shared/factories/storage"
+	vdbStorage "github.com/makerdao/vulcanizedb/libraries/shared/storage"
+	"github.com/makerdao/vulcanizedb/libraries/shared/test_data"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/test_config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Storage layouts", func() {
+	const (
+		schema    = "storage_test_layout"
+		rawLayout = `{
+			"storage": [
+				{"label": "wards", "offset": 0, "slot": "0", "type": "t_mapping(t_address,t_uint256)"},
+				{"label": "urns", "offset": 0, "slot": "1", "type": "t_mapping(t_uint256,t_address)"}
+			],
+			"types": {
+				"t_address": {"encoding": "inplace", "label": "address", "numberOfBytes": "20"},
+				"t_mapping(t_address,t_uint256)": {"encoding": "mapping", "key": "t_address", "label": "mapping(address => uint256)", "numberOfBytes": "32", "value": "t_uint256"},
+				"t_mapping(t_uint256,t_address)": {"encoding": "mapping", "key": "t_uint256", "label": "mapping(uint256 => address)", "numberOfBytes": "32", "value": "t_address"},
+				"t_uint256": {"encoding": "inplace", "label": "uint256", "numberOfBytes": "32"}
+			}
+		}`
+		guy = "0x00000000000000000000000000000000000000aa"
+	)
+
+	var db *postgres.DB
+
+	BeforeEach(func() {
+		db = test_config.NewTestDB(test_config.NewTestNode())
+		test_config.CleanTestDB(db)
+	})
+
+	Describe("NewLayoutTransformer", func() {
+		It("configures a transformer from the contract's storage layout", func() {
+			layoutFile, fileErr := ioutil.TempFile("", "layout")
+			Expect(fileErr).NotTo(HaveOccurred())
+			defer os.Remove(layoutFile.Name())
+			_, writeErr := layoutFile.WriteString(rawLayout)
+			Expect(writeErr).NotTo(HaveOccurred())
+			address := "0x00000000000000000000000000000000000000cc"
+
+			transformer, err := storage.NewLayoutTransformer(storage.LayoutConfig{
+				Address:           address,
+				StorageLayoutPath: layoutFile.Name(),
+				Schema:            schema,
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(transformer.KeccakContractAddress()).To(Equal(crypto.Keccak256Hash(common.HexToAddress(address).Bytes())))
+		})
+
+		It("returns an error if the storage layout can't be read", func() {
+			_, err := storage.NewLayoutTransformer(storage.LayoutConfig{StorageLayoutPath: "nonexistent.json"})
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("LayoutKeysLoader", func() {
+		It("derives keys for the values returned by each variable's key query", func() {
+			layout, parseErr := vdbStorage.ParseSolcStorageLayout([]byte(rawLayout))
+			Expect(parseErr).NotTo(HaveOccurred())
+			loader := storage.NewLayoutKeysLoader(layout, map[string]string{
+				"wards": `SELECT guy FROM (VALUES ('` + guy + `')) AS known (guy)`,
+				"urns":  `SELECT 10 AS id`,
+			})
+			loader.SetDB(db)
+
+			mappings, err := loader.LoadMappings()
+
+			Expect(err).NotTo(HaveOccurred())
+			wardsKey := crypto.Keccak256Hash(common.FromHex("0x000000000000000000000000" + guy[2:] + vdbStorage.IndexZero))
+			urnsKey := crypto.Keccak256Hash(common.FromHex("0x000000000000000000000000000000000000000000000000000000000000000a" + vdbStorage.IndexOne))
+			Expect(mappings).To(Equal(map[common.Hash]vdbStorage.ValueMetadata{
+				wardsKey: vdbStorage.GetValueMetadata("wards", map[vdbStorage.Key]string{"guy": guy}, vdbStorage.Uint256),
+				urnsKey:  vdbStorage.GetValueMetadata("urns", map[vdbStorage.Key]string{"id": "0xa"}, vdbStorage.Address),
+			}))
+		})
+
+		It("returns an error if a key query fails", func() {
+			loader := storage.NewLayoutKeysLoader(vdbStorage.SolcStorageLayout{}, map[string]string{"wards": "SELECT nonexistent"})
+			loader.SetDB(db)
+
+			_, err := loader.LoadMappings()
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("LayoutRepository", func() {
+		var (
+			repository       storage.Repository
+			diffID, headerID int64
+		)
+
+		BeforeEach(func() {
+			repository = storage.NewLayoutRepository(schema)
+			repository.SetDB(db)
+			var insertHeaderErr error
+			headerID, insertHeaderErr = repositories.NewHeaderRepository(db).CreateOrUpdateHeader(fakes.FakeHeader)
+			Expect(insertHeaderErr).NotTo(HaveOccurred())
+			var insertDiffErr error
+			diffID, insertDiffErr = repositories.NewStorageDiffRepository(db).CreateStorageDiff(vdbStorage.RawDiff{
+				HashedAddress: test_data.FakeHash(),
+				BlockHash:     test_data.FakeHash(),
+				BlockHeight:   1,
+				StorageKey:    test_data.FakeHash(),
+				StorageValue:  test_data.FakeHash(),
+			})
+			Expect(insertDiffErr).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			db.MustExec(`DROP SCHEMA IF EXISTS ` + schema + ` CASCADE`)
+		})
+
+		It("writes a value to its variable's table, with a column for each key", func() {
+			metadata := vdbStorage.GetValueMetadata("wards", map[vdbStorage.Key]string{"guy": guy}, vdbStorage.Uint256)
+
+			err := repository.Create(diffID, headerID, metadata, "1")
+
+			Expect(err).NotTo(HaveOccurred())
+			var result struct {
+				DiffID   int64 `db:"diff_id"`
+				HeaderID int64 `db:"header_id"`
+				Guy      string
+				Value    string
+			}
+			getErr := db.Get(&result, `SELECT diff_id, header_id, guy, value FROM `+schema+`.wards`)
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(result.DiffID).To(Equal(diffID))
+			Expect(result.HeaderID).To(Equal(headerID))
+			Expect(result.Guy).To(Equal(guy))
+			Expect(result.Value).To(Equal("1"))
+		})
+
+		It("doesn't duplicate values", func() {
+			metadata := vdbStorage.GetValueMetadata("wards", map[vdbStorage.Key]string{"guy": guy}, vdbStorage.Uint256)
+			createErr := repository.Create(diffID, headerID, metadata, "1")
+			Expect(createErr).NotTo(HaveOccurred())
+
+			createTwoErr := repository.Create(diffID, headerID, metadata, "1")
+
+			Expect(createTwoErr).NotTo(HaveOccurred())
+			var count int
+			getErr := db.Get(&count, `SELECT count(*) FROM `+schema+`.wards`)
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(count).To(Equal(1))
+		})
+
+		It("writes each value of a packed slot to its own table", func() {
+			metadata := vdbStorage.GetValueMetadataForPackedSlot("owner_live", nil, vdbStorage.PackedSlot,
+				map[int]string{0: "owner", 1: "live"},
+				map[int]vdbStorage.ValueType{0: vdbStorage.Address, 1: vdbStorage.Bool})
+
+			err := repository.Create(diffID, headerID, metadata, map[int]string{0: guy, 1: "true"})
+
+			Expect(err).NotTo(HaveOccurred())
+			var owner, live string
+			ownerErr := db.Get(&owner, `SELECT value FROM `+schema+`.owner`)
+			Expect(ownerErr).NotTo(HaveOccurred())
+			Expect(owner).To(Equal(guy))
+			liveErr := db.Get(&live, `SELECT value FROM `+schema+`.live`)
+			Expect(liveErr).NotTo(HaveOccurred())
+			Expect(live).To(Equal("true"))
+		})
+
+		It("writes the elements of a packed array to the array's table, with their index", func() {
+			metadata := vdbStorage.GetValueMetadataForPackedSlot("history", nil, vdbStorage.PackedSlot,
+				map[int]string{0: "0", 1: "1"},
+				map[int]vdbStorage.ValueType{0: vdbStorage.Uint128, 1: vdbStorage.Uint128})
+
+			err := repository.Create(diffID, headerID, metadata, map[int]string{0: "10", 1: "20"})
+
+			Expect(err).NotTo(HaveOccurred())
+			var values []string
+			getErr := db.Select(&values, `SELECT value FROM `+schema+`.history ORDER BY "index"`)
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(values).To(Equal([]string{"10", "20"}))
+		})
+
+		It("deletes values created from orphaned diffs", func() {
+			metadata := vdbStorage.GetValueMetadata("wards", map[vdbStorage.Key]string{"guy": guy}, vdbStorage.Uint256)
+			createErr := repository.Create(diffID, headerID, metadata, "1")
+			Expect(createErr).NotTo(HaveOccurred())
+			db.MustExec(`UPDATE public.storage_diff SET orphaned = TRUE WHERE id = $1`, diffID)
+
+			err := repository.(storage.OrphanedValuesRepository).DeleteOrphanedValues(0)
+
+			Expect(err).NotTo(HaveOccurred())
+			var count int
+			getErr := db.Get(&count, `SELECT count(*) FROM `+schema+`.wards`)
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(count).To(BeZero())
+		})
+
+		It("returns an error for a value of an unexpected type", func() {
+			metadata := vdbStorage.GetValueMetadata("wards", nil, vdbStorage.Uint256)
+
+			err := repository.Create(diffID, headerID, metadata, 1)
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -25,3 +25,9 @@ type Repository interface {
 	Create(diffID, headerID int64, metadata storage.ValueMetadata, value interface{}) error
 	SetDB(db *postgres.DB)
 }
+
+// OrphanedValuesRepository is implemented by repositories that can delete values created from orphaned diffs, e.g. with
+// repository.DeleteOrphanedStorageValues
+type OrphanedValuesRepository interface {
+	DeleteOrphanedValues(afterBlockHeight int64) error
+}
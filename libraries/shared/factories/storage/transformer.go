@@ -17,21 +17,31 @@
 package storage
 
 import (
+	"fmt"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/makerdao/vulcanizedb/libraries/shared/storage"
 	"github.com/makerdao/vulcanizedb/libraries/shared/transformer"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
 )
 
+// ErrMissingDiffRepository is returned for String and Bytes values by a Transformer not created with NewTransformer
+var ErrMissingDiffRepository = fmt.Errorf("storage transformer has no diff repository, create it with NewTransformer")
+
 type Transformer struct {
 	HashedAddress     common.Hash
 	StorageKeysLookup KeysLookup
 	Repository        Repository
+	DiffRepository    datastore.StorageDiffRepository
 }
 
 func (transformer Transformer) NewTransformer(db *postgres.DB) transformer.StorageTransformer {
 	transformer.StorageKeysLookup.SetDB(db)
 	transformer.Repository.SetDB(db)
+	transformer.DiffRepository = repositories.NewStorageDiffRepository(db)
 	return transformer
 }
 
@@ -39,14 +49,151 @@ func (transformer Transformer) KeccakContractAddress() common.Hash {
 	return transformer.HashedAddress
 }
 
+// DeleteOrphanedValues deletes the values created from orphaned diffs above the height, if the repository supports it
+func (transformer Transformer) DeleteOrphanedValues(afterBlockHeight int64) error {
+	if repository, ok := transformer.Repository.(OrphanedValuesRepository); ok {
+		return repository.DeleteOrphanedValues(afterBlockHeight)
+	}
+	return nil
+}
+
 func (transformer Transformer) Execute(diff storage.PersistedDiff) error {
-	metadata, lookupErr := transformer.StorageKeysLookup.Lookup(diff.StorageKey)
+	metadata, lookupErr := transformer.lookup(diff)
 	if lookupErr != nil {
 		return lookupErr
 	}
+	if metadata.Type.IsDynamic() {
+		return transformer.executeDynamic(diff, metadata)
+	}
 	value, decodeErr := storage.Decode(diff, metadata)
 	if decodeErr != nil {
 		return decodeErr
 	}
 	return transformer.Repository.Create(diff.ID, diff.HeaderID, metadata, value)
 }
+
+// The data slots of String and Bytes values are only mapped once their length is observed, so a key that isn't found
+// is looked up again after mapping the data slots of every such value for its latest length at the diff's height
+func (transformer Transformer) lookup(diff storage.PersistedDiff) (storage.ValueMetadata, error) {
+	metadata, lookupErr := transformer.StorageKeysLookup.Lookup(diff.StorageKey)
+	if _, notFound := lookupErr.(storage.ErrKeyNotFound); !notFound || transformer.DiffRepository == nil {
+		return metadata, lookupErr
+	}
+	dynamicLookup, ok := transformer.StorageKeysLookup.(DynamicKeysLookup)
+	if !ok {
+		return metadata, lookupErr
+	}
+	lengthKeys, err := dynamicLookup.GetDynamicLengthKeys()
+	if err != nil || len(lengthKeys) == 0 {
+		return metadata, lookupErr
+	}
+	lengthSlots, err := transformer.getLatestSlotValues(diff, lengthKeys)
+	if err != nil {
+		return metadata, err
+	}
+	for i, lengthKey := range lengthKeys {
+		numberOfDataSlots, slotsErr := storage.GetNumberOfDynamicDataSlots(lengthSlots[i])
+		if slotsErr == nil {
+			dynamicLookup.AddDynamicDataKeys(lengthKey, numberOfDataSlots)
+		}
+	}
+	return transformer.StorageKeysLookup.Lookup(diff.StorageKey)
+}
+
+// String and Bytes values of up to 31 bytes are decoded straight from the diff to their length slot. Longer values can
+// span several slots, each with its own diff. Their diffs are buffered (by returning an error, so that the watcher
+// queues them) until a diff to one of the value's slots from a later block has arrived. The value is then reassembled
+// from the latest value of each of its slots, and created once, for the block's last diff to one of its slots.
+func (transformer Transformer) executeDynamic(diff storage.PersistedDiff, metadata storage.ValueMetadata) error {
+	if transformer.DiffRepository == nil {
+		return ErrMissingDiffRepository
+	}
+	if diff.StorageKey == metadata.LengthKey || diff.StorageKey == crypto.Keccak256Hash(metadata.LengthKey.Bytes()) {
+		numberOfDataSlots, err := storage.GetNumberOfDynamicDataSlots(diff.StorageValue)
+		if err != nil {
+			return err
+		}
+		if numberOfDataSlots == 0 {
+			return transformer.createDynamic(diff, metadata, diff.StorageValue, nil)
+		}
+	}
+
+	keys, err := transformer.getDynamicKeys(diff, metadata)
+	if err != nil {
+		return err
+	}
+	blockComplete, err := transformer.DiffRepository.HasDiffsAfter(diff.HashedAddress, withHashedKeys(keys), diff.BlockHeight)
+	if err != nil {
+		return err
+	}
+	if !blockComplete {
+		return storage.ErrDynamicValueBuffered{Name: metadata.Name, BlockHeight: diff.BlockHeight}
+	}
+
+	// Every diff from the block has arrived, so the length slot may have changed since the keys were derived
+	keys, err = transformer.getDynamicKeys(diff, metadata)
+	if err != nil {
+		return err
+	}
+	latestDiffID, err := transformer.DiffRepository.GetLatestDiffID(diff.HashedAddress, diff.BlockHash, withHashedKeys(keys))
+	if err != nil {
+		return err
+	}
+	if diff.ID != latestDiffID {
+		return nil
+	}
+	slots, err := transformer.getLatestSlotValues(diff, keys)
+	if err != nil {
+		return err
+	}
+	return transformer.createDynamic(diff, metadata, slots[0], slots[1:])
+}
+
+// Returns the value's length key followed by the keys of the data slots its latest length at the diff's height takes
+func (transformer Transformer) getDynamicKeys(diff storage.PersistedDiff, metadata storage.ValueMetadata) ([]common.Hash, error) {
+	lengthSlots, err := transformer.getLatestSlotValues(diff, []common.Hash{metadata.LengthKey})
+	if err != nil {
+		return nil, err
+	}
+	numberOfDataSlots, err := storage.GetNumberOfDynamicDataSlots(lengthSlots[0])
+	if err != nil {
+		return nil, err
+	}
+	if dynamicLookup, ok := transformer.StorageKeysLookup.(DynamicKeysLookup); ok {
+		dynamicLookup.AddDynamicDataKeys(metadata.LengthKey, numberOfDataSlots)
+	}
+	return append([]common.Hash{metadata.LengthKey}, storage.GetDynamicDataKeys(metadata.LengthKey, numberOfDataSlots)...), nil
+}
+
+func (transformer Transformer) createDynamic(diff storage.PersistedDiff, metadata storage.ValueMetadata, lengthSlot common.Hash, dataSlots []common.Hash) error {
+	value, err := storage.DecodeDynamic(metadata.Type, lengthSlot, dataSlots)
+	if err != nil {
+		return err
+	}
+	return transformer.Repository.Create(diff.ID, diff.HeaderID, metadata, value)
+}
+
+// Diffs are keyed by either the raw or the hashed slot key, depending on their source. Slots without a diff hold zero.
+func (transformer Transformer) getLatestSlotValues(diff storage.PersistedDiff, keys []common.Hash) ([]common.Hash, error) {
+	latestValues, err := transformer.DiffRepository.GetLatestSlotValues(diff.HashedAddress, withHashedKeys(keys), diff.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]common.Hash, len(keys))
+	for i, key := range keys {
+		if value, ok := latestValues[key]; ok {
+			values[i] = value
+		} else {
+			values[i] = latestValues[crypto.Keccak256Hash(key.Bytes())]
+		}
+	}
+	return values, nil
+}
+
+func withHashedKeys(keys []common.Hash) []common.Hash {
+	result := make([]common.Hash, 0, len(keys)*2)
+	for _, key := range keys {
+		result = append(result, key, crypto.Keccak256Hash(key.Bytes()))
+	}
+	return result
+}
@@ -17,9 +17,11 @@
 package storage_test
 
 import (
+	"math/big"
 	"math/rand"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	storage_factory "github.com/makerdao/vulcanizedb/libraries/shared/factories/storage"
 	"github.com/makerdao/vulcanizedb/libraries/shared/mocks"
 	"github.com/makerdao/vulcanizedb/libraries/shared/storage"
@@ -32,16 +34,19 @@ var _ = Describe("Storage transformer", func() {
 	var (
 		storageKeysLookup *mocks.MockStorageKeysLookup
 		repository        *mocks.MockStorageRepository
+		diffRepository    *fakes.MockStorageDiffRepository
 		t                 storage_factory.Transformer
 	)
 
 	BeforeEach(func() {
 		storageKeysLookup = &mocks.MockStorageKeysLookup{}
 		repository = &mocks.MockStorageRepository{}
+		diffRepository = &fakes.MockStorageDiffRepository{}
 		t = storage_factory.Transformer{
 			HashedAddress:     common.Hash{},
 			StorageKeysLookup: storageKeysLookup,
 			Repository:        repository,
+			DiffRepository:    diffRepository,
 		}
 	})
 
@@ -67,6 +72,46 @@ var _ = Describe("Storage transformer", func() {
 		Expect(err).To(MatchError(fakes.FakeError))
 	})
 
+	Describe("when the key isn't found", func() {
+		var (
+			lengthKey = common.HexToHash("0x2")
+			notFound  = storage.ErrKeyNotFound{Key: lengthKey.Hex()}
+		)
+
+		BeforeEach(func() {
+			storageKeysLookup.LookupErr = notFound
+			storageKeysLookup.DynamicLengthKeys = []common.Hash{lengthKey}
+			diffRepository.SlotValues = map[common.Hash]common.Hash{lengthKey: common.BigToHash(big.NewInt(65*2 + 1))}
+		})
+
+		It("maps data slots for the latest length of dynamic values and looks the key up again", func() {
+			storageKeysLookup.FoundAfterAddingDataKeys = true
+			storageKeysLookup.Metadata = storage.ValueMetadata{Type: storage.Address}
+
+			err := t.Execute(storage.PersistedDiff{})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diffRepository.GetLatestSlotValuesPassedKeys).To(ConsistOf(
+				ConsistOf(lengthKey, crypto.Keccak256Hash(lengthKey.Bytes()))))
+			Expect(storageKeysLookup.AddedNumberOfDataSlots).To(Equal(map[common.Hash]int{lengthKey: 3}))
+		})
+
+		It("returns key not found if the key isn't a data slot either", func() {
+			err := t.Execute(storage.PersistedDiff{})
+
+			Expect(err).To(MatchError(notFound))
+		})
+
+		It("returns key not found without a diff repository", func() {
+			t.DiffRepository = nil
+
+			err := t.Execute(storage.PersistedDiff{})
+
+			Expect(err).To(MatchError(notFound))
+			Expect(storageKeysLookup.AddedNumberOfDataSlots).To(BeEmpty())
+		})
+	})
+
 	It("creates storage row with decoded data", func() {
 		fakeMetadata := storage.ValueMetadata{Type: storage.Address}
 		storageKeysLookup.Metadata = fakeMetadata
@@ -163,4 +208,129 @@ var _ = Describe("Storage transformer", func() {
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
 	})
+
+	Describe("when a storage row is part of a dynamic value", func() {
+		var (
+			lengthKey    = common.HexToHash("0x2")
+			dataKeys     = storage.GetDynamicDataKeys(lengthKey, 2)
+			fakeMetadata = storage.ValueMetadata{Name: "name", Type: storage.String, LengthKey: lengthKey}
+			longValue    = "a string that takes two storage slots"
+			fakeRow      = storage.PersistedDiff{
+				ID:       rand.Int63(),
+				HeaderID: rand.Int63(),
+				RawDiff: storage.RawDiff{
+					BlockHeight: rand.Int(),
+					StorageKey:  crypto.Keccak256Hash(dataKeys[1].Bytes()),
+				},
+			}
+		)
+
+		BeforeEach(func() {
+			storageKeysLookup.Metadata = fakeMetadata
+			diffRepository.HasDiffsAfterReturnExists = true
+			diffRepository.GetLatestDiffIDReturnID = fakeRow.ID
+			diffRepository.SlotValues = map[common.Hash]common.Hash{
+				lengthKey:   common.BigToHash(big.NewInt(int64(len(longValue)*2 + 1))),
+				dataKeys[0]: common.BytesToHash([]byte(longValue[:32])),
+				crypto.Keccak256Hash(dataKeys[1].Bytes()): common.BytesToHash(common.RightPadBytes([]byte(longValue[32:]), 32)),
+			}
+		})
+
+		It("buffers the row until every diff from its block has arrived", func() {
+			diffRepository.HasDiffsAfterReturnExists = false
+
+			err := t.Execute(fakeRow)
+
+			Expect(err).To(MatchError(storage.ErrDynamicValueBuffered{Name: "name", BlockHeight: fakeRow.BlockHeight}))
+			Expect(diffRepository.HasDiffsAfterPassedHeight).To(Equal(fakeRow.BlockHeight))
+			Expect(diffRepository.HasDiffsAfterPassedKeys).To(ConsistOf(lengthKey, crypto.Keccak256Hash(lengthKey.Bytes()),
+				dataKeys[0], crypto.Keccak256Hash(dataKeys[0].Bytes()), dataKeys[1], crypto.Keccak256Hash(dataKeys[1].Bytes())))
+			Expect(repository.PassedValue).To(BeNil())
+		})
+
+		It("maps the data slots its observed length takes", func() {
+			err := t.Execute(fakeRow)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storageKeysLookup.AddedNumberOfDataSlots).To(Equal(map[common.Hash]int{lengthKey: 2}))
+		})
+
+		It("creates a short value from its length slot without buffering it", func() {
+			shortValue := "short"
+			lengthRow := fakeRow
+			lengthRow.StorageKey = crypto.Keccak256Hash(lengthKey.Bytes())
+			lengthRow.StorageValue = common.BytesToHash(append(common.RightPadBytes([]byte(shortValue), 31), byte(len(shortValue)*2)))
+			diffRepository.HasDiffsAfterReturnExists = false
+
+			err := t.Execute(lengthRow)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diffRepository.HasDiffsAfterPassedKeys).To(BeNil())
+			Expect(repository.PassedDiffID).To(Equal(lengthRow.ID))
+			Expect(repository.PassedValue).To(Equal(shortValue))
+		})
+
+		It("returns an error without a diff repository", func() {
+			t.DiffRepository = nil
+
+			err := t.Execute(fakeRow)
+
+			Expect(err).To(MatchError(storage_factory.ErrMissingDiffRepository))
+		})
+
+		It("passes the value reassembled from its raw and hashed slots to the repository", func() {
+			err := t.Execute(fakeRow)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repository.PassedDiffID).To(Equal(fakeRow.ID))
+			Expect(repository.PassedHeaderID).To(Equal(fakeRow.HeaderID))
+			Expect(repository.PassedMetadata).To(Equal(fakeMetadata))
+			Expect(repository.PassedValue).To(Equal(longValue))
+		})
+
+		It("only creates the value for the last diff to one of its slots in the block", func() {
+			diffRepository.GetLatestDiffIDReturnID = fakeRow.ID + 1
+
+			err := t.Execute(fakeRow)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diffRepository.GetLatestDiffIDPassedKeys).To(ContainElement(lengthKey))
+			Expect(diffRepository.GetLatestDiffIDPassedKeys).To(ContainElement(crypto.Keccak256Hash(dataKeys[1].Bytes())))
+			Expect(repository.PassedValue).To(BeNil())
+		})
+
+		It("returns an error if getting slot values fails", func() {
+			diffRepository.GetLatestSlotValuesReturnError = fakes.FakeError
+
+			err := t.Execute(fakeRow)
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+
+		It("returns an error if creating the value fails", func() {
+			repository.CreateErr = fakes.FakeError
+
+			err := t.Execute(fakeRow)
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+	})
+
+	Describe("DeleteOrphanedValues", func() {
+		It("deletes the repository's values created from orphaned diffs above the height", func() {
+			err := t.DeleteOrphanedValues(10)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repository.DeleteOrphanedValuesCalled).To(BeTrue())
+			Expect(repository.DeleteOrphanedValuesPassedAfter).To(Equal(int64(10)))
+		})
+
+		It("returns an error if deleting the values fails", func() {
+			repository.DeleteOrphanedValuesErr = fakes.FakeError
+
+			err := t.DeleteOrphanedValues(10)
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+	})
 })
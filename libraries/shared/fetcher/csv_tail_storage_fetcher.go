@@ -17,6 +17,8 @@
 package fetcher
 
 import (
+	"context"
+
 	"github.com/makerdao/vulcanizedb/libraries/shared/storage"
 	"github.com/makerdao/vulcanizedb/pkg/fs"
 	"github.com/sirupsen/logrus"
@@ -31,18 +33,42 @@ func NewCsvTailStorageFetcher(tailer fs.Tailer) CsvTailStorageFetcher {
 	return CsvTailStorageFetcher{tailer: tailer}
 }
 
-func (storageFetcher CsvTailStorageFetcher) FetchStorageDiffs(out chan<- storage.RawDiff, errs chan<- error) {
+func (storageFetcher CsvTailStorageFetcher) FetchStorageDiffs(ctx context.Context, out chan<- storage.RawDiff, errs chan<- error) {
 	t, tailErr := storageFetcher.tailer.Tail()
 	if tailErr != nil {
-		errs <- tailErr
+		sendErr(ctx, errs, tailErr)
+		return
 	}
 	logrus.Debug("fetching storage diffs...")
-	for line := range t.Lines {
-		diff, parseErr := storage.FromParityCsvRow(strings.Split(line.Text, ","))
-		if parseErr != nil {
-			errs <- parseErr
-		} else {
-			out <- diff
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case line, ok := <-t.Lines:
+			if !ok {
+				return
+			}
+			diff, parseErr := storage.FromParityCsvRow(strings.Split(line.Text, ","))
+			if parseErr != nil {
+				sendErr(ctx, errs, parseErr)
+			} else {
+				sendDiff(ctx, out, diff)
+			}
 		}
 	}
 }
+
+// Sends err unless ctx is cancelled first, so that fetchers do not block once nothing is receiving
+func sendErr(ctx context.Context, errs chan<- error, err error) {
+	select {
+	case errs <- err:
+	case <-ctx.Done():
+	}
+}
+
+func sendDiff(ctx context.Context, out chan<- storage.RawDiff, diff storage.RawDiff) {
+	select {
+	case out <- diff:
+	case <-ctx.Done():
+	}
+}
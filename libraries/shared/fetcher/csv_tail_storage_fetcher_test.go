@@ -17,6 +17,7 @@
 package fetcher_test
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -48,7 +49,7 @@ var _ = Describe("Csv Tail Storage Fetcher", func() {
 	It("adds error to errors channel if tailing file fails", func(done Done) {
 		mockTailer.TailErr = fakes.FakeError
 
-		go storageFetcher.FetchStorageDiffs(diffsChannel, errorsChannel)
+		go storageFetcher.FetchStorageDiffs(context.Background(), diffsChannel, errorsChannel)
 
 		Expect(<-errorsChannel).To(MatchError(fakes.FakeError))
 		close(done)
@@ -57,7 +58,7 @@ var _ = Describe("Csv Tail Storage Fetcher", func() {
 	It("adds parsed csv row to rows channel for storage diff", func(done Done) {
 		line := getFakeLine()
 
-		go storageFetcher.FetchStorageDiffs(diffsChannel, errorsChannel)
+		go storageFetcher.FetchStorageDiffs(context.Background(), diffsChannel, errorsChannel)
 		mockTailer.Lines <- line
 
 		expectedRow, err := storage.FromParityCsvRow(strings.Split(line.Text, ","))
@@ -69,7 +70,7 @@ var _ = Describe("Csv Tail Storage Fetcher", func() {
 	It("adds error to errors channel if parsing csv fails", func(done Done) {
 		line := &tail.Line{Text: "invalid"}
 
-		go storageFetcher.FetchStorageDiffs(diffsChannel, errorsChannel)
+		go storageFetcher.FetchStorageDiffs(context.Background(), diffsChannel, errorsChannel)
 		mockTailer.Lines <- line
 
 		Expect(<-errorsChannel).To(HaveOccurred())
@@ -81,6 +82,15 @@ var _ = Describe("Csv Tail Storage Fetcher", func() {
 		}
 		close(done)
 	})
+
+	It("returns once the context is cancelled", func(done Done) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		storageFetcher.FetchStorageDiffs(ctx, diffsChannel, errorsChannel)
+
+		close(done)
+	})
 })
 
 func getFakeLine() *tail.Line {
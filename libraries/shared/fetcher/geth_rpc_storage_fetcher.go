@@ -15,6 +15,7 @@
 package fetcher
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/rlp"
@@ -36,23 +37,28 @@ func NewGethRpcStorageFetcher(streamer streamer.Streamer, statediffPayloadChan c
 	}
 }
 
-func (fetcher GethRpcStorageFetcher) FetchStorageDiffs(out chan<- storage.RawDiff, errs chan<- error) {
+func (fetcher GethRpcStorageFetcher) FetchStorageDiffs(ctx context.Context, out chan<- storage.RawDiff, errs chan<- error) {
 	ethStatediffPayloadChan := fetcher.statediffPayloadChan
 	clientSubscription, clientSubErr := fetcher.streamer.Stream(ethStatediffPayloadChan)
 	if clientSubErr != nil {
-		errs <- clientSubErr
+		sendErr(ctx, errs, clientSubErr)
 		panic(fmt.Sprintf("Error creating a geth client subscription: %v", clientSubErr))
 	}
 	logrus.Info("Successfully created a geth client subscription: ", clientSubscription)
 
 	for {
-		diff := <-ethStatediffPayloadChan
+		var diff statediff.Payload
+		select {
+		case <-ctx.Done():
+			return
+		case diff = <-ethStatediffPayloadChan:
+		}
 		logrus.Trace("received a statediff")
 		stateDiff := new(statediff.StateDiff)
 		decodeErr := rlp.DecodeBytes(diff.StateDiffRlp, stateDiff)
 		if decodeErr != nil {
 			logrus.Warn("Error decoding state diff into RLP: ", decodeErr)
-			errs <- decodeErr
+			sendErr(ctx, errs, decodeErr)
 		}
 
 		accounts := getAccountsFromDiff(*stateDiff)
@@ -67,10 +73,10 @@ func (fetcher GethRpcStorageFetcher) FetchStorageDiffs(out chan<- storage.RawDif
 					"storage key: ", diff.StorageKey.Hex(),
 					"storage value: ", diff.StorageValue.Hex())
 				if formatErr != nil {
-					errs <- formatErr
+					sendErr(ctx, errs, formatErr)
 				}
 
-				out <- diff
+				sendDiff(ctx, out, diff)
 			}
 		}
 	}
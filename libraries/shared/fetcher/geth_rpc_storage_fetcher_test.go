@@ -15,6 +15,7 @@
 package fetcher_test
 
 import (
+	"context"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -74,7 +75,7 @@ var _ = Describe("Geth RPC Storage Fetcher", func() {
 
 		go func() {
 			failedSub := func() {
-				statediffFetcher.FetchStorageDiffs(storagediffChan, errorChan)
+				statediffFetcher.FetchStorageDiffs(context.Background(), storagediffChan, errorChan)
 			}
 			Expect(failedSub).To(Panic())
 		}()
@@ -86,7 +87,7 @@ var _ = Describe("Geth RPC Storage Fetcher", func() {
 	It("streams StatediffPayloads from a Geth RPC subscription", func(done Done) {
 		streamer.SetPayloads([]statediff.Payload{test_data.MockStatediffPayload})
 
-		go statediffFetcher.FetchStorageDiffs(storagediffChan, errorChan)
+		go statediffFetcher.FetchStorageDiffs(context.Background(), storagediffChan, errorChan)
 
 		streamedPayload := <-statediffPayloadChan
 		Expect(streamedPayload).To(Equal(test_data.MockStatediffPayload))
@@ -98,7 +99,7 @@ var _ = Describe("Geth RPC Storage Fetcher", func() {
 		badStatediffPayload := statediff.Payload{}
 		streamer.SetPayloads([]statediff.Payload{badStatediffPayload})
 
-		go statediffFetcher.FetchStorageDiffs(storagediffChan, errorChan)
+		go statediffFetcher.FetchStorageDiffs(context.Background(), storagediffChan, errorChan)
 
 		Expect(<-errorChan).To(MatchError("EOF"))
 
@@ -108,7 +109,7 @@ var _ = Describe("Geth RPC Storage Fetcher", func() {
 	It("adds parsed statediff payloads to the rows channel", func(done Done) {
 		streamer.SetPayloads([]statediff.Payload{test_data.MockStatediffPayload})
 
-		go statediffFetcher.FetchStorageDiffs(storagediffChan, errorChan)
+		go statediffFetcher.FetchStorageDiffs(context.Background(), storagediffChan, errorChan)
 
 		height := test_data.BlockNumber
 		intHeight := int(height.Int64())
@@ -163,7 +164,7 @@ var _ = Describe("Geth RPC Storage Fetcher", func() {
 		}
 		streamer.SetPayloads([]statediff.Payload{badStatediffPayload})
 
-		go statediffFetcher.FetchStorageDiffs(storagediffChan, errorChan)
+		go statediffFetcher.FetchStorageDiffs(context.Background(), storagediffChan, errorChan)
 
 		Expect(<-errorChan).To(MatchError("rlp: input contains more than one value"))
 
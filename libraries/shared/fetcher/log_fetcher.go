@@ -17,16 +17,21 @@
 package fetcher
 
 import (
+	"context"
+	"math/big"
+
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 
 	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/eth/middleware"
+	"github.com/sirupsen/logrus"
 )
 
 type ILogFetcher interface {
-	FetchLogs(contractAddresses []common.Address, topics []common.Hash, missingHeader core.Header) ([]types.Log, error)
-	// TODO Extend FetchLogs for doing several blocks at a time
+	FetchLogs(ctx context.Context, contractAddresses []common.Address, topics [][]common.Hash, missingHeader core.Header) ([]types.Log, error)
+	FetchLogsInRange(ctx context.Context, contractAddresses []common.Address, topics [][]common.Hash, startingBlockNumber, endingBlockNumber int64) ([]types.Log, error)
 }
 
 type LogFetcher struct {
@@ -39,17 +44,18 @@ func NewLogFetcher(blockchain core.BlockChain) *LogFetcher {
 	}
 }
 
-// Checks all topic0s, on all addresses, fetching matching logs for the given header
-func (logFetcher LogFetcher) FetchLogs(addresses []common.Address, topic0s []common.Hash, header core.Header) ([]types.Log, error) {
+// Checks all topics, on all addresses, fetching matching logs for the given header. Topics are matched by position:
+// a log matches if, for each position, its topic is any of the given hashes (or the position's filter is empty).
+func (logFetcher LogFetcher) FetchLogs(ctx context.Context, addresses []common.Address, topics [][]common.Hash, header core.Header) ([]types.Log, error) {
 	blockHash := common.HexToHash(header.Hash)
 	query := ethereum.FilterQuery{
 		BlockHash: &blockHash,
 		Addresses: addresses,
-		// Search for _any_ of the topics in topic0 position; see docs on `FilterQuery`
-		Topics: [][]common.Hash{topic0s},
+		// Search for _any_ of the topics in each position; see docs on `FilterQuery`
+		Topics: topics,
 	}
 
-	logs, err := logFetcher.blockChain.GetEthLogsWithCustomQuery(query)
+	logs, err := logFetcher.blockChain.GetEthLogsWithCustomQuery(ctx, query)
 	if err != nil {
 		// TODO review aggregate fetching error handling
 		return []types.Log{}, err
@@ -57,3 +63,35 @@ func (logFetcher LogFetcher) FetchLogs(addresses []common.Address, topic0s []com
 
 	return logs, nil
 }
+
+// Checks all topics, on all addresses, fetching matching logs for the inclusive block range. If the node rejects the
+// query for matching too many logs, the range is split in half and each half is fetched separately.
+func (logFetcher LogFetcher) FetchLogsInRange(ctx context.Context, addresses []common.Address, topics [][]common.Hash, startingBlockNumber, endingBlockNumber int64) ([]types.Log, error) {
+	query := ethereum.FilterQuery{
+		FromBlock: big.NewInt(startingBlockNumber),
+		ToBlock:   big.NewInt(endingBlockNumber),
+		Addresses: addresses,
+		// Search for _any_ of the topics in each position; see docs on `FilterQuery`
+		Topics: topics,
+	}
+
+	logs, err := logFetcher.blockChain.GetEthLogsWithCustomQuery(ctx, query)
+	if err == nil {
+		return logs, nil
+	}
+	if startingBlockNumber >= endingBlockNumber || !middleware.IsRangeTooLargeErr(err) {
+		return []types.Log{}, err
+	}
+
+	midpoint := startingBlockNumber + (endingBlockNumber-startingBlockNumber)/2
+	logrus.Debugf("splitting log query for blocks %d-%d: %s", startingBlockNumber, endingBlockNumber, err.Error())
+	lowerLogs, lowerErr := logFetcher.FetchLogsInRange(ctx, addresses, topics, startingBlockNumber, midpoint)
+	if lowerErr != nil {
+		return []types.Log{}, lowerErr
+	}
+	upperLogs, upperErr := logFetcher.FetchLogsInRange(ctx, addresses, topics, midpoint+1, endingBlockNumber)
+	if upperErr != nil {
+		return []types.Log{}, upperErr
+	}
+	return append(lowerLogs, upperLogs...), nil
+}
@@ -17,6 +17,10 @@
 package fetcher_test
 
 import (
+	"context"
+	"errors"
+	"math/big"
+
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	. "github.com/onsi/ginkgo"
@@ -39,9 +43,13 @@ var _ = Describe("LogFetcher", func() {
 				common.HexToAddress("0xanotherFakeAddress"),
 			}
 
-			topicZeros := []common.Hash{common.BytesToHash([]byte{1, 2, 3, 4, 5})}
+			topics := [][]common.Hash{
+				{common.BytesToHash([]byte{1, 2, 3, 4, 5})},
+				nil,
+				{common.BytesToHash([]byte{6, 7, 8})},
+			}
 
-			_, err := logFetcher.FetchLogs(addresses, topicZeros, header)
+			_, err := logFetcher.FetchLogs(context.Background(), addresses, topics, header)
 
 			address1 := common.HexToAddress("0xfakeAddress")
 			address2 := common.HexToAddress("0xanotherFakeAddress")
@@ -51,7 +59,7 @@ var _ = Describe("LogFetcher", func() {
 			expectedQuery := ethereum.FilterQuery{
 				BlockHash: &blockHash,
 				Addresses: []common.Address{address1, address2},
-				Topics:    [][]common.Hash{topicZeros},
+				Topics:    topics,
 			}
 			blockChain.AssertGetEthLogsWithCustomQueryCalledWith(expectedQuery)
 		})
@@ -61,10 +69,82 @@ var _ = Describe("LogFetcher", func() {
 			blockChain.SetGetEthLogsWithCustomQueryErr(fakes.FakeError)
 			logFetcher := fetcher.NewLogFetcher(blockChain)
 
-			_, err := logFetcher.FetchLogs([]common.Address{}, []common.Hash{}, core.Header{})
+			_, err := logFetcher.FetchLogs(context.Background(), []common.Address{}, [][]common.Hash{}, core.Header{})
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
 	})
+	Describe("FetchLogsInRange", func() {
+		var (
+			blockChain *fakes.MockBlockChain
+			logFetcher *fetcher.LogFetcher
+			addresses  []common.Address
+			topics     [][]common.Hash
+		)
+
+		BeforeEach(func() {
+			blockChain = fakes.NewMockBlockChain()
+			logFetcher = fetcher.NewLogFetcher(blockChain)
+			addresses = []common.Address{common.HexToAddress("0xfakeAddress")}
+			topics = [][]common.Hash{{common.BytesToHash([]byte{1, 2, 3, 4, 5})}}
+		})
+
+		It("fetches logs for the given block range", func() {
+			_, err := logFetcher.FetchLogsInRange(context.Background(), addresses, topics, 10, 20)
+
+			Expect(err).NotTo(HaveOccurred())
+			expectedQuery := ethereum.FilterQuery{
+				FromBlock: big.NewInt(10),
+				ToBlock:   big.NewInt(20),
+				Addresses: addresses,
+				Topics:    topics,
+			}
+			blockChain.AssertGetEthLogsWithCustomQueryCalledWith(expectedQuery)
+		})
+
+		It("splits the range if the node returns too many results", func() {
+			tooManyResultsErr := errors.New("query returned more than 10000 results")
+			blockChain.SetGetEthLogsWithCustomQueryRangeLimit(3, tooManyResultsErr)
+
+			_, err := logFetcher.FetchLogsInRange(context.Background(), addresses, topics, 1, 10)
+
+			Expect(err).NotTo(HaveOccurred())
+			var fetchedRanges [][2]int64
+			for _, query := range blockChain.PassedLogQueries {
+				fetchedRanges = append(fetchedRanges, [2]int64{query.FromBlock.Int64(), query.ToBlock.Int64()})
+			}
+			Expect(fetchedRanges).To(Equal([][2]int64{
+				{1, 10}, {1, 5}, {1, 3}, {4, 5}, {6, 10}, {6, 8}, {9, 10},
+			}))
+		})
+
+		It("returns the error if a single block returns too many results", func() {
+			tooManyResultsErr := errors.New("query returned more than 10000 results")
+			blockChain.SetGetEthLogsWithCustomQueryRangeLimit(1, tooManyResultsErr)
+			blockChain.SetGetEthLogsWithCustomQueryErr(tooManyResultsErr)
+
+			_, err := logFetcher.FetchLogsInRange(context.Background(), addresses, topics, 1, 2)
+
+			Expect(err).To(MatchError(tooManyResultsErr))
+		})
+
+		It("does not split the range for rate limit errors", func() {
+			blockChain.SetGetEthLogsWithCustomQueryErr(errors.New("daily request count limit exceeded"))
+
+			_, err := logFetcher.FetchLogsInRange(context.Background(), addresses, topics, 1, 10)
+
+			Expect(err).To(HaveOccurred())
+			Expect(len(blockChain.PassedLogQueries)).To(Equal(1))
+		})
+
+		It("does not split the range for other errors", func() {
+			blockChain.SetGetEthLogsWithCustomQueryErr(fakes.FakeError)
+
+			_, err := logFetcher.FetchLogsInRange(context.Background(), addresses, topics, 1, 10)
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(len(blockChain.PassedLogQueries)).To(Equal(1))
+		})
+	})
 })
@@ -14,8 +14,13 @@
 
 package fetcher
 
-import "github.com/makerdao/vulcanizedb/libraries/shared/storage"
+import (
+	"context"
+
+	"github.com/makerdao/vulcanizedb/libraries/shared/storage"
+)
 
 type IStorageFetcher interface {
-	FetchStorageDiffs(out chan<- storage.RawDiff, errs chan<- error)
+	// Sends fetched diffs to out and errors to errs until ctx is cancelled
+	FetchStorageDiffs(ctx context.Context, out chan<- storage.RawDiff, errs chan<- error)
 }
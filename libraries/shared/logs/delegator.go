@@ -18,10 +18,14 @@ package logs
 
 import (
 	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/makerdao/vulcanizedb/libraries/shared/chunker"
 	"github.com/makerdao/vulcanizedb/libraries/shared/transformer"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
 	"github.com/sirupsen/logrus"
 )
 
@@ -36,9 +40,13 @@ type ILogDelegator interface {
 }
 
 type LogDelegator struct {
-	Chunker       chunker.Chunker
-	LogRepository datastore.HeaderSyncLogRepository
-	Transformers  []transformer.EventTransformer
+	BatchSize              int
+	Chunker                chunker.Chunker
+	LogRepository          datastore.HeaderSyncLogRepository
+	Transformers           []transformer.EventTransformer
+	lastBlockNumber        int64
+	lastLogID              int64
+	registeredTransformers int
 }
 
 func (delegator *LogDelegator) AddTransformer(t transformer.EventTransformer) {
@@ -46,40 +54,169 @@ func (delegator *LogDelegator) AddTransformer(t transformer.EventTransformer) {
 	delegator.Chunker.AddConfig(t.GetConfig())
 }
 
+// Delegates the next batch of untransformed logs, in block order. Each call picks up after the last log delegated, until
+// no logs remain - at which point ErrNoLogs is returned and the next call starts again from the earliest untransformed
+// log.
 func (delegator *LogDelegator) DelegateLogs() error {
 	if len(delegator.Transformers) < 1 {
 		return ErrNoTransformers
 	}
 
-	persistedLogs, fetchErr := delegator.LogRepository.GetUntransformedHeaderSyncLogs()
+	registerErr := delegator.registerNewTransformers()
+	if registerErr != nil {
+		logrus.Errorf("error marking logs for new transformers: %s", registerErr.Error())
+		return registerErr
+	}
+
+	persistedLogs, fetchErr := delegator.LogRepository.GetUntransformedHeaderSyncLogs(delegator.lastBlockNumber,
+		delegator.lastLogID, delegator.BatchSize)
 	if fetchErr != nil {
 		logrus.Errorf("error loading logs from db: %s", fetchErr.Error())
 		return fetchErr
 	}
 
 	if len(persistedLogs) < 1 {
+		delegator.lastBlockNumber, delegator.lastLogID = 0, 0
 		return ErrNoLogs
 	}
 
-	transformErr := delegator.delegateLogs(persistedLogs)
-	if transformErr != nil {
-		logrus.Errorf("error transforming logs: %s", transformErr)
-		return transformErr
+	executeErr, delegateErr := delegator.delegateLogs(persistedLogs)
+	if delegateErr != nil {
+		logrus.Errorf("error transforming logs: %s", delegateErr)
+		return delegateErr
+	}
+
+	// logs a transformer failed on are delegated to it again once the delegator starts over
+	lastLog := persistedLogs[len(persistedLogs)-1]
+	delegator.lastBlockNumber, delegator.lastLogID = int64(lastLog.Log.BlockNumber), lastLog.ID
+	return executeErr
+}
+
+// Marks logs relevant to transformers that have never been registered (in this or an earlier run) as untransformed if
+// those transformers have not processed them, so that logs already transformed by the other transformers are delegated
+// to the new ones as well.
+func (delegator *LogDelegator) registerNewTransformers() error {
+	pending := delegator.Transformers[delegator.registeredTransformers:]
+	if len(pending) < 1 {
+		return nil
+	}
+	var names []string
+	for _, t := range pending {
+		names = append(names, t.GetConfig().TransformerName)
+	}
+	newNames, getErr := delegator.LogRepository.GetNewHeaderSyncLogTransformers(names)
+	if getErr != nil {
+		return getErr
 	}
 
+	for _, t := range pending {
+		config := t.GetConfig()
+		if !containsName(newNames, config.TransformerName) {
+			continue
+		}
+		markedCount, markErr := delegator.LogRepository.MarkHeaderSyncLogsUntransformedBy(config.TransformerName,
+			config.ContractAddresses, common.HexToHash(config.Topic), config.TopicFilters())
+		if markErr != nil {
+			return markErr
+		}
+		if markedCount > 0 {
+			logrus.Infof("delegating %d previously transformed logs to %s transformer", markedCount,
+				config.TransformerName)
+		}
+	}
+
+	registerErr := delegator.LogRepository.RegisterHeaderSyncLogTransformers(names)
+	if registerErr != nil {
+		return registerErr
+	}
+	delegator.registeredTransformers += len(pending)
 	return nil
 }
 
-func (delegator *LogDelegator) delegateLogs(logs []core.HeaderSyncLog) error {
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Executes each transformer on the logs relevant to it that it has not already processed, recording which logs each
+// transformer processed. Logs are only marked transformed once every transformer they are relevant to has processed
+// them. A failing transformer does not stop the others - logs it failed on are marked untransformed, so that they are
+// delegated to it again once the delegator starts over from the earliest untransformed log, and the first transformer
+// error is returned as executeErr once the logs have been marked. Errors recording the results are returned as err.
+func (delegator *LogDelegator) delegateLogs(logs []core.HeaderSyncLog) (executeErr, err error) {
 	chunkedLogs := delegator.Chunker.ChunkLogs(logs)
+	failedLogIDs := map[int64]bool{}
 	for _, t := range delegator.Transformers {
 		transformerName := t.GetConfig().TransformerName
-		logChunk := chunkedLogs[transformerName]
-		err := t.Execute(logChunk)
-		if err != nil {
-			logrus.Errorf("%v transformer failed to execute in watcher: %v", transformerName, err)
-			return err
+		logChunk := getLogsNotTransformedBy(transformerName, chunkedLogs[transformerName])
+		if len(logChunk) < 1 {
+			continue
+		}
+		start := time.Now()
+		transformErr := t.Execute(logChunk)
+		metrics.ObserveTransformerExecution(transformerName, start, transformErr)
+		if transformErr != nil {
+			logrus.Errorf("%v transformer failed to execute in watcher: %v", transformerName, transformErr)
+			if executeErr == nil {
+				executeErr = transformErr
+			}
+			for _, log := range logChunk {
+				failedLogIDs[log.ID] = true
+			}
+			continue
+		}
+		createErr := delegator.LogRepository.CreateHeaderSyncLogTransformations(transformerName, getLogIDs(logChunk))
+		if createErr != nil {
+			return nil, createErr
 		}
 	}
-	return nil
+
+	var transformedLogIDs, untransformedLogIDs []int64
+	for _, log := range logs {
+		if failedLogIDs[log.ID] {
+			untransformedLogIDs = append(untransformedLogIDs, log.ID)
+		} else {
+			transformedLogIDs = append(transformedLogIDs, log.ID)
+		}
+	}
+	markErr := delegator.LogRepository.MarkHeaderSyncLogsTransformed(transformedLogIDs)
+	if markErr != nil {
+		return nil, markErr
+	}
+	unmarkErr := delegator.LogRepository.MarkHeaderSyncLogsUntransformed(untransformedLogIDs)
+	if unmarkErr != nil {
+		return nil, unmarkErr
+	}
+	return executeErr, nil
+}
+
+func getLogsNotTransformedBy(transformerName string, logs []core.HeaderSyncLog) []core.HeaderSyncLog {
+	var result []core.HeaderSyncLog
+	for _, log := range logs {
+		if !wasTransformedBy(transformerName, log) {
+			result = append(result, log)
+		}
+	}
+	return result
+}
+
+func wasTransformedBy(transformerName string, log core.HeaderSyncLog) bool {
+	for _, name := range log.TransformedBy {
+		if name == transformerName {
+			return true
+		}
+	}
+	return false
+}
+
+func getLogIDs(logs []core.HeaderSyncLog) []int64 {
+	var logIDs []int64
+	for _, log := range logs {
+		logIDs = append(logIDs, log.ID)
+	}
+	return logIDs
 }
@@ -80,6 +80,72 @@ var _ = Describe("Log delegator", func() {
 			Expect(mockLogRepository.GetCalled).To(BeTrue())
 		})
 
+		It("gets untransformed logs in batches of the configured size", func() {
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			mockLogRepository.ReturnLogs = []core.HeaderSyncLog{{}}
+			delegator := newDelegator(mockLogRepository)
+			delegator.BatchSize = 10
+			delegator.AddTransformer(&mocks.MockEventTransformer{})
+
+			err := delegator.DelegateLogs()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockLogRepository.GetPassedAfterBlock).To(Equal(int64(0)))
+			Expect(mockLogRepository.GetPassedAfterID).To(Equal(int64(0)))
+			Expect(mockLogRepository.GetPassedLimit).To(Equal(10))
+		})
+
+		It("gets the next batch after the last delegated log", func() {
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			mockLogRepository.ReturnLogs = []core.HeaderSyncLog{
+				{ID: 2, Log: types.Log{BlockNumber: 5}},
+				{ID: 7, Log: types.Log{BlockNumber: 6}},
+			}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(&mocks.MockEventTransformer{})
+
+			firstErr := delegator.DelegateLogs()
+			Expect(firstErr).NotTo(HaveOccurred())
+			secondErr := delegator.DelegateLogs()
+
+			Expect(secondErr).NotTo(HaveOccurred())
+			Expect(mockLogRepository.GetPassedAfterBlock).To(Equal(int64(6)))
+			Expect(mockLogRepository.GetPassedAfterID).To(Equal(int64(7)))
+		})
+
+		It("starts from the earliest log again once no logs remain", func() {
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			mockLogRepository.ReturnLogs = []core.HeaderSyncLog{{ID: 7, Log: types.Log{BlockNumber: 6}}}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(&mocks.MockEventTransformer{})
+			firstErr := delegator.DelegateLogs()
+			Expect(firstErr).NotTo(HaveOccurred())
+			mockLogRepository.ReturnLogs = nil
+			secondErr := delegator.DelegateLogs()
+			Expect(secondErr).To(MatchError(logs.ErrNoLogs))
+
+			_ = delegator.DelegateLogs()
+
+			Expect(mockLogRepository.GetPassedAfterBlock).To(Equal(int64(0)))
+			Expect(mockLogRepository.GetPassedAfterID).To(Equal(int64(0)))
+		})
+
+		It("advances past logs that failed to transform", func() {
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			fakeTransformer := &mocks.MockEventTransformer{ExecuteError: fakes.FakeError}
+			fakeTransformer.SetTransformerConfig(mocks.FakeTransformerConfig)
+			mockLogRepository.ReturnLogs = []core.HeaderSyncLog{newMatchingLog(7, 6, mocks.FakeTransformerConfig)}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(fakeTransformer)
+			firstErr := delegator.DelegateLogs()
+			Expect(firstErr).To(MatchError(fakes.FakeError))
+
+			_ = delegator.DelegateLogs()
+
+			Expect(mockLogRepository.GetPassedAfterBlock).To(Equal(int64(6)))
+			Expect(mockLogRepository.GetPassedAfterID).To(Equal(int64(7)))
+		})
+
 		It("returns error if getting untransformed logs fails", func() {
 			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
 			mockLogRepository.GetError = fakes.FakeError
@@ -123,17 +189,237 @@ var _ = Describe("Log delegator", func() {
 			Expect(fakeTransformer.PassedLogs).To(Equal(fakeHeaderSyncLogs))
 		})
 
-		It("returns error if transformer returns an error", func() {
+		It("returns error if a transformer fails", func() {
 			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
-			mockLogRepository.ReturnLogs = []core.HeaderSyncLog{{}}
+			fakeTransformer := &mocks.MockEventTransformer{ExecuteError: fakes.FakeError}
+			fakeTransformer.SetTransformerConfig(mocks.FakeTransformerConfig)
+			mockLogRepository.ReturnLogs = []core.HeaderSyncLog{newMatchingLog(1, 1, mocks.FakeTransformerConfig)}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(fakeTransformer)
+
+			err := delegator.DelegateLogs()
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(mockLogRepository.PassedUntransformedLogIDs).To(Equal([]int64{1}))
+		})
+
+		It("delegates to other transformers when one fails", func() {
+			failingTransformer := &mocks.MockEventTransformer{ExecuteError: fakes.FakeError}
+			failingTransformer.SetTransformerConfig(mocks.FakeTransformerConfig)
+			otherConfig := mocks.FakeTransformerConfig
+			otherConfig.TransformerName = "OtherFakeTransformer"
+			otherTransformer := &mocks.MockEventTransformer{}
+			otherTransformer.SetTransformerConfig(otherConfig)
+			fakeHeaderSyncLogs := []core.HeaderSyncLog{newMatchingLog(1, 1, mocks.FakeTransformerConfig)}
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			mockLogRepository.ReturnLogs = fakeHeaderSyncLogs
 			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(failingTransformer)
+			delegator.AddTransformer(otherTransformer)
+
+			err := delegator.DelegateLogs()
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(otherTransformer.PassedLogs).To(Equal(fakeHeaderSyncLogs))
+			Expect(mockLogRepository.PassedTransformerLogIDs).To(Equal(map[string][]int64{
+				otherConfig.TransformerName: {1},
+			}))
+		})
+
+		It("does not mark logs transformed if a transformer failed on them", func() {
 			fakeTransformer := &mocks.MockEventTransformer{ExecuteError: fakes.FakeError}
+			fakeTransformer.SetTransformerConfig(mocks.FakeTransformerConfig)
+			irrelevantLog := core.HeaderSyncLog{ID: 2, Log: types.Log{Topics: []common.Hash{{}}}}
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			mockLogRepository.ReturnLogs = []core.HeaderSyncLog{
+				newMatchingLog(1, 1, mocks.FakeTransformerConfig),
+				irrelevantLog,
+			}
+			delegator := newDelegator(mockLogRepository)
 			delegator.AddTransformer(fakeTransformer)
 
 			err := delegator.DelegateLogs()
 
-			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(mockLogRepository.PassedTransformedLogIDs).To(Equal([]int64{irrelevantLog.ID}))
+			Expect(mockLogRepository.PassedUntransformedLogIDs).To(Equal([]int64{1}))
+		})
+
+		It("returns error if marking failed logs untransformed fails", func() {
+			fakeTransformer := &mocks.MockEventTransformer{ExecuteError: fakes.FakeError}
+			fakeTransformer.SetTransformerConfig(mocks.FakeTransformerConfig)
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			mockLogRepository.ReturnLogs = []core.HeaderSyncLog{newMatchingLog(1, 1, mocks.FakeTransformerConfig)}
+			mockLogRepository.MarkUntransformedError = fakes.FakeError
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(fakeTransformer)
+
+			err := delegator.DelegateLogs()
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+
+		It("records the logs each transformer processed", func() {
+			fakeTransformer := &mocks.MockEventTransformer{}
+			fakeTransformer.SetTransformerConfig(mocks.FakeTransformerConfig)
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			mockLogRepository.ReturnLogs = []core.HeaderSyncLog{
+				newMatchingLog(1, 1, mocks.FakeTransformerConfig),
+				newMatchingLog(2, 1, mocks.FakeTransformerConfig),
+			}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(fakeTransformer)
+
+			err := delegator.DelegateLogs()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockLogRepository.PassedTransformerLogIDs).To(Equal(map[string][]int64{
+				mocks.FakeTransformerConfig.TransformerName: {1, 2},
+			}))
+			Expect(mockLogRepository.PassedTransformedLogIDs).To(Equal([]int64{1, 2}))
+		})
+
+		It("returns error if recording processed logs fails", func() {
+			fakeTransformer := &mocks.MockEventTransformer{}
+			fakeTransformer.SetTransformerConfig(mocks.FakeTransformerConfig)
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			mockLogRepository.ReturnLogs = []core.HeaderSyncLog{newMatchingLog(1, 1, mocks.FakeTransformerConfig)}
+			mockLogRepository.CreateTransformationsError = fakes.FakeError
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(fakeTransformer)
+
+			err := delegator.DelegateLogs()
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+
+		It("returns error if marking logs transformed fails", func() {
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			mockLogRepository.ReturnLogs = []core.HeaderSyncLog{{}}
+			mockLogRepository.MarkTransformedError = fakes.FakeError
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(&mocks.MockEventTransformer{})
+
+			err := delegator.DelegateLogs()
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+
+		It("does not delegate logs to transformers that already processed them", func() {
+			fakeTransformer := &mocks.MockEventTransformer{}
+			fakeTransformer.SetTransformerConfig(mocks.FakeTransformerConfig)
+			processedLog := newMatchingLog(1, 1, mocks.FakeTransformerConfig)
+			processedLog.TransformedBy = []string{mocks.FakeTransformerConfig.TransformerName}
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			mockLogRepository.ReturnLogs = []core.HeaderSyncLog{processedLog}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(fakeTransformer)
+
+			err := delegator.DelegateLogs()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeTransformer.ExecuteWasCalled).To(BeFalse())
+			Expect(mockLogRepository.PassedTransformedLogIDs).To(Equal([]int64{1}))
+		})
+
+		It("marks logs untransformed for each newly added transformer once", func() {
+			fakeTransformer := &mocks.MockEventTransformer{}
+			fakeTransformer.SetTransformerConfig(mocks.FakeTransformerConfig)
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(fakeTransformer)
+			_ = delegator.DelegateLogs()
+			otherConfig := mocks.FakeTransformerConfig
+			otherConfig.TransformerName = "OtherFakeTransformer"
+			otherTransformer := &mocks.MockEventTransformer{}
+			otherTransformer.SetTransformerConfig(otherConfig)
+			delegator.AddTransformer(otherTransformer)
+
+			_ = delegator.DelegateLogs()
+
+			Expect(mockLogRepository.MarkUntransformedByPassedNames).To(Equal([]string{
+				mocks.FakeTransformerConfig.TransformerName,
+				otherConfig.TransformerName,
+			}))
+		})
+
+		It("marks logs untransformed with the new transformer's topic filters", func() {
+			config := mocks.FakeTransformerConfig
+			config.Topic1 = []string{fakes.FakeHash.Hex()}
+			fakeTransformer := &mocks.MockEventTransformer{}
+			fakeTransformer.SetTransformerConfig(config)
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(fakeTransformer)
+
+			_ = delegator.DelegateLogs()
+
+			Expect(mockLogRepository.MarkUntransformedByPassedTopicFilters).To(Equal([][][]common.Hash{
+				config.TopicFilters(),
+			}))
+		})
+
+		It("does not mark logs untransformed for transformers registered by an earlier run", func() {
+			fakeTransformer := &mocks.MockEventTransformer{}
+			fakeTransformer.SetTransformerConfig(mocks.FakeTransformerConfig)
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			mockLogRepository.RegisteredTransformerNames = []string{mocks.FakeTransformerConfig.TransformerName}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(fakeTransformer)
+
+			_ = delegator.DelegateLogs()
+
+			Expect(mockLogRepository.MarkUntransformedByPassedNames).To(BeEmpty())
+		})
+
+		It("registers transformers once logs have been marked for them", func() {
+			fakeTransformer := &mocks.MockEventTransformer{}
+			fakeTransformer.SetTransformerConfig(mocks.FakeTransformerConfig)
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(fakeTransformer)
+
+			_ = delegator.DelegateLogs()
+
+			Expect(mockLogRepository.RegisteredTransformerNames).To(Equal([]string{
+				mocks.FakeTransformerConfig.TransformerName,
+			}))
+		})
+
+		It("returns error if getting new transformers fails", func() {
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			mockLogRepository.GetNewTransformersError = fakes.FakeError
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(&mocks.MockEventTransformer{})
+
+			err := delegator.DelegateLogs()
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(mockLogRepository.GetCalled).To(BeFalse())
+		})
+
+		It("returns error if registering transformers fails", func() {
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			mockLogRepository.RegisterTransformersError = fakes.FakeError
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(&mocks.MockEventTransformer{})
+
+			err := delegator.DelegateLogs()
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(mockLogRepository.GetCalled).To(BeFalse())
+		})
+
+		It("returns error if marking logs untransformed for a new transformer fails", func() {
+			mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
+			mockLogRepository.MarkUntransformedByError = fakes.FakeError
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(&mocks.MockEventTransformer{})
+
+			err := delegator.DelegateLogs()
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(mockLogRepository.GetCalled).To(BeFalse())
 		})
 
 		It("returns nil for error when logs returned and delegated", func() {
@@ -163,3 +449,14 @@ func newDelegator(headerSyncLogRepository *fakes.MockHeaderSyncLogRepository) *l
 		LogRepository: headerSyncLogRepository,
 	}
 }
+
+func newMatchingLog(id int64, blockNumber uint64, config transformer.EventTransformerConfig) core.HeaderSyncLog {
+	return core.HeaderSyncLog{
+		ID: id,
+		Log: types.Log{
+			Address:     common.HexToAddress(config.ContractAddresses[0]),
+			Topics:      []common.Hash{common.HexToHash(config.Topic)},
+			BlockNumber: blockNumber,
+		},
+	}
+}
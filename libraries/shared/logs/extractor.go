@@ -17,8 +17,14 @@
 package logs
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"sort"
+	"strings"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/makerdao/vulcanizedb/libraries/shared/constants"
 	"github.com/makerdao/vulcanizedb/libraries/shared/fetcher"
 	"github.com/makerdao/vulcanizedb/libraries/shared/transactions"
@@ -28,25 +34,65 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// Number of blocks backfilled at a time for newly watched logs, when no RangeSize is configured
+const DefaultBackfillRangeSize = int64(100)
+
 var (
+	ErrHeaderHashMismatch = errors.New("log block hash does not match stored header")
 	ErrNoUncheckedHeaders = errors.New("no unchecked headers available for log fetching")
 	ErrNoWatchedAddresses = errors.New("no watched addresses configured in the log extractor")
 )
 
 type ILogExtractor interface {
 	AddTransformerConfig(config transformer.EventTransformerConfig) error
-	ExtractLogs(recheckHeaders constants.TransformerExecution) error
+	ExtractLogs(ctx context.Context, recheckHeaders constants.TransformerExecution) error
 }
 
 type LogExtractor struct {
 	Addresses                []common.Address
+	BlockChain               core.BlockChain
 	CheckedHeadersRepository datastore.CheckedHeadersRepository
 	CheckedLogsRepository    datastore.CheckedLogsRepository
+	Confirmations            int64
+	EndingBlock              *int64
+	FastThenConfirm          bool
 	Fetcher                  fetcher.ILogFetcher
 	LogRepository            datastore.HeaderSyncLogRepository
+	RangeSize                int64
 	StartingBlock            *int64
 	Syncer                   transactions.ITransactionsSyncer
 	Topics                   []common.Hash
+	watchedLogSets           []watchedLogSet
+}
+
+// The addresses and topics of a transformer, along with the blocks it watches
+type watchedLogSet struct {
+	addresses     []common.Address
+	topic0        common.Hash
+	topicFilters  [][]common.Hash // topic1 to topic3 by position; empty positions match any topic
+	startingBlock int64
+	endingBlock   int64 // -1 for indefinite
+	syncReceipts  bool
+}
+
+func (set watchedLogSet) overlaps(startingBlock, endingBlock int64) bool {
+	return set.startingBlock <= endingBlock && (set.endingBlock == -1 || set.endingBlock >= startingBlock)
+}
+
+func (set watchedLogSet) matches(log types.Log) bool {
+	if len(log.Topics) < 1 || log.Topics[0] != set.topic0 || !set.overlaps(int64(log.BlockNumber), int64(log.BlockNumber)) {
+		return false
+	}
+	if !containsAddress(set.addresses, log.Address) {
+		return false
+	}
+	for i, filter := range set.topicFilters {
+		position := i + 1
+		if len(filter) > 0 && (len(log.Topics) <= position || !containsHash(filter, log.Topics[position])) {
+			return false
+		}
+	}
+	return true
 }
 
 // Add additional logs to extract
@@ -62,40 +108,164 @@ func (extractor *LogExtractor) AddTransformerConfig(config transformer.EventTran
 		extractor.StartingBlock = &config.StartingBlockNumber
 	}
 
+	endingBlockNumber := getEndingBlockNumber(config)
+	if extractor.EndingBlock == nil || endingBlockNumber == -1 ||
+		laterEndingBlockNumber(endingBlockNumber, *extractor.EndingBlock) {
+		extractor.EndingBlock = &endingBlockNumber
+	}
+
 	addresses := transformer.HexStringsToAddresses(config.ContractAddresses)
 	extractor.Addresses = append(extractor.Addresses, addresses...)
 	extractor.Topics = append(extractor.Topics, common.HexToHash(config.Topic))
+	extractor.watchedLogSets = append(extractor.watchedLogSets, watchedLogSet{
+		addresses:     addresses,
+		topic0:        common.HexToHash(config.Topic),
+		topicFilters:  config.TopicFilters(),
+		startingBlock: config.StartingBlockNumber,
+		endingBlock:   getEndingBlockNumber(config),
+		syncReceipts:  config.SyncReceipts,
+	})
 	return nil
 }
 
-// Fetch and persist watched logs
-func (extractor LogExtractor) ExtractLogs(recheckHeaders constants.TransformerExecution) error {
+// Fetch and persist watched logs. Only headers between the earliest starting block and the latest ending block of the
+// configured transformers are checked, and each header is only queried for the addresses of transformers watching its
+// block. With Confirmations set, only headers at least that many blocks below the chain head are checked, unless
+// FastThenConfirm is set - in which case all headers are checked immediately and re-checked once they are confirmed.
+// Headers checked before they were confirmed are recorded as such, so that they're re-checked even after a restart.
+// Headers checked before a transformer was added are backfilled for that transformer one range per pass, after any
+// unchecked headers, so that backfilling keeps making progress while new headers arrive at the chain head.
+func (extractor *LogExtractor) ExtractLogs(ctx context.Context, recheckHeaders constants.TransformerExecution) error {
 	if len(extractor.Addresses) < 1 {
 		logrus.Errorf("error extracting logs: %s", ErrNoWatchedAddresses.Error())
 		return ErrNoWatchedAddresses
 	}
 
-	uncheckedHeaders, uncheckedHeadersErr := extractor.CheckedHeadersRepository.UncheckedHeaders(*extractor.StartingBlock, -1, getCheckCount(recheckHeaders))
+	checkCount := getCheckCount(recheckHeaders)
+	endingBlock := *extractor.EndingBlock
+	confirmedBlock := int64(-1)
+	if extractor.Confirmations > 0 {
+		var confirmedBlockErr error
+		confirmedBlock, confirmedBlockErr = extractor.getConfirmedBlock(ctx)
+		if confirmedBlockErr != nil {
+			logrus.Errorf("error getting confirmed block: %s", confirmedBlockErr.Error())
+			return confirmedBlockErr
+		}
+		if endingBlock != -1 && endingBlock < confirmedBlock {
+			confirmedBlock = endingBlock
+		}
+		if extractor.FastThenConfirm {
+			confirmErr := extractor.confirmHeaders(ctx, confirmedBlock)
+			if confirmErr != nil {
+				return confirmErr
+			}
+		} else {
+			if confirmedBlock < *extractor.StartingBlock {
+				return ErrNoUncheckedHeaders
+			}
+			endingBlock = confirmedBlock
+		}
+	}
+
+	uncheckedHeaders, uncheckedHeadersErr := extractor.CheckedHeadersRepository.UncheckedHeaders(*extractor.StartingBlock, endingBlock, checkCount)
 	if uncheckedHeadersErr != nil {
 		logrus.Errorf("error fetching missing headers: %s", uncheckedHeadersErr)
 		return uncheckedHeadersErr
 	}
 
 	if len(uncheckedHeaders) < 1 {
-		return ErrNoUncheckedHeaders
+		return extractor.backfillWatchedLogs(ctx)
+	}
+
+	if extractor.FastThenConfirm && confirmedBlock != -1 {
+		markUnconfirmedErr := extractor.markHeadersUnconfirmed(uncheckedHeaders, confirmedBlock)
+		if markUnconfirmedErr != nil {
+			return markUnconfirmedErr
+		}
+	}
+	extractErr := extractor.extractLogsForHeaders(ctx, uncheckedHeaders)
+	if extractErr != nil {
+		return extractErr
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	backfillErr := extractor.backfillWatchedLogs(ctx)
+	if backfillErr != nil && backfillErr != ErrNoUncheckedHeaders {
+		return backfillErr
+	}
+	return nil
+}
+
+// Re-check headers that were checked before they were confirmed, and have been confirmed since
+func (extractor *LogExtractor) confirmHeaders(ctx context.Context, confirmedBlock int64) error {
+	confirmedHeaders, confirmedHeadersErr := extractor.CheckedHeadersRepository.UnconfirmedHeaders(*extractor.StartingBlock, confirmedBlock)
+	if confirmedHeadersErr != nil {
+		logrus.Errorf("error fetching newly confirmed headers: %s", confirmedHeadersErr)
+		return confirmedHeadersErr
+	}
+	if len(confirmedHeaders) < 1 {
+		return nil
+	}
+
+	extractErr := extractor.extractLogsForHeaders(ctx, confirmedHeaders)
+	if extractErr != nil {
+		return extractErr
+	}
+	markConfirmedErr := extractor.CheckedHeadersRepository.MarkHeadersConfirmed(getHeaderIDs(confirmedHeaders))
+	if markConfirmedErr != nil {
+		logrus.Errorf("error marking headers confirmed: %s", markConfirmedErr)
+		return markConfirmedErr
+	}
+	return nil
+}
+
+// Record the headers above the confirmed block before checking them, so that none is checked without being re-checked
+func (extractor *LogExtractor) markHeadersUnconfirmed(headers []core.Header, confirmedBlock int64) error {
+	var unconfirmedHeaderIDs []int64
+	for _, header := range headers {
+		if header.BlockNumber > confirmedBlock {
+			unconfirmedHeaderIDs = append(unconfirmedHeaderIDs, header.Id)
+		}
 	}
+	if len(unconfirmedHeaderIDs) < 1 {
+		return nil
+	}
+	markUnconfirmedErr := extractor.CheckedHeadersRepository.MarkHeadersUnconfirmed(unconfirmedHeaderIDs)
+	if markUnconfirmedErr != nil {
+		logrus.Errorf("error marking headers unconfirmed: %s", markUnconfirmedErr)
+	}
+	return markUnconfirmedErr
+}
 
-	for _, header := range uncheckedHeaders {
-		logs, fetchLogsErr := extractor.Fetcher.FetchLogs(extractor.Addresses, extractor.Topics, header)
+// Stops between headers once ctx is cancelled, so that no header is left partially processed
+func (extractor *LogExtractor) extractLogsForHeaders(ctx context.Context, headers []core.Header) error {
+	if extractor.RangeSize > 0 {
+		return extractor.extractLogsForHeaderRanges(ctx, headers)
+	}
+	for _, header := range headers {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		addresses, topics := extractor.getWatchedLogFilter(header.BlockNumber, header.BlockNumber)
+		if len(addresses) < 1 {
+			markHeaderCheckedErr := extractor.CheckedHeadersRepository.MarkHeaderChecked(header.Id)
+			if markHeaderCheckedErr != nil {
+				logError("error marking header checked: %s", markHeaderCheckedErr, header)
+				return markHeaderCheckedErr
+			}
+			continue
+		}
+
+		logs, fetchLogsErr := extractor.Fetcher.FetchLogs(ctx, addresses, topics, header)
 		if fetchLogsErr != nil {
 			logError("error fetching logs for header: %s", fetchLogsErr, header)
 			return fetchLogsErr
 		}
 
 		if len(logs) > 0 {
-			transactionsSyncErr := extractor.Syncer.SyncTransactions(header.Id, logs)
+			transactionsSyncErr := extractor.syncTransactions(ctx, header, logs)
 			if transactionsSyncErr != nil {
-				logError("error syncing transactions: %s", transactionsSyncErr, header)
 				return transactionsSyncErr
 			}
 
@@ -115,10 +285,350 @@ func (extractor LogExtractor) ExtractLogs(recheckHeaders constants.TransformerEx
 	return nil
 }
 
+// Fetch logs for contiguous runs of at most RangeSize headers with one query per run, rather than one per header
+func (extractor *LogExtractor) extractLogsForHeaderRanges(ctx context.Context, headers []core.Header) error {
+	for _, headerRange := range getContiguousHeaderRanges(headers, extractor.RangeSize) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		extractErr := extractor.extractLogsForHeaderRange(ctx, headerRange)
+		if extractErr != nil {
+			return extractErr
+		}
+	}
+	return nil
+}
+
+func (extractor *LogExtractor) extractLogsForHeaderRange(ctx context.Context, headers []core.Header) error {
+	startingBlock := headers[0].BlockNumber
+	endingBlock := headers[len(headers)-1].BlockNumber
+	checkedHeaderIDs := getHeaderIDs(headers)
+	addresses, topics := extractor.getWatchedLogFilter(startingBlock, endingBlock)
+	if len(addresses) > 0 {
+		var persistErr error
+		checkedHeaderIDs, persistErr = extractor.persistLogsForHeaderRange(ctx, addresses, topics, headers)
+		if persistErr != nil {
+			return persistErr
+		}
+	}
+
+	markHeadersCheckedErr := extractor.CheckedHeadersRepository.MarkHeadersChecked(checkedHeaderIDs)
+	if markHeadersCheckedErr != nil {
+		logrus.WithFields(logrus.Fields{
+			"startingBlock": startingBlock,
+			"endingBlock":   endingBlock,
+		}).Errorf("error marking headers checked: %s", markHeadersCheckedErr.Error())
+		return markHeadersCheckedErr
+	}
+	return nil
+}
+
+// Fetch and persist logs for a contiguous run of headers with one query, returning the ids of the headers whose logs
+// were persisted. Headers whose stored hash does not match the fetched logs are left out, so that their logs are
+// fetched again once headerSync replaces them.
+func (extractor *LogExtractor) persistLogsForHeaderRange(ctx context.Context, addresses []common.Address, topics [][]common.Hash, headers []core.Header) ([]int64, error) {
+	startingBlock := headers[0].BlockNumber
+	endingBlock := headers[len(headers)-1].BlockNumber
+	logs, fetchLogsErr := extractor.Fetcher.FetchLogsInRange(ctx, addresses, topics, startingBlock, endingBlock)
+	if fetchLogsErr != nil {
+		logrus.WithFields(logrus.Fields{
+			"startingBlock": startingBlock,
+			"endingBlock":   endingBlock,
+		}).Errorf("error fetching logs for header range: %s", fetchLogsErr.Error())
+		return nil, fetchLogsErr
+	}
+
+	headersByBlockNumber := make(map[int64]core.Header)
+	for _, header := range headers {
+		headersByBlockNumber[header.BlockNumber] = header
+	}
+	logsByHeaderID := make(map[int64][]types.Log)
+	staleBlockNumbers := make(map[int64]bool)
+	for _, log := range logs {
+		header, ok := headersByBlockNumber[int64(log.BlockNumber)]
+		if !ok {
+			continue
+		}
+		if log.BlockHash != common.HexToHash(header.Hash) {
+			staleBlockNumbers[header.BlockNumber] = true
+			continue
+		}
+		logsByHeaderID[header.Id] = append(logsByHeaderID[header.Id], log)
+	}
+
+	var persistedHeaderIDs []int64
+	for _, header := range headers {
+		if staleBlockNumbers[header.BlockNumber] {
+			logError("fetched logs do not match stored header hash: %s", ErrHeaderHashMismatch, header)
+			continue
+		}
+		headerLogs := logsByHeaderID[header.Id]
+		if len(headerLogs) > 0 {
+			transactionsSyncErr := extractor.syncTransactions(ctx, header, headerLogs)
+			if transactionsSyncErr != nil {
+				return nil, transactionsSyncErr
+			}
+
+			createLogsErr := extractor.LogRepository.CreateHeaderSyncLogs(header.Id, headerLogs)
+			if createLogsErr != nil {
+				logError("error persisting logs: %s", createLogsErr, header)
+				return nil, createLogsErr
+			}
+		}
+		persistedHeaderIDs = append(persistedHeaderIDs, header.Id)
+	}
+	return persistedHeaderIDs, nil
+}
+
+// Persist the transactions that emitted a header's logs, along with their receipts for logs watched by transformers that
+// sync receipts
+func (extractor *LogExtractor) syncTransactions(ctx context.Context, header core.Header, logs []types.Log) error {
+	transactionsSyncErr := extractor.Syncer.SyncTransactions(ctx, header.Id, logs)
+	if transactionsSyncErr != nil {
+		logError("error syncing transactions: %s", transactionsSyncErr, header)
+		return transactionsSyncErr
+	}
+
+	var receiptLogs []types.Log
+	for _, log := range logs {
+		for _, set := range extractor.watchedLogSets {
+			if set.syncReceipts && set.matches(log) {
+				receiptLogs = append(receiptLogs, log)
+				break
+			}
+		}
+	}
+	if len(receiptLogs) < 1 {
+		return nil
+	}
+	receiptsSyncErr := extractor.Syncer.SyncReceipts(ctx, header.Id, receiptLogs)
+	if receiptsSyncErr != nil {
+		logError("error syncing receipts: %s", receiptsSyncErr, header)
+		return receiptsSyncErr
+	}
+	return nil
+}
+
+// Fetch logs for the next range of headers that were checked before a watched address + topics were added, querying
+// only the newly watched addresses. Returns ErrNoUncheckedHeaders if there is nothing left to backfill.
+func (extractor *LogExtractor) backfillWatchedLogs(ctx context.Context) error {
+	backfills, getBackfillsErr := extractor.CheckedLogsRepository.GetLogBackfills()
+	if getBackfillsErr != nil {
+		logrus.Errorf("error fetching log backfills: %s", getBackfillsErr.Error())
+		return getBackfillsErr
+	}
+	if len(backfills) < 1 {
+		return ErrNoUncheckedHeaders
+	}
+
+	// addresses added together share their backfill progress, so they can be backfilled with the same query
+	backfill := backfills[0]
+	var addresses []string
+	for _, b := range backfills {
+		if b.TopicZero == backfill.TopicZero && b.TopicFilters == backfill.TopicFilters &&
+			b.NextBlockNumber == backfill.NextBlockNumber && b.EndingBlockNumber == backfill.EndingBlockNumber {
+			addresses = append(addresses, b.ContractAddress)
+		}
+	}
+
+	rangeSize := extractor.RangeSize
+	if rangeSize < 1 {
+		rangeSize = DefaultBackfillRangeSize
+	}
+	endingBlock := backfill.NextBlockNumber + rangeSize - 1
+	if endingBlock > backfill.EndingBlockNumber {
+		endingBlock = backfill.EndingBlockNumber
+	}
+	headers, getHeadersErr := extractor.CheckedHeadersRepository.CheckedHeaders(backfill.NextBlockNumber, endingBlock)
+	if getHeadersErr != nil {
+		logrus.Errorf("error fetching checked headers to backfill: %s", getHeadersErr.Error())
+		return getHeadersErr
+	}
+
+	topicFilters, decodeErr := decodeTopicFilters(backfill.TopicFilters)
+	if decodeErr != nil {
+		logrus.Errorf("error decoding topic filters of log backfill: %s", decodeErr.Error())
+		return decodeErr
+	}
+	watchedAddresses := transformer.HexStringsToAddresses(addresses)
+	topics := trimTrailingWildcards(append([][]common.Hash{{common.HexToHash(backfill.TopicZero)}}, topicFilters...))
+	for _, headerRange := range getContiguousHeaderRanges(headers, rangeSize) {
+		_, persistErr := extractor.persistLogsForHeaderRange(ctx, watchedAddresses, topics, headerRange)
+		if persistErr != nil {
+			return persistErr
+		}
+	}
+
+	updateErr := extractor.CheckedLogsRepository.UpdateLogBackfill(addresses, backfill.TopicZero, backfill.TopicFilters, endingBlock+1)
+	if updateErr != nil {
+		logrus.Errorf("error updating log backfill: %s", updateErr.Error())
+		return updateErr
+	}
+	return nil
+}
+
+// Returns the addresses and topics of the transformers watching any block in the passed range. Topics are given by
+// position: a position matches any of the transformers' filters for it, or any topic at all if some transformer does
+// not filter that position. Logs are matched to the exact filters of each transformer when they are chunked.
+func (extractor *LogExtractor) getWatchedLogFilter(startingBlock, endingBlock int64) ([]common.Address, [][]common.Hash) {
+	var addresses []common.Address
+	topics := make([][]common.Hash, 4)
+	wildcardPositions := make([]bool, 4)
+	seenAddresses := make(map[common.Address]bool)
+	seenTopics := make([]map[common.Hash]bool, 4)
+	for i := range seenTopics {
+		seenTopics[i] = make(map[common.Hash]bool)
+	}
+	for _, set := range extractor.watchedLogSets {
+		if !set.overlaps(startingBlock, endingBlock) {
+			continue
+		}
+		for _, address := range set.addresses {
+			if !seenAddresses[address] {
+				seenAddresses[address] = true
+				addresses = append(addresses, address)
+			}
+		}
+		setTopics := append([][]common.Hash{{set.topic0}}, set.topicFilters...)
+		for position, filter := range setTopics {
+			if len(filter) == 0 {
+				wildcardPositions[position] = true
+			}
+			for _, topic := range filter {
+				if !seenTopics[position][topic] {
+					seenTopics[position][topic] = true
+					topics[position] = append(topics[position], topic)
+				}
+			}
+		}
+	}
+	if len(addresses) < 1 {
+		return nil, nil
+	}
+	for position, isWildcard := range wildcardPositions {
+		if isWildcard {
+			topics[position] = nil
+		}
+	}
+	return addresses, trimTrailingWildcards(topics)
+}
+
+// Drops empty positions from the end of a topic filter, since they match any topic (including a missing one)
+func trimTrailingWildcards(topics [][]common.Hash) [][]common.Hash {
+	for len(topics) > 0 && len(topics[len(topics)-1]) == 0 {
+		topics = topics[:len(topics)-1]
+	}
+	return topics
+}
+
+// Encodes topic1 to topic3 filters in a canonical form for tracking watched logs; an empty string means no filters
+func encodeTopicFilters(topicFilters [][]common.Hash) (string, error) {
+	var encoded [][]string
+	for _, filter := range trimTrailingWildcards(topicFilters) {
+		hexTopics := []string{}
+		for _, topic := range filter {
+			hexTopics = append(hexTopics, strings.ToLower(topic.Hex()))
+		}
+		sort.Strings(hexTopics)
+		encoded = append(encoded, hexTopics)
+	}
+	if len(encoded) == 0 {
+		return "", nil
+	}
+	encodedBytes, err := json.Marshal(encoded)
+	return string(encodedBytes), err
+}
+
+func decodeTopicFilters(encoded string) ([][]common.Hash, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+	var hexFilters [][]string
+	err := json.Unmarshal([]byte(encoded), &hexFilters)
+	if err != nil {
+		return nil, err
+	}
+	topicFilters := make([][]common.Hash, len(hexFilters))
+	for i, hexTopics := range hexFilters {
+		topicFilters[i] = transformer.HexStringsToHashes(hexTopics)
+	}
+	return topicFilters, nil
+}
+
+func containsAddress(addresses []common.Address, target common.Address) bool {
+	for _, address := range addresses {
+		if address == target {
+			return true
+		}
+	}
+	return false
+}
+
+func containsHash(hashes []common.Hash, target common.Hash) bool {
+	for _, hash := range hashes {
+		if hash == target {
+			return true
+		}
+	}
+	return false
+}
+
+func getHeaderIDs(headers []core.Header) []int64 {
+	var headerIDs []int64
+	for _, header := range headers {
+		headerIDs = append(headerIDs, header.Id)
+	}
+	return headerIDs
+}
+
+func getContiguousHeaderRanges(headers []core.Header, maxRangeSize int64) [][]core.Header {
+	sortedHeaders := make([]core.Header, len(headers))
+	copy(sortedHeaders, headers)
+	sort.Slice(sortedHeaders, func(i, j int) bool {
+		return sortedHeaders[i].BlockNumber < sortedHeaders[j].BlockNumber
+	})
+
+	var ranges [][]core.Header
+	var currentRange []core.Header
+	for _, header := range sortedHeaders {
+		if len(currentRange) > 0 {
+			previous := currentRange[len(currentRange)-1]
+			if header.BlockNumber != previous.BlockNumber+1 || int64(len(currentRange)) >= maxRangeSize {
+				ranges = append(ranges, currentRange)
+				currentRange = nil
+			}
+		}
+		currentRange = append(currentRange, header)
+	}
+	if len(currentRange) > 0 {
+		ranges = append(ranges, currentRange)
+	}
+	return ranges
+}
+
+func (extractor *LogExtractor) getConfirmedBlock(ctx context.Context) (int64, error) {
+	lastBlock, lastBlockErr := extractor.BlockChain.LastBlock(ctx)
+	if lastBlockErr != nil {
+		return 0, lastBlockErr
+	}
+	return lastBlock.Int64() - extractor.Confirmations, nil
+}
+
 func earlierStartingBlockNumber(transformerBlock, watcherBlock int64) bool {
 	return transformerBlock < watcherBlock
 }
 
+func laterEndingBlockNumber(transformerBlock, watcherBlock int64) bool {
+	return watcherBlock != -1 && transformerBlock > watcherBlock
+}
+
+func getEndingBlockNumber(config transformer.EventTransformerConfig) int64 {
+	if config.IsIndefinite() {
+		return -1
+	}
+	return config.EndingBlockNumber
+}
+
 func logError(description string, err error, header core.Header) {
 	logrus.WithFields(logrus.Fields{
 		"headerId":    header.Id,
@@ -136,16 +646,18 @@ func getCheckCount(recheckHeaders constants.TransformerExecution) int64 {
 }
 
 func (extractor *LogExtractor) updateCheckedHeaders(config transformer.EventTransformerConfig) error {
-	alreadyWatchingLog, watchingLogErr := extractor.CheckedLogsRepository.AlreadyWatchingLog(config.ContractAddresses, config.Topic)
+	topicFilters, encodeErr := encodeTopicFilters(config.TopicFilters())
+	if encodeErr != nil {
+		return encodeErr
+	}
+	alreadyWatchingLog, watchingLogErr := extractor.CheckedLogsRepository.AlreadyWatchingLog(config.ContractAddresses,
+		config.Topic, topicFilters)
 	if watchingLogErr != nil {
 		return watchingLogErr
 	}
 	if !alreadyWatchingLog {
-		uncheckHeadersErr := extractor.CheckedHeadersRepository.MarkHeadersUnchecked(config.StartingBlockNumber)
-		if uncheckHeadersErr != nil {
-			return uncheckHeadersErr
-		}
-		markLogWatchedErr := extractor.CheckedLogsRepository.MarkLogWatched(config.ContractAddresses, config.Topic)
+		markLogWatchedErr := extractor.CheckedLogsRepository.MarkLogWatched(config.ContractAddresses, config.Topic,
+			topicFilters, config.StartingBlockNumber, getEndingBlockNumber(config))
 		if markLogWatchedErr != nil {
 			return markLogWatchedErr
 		}
@@ -17,6 +17,7 @@
 package logs_test
 
 import (
+	"context"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/makerdao/vulcanizedb/libraries/shared/constants"
@@ -27,6 +28,7 @@ import (
 	"github.com/makerdao/vulcanizedb/pkg/fakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"math/big"
 	"math/rand"
 )
 
@@ -62,6 +64,29 @@ var _ = Describe("Log extractor", func() {
 			Expect(*extractor.StartingBlock).To(Equal(earlierStartingBlockNumber))
 		})
 
+		It("updates extractor's ending block number to latest available", func() {
+			earlierEndingBlockNumber := rand.Int63n(1000) + 1
+			laterEndingBlockNumber := earlierEndingBlockNumber + 1
+
+			errOne := extractor.AddTransformerConfig(getTransformerConfigWithEndingBlock(laterEndingBlockNumber))
+			Expect(errOne).NotTo(HaveOccurred())
+			errTwo := extractor.AddTransformerConfig(getTransformerConfigWithEndingBlock(earlierEndingBlockNumber))
+			Expect(errTwo).NotTo(HaveOccurred())
+
+			Expect(*extractor.EndingBlock).To(Equal(laterEndingBlockNumber))
+		})
+
+		It("does not set an ending block number if any transformer is indefinite", func() {
+			errOne := extractor.AddTransformerConfig(getTransformerConfigWithEndingBlock(rand.Int63n(1000) + 1))
+			Expect(errOne).NotTo(HaveOccurred())
+			errTwo := extractor.AddTransformerConfig(getTransformerConfigWithEndingBlock(-1))
+			Expect(errTwo).NotTo(HaveOccurred())
+			errThree := extractor.AddTransformerConfig(getTransformerConfigWithEndingBlock(rand.Int63n(1000) + 1))
+			Expect(errThree).NotTo(HaveOccurred())
+
+			Expect(*extractor.EndingBlock).To(Equal(int64(-1)))
+		})
+
 		It("adds transformer's addresses to extractor's watched addresses", func() {
 			addresses := []string{"0xA", "0xB"}
 			configWithAddresses := transformer.EventTransformerConfig{
@@ -115,23 +140,30 @@ var _ = Describe("Log extractor", func() {
 				checkedLogsRepository.AlreadyWatchingLogReturn = false
 			})
 
-			It("marks headers since transformer's starting block number as unchecked", func() {
+			It("marks log watched from the transformer's starting block number", func() {
 				blockNumber := rand.Int63()
 
 				err := extractor.AddTransformerConfig(getTransformerConfig(blockNumber))
 
 				Expect(err).NotTo(HaveOccurred())
-				Expect(checkedHeadersRepository.MarkHeadersUncheckedCalled).To(BeTrue())
-				Expect(checkedHeadersRepository.MarkHeadersUncheckedStartingBlockNumber).To(Equal(blockNumber))
+				Expect(checkedLogsRepository.MarkLogWatchedStartingBlockNumber).To(Equal(blockNumber))
+				Expect(checkedLogsRepository.MarkLogWatchedEndingBlockNumber).To(Equal(int64(-1)))
 			})
 
-			It("returns error if marking headers unchecked returns error", func() {
-				checkedHeadersRepository.MarkHeadersUncheckedReturnError = fakes.FakeError
+			It("marks log watched up to the transformer's ending block number", func() {
+				endingBlockNumber := rand.Int63n(1000) + 1
+
+				err := extractor.AddTransformerConfig(getTransformerConfigWithEndingBlock(endingBlockNumber))
 
+				Expect(err).NotTo(HaveOccurred())
+				Expect(checkedLogsRepository.MarkLogWatchedEndingBlockNumber).To(Equal(endingBlockNumber))
+			})
+
+			It("does not mark any headers unchecked", func() {
 				err := extractor.AddTransformerConfig(getTransformerConfig(rand.Int63()))
 
-				Expect(err).To(HaveOccurred())
-				Expect(err).To(MatchError(fakes.FakeError))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(checkedHeadersRepository.MarkHeadersUncheckedCalled).To(BeFalse())
 			})
 
 			It("persists that tranformer's log has been checked", func() {
@@ -142,6 +174,20 @@ var _ = Describe("Log extractor", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(checkedLogsRepository.MarkLogWatchedAddresses).To(Equal(config.ContractAddresses))
 				Expect(checkedLogsRepository.MarkLogWatchedTopicZero).To(Equal(config.Topic))
+				Expect(checkedLogsRepository.MarkLogWatchedTopicFilters).To(BeEmpty())
+			})
+
+			It("persists the transformer's topic filters in a canonical form", func() {
+				config := getTransformerConfig(rand.Int63())
+				config.Topic2 = []string{"0x2B", "0x2a"}
+
+				err := extractor.AddTransformerConfig(config)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(checkedLogsRepository.AlreadyWatchingLogTopicFilters).To(Equal(
+					`[[],["` + common.HexToHash("0x2a").Hex() + `","` + common.HexToHash("0x2b").Hex() + `"]]`))
+				Expect(checkedLogsRepository.MarkLogWatchedTopicFilters).To(Equal(
+					checkedLogsRepository.AlreadyWatchingLogTopicFilters))
 			})
 
 			It("returns error if marking logs checked returns error", func() {
@@ -157,7 +203,7 @@ var _ = Describe("Log extractor", func() {
 
 	Describe("ExtractLogs", func() {
 		It("returns error if no watched addresses configured", func() {
-			err := extractor.ExtractLogs(constants.HeaderUnchecked)
+			err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(logs.ErrNoWatchedAddresses))
@@ -171,7 +217,7 @@ var _ = Describe("Log extractor", func() {
 				startingBlockNumber := rand.Int63()
 				extractor.AddTransformerConfig(getTransformerConfig(startingBlockNumber))
 
-				err := extractor.ExtractLogs(constants.HeaderUnchecked)
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(err).NotTo(HaveOccurred())
 				Expect(mockCheckedHeadersRepository.UncheckedHeadersStartingBlockNumber).To(Equal(startingBlockNumber))
@@ -188,7 +234,7 @@ var _ = Describe("Log extractor", func() {
 				startingBlockNumber := rand.Int63()
 				extractor.AddTransformerConfig(getTransformerConfig(startingBlockNumber))
 
-				err := extractor.ExtractLogs(constants.HeaderRecheck)
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderRecheck)
 
 				Expect(err).NotTo(HaveOccurred())
 				Expect(mockCheckedHeadersRepository.UncheckedHeadersStartingBlockNumber).To(Equal(startingBlockNumber))
@@ -197,13 +243,28 @@ var _ = Describe("Log extractor", func() {
 			})
 		})
 
+		Describe("when transformers have ending blocks", func() {
+			It("only gets headers up to the latest ending block", func() {
+				mockCheckedHeadersRepository := &fakes.MockCheckedHeadersRepository{}
+				mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = []core.Header{{}}
+				extractor.CheckedHeadersRepository = mockCheckedHeadersRepository
+				endingBlockNumber := rand.Int63n(1000) + 1
+				extractor.AddTransformerConfig(getTransformerConfigWithEndingBlock(endingBlockNumber))
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockCheckedHeadersRepository.UncheckedHeadersEndingBlockNumber).To(Equal(endingBlockNumber))
+			})
+		})
+
 		It("returns error if getting unchecked headers fails", func() {
 			addTransformerConfig(extractor)
 			mockCheckedHeadersRepository := &fakes.MockCheckedHeadersRepository{}
 			mockCheckedHeadersRepository.UncheckedHeadersReturnError = fakes.FakeError
 			extractor.CheckedHeadersRepository = mockCheckedHeadersRepository
 
-			err := extractor.ExtractLogs(constants.HeaderUnchecked)
+			err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -215,7 +276,7 @@ var _ = Describe("Log extractor", func() {
 				mockLogFetcher := &mocks.MockLogFetcher{}
 				extractor.Fetcher = mockLogFetcher
 
-				_ = extractor.ExtractLogs(constants.HeaderUnchecked)
+				_ = extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(mockLogFetcher.FetchCalled).To(BeFalse())
 			})
@@ -225,7 +286,7 @@ var _ = Describe("Log extractor", func() {
 				mockLogFetcher := &mocks.MockLogFetcher{}
 				extractor.Fetcher = mockLogFetcher
 
-				err := extractor.ExtractLogs(constants.HeaderUnchecked)
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(err).To(MatchError(logs.ErrNoUncheckedHeaders))
 			})
@@ -237,18 +298,18 @@ var _ = Describe("Log extractor", func() {
 				config := transformer.EventTransformerConfig{
 					ContractAddresses:   []string{fakes.FakeAddress.Hex()},
 					Topic:               fakes.FakeHash.Hex(),
-					StartingBlockNumber: rand.Int63(),
+					StartingBlockNumber: 0,
 				}
 				addTransformerErr := extractor.AddTransformerConfig(config)
 				Expect(addTransformerErr).NotTo(HaveOccurred())
 				mockLogFetcher := &mocks.MockLogFetcher{}
 				extractor.Fetcher = mockLogFetcher
 
-				err := extractor.ExtractLogs(constants.HeaderUnchecked)
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(err).NotTo(HaveOccurred())
 				Expect(mockLogFetcher.FetchCalled).To(BeTrue())
-				expectedTopics := []common.Hash{common.HexToHash(config.Topic)}
+				expectedTopics := [][]common.Hash{{common.HexToHash(config.Topic)}}
 				Expect(mockLogFetcher.Topics).To(Equal(expectedTopics))
 				expectedAddresses := transformer.HexStringsToAddresses(config.ContractAddresses)
 				Expect(mockLogFetcher.ContractAddresses).To(Equal(expectedAddresses))
@@ -261,7 +322,7 @@ var _ = Describe("Log extractor", func() {
 				mockLogFetcher.ReturnError = fakes.FakeError
 				extractor.Fetcher = mockLogFetcher
 
-				err := extractor.ExtractLogs(constants.HeaderUnchecked)
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -274,7 +335,7 @@ var _ = Describe("Log extractor", func() {
 					mockTransactionSyncer := &fakes.MockTransactionSyncer{}
 					extractor.Syncer = mockTransactionSyncer
 
-					err := extractor.ExtractLogs(constants.HeaderUnchecked)
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 					Expect(err).NotTo(HaveOccurred())
 					Expect(mockTransactionSyncer.SyncTransactionsCalled).To(BeFalse())
@@ -289,7 +350,7 @@ var _ = Describe("Log extractor", func() {
 					mockTransactionSyncer := &fakes.MockTransactionSyncer{}
 					extractor.Syncer = mockTransactionSyncer
 
-					err := extractor.ExtractLogs(constants.HeaderUnchecked)
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 					Expect(err).NotTo(HaveOccurred())
 					Expect(mockTransactionSyncer.SyncTransactionsCalled).To(BeTrue())
@@ -303,12 +364,67 @@ var _ = Describe("Log extractor", func() {
 					mockTransactionSyncer.SyncTransactionsError = fakes.FakeError
 					extractor.Syncer = mockTransactionSyncer
 
-					err := extractor.ExtractLogs(constants.HeaderUnchecked)
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 					Expect(err).To(HaveOccurred())
 					Expect(err).To(MatchError(fakes.FakeError))
 				})
 
+				Describe("syncing receipts", func() {
+					var (
+						mockTransactionSyncer *fakes.MockTransactionSyncer
+						watchedLog            types.Log
+						otherLog              types.Log
+						otherAddress          = common.HexToAddress("0xB")
+					)
+
+					BeforeEach(func() {
+						addUncheckedHeader(extractor)
+						watchedLog = types.Log{Address: fakes.FakeAddress, Topics: []common.Hash{fakes.FakeHash}, TxHash: common.HexToHash("0x1")}
+						otherLog = types.Log{Address: otherAddress, Topics: []common.Hash{fakes.FakeHash}, TxHash: common.HexToHash("0x2")}
+						mockLogFetcher := &mocks.MockLogFetcher{}
+						mockLogFetcher.ReturnLogs = []types.Log{watchedLog, otherLog}
+						extractor.Fetcher = mockLogFetcher
+						mockTransactionSyncer = &fakes.MockTransactionSyncer{}
+						extractor.Syncer = mockTransactionSyncer
+					})
+
+					It("does not sync receipts unless a transformer opts in", func() {
+						addTransformerConfig(extractor)
+
+						err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+						Expect(err).NotTo(HaveOccurred())
+						Expect(mockTransactionSyncer.SyncReceiptsCalled).To(BeFalse())
+					})
+
+					It("syncs receipts for logs watched by transformers that opt in", func() {
+						config := getTransformerConfig(0)
+						config.SyncReceipts = true
+						Expect(extractor.AddTransformerConfig(config)).To(Succeed())
+						otherConfig := getTransformerConfig(0)
+						otherConfig.ContractAddresses = []string{otherAddress.Hex()}
+						Expect(extractor.AddTransformerConfig(otherConfig)).To(Succeed())
+
+						err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+						Expect(err).NotTo(HaveOccurred())
+						Expect(mockTransactionSyncer.SyncTransactionsCalled).To(BeTrue())
+						Expect(mockTransactionSyncer.SyncReceiptsPassedLogs).To(Equal([]types.Log{watchedLog}))
+					})
+
+					It("returns error if syncing receipts fails", func() {
+						config := getTransformerConfig(0)
+						config.SyncReceipts = true
+						Expect(extractor.AddTransformerConfig(config)).To(Succeed())
+						mockTransactionSyncer.SyncReceiptsError = fakes.FakeError
+
+						err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+						Expect(err).To(MatchError(fakes.FakeError))
+					})
+				})
+
 				It("persists fetched logs", func() {
 					addUncheckedHeader(extractor)
 					addTransformerConfig(extractor)
@@ -323,7 +439,7 @@ var _ = Describe("Log extractor", func() {
 					mockLogRepository := &fakes.MockHeaderSyncLogRepository{}
 					extractor.LogRepository = mockLogRepository
 
-					err := extractor.ExtractLogs(constants.HeaderUnchecked)
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 					Expect(err).NotTo(HaveOccurred())
 					Expect(mockLogRepository.PassedLogs).To(Equal(fakeLogs))
@@ -337,7 +453,7 @@ var _ = Describe("Log extractor", func() {
 					mockLogRepository.CreateError = fakes.FakeError
 					extractor.LogRepository = mockLogRepository
 
-					err := extractor.ExtractLogs(constants.HeaderUnchecked)
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 					Expect(err).To(HaveOccurred())
 					Expect(err).To(MatchError(fakes.FakeError))
@@ -352,7 +468,7 @@ var _ = Describe("Log extractor", func() {
 				mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = []core.Header{{Id: headerID}}
 				extractor.CheckedHeadersRepository = mockCheckedHeadersRepository
 
-				err := extractor.ExtractLogs(constants.HeaderUnchecked)
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(err).NotTo(HaveOccurred())
 				Expect(mockCheckedHeadersRepository.MarkHeaderCheckedHeaderID).To(Equal(headerID))
@@ -366,7 +482,7 @@ var _ = Describe("Log extractor", func() {
 				mockCheckedHeadersRepository.MarkHeaderCheckedReturnError = fakes.FakeError
 				extractor.CheckedHeadersRepository = mockCheckedHeadersRepository
 
-				err := extractor.ExtractLogs(constants.HeaderUnchecked)
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -376,9 +492,537 @@ var _ = Describe("Log extractor", func() {
 				addUncheckedHeader(extractor)
 				addTransformerConfig(extractor)
 
-				err := extractor.ExtractLogs(constants.HeaderUnchecked)
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Describe("when fetching logs for header ranges", func() {
+			var (
+				mockCheckedHeadersRepository *fakes.MockCheckedHeadersRepository
+				mockLogFetcher               *mocks.MockLogFetcher
+				mockLogRepository            *fakes.MockHeaderSyncLogRepository
+			)
+
+			BeforeEach(func() {
+				mockCheckedHeadersRepository = &fakes.MockCheckedHeadersRepository{}
+				mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = []core.Header{
+					{Id: 5, BlockNumber: 5, Hash: common.BytesToHash([]byte{5}).Hex()},
+					{Id: 1, BlockNumber: 1, Hash: common.BytesToHash([]byte{1}).Hex()},
+					{Id: 2, BlockNumber: 2, Hash: common.BytesToHash([]byte{2}).Hex()},
+					{Id: 3, BlockNumber: 3, Hash: common.BytesToHash([]byte{3}).Hex()},
+				}
+				mockLogFetcher = &mocks.MockLogFetcher{}
+				mockLogRepository = &fakes.MockHeaderSyncLogRepository{}
+				extractor.CheckedHeadersRepository = mockCheckedHeadersRepository
+				extractor.Fetcher = mockLogFetcher
+				extractor.LogRepository = mockLogRepository
+				extractor.RangeSize = 2
+				addTransformerConfig(extractor)
+			})
+
+			It("fetches logs for contiguous runs of headers up to the range size", func() {
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogFetcher.FetchCalled).To(BeFalse())
+				Expect(mockLogFetcher.PassedStartingBlockNumbers).To(Equal([]int64{1, 3, 5}))
+				Expect(mockLogFetcher.PassedEndingBlockNumbers).To(Equal([]int64{2, 3, 5}))
+			})
+
+			It("persists fetched logs with their header", func() {
+				fetchedLog := types.Log{BlockNumber: 2, BlockHash: common.BytesToHash([]byte{2})}
+				mockLogFetcher.ReturnLogs = []types.Log{fetchedLog}
+				extractor.RangeSize = 5
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogRepository.PassedHeaderID).To(Equal(int64(2)))
+				Expect(mockLogRepository.PassedLogs).To(Equal([]types.Log{fetchedLog}))
+			})
+
+			It("marks every header in the range checked", func() {
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockCheckedHeadersRepository.MarkHeadersCheckedHeaderIDs).To(Equal([]int64{1, 2, 3, 5}))
+			})
+
+			It("leaves headers unchecked if fetched logs do not match their hash", func() {
+				mockLogFetcher.ReturnLogs = []types.Log{{BlockNumber: 2, BlockHash: common.BytesToHash([]byte{9})}}
+				extractor.RangeSize = 5
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogRepository.PassedLogs).To(BeEmpty())
+				Expect(mockCheckedHeadersRepository.MarkHeadersCheckedHeaderIDs).To(Equal([]int64{1, 3, 5}))
+			})
+
+			It("returns error if fetching logs fails", func() {
+				mockLogFetcher.ReturnError = fakes.FakeError
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).To(MatchError(fakes.FakeError))
+				Expect(mockCheckedHeadersRepository.MarkHeadersCheckedHeaderIDs).To(BeEmpty())
+			})
+
+			It("returns error if marking headers checked fails", func() {
+				mockCheckedHeadersRepository.MarkHeadersCheckedReturnError = fakes.FakeError
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).To(MatchError(fakes.FakeError))
+			})
+		})
+
+		Describe("when transformers watch different blocks", func() {
+			var (
+				laterAddress                 = common.HexToAddress("0x" + fakes.RandomString(40))
+				laterTopic                   = common.HexToHash("0x" + fakes.RandomString(64))
+				mockCheckedHeadersRepository *fakes.MockCheckedHeadersRepository
+				mockLogFetcher               *mocks.MockLogFetcher
+			)
+
+			BeforeEach(func() {
+				mockCheckedHeadersRepository = &fakes.MockCheckedHeadersRepository{}
+				mockLogFetcher = &mocks.MockLogFetcher{}
+				extractor.CheckedHeadersRepository = mockCheckedHeadersRepository
+				extractor.Fetcher = mockLogFetcher
+				earlierConfig := getTransformerConfigWithEndingBlock(9)
+				laterConfig := transformer.EventTransformerConfig{
+					ContractAddresses:   []string{laterAddress.Hex()},
+					Topic:               laterTopic.Hex(),
+					StartingBlockNumber: 10,
+				}
+				extractor.AddTransformerConfig(earlierConfig)
+				extractor.AddTransformerConfig(laterConfig)
+			})
+
+			It("only queries the addresses and topics of transformers watching the header's block", func() {
+				mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = []core.Header{{BlockNumber: 10}}
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogFetcher.ContractAddresses).To(Equal([]common.Address{laterAddress}))
+				Expect(mockLogFetcher.Topics).To(Equal([][]common.Hash{{laterTopic}}))
+			})
+
+			It("only queries the addresses and topics of transformers watching the header range", func() {
+				mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = []core.Header{{BlockNumber: 8}, {BlockNumber: 9}}
+				extractor.RangeSize = 2
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogFetcher.ContractAddresses).To(Equal([]common.Address{fakes.FakeAddress}))
+				Expect(mockLogFetcher.Topics).To(Equal([][]common.Hash{{fakes.FakeHash}}))
+			})
+		})
+
+		Describe("when transformers filter on topics after topic0", func() {
+			var (
+				mockCheckedHeadersRepository *fakes.MockCheckedHeadersRepository
+				mockLogFetcher               *mocks.MockLogFetcher
+				topicOne                     = common.HexToHash("0x11")
+				anotherTopicOne              = common.HexToHash("0x12")
+				topicThree                   = common.HexToHash("0x31")
+			)
+
+			BeforeEach(func() {
+				mockCheckedHeadersRepository = &fakes.MockCheckedHeadersRepository{}
+				mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = []core.Header{{BlockNumber: 1}}
+				mockLogFetcher = &mocks.MockLogFetcher{}
+				extractor.CheckedHeadersRepository = mockCheckedHeadersRepository
+				extractor.Fetcher = mockLogFetcher
+				config := getTransformerConfig(0)
+				config.Topic1 = []string{topicOne.Hex()}
+				config.Topic3 = []string{topicThree.Hex()}
+				extractor.AddTransformerConfig(config)
+			})
+
+			It("queries the node with the transformer's topic filters", func() {
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogFetcher.Topics).To(Equal([][]common.Hash{{fakes.FakeHash}, {topicOne}, nil, {topicThree}}))
+			})
+
+			It("combines the topic filters of transformers watching the same block", func() {
+				otherConfig := getTransformerConfig(0)
+				otherConfig.Topic1 = []string{anotherTopicOne.Hex()}
+				extractor.AddTransformerConfig(otherConfig)
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogFetcher.Topics).To(Equal([][]common.Hash{{fakes.FakeHash}, {topicOne, anotherTopicOne}}))
+			})
+
+			It("does not filter a position if any transformer watching the block leaves it unfiltered", func() {
+				extractor.AddTransformerConfig(getTransformerConfig(0))
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogFetcher.Topics).To(Equal([][]common.Hash{{fakes.FakeHash}}))
+			})
+
+			It("backfills with the topic filters of the watched logs", func() {
+				mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = nil
+				mockCheckedHeadersRepository.CheckedHeadersReturnHeaders = []core.Header{{Id: 1, BlockNumber: 10}}
+				encodedFilters := `[["` + topicOne.Hex() + `"]]`
+				checkedLogsRepository.GetLogBackfillsReturn = []core.LogBackfill{{
+					ContractAddress:   fakes.FakeAddress.Hex(),
+					TopicZero:         fakes.FakeHash.Hex(),
+					TopicFilters:      encodedFilters,
+					NextBlockNumber:   10,
+					EndingBlockNumber: 10,
+				}}
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogFetcher.Topics).To(Equal([][]common.Hash{{fakes.FakeHash}, {topicOne}}))
+				Expect(checkedLogsRepository.UpdateLogBackfillCalls[0].TopicFilters).To(Equal(encodedFilters))
+			})
+		})
+
+		Describe("when no transformer watches a header's block", func() {
+			var (
+				mockCheckedHeadersRepository *fakes.MockCheckedHeadersRepository
+				mockLogFetcher               *mocks.MockLogFetcher
+			)
+
+			BeforeEach(func() {
+				mockCheckedHeadersRepository = &fakes.MockCheckedHeadersRepository{}
+				mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = []core.Header{{Id: 3, BlockNumber: 3}}
+				mockLogFetcher = &mocks.MockLogFetcher{}
+				extractor.CheckedHeadersRepository = mockCheckedHeadersRepository
+				extractor.Fetcher = mockLogFetcher
+				extractor.AddTransformerConfig(getTransformerConfig(10))
+			})
+
+			It("marks the header checked without fetching logs", func() {
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogFetcher.FetchCalled).To(BeFalse())
+				Expect(mockCheckedHeadersRepository.MarkHeaderCheckedHeaderID).To(Equal(int64(3)))
+			})
+
+			It("marks the header range checked without fetching logs", func() {
+				extractor.RangeSize = 2
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogFetcher.FetchInRangeCalled).To(BeFalse())
+				Expect(mockCheckedHeadersRepository.MarkHeadersCheckedHeaderIDs).To(Equal([]int64{3}))
+			})
+		})
+
+		Describe("when there are logs to backfill", func() {
+			var (
+				backfillAddress              = common.HexToAddress("0x" + fakes.RandomString(40))
+				backfillTopic                = common.HexToHash("0x" + fakes.RandomString(64))
+				mockCheckedHeadersRepository *fakes.MockCheckedHeadersRepository
+				mockLogFetcher               *mocks.MockLogFetcher
+				mockLogRepository            *fakes.MockHeaderSyncLogRepository
+			)
+
+			BeforeEach(func() {
+				mockCheckedHeadersRepository = &fakes.MockCheckedHeadersRepository{}
+				mockCheckedHeadersRepository.CheckedHeadersReturnHeaders = []core.Header{
+					{Id: 1, BlockNumber: 10, Hash: common.BytesToHash([]byte{1}).Hex()},
+					{Id: 2, BlockNumber: 11, Hash: common.BytesToHash([]byte{2}).Hex()},
+				}
+				checkedLogsRepository.GetLogBackfillsReturn = []core.LogBackfill{{
+					ContractAddress:   backfillAddress.Hex(),
+					TopicZero:         backfillTopic.Hex(),
+					NextBlockNumber:   10,
+					EndingBlockNumber: 1000,
+				}}
+				mockLogFetcher = &mocks.MockLogFetcher{}
+				mockLogRepository = &fakes.MockHeaderSyncLogRepository{}
+				extractor.CheckedHeadersRepository = mockCheckedHeadersRepository
+				extractor.Fetcher = mockLogFetcher
+				extractor.LogRepository = mockLogRepository
+				extractor.RangeSize = 50
+				addTransformerConfig(extractor)
+			})
+
+			It("fetches logs for the next range of checked headers with only the backfilled addresses", func() {
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockCheckedHeadersRepository.CheckedHeadersStartingBlockNumber).To(Equal(int64(10)))
+				Expect(mockCheckedHeadersRepository.CheckedHeadersEndingBlockNumber).To(Equal(int64(59)))
+				Expect(mockLogFetcher.ContractAddresses).To(Equal([]common.Address{backfillAddress}))
+				Expect(mockLogFetcher.Topics).To(Equal([][]common.Hash{{backfillTopic}}))
+				Expect(mockLogFetcher.PassedStartingBlockNumbers).To(Equal([]int64{10}))
+				Expect(mockLogFetcher.PassedEndingBlockNumbers).To(Equal([]int64{11}))
+			})
+
+			It("backfills addresses sharing progress together", func() {
+				otherAddress := common.HexToAddress("0x" + fakes.RandomString(40))
+				laterAddress := common.HexToAddress("0x" + fakes.RandomString(40))
+				checkedLogsRepository.GetLogBackfillsReturn = append(checkedLogsRepository.GetLogBackfillsReturn,
+					core.LogBackfill{
+						ContractAddress:   otherAddress.Hex(),
+						TopicZero:         backfillTopic.Hex(),
+						NextBlockNumber:   10,
+						EndingBlockNumber: 1000,
+					}, core.LogBackfill{
+						ContractAddress:   laterAddress.Hex(),
+						TopicZero:         backfillTopic.Hex(),
+						NextBlockNumber:   20,
+						EndingBlockNumber: 1000,
+					})
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogFetcher.ContractAddresses).To(Equal([]common.Address{backfillAddress, otherAddress}))
+			})
+
+			It("does not backfill past the backfill's ending block", func() {
+				checkedLogsRepository.GetLogBackfillsReturn[0].EndingBlockNumber = 20
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockCheckedHeadersRepository.CheckedHeadersEndingBlockNumber).To(Equal(int64(20)))
+				Expect(checkedLogsRepository.UpdateLogBackfillCalls[0].NextBlockNumber).To(Equal(int64(21)))
+			})
+
+			It("backfills the default range size if no range size is configured", func() {
+				extractor.RangeSize = 0
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockCheckedHeadersRepository.CheckedHeadersEndingBlockNumber).To(
+					Equal(10 + logs.DefaultBackfillRangeSize - 1))
+			})
+
+			It("persists backfilled logs", func() {
+				fetchedLog := types.Log{BlockNumber: 11, BlockHash: common.BytesToHash([]byte{2})}
+				mockLogFetcher.ReturnLogs = []types.Log{fetchedLog}
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogRepository.PassedHeaderID).To(Equal(int64(2)))
+				Expect(mockLogRepository.PassedLogs).To(Equal([]types.Log{fetchedLog}))
+			})
+
+			It("records backfill progress without changing header check counts", func() {
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(checkedLogsRepository.UpdateLogBackfillCalls).To(Equal([]fakes.UpdateLogBackfillCall{{
+					Addresses:       []string{backfillAddress.Hex()},
+					TopicZero:       backfillTopic.Hex(),
+					NextBlockNumber: 60,
+				}}))
+				Expect(mockCheckedHeadersRepository.MarkHeadersCheckedHeaderIDs).To(BeEmpty())
+			})
+
+			It("backfills a range after checking unchecked headers", func() {
+				mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = []core.Header{{Id: 3, BlockNumber: 100}}
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockCheckedHeadersRepository.MarkHeadersCheckedHeaderIDs).To(Equal([]int64{3}))
+				Expect(checkedLogsRepository.UpdateLogBackfillCalls).To(HaveLen(1))
+			})
+
+			It("does not backfill if checking unchecked headers fails", func() {
+				mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = []core.Header{{Id: 3, BlockNumber: 100}}
+				mockCheckedHeadersRepository.MarkHeadersCheckedReturnError = fakes.FakeError
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).To(MatchError(fakes.FakeError))
+				Expect(checkedLogsRepository.UpdateLogBackfillCalls).To(BeEmpty())
+			})
+
+			It("returns error if getting backfills fails", func() {
+				checkedLogsRepository.GetLogBackfillsError = fakes.FakeError
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).To(MatchError(fakes.FakeError))
+			})
+
+			It("returns error if getting checked headers fails", func() {
+				mockCheckedHeadersRepository.CheckedHeadersReturnError = fakes.FakeError
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).To(MatchError(fakes.FakeError))
+				Expect(checkedLogsRepository.UpdateLogBackfillCalls).To(BeEmpty())
+			})
+
+			It("does not record progress if fetching logs fails", func() {
+				mockLogFetcher.ReturnError = fakes.FakeError
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).To(MatchError(fakes.FakeError))
+				Expect(checkedLogsRepository.UpdateLogBackfillCalls).To(BeEmpty())
+			})
+
+			It("returns error if recording progress fails", func() {
+				checkedLogsRepository.UpdateLogBackfillError = fakes.FakeError
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).To(MatchError(fakes.FakeError))
+			})
+		})
+
+		Describe("when confirmations are required", func() {
+			var (
+				blockChain                   *fakes.MockBlockChain
+				mockCheckedHeadersRepository *fakes.MockCheckedHeadersRepository
+			)
+
+			BeforeEach(func() {
+				blockChain = fakes.NewMockBlockChain()
+				blockChain.SetLastBlock(big.NewInt(100))
+				mockCheckedHeadersRepository = &fakes.MockCheckedHeadersRepository{}
+				mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = []core.Header{{BlockNumber: 91}}
+				extractor.BlockChain = blockChain
+				extractor.CheckedHeadersRepository = mockCheckedHeadersRepository
+				extractor.Confirmations = 10
+				extractor.AddTransformerConfig(getTransformerConfig(50))
+			})
+
+			It("only gets headers with the required confirmations", func() {
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(err).NotTo(HaveOccurred())
+				Expect(mockCheckedHeadersRepository.UncheckedHeadersStartingBlockNumber).To(Equal(int64(50)))
+				Expect(mockCheckedHeadersRepository.UncheckedHeadersEndingBlockNumber).To(Equal(int64(90)))
+				Expect(mockCheckedHeadersRepository.UncheckedHeadersCheckCount).To(Equal(int64(1)))
+			})
+
+			It("does not get headers after the latest ending block", func() {
+				endingBlockNumber := int64(80)
+				extractor.EndingBlock = &endingBlockNumber
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockCheckedHeadersRepository.UncheckedHeadersEndingBlockNumber).To(Equal(int64(80)))
+			})
+
+			It("returns error that no unchecked headers were found if no headers are confirmed", func() {
+				blockChain.SetLastBlock(big.NewInt(55))
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).To(MatchError(logs.ErrNoUncheckedHeaders))
+				Expect(mockCheckedHeadersRepository.UncheckedHeadersCalls).To(BeEmpty())
+			})
+
+			It("returns error if getting the last block fails", func() {
+				blockChain.SetLastBlockErr(fakes.FakeError)
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).To(MatchError(fakes.FakeError))
+			})
+
+			Describe("in fast then confirm mode", func() {
+				BeforeEach(func() {
+					extractor.FastThenConfirm = true
+				})
+
+				It("gets all unchecked headers up to the chain head", func() {
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(mockCheckedHeadersRepository.UncheckedHeadersCalls).To(ConsistOf(fakes.UncheckedHeadersCall{
+						StartingBlockNumber: 50,
+						EndingBlockNumber:   -1,
+						CheckCount:          1,
+					}))
+				})
+
+				It("records the headers it checks before they are confirmed", func() {
+					mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = []core.Header{
+						{Id: 1, BlockNumber: 90},
+						{Id: 2, BlockNumber: 91},
+					}
+
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(mockCheckedHeadersRepository.MarkHeadersUnconfirmedHeaderIDs).To(Equal([]int64{2}))
+				})
+
+				It("returns error if recording unconfirmed headers fails", func() {
+					mockCheckedHeadersRepository.MarkHeadersUnconfirmedReturnError = fakes.FakeError
+
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+					Expect(err).To(MatchError(fakes.FakeError))
+				})
+
+				It("re-checks confirmed headers that were checked before they were confirmed", func() {
+					confirmedHeader := core.Header{Id: 3, BlockNumber: 85}
+					mockCheckedHeadersRepository.UnconfirmedHeadersReturnHeaders = []core.Header{confirmedHeader}
+					mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = nil
+					mockLogFetcher := &mocks.MockLogFetcher{}
+					extractor.Fetcher = mockLogFetcher
+
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+					Expect(err).To(MatchError(logs.ErrNoUncheckedHeaders))
+					Expect(mockCheckedHeadersRepository.UnconfirmedHeadersCalls).To(ConsistOf(fakes.UncheckedHeadersCall{
+						StartingBlockNumber: 50,
+						EndingBlockNumber:   90,
+					}))
+					Expect(mockLogFetcher.MissingHeader).To(Equal(confirmedHeader))
+					Expect(mockCheckedHeadersRepository.MarkHeadersConfirmedHeaderIDs).To(Equal([]int64{3}))
+				})
+
+				It("does not record headers confirmed if re-checking them fails", func() {
+					mockCheckedHeadersRepository.UnconfirmedHeadersReturnHeaders = []core.Header{{Id: 3, BlockNumber: 85}}
+					mockLogFetcher := &mocks.MockLogFetcher{}
+					mockLogFetcher.ReturnError = fakes.FakeError
+					extractor.Fetcher = mockLogFetcher
+
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+					Expect(err).To(MatchError(fakes.FakeError))
+					Expect(mockCheckedHeadersRepository.MarkHeadersConfirmedHeaderIDs).To(BeEmpty())
+				})
+
+				It("returns error if getting unconfirmed headers fails", func() {
+					mockCheckedHeadersRepository.UnconfirmedHeadersReturnError = fakes.FakeError
+
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+					Expect(err).To(MatchError(fakes.FakeError))
+				})
+
+				It("returns error if recording confirmed headers fails", func() {
+					mockCheckedHeadersRepository.UnconfirmedHeadersReturnHeaders = []core.Header{{Id: 3, BlockNumber: 85}}
+					mockCheckedHeadersRepository.MarkHeadersConfirmedReturnError = fakes.FakeError
+
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+					Expect(err).To(MatchError(fakes.FakeError))
+				})
 			})
 		})
 	})
@@ -388,7 +1032,7 @@ func addTransformerConfig(extractor *logs.LogExtractor) {
 	fakeConfig := transformer.EventTransformerConfig{
 		ContractAddresses:   []string{fakes.FakeAddress.Hex()},
 		Topic:               fakes.FakeHash.Hex(),
-		StartingBlockNumber: rand.Int63(),
+		StartingBlockNumber: 0,
 	}
 	extractor.AddTransformerConfig(fakeConfig)
 }
@@ -412,3 +1056,12 @@ func getTransformerConfig(startingBlockNumber int64) transformer.EventTransforme
 		StartingBlockNumber: startingBlockNumber,
 	}
 }
+
+func getTransformerConfigWithEndingBlock(endingBlockNumber int64) transformer.EventTransformerConfig {
+	return transformer.EventTransformerConfig{
+		ContractAddresses:   []string{fakes.FakeAddress.Hex()},
+		Topic:               fakes.FakeHash.Hex(),
+		StartingBlockNumber: 0,
+		EndingBlockNumber:   endingBlockNumber,
+	}
+}
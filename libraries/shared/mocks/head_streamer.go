@@ -0,0 +1,68 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package mocks
+
+import (
+	"github.com/makerdao/vulcanizedb/libraries/shared/streamer"
+)
+
+type MockSubscription struct {
+	errs              chan error
+	UnsubscribeCalled bool
+}
+
+func NewMockSubscription() *MockSubscription {
+	return &MockSubscription{errs: make(chan error, 1)}
+}
+
+func (subscription *MockSubscription) Err() <-chan error {
+	return subscription.errs
+}
+
+func (subscription *MockSubscription) Unsubscribe() {
+	subscription.UnsubscribeCalled = true
+}
+
+// Sends the heads to stream, then closes the subscription with ErrToReturn unless it's kept open
+type MockHeadStreamer struct {
+	HeadsToStream    []streamer.NewHead
+	ErrToReturn      error
+	KeepSubscription bool
+	StreamErr        error
+	StreamCalled     bool
+	Subscription     *MockSubscription
+}
+
+func NewMockHeadStreamer() *MockHeadStreamer {
+	return &MockHeadStreamer{Subscription: NewMockSubscription()}
+}
+
+func (mhs *MockHeadStreamer) Stream(headChan chan streamer.NewHead) (streamer.Subscription, error) {
+	mhs.StreamCalled = true
+	if mhs.StreamErr != nil {
+		return nil, mhs.StreamErr
+	}
+	go func() {
+		for _, head := range mhs.HeadsToStream {
+			headChan <- head
+		}
+		if !mhs.KeepSubscription {
+			mhs.Subscription.errs <- mhs.ErrToReturn
+		}
+	}()
+	return mhs.Subscription, nil
+}
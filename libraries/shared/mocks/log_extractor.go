@@ -17,6 +17,8 @@
 package mocks
 
 import (
+	"context"
+
 	"github.com/makerdao/vulcanizedb/libraries/shared/constants"
 	"github.com/makerdao/vulcanizedb/libraries/shared/logs"
 	"github.com/makerdao/vulcanizedb/libraries/shared/transformer"
@@ -34,7 +36,7 @@ func (extractor *MockLogExtractor) AddTransformerConfig(config transformer.Event
 	return extractor.AddTransformerConfigError
 }
 
-func (extractor *MockLogExtractor) ExtractLogs(recheckHeaders constants.TransformerExecution) error {
+func (extractor *MockLogExtractor) ExtractLogs(ctx context.Context, recheckHeaders constants.TransformerExecution) error {
 	extractor.ExtractLogsCount++
 	if len(extractor.ExtractLogsErrors) > 1 {
 		var errorThisRun error
@@ -17,24 +17,37 @@
 package mocks
 
 import (
+	"context"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 )
 
 type MockLogFetcher struct {
-	ContractAddresses []common.Address
-	FetchCalled       bool
-	MissingHeader     core.Header
-	ReturnError       error
-	ReturnLogs        []types.Log
-	Topics            []common.Hash
+	ContractAddresses          []common.Address
+	FetchCalled                bool
+	FetchInRangeCalled         bool
+	MissingHeader              core.Header
+	PassedEndingBlockNumbers   []int64
+	PassedStartingBlockNumbers []int64
+	ReturnError                error
+	ReturnLogs                 []types.Log
+	Topics                     [][]common.Hash
 }
 
-func (fetcher *MockLogFetcher) FetchLogs(contractAddresses []common.Address, topics []common.Hash, missingHeader core.Header) ([]types.Log, error) {
+func (fetcher *MockLogFetcher) FetchLogs(ctx context.Context, contractAddresses []common.Address, topics [][]common.Hash, missingHeader core.Header) ([]types.Log, error) {
 	fetcher.FetchCalled = true
 	fetcher.ContractAddresses = contractAddresses
 	fetcher.Topics = topics
 	fetcher.MissingHeader = missingHeader
 	return fetcher.ReturnLogs, fetcher.ReturnError
 }
+
+func (fetcher *MockLogFetcher) FetchLogsInRange(ctx context.Context, contractAddresses []common.Address, topics [][]common.Hash, startingBlockNumber, endingBlockNumber int64) ([]types.Log, error) {
+	fetcher.FetchInRangeCalled = true
+	fetcher.ContractAddresses = contractAddresses
+	fetcher.Topics = topics
+	fetcher.PassedStartingBlockNumbers = append(fetcher.PassedStartingBlockNumbers, startingBlockNumber)
+	fetcher.PassedEndingBlockNumbers = append(fetcher.PassedEndingBlockNumbers, endingBlockNumber)
+	return fetcher.ReturnLogs, fetcher.ReturnError
+}
@@ -0,0 +1,46 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package mocks
+
+import (
+	"sync"
+
+	"github.com/makerdao/vulcanizedb/pkg/core"
+)
+
+type MockReorgListener struct {
+	HandleReorgErr error
+	handledReorgs  []core.Reorg
+	mutex          sync.Mutex
+}
+
+func (listener *MockReorgListener) HandleReorg(reorg core.Reorg) error {
+	listener.mutex.Lock()
+	defer listener.mutex.Unlock()
+	listener.handledReorgs = append(listener.handledReorgs, reorg)
+	return listener.HandleReorgErr
+}
+
+func (listener *MockReorgListener) HandledReorgIDs() []int64 {
+	listener.mutex.Lock()
+	defer listener.mutex.Unlock()
+	var reorgIDs []int64
+	for _, reorg := range listener.handledReorgs {
+		reorgIDs = append(reorgIDs, reorg.ID)
+	}
+	return reorgIDs
+}
@@ -16,7 +16,11 @@
 
 package mocks
 
-import "github.com/makerdao/vulcanizedb/libraries/shared/storage"
+import (
+	"context"
+
+	"github.com/makerdao/vulcanizedb/libraries/shared/storage"
+)
 
 type MockStorageFetcher struct {
 	DiffsToReturn []storage.RawDiff
@@ -27,11 +31,19 @@ func NewMockStorageFetcher() *MockStorageFetcher {
 	return &MockStorageFetcher{}
 }
 
-func (fetcher *MockStorageFetcher) FetchStorageDiffs(out chan<- storage.RawDiff, errs chan<- error) {
+func (fetcher *MockStorageFetcher) FetchStorageDiffs(ctx context.Context, out chan<- storage.RawDiff, errs chan<- error) {
 	for _, diff := range fetcher.DiffsToReturn {
-		out <- diff
+		select {
+		case out <- diff:
+		case <-ctx.Done():
+			return
+		}
 	}
 	for _, err := range fetcher.ErrsToReturn {
-		errs <- err
+		select {
+		case errs <- err:
+		case <-ctx.Done():
+			return
+		}
 	}
 }
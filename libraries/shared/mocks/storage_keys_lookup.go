@@ -23,16 +23,34 @@ import (
 )
 
 type MockStorageKeysLookup struct {
-	Metadata     storage.ValueMetadata
-	LookupCalled bool
-	LookupErr    error
+	Metadata                 storage.ValueMetadata
+	LookupCalled             bool
+	LookupErr                error
+	FoundAfterAddingDataKeys bool
+	DynamicLengthKeys        []common.Hash
+	GetDynamicLengthKeysErr  error
+	AddedNumberOfDataSlots   map[common.Hash]int
 }
 
 func (mappings *MockStorageKeysLookup) Lookup(key common.Hash) (storage.ValueMetadata, error) {
 	mappings.LookupCalled = true
+	if mappings.FoundAfterAddingDataKeys && len(mappings.AddedNumberOfDataSlots) > 0 {
+		return mappings.Metadata, nil
+	}
 	return mappings.Metadata, mappings.LookupErr
 }
 
+func (mappings *MockStorageKeysLookup) GetDynamicLengthKeys() ([]common.Hash, error) {
+	return mappings.DynamicLengthKeys, mappings.GetDynamicLengthKeysErr
+}
+
+func (mappings *MockStorageKeysLookup) AddDynamicDataKeys(lengthKey common.Hash, numberOfDataSlots int) {
+	if mappings.AddedNumberOfDataSlots == nil {
+		mappings.AddedNumberOfDataSlots = make(map[common.Hash]int)
+	}
+	mappings.AddedNumberOfDataSlots[lengthKey] = numberOfDataSlots
+}
+
 func (*MockStorageKeysLookup) SetDB(db *postgres.DB) {
 	panic("implement me")
 }
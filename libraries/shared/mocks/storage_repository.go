@@ -22,11 +22,14 @@ import (
 )
 
 type MockStorageRepository struct {
-	CreateErr      error
-	PassedHeaderID int64
-	PassedDiffID   int64
-	PassedMetadata storage.ValueMetadata
-	PassedValue    interface{}
+	CreateErr                       error
+	DeleteOrphanedValuesCalled      bool
+	DeleteOrphanedValuesErr         error
+	DeleteOrphanedValuesPassedAfter int64
+	PassedHeaderID                  int64
+	PassedDiffID                    int64
+	PassedMetadata                  storage.ValueMetadata
+	PassedValue                     interface{}
 }
 
 func (repository *MockStorageRepository) Create(diffID, headerID int64, metadata storage.ValueMetadata, value interface{}) error {
@@ -37,6 +40,12 @@ func (repository *MockStorageRepository) Create(diffID, headerID int64, metadata
 	return repository.CreateErr
 }
 
+func (repository *MockStorageRepository) DeleteOrphanedValues(afterBlockHeight int64) error {
+	repository.DeleteOrphanedValuesPassedAfter = afterBlockHeight
+	repository.DeleteOrphanedValuesCalled = true
+	return repository.DeleteOrphanedValuesErr
+}
+
 func (*MockStorageRepository) SetDB(db *postgres.DB) {
 	panic("implement me")
 }
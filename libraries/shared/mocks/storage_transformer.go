@@ -24,9 +24,12 @@ import (
 )
 
 type MockStorageTransformer struct {
-	KeccakOfAddress common.Hash
-	ExecuteErr      error
-	PassedDiff      storage.PersistedDiff
+	KeccakOfAddress                 common.Hash
+	ExecuteErr                      error
+	PassedDiff                      storage.PersistedDiff
+	DeleteOrphanedValuesCalled      bool
+	DeleteOrphanedValuesErr         error
+	DeleteOrphanedValuesPassedAfter int64
 }
 
 func (transformer *MockStorageTransformer) Execute(diff storage.PersistedDiff) error {
@@ -34,6 +37,12 @@ func (transformer *MockStorageTransformer) Execute(diff storage.PersistedDiff) e
 	return transformer.ExecuteErr
 }
 
+func (transformer *MockStorageTransformer) DeleteOrphanedValues(afterBlockHeight int64) error {
+	transformer.DeleteOrphanedValuesPassedAfter = afterBlockHeight
+	transformer.DeleteOrphanedValuesCalled = true
+	return transformer.DeleteOrphanedValuesErr
+}
+
 func (transformer *MockStorageTransformer) KeccakContractAddress() common.Hash {
 	return transformer.KeccakOfAddress
 }
@@ -0,0 +1,36 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repository
+
+import (
+	"fmt"
+
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+)
+
+// DeleteOrphanedStorageValues deletes the rows of a storage table that were created from diffs of orphaned blocks above
+// afterBlockHeight. The table must record the diff each row was created from in a diff_id column.
+func DeleteOrphanedStorageValues(db *postgres.DB, table string, afterBlockHeight int64) (int64, error) {
+	result, err := db.Exec(fmt.Sprintf(`DELETE FROM %s
+		USING public.storage_diff
+		WHERE %s.diff_id = storage_diff.id AND storage_diff.orphaned IS TRUE AND storage_diff.block_height > $1`,
+		table, table), afterBlockHeight)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,86 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repository_test
+
+import (
+	"github.com/makerdao/vulcanizedb/libraries/shared/repository"
+	"github.com/makerdao/vulcanizedb/libraries/shared/storage"
+	"github.com/makerdao/vulcanizedb/libraries/shared/test_data"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/makerdao/vulcanizedb/test_config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DeleteOrphanedStorageValues", func() {
+	const createTestStorageTableQuery = `CREATE TABLE public.test_storage_values(
+		id      SERIAL PRIMARY KEY,
+		diff_id BIGINT NOT NULL REFERENCES public.storage_diff (id) ON DELETE CASCADE,
+		value   TEXT
+		);`
+
+	var (
+		db                          *postgres.DB
+		canonicalDiffID, orphanedID int64
+	)
+
+	BeforeEach(func() {
+		db = test_config.NewTestDB(test_config.NewTestNode())
+		test_config.CleanTestDB(db)
+		db.MustExec(createTestStorageTableQuery)
+		diffRepository := repositories.NewStorageDiffRepository(db)
+		var createErr, createOrphanedErr error
+		canonicalDiffID, createErr = diffRepository.CreateStorageDiff(storage.RawDiff{BlockHash: test_data.FakeHash(),
+			BlockHeight: 10})
+		Expect(createErr).NotTo(HaveOccurred())
+		orphanedID, createOrphanedErr = diffRepository.CreateStorageDiff(storage.RawDiff{BlockHash: test_data.FakeHash(),
+			BlockHeight: 10})
+		Expect(createOrphanedErr).NotTo(HaveOccurred())
+		db.MustExec(`UPDATE public.storage_diff SET orphaned = TRUE WHERE id = $1`, orphanedID)
+		db.MustExec(`INSERT INTO public.test_storage_values (diff_id, value) VALUES ($1, 'canonical'), ($2, 'orphaned')`,
+			canonicalDiffID, orphanedID)
+	})
+
+	AfterEach(func() {
+		db.MustExec(`DROP TABLE public.test_storage_values`)
+	})
+
+	It("deletes values created from orphaned diffs", func() {
+		deleted, err := repository.DeleteOrphanedStorageValues(db, "public.test_storage_values", 9)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(Equal(int64(1)))
+		var values []string
+		getErr := db.Select(&values, `SELECT value FROM public.test_storage_values`)
+		Expect(getErr).NotTo(HaveOccurred())
+		Expect(values).To(ConsistOf("canonical"))
+	})
+
+	It("doesn't delete values created from orphaned diffs at or below the height", func() {
+		deleted, err := repository.DeleteOrphanedStorageValues(db, "public.test_storage_values", 10)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(BeZero())
+	})
+
+	It("returns an error if the table doesn't exist", func() {
+		_, err := repository.DeleteOrphanedStorageValues(db, "public.nonexistent", 0)
+
+		Expect(err).To(HaveOccurred())
+	})
+})
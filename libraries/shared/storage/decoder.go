@@ -17,45 +17,66 @@
 package storage
 
 import (
-	"fmt"
 	"math/big"
+	"strconv"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 const (
-	bitsPerByte = 8
+	bitsPerByte  = 8
+	bytesPerSlot = 32
 )
 
 func Decode(diff PersistedDiff, metadata ValueMetadata) (interface{}, error) {
 	switch metadata.Type {
-	case Uint256:
-		return decodeInteger(diff.StorageValue.Bytes()), nil
-	case Uint48:
-		return decodeInteger(diff.StorageValue.Bytes()), nil
-	case Uint128:
-		return decodeInteger(diff.StorageValue.Bytes()), nil
-	case Address:
-		return decodeAddress(diff.StorageValue.Bytes()), nil
 	case Bytes32:
 		return diff.StorageValue.Hex(), nil
 	case PackedSlot:
-		return decodePackedSlot(diff.StorageValue.Bytes(), metadata.PackedTypes), nil
+		return decodePackedSlot(diff.StorageValue.Bytes(), metadata.PackedTypes)
+	case String, Bytes:
+		return DecodeDynamic(metadata.Type, diff.StorageValue, nil)
 	default:
-		panic(fmt.Sprintf("can't decode unknown type: %d", metadata.Type))
+		return decodeItem(diff.StorageValue.Bytes(), metadata.Type)
 	}
 }
 
+// Values are stored in the lowest-order bytes of their slot, so a value that doesn't fill the slot is read from the end
+func decodeItem(raw []byte, valueType ValueType) (string, error) {
+	lengthOfItem, err := getNumberOfBytes(valueType)
+	if err != nil {
+		return "", err
+	}
+	if lengthOfItem > len(raw) {
+		return "", ErrValueOverflowsSlot{Type: valueType}
+	}
+	return decodeIndividualItem(raw[len(raw)-lengthOfItem:], valueType)
+}
+
 func decodeInteger(raw []byte) string {
 	n := big.NewInt(0).SetBytes(raw)
 	return n.String()
 }
 
+// Decodes a two's complement signed integer as wide as raw
+func decodeSignedInteger(raw []byte) string {
+	n := big.NewInt(0).SetBytes(raw)
+	if len(raw) > 0 && raw[0]&0x80 != 0 {
+		n.Sub(n, big.NewInt(0).Lsh(big.NewInt(1), uint(len(raw)*bitsPerByte)))
+	}
+	return n.String()
+}
+
+func decodeBool(raw []byte) string {
+	return strconv.FormatBool(big.NewInt(0).SetBytes(raw).Sign() != 0)
+}
+
 func decodeAddress(raw []byte) string {
 	return common.BytesToAddress(raw).Hex()
 }
 
-func decodePackedSlot(raw []byte, packedTypes map[int]ValueType) map[int]string {
+func decodePackedSlot(raw []byte, packedTypes map[int]ValueType) (map[int]string, error) {
 	storageSlotData := raw
 	decodedStorageSlotItems := map[int]string{}
 	numberOfTypes := len(packedTypes)
@@ -65,42 +86,77 @@ func decodePackedSlot(raw []byte, packedTypes map[int]ValueType) map[int]string
 		lengthOfStorageData := len(storageSlotData)
 
 		//get item details (type, length, starting index, value bytes)
-		itemType := packedTypes[position]
-		lengthOfItem := getNumberOfBytes(itemType)
+		itemType, ok := packedTypes[position]
+		if !ok {
+			return nil, ErrPackedPositionMissing{Position: position}
+		}
+		lengthOfItem, err := getNumberOfBytes(itemType)
+		if err != nil {
+			return nil, err
+		}
 		itemStartingIndex := lengthOfStorageData - lengthOfItem
+		if itemStartingIndex < 0 {
+			return nil, ErrValueOverflowsSlot{Type: itemType}
+		}
 		itemValueBytes := storageSlotData[itemStartingIndex:]
 
 		//decode item's bytes and set in results map
-		decodedValue := decodeIndividualItem(itemValueBytes, itemType)
+		decodedValue, err := decodeIndividualItem(itemValueBytes, itemType)
+		if err != nil {
+			return nil, err
+		}
 		decodedStorageSlotItems[position] = decodedValue
 
 		//pop last item off raw slot data before moving on
 		storageSlotData = storageSlotData[0:itemStartingIndex]
 	}
 
-	return decodedStorageSlotItems
+	return decodedStorageSlotItems, nil
 }
 
-func decodeIndividualItem(itemBytes []byte, valueType ValueType) string {
-	switch valueType {
-	case Uint48, Uint128:
-		return decodeInteger(itemBytes)
-	case Address:
-		return decodeAddress(itemBytes)
+func decodeIndividualItem(itemBytes []byte, valueType ValueType) (string, error) {
+	switch {
+	case valueType == Address:
+		return decodeAddress(itemBytes), nil
+	case valueType == Bool:
+		return decodeBool(itemBytes), nil
+	case valueType.isUnsigned():
+		return decodeInteger(itemBytes), nil
+	case valueType.isSigned():
+		return decodeSignedInteger(itemBytes), nil
+	case valueType.isFixedBytes():
+		return hexutil.Encode(itemBytes), nil
 	default:
-		panic(fmt.Sprintf("can't decode unknown type: %d", valueType))
+		return "", ErrUnknownValueType{Type: valueType}
 	}
 }
 
-func getNumberOfBytes(valueType ValueType) int {
-	switch valueType {
-	case Uint48:
-		return 48 / bitsPerByte
-	case Uint128:
-		return 128 / bitsPerByte
-	case Address:
-		return 20
+func getNumberOfBytes(valueType ValueType) (int, error) {
+	switch {
+	case valueType == Uint48:
+		return 48 / bitsPerByte, nil
+	case valueType == Uint128:
+		return 128 / bitsPerByte, nil
+	case valueType == Uint256, valueType == Bytes32:
+		return bytesPerSlot, nil
+	case valueType == Address:
+		return common.AddressLength, nil
+	case valueType == Bool:
+		return 1, nil
+	case valueType.isUnsigned():
+		return getIntegerNumberOfBytes(valueType, int(valueType-uintBase))
+	case valueType.isSigned():
+		return getIntegerNumberOfBytes(valueType, int(valueType-intBase))
+	case valueType.isFixedBytes():
+		return int(valueType - bytesBase), nil
 	default:
-		panic(fmt.Sprintf("ValueType %d not recognized", valueType))
+		return 0, ErrUnknownValueType{Type: valueType}
+	}
+}
+
+func getIntegerNumberOfBytes(valueType ValueType, bits int) (int, error) {
+	if bits%bitsPerByte != 0 || bits > bytesPerSlot*bitsPerByte {
+		return 0, ErrUnknownValueType{Type: valueType}
 	}
+	return bits / bitsPerByte, nil
 }
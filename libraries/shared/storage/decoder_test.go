@@ -70,6 +70,99 @@ var _ = Describe("Storage decoder", func() {
 		Expect(result).To(Equal(fakeAddress.Hex()))
 	})
 
+	It("decodes negative int256 as two's complement", func() {
+		fakeInt := common.HexToHash("fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffac7")
+		diff := storage.PersistedDiff{RawDiff: storage.RawDiff{StorageValue: fakeInt}}
+		metadata := storage.ValueMetadata{Type: storage.Int256}
+
+		result, err := storage.Decode(diff, metadata)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("-1337"))
+	})
+
+	It("decodes positive int256", func() {
+		fakeInt := common.HexToHash("0000000000000000000000000000000000000000000000000000000000000539")
+		diff := storage.PersistedDiff{RawDiff: storage.RawDiff{StorageValue: fakeInt}}
+		metadata := storage.ValueMetadata{Type: storage.Int256}
+
+		result, err := storage.Decode(diff, metadata)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("1337"))
+	})
+
+	It("decodes signed integers narrower than the slot from their own width", func() {
+		fakeInt := common.HexToHash("00000000000000000000000000000000000000000000000000000000000000ff")
+		diff := storage.PersistedDiff{RawDiff: storage.RawDiff{StorageValue: fakeInt}}
+		metadata := storage.ValueMetadata{Type: storage.Int8}
+
+		result, err := storage.Decode(diff, metadata)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("-1"))
+	})
+
+	It("decodes uint8", func() {
+		fakeInt := common.HexToHash("0000000000000000000000000000000000000000000000000000000000000012")
+		diff := storage.PersistedDiff{RawDiff: storage.RawDiff{StorageValue: fakeInt}}
+		metadata := storage.ValueMetadata{Type: storage.Uint8}
+
+		result, err := storage.Decode(diff, metadata)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("18"))
+	})
+
+	It("decodes bool", func() {
+		diff := storage.PersistedDiff{RawDiff: storage.RawDiff{StorageValue: common.HexToHash("01")}}
+		metadata := storage.ValueMetadata{Type: storage.Bool}
+
+		result, err := storage.Decode(diff, metadata)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("true"))
+	})
+
+	It("decodes bytes4", func() {
+		diff := storage.PersistedDiff{RawDiff: storage.RawDiff{StorageValue: common.HexToHash("a9059cbb")}}
+		metadata := storage.ValueMetadata{Type: storage.Bytes4}
+
+		result, err := storage.Decode(diff, metadata)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("0xa9059cbb"))
+	})
+
+	It("decodes a string stored in its own slot", func() {
+		value := common.BytesToHash(append(common.RightPadBytes([]byte("abc"), 31), 6))
+		diff := storage.PersistedDiff{RawDiff: storage.RawDiff{StorageValue: value}}
+		metadata := storage.ValueMetadata{Type: storage.String}
+
+		result, err := storage.Decode(diff, metadata)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("abc"))
+	})
+
+	It("returns an error for an unknown type", func() {
+		diff := storage.PersistedDiff{RawDiff: storage.RawDiff{StorageValue: common.HexToHash("01")}}
+		metadata := storage.ValueMetadata{Type: storage.ValueType(-1)}
+
+		_, err := storage.Decode(diff, metadata)
+
+		Expect(err).To(MatchError(storage.ErrUnknownValueType{Type: storage.ValueType(-1)}))
+	})
+
+	It("returns an error for an integer whose width isn't a whole number of bytes", func() {
+		diff := storage.PersistedDiff{RawDiff: storage.RawDiff{StorageValue: common.HexToHash("01")}}
+		metadata := storage.ValueMetadata{Type: storage.UintN(12)}
+
+		_, err := storage.Decode(diff, metadata)
+
+		Expect(err).To(MatchError(storage.ErrUnknownValueType{Type: storage.UintN(12)}))
+	})
+
 	Describe("when there are multiple items packed in the storage slot", func() {
 		It("decodes uint48 items", func() {
 			//this is a real storage data example
@@ -169,5 +262,56 @@ var _ = Describe("Storage decoder", func() {
 			Expect(decodedValues[1]).To(Equal(big.NewInt(0).SetBytes(common.HexToHash("2a30").Bytes()).String()))
 			Expect(decodedValues[2]).To(Equal(big.NewInt(0).SetBytes(common.HexToHash("2a300").Bytes()).String()))
 		})
+
+		It("decodes bool, uint8, int32, bytes4 and uint64 items", func() {
+			packedStorage := common.HexToHash("000000000000000000000000" + "0000000060f3a1c0" + "a9059cbb" + "fffffffe" + "12" + "01")
+			diff := storage.PersistedDiff{RawDiff: storage.RawDiff{StorageValue: packedStorage}}
+			packedTypes := map[int]storage.ValueType{
+				0: storage.Bool,
+				1: storage.Uint8,
+				2: storage.Int32,
+				3: storage.Bytes4,
+				4: storage.Uint64,
+			}
+
+			metadata := storage.ValueMetadata{
+				Type:        storage.PackedSlot,
+				PackedTypes: packedTypes,
+			}
+
+			result, err := storage.Decode(diff, metadata)
+			decodedValues := result.(map[int]string)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decodedValues[0]).To(Equal("true"))
+			Expect(decodedValues[1]).To(Equal("18"))
+			Expect(decodedValues[2]).To(Equal("-2"))
+			Expect(decodedValues[3]).To(Equal("0xa9059cbb"))
+			Expect(decodedValues[4]).To(Equal("1626579392"))
+		})
+
+		It("returns an error if the packed items overflow the slot", func() {
+			diff := storage.PersistedDiff{RawDiff: storage.RawDiff{StorageValue: common.HexToHash("01")}}
+			metadata := storage.ValueMetadata{
+				Type:        storage.PackedSlot,
+				PackedTypes: map[int]storage.ValueType{0: storage.Uint128, 1: storage.Uint128, 2: storage.Bool},
+			}
+
+			_, err := storage.Decode(diff, metadata)
+
+			Expect(err).To(MatchError(storage.ErrValueOverflowsSlot{Type: storage.Bool}))
+		})
+
+		It("returns an error if a packed item's type is unknown", func() {
+			diff := storage.PersistedDiff{RawDiff: storage.RawDiff{StorageValue: common.HexToHash("01")}}
+			metadata := storage.ValueMetadata{
+				Type:        storage.PackedSlot,
+				PackedTypes: map[int]storage.ValueType{0: storage.Bool, 1: storage.ValueType(-1)},
+			}
+
+			_, err := storage.Decode(diff, metadata)
+
+			Expect(err).To(MatchError(storage.ErrUnknownValueType{Type: storage.ValueType(-1)}))
+		})
 	})
 })
@@ -0,0 +1,117 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// String and Bytes values are only decoded up to MaxDynamicDataSlots * 32 bytes
+const MaxDynamicDataSlots = 128
+
+// Solidity stores a String or Bytes value of up to 31 bytes in its slot, followed by a last byte of length * 2.
+// Longer values store length * 2 + 1 in their slot, and their data in consecutive slots starting at keccak(slot).
+func DecodeDynamic(valueType ValueType, lengthSlot common.Hash, dataSlots []common.Hash) (string, error) {
+	if !valueType.IsDynamic() {
+		return "", ErrUnknownValueType{Type: valueType}
+	}
+	var data []byte
+	if isShortDynamicValue(lengthSlot) {
+		length := int(lengthSlot[bytesPerSlot-1] / 2)
+		if length > bytesPerSlot-1 {
+			return "", ErrDynamicValueMalformed{Slot: lengthSlot.Hex()}
+		}
+		data = lengthSlot[:length]
+	} else {
+		length := getLongDynamicLength(lengthSlot)
+		if !length.IsInt64() || length.Int64() > int64(len(dataSlots)*bytesPerSlot) {
+			return "", ErrDynamicValueIncomplete{DataSlots: len(dataSlots)}
+		}
+		for _, slot := range dataSlots {
+			data = append(data, slot.Bytes()...)
+		}
+		data = data[:length.Int64()]
+	}
+	if valueType == String {
+		return string(data), nil
+	}
+	return hexutil.Encode(data), nil
+}
+
+// GetNumberOfDynamicDataSlots returns how many data slots hold the value whose length slot is given
+func GetNumberOfDynamicDataSlots(lengthSlot common.Hash) (int, error) {
+	if isShortDynamicValue(lengthSlot) {
+		return 0, nil
+	}
+	length := getLongDynamicLength(lengthSlot)
+	slots := new(big.Int).Add(length, big.NewInt(bytesPerSlot-1))
+	slots.Div(slots, big.NewInt(bytesPerSlot))
+	if !slots.IsInt64() || slots.Int64() > MaxDynamicDataSlots {
+		return 0, ErrDynamicValueTooLong{Length: length.String()}
+	}
+	return int(slots.Int64()), nil
+}
+
+// GetDynamicDataKeys returns the keys of the first count data slots of the value whose length is stored at lengthKey
+func GetDynamicDataKeys(lengthKey common.Hash, count int) []common.Hash {
+	start := new(big.Int).SetBytes(crypto.Keccak256(lengthKey.Bytes()))
+	keys := make([]common.Hash, count)
+	for i := range keys {
+		key := new(big.Int).Add(start, big.NewInt(int64(i)))
+		keys[i] = common.BigToHash(key)
+	}
+	return keys
+}
+
+// AddDynamicLengthKeys records the key of its length slot on the metadata of every String and Bytes value. Their data
+// slots are only mapped with AddDynamicDataKeys, once the value's length is known.
+func AddDynamicLengthKeys(currentMappings map[common.Hash]ValueMetadata) map[common.Hash]ValueMetadata {
+	for k, v := range currentMappings {
+		if v.Type.IsDynamic() {
+			v.LengthKey = k
+			currentMappings[k] = v
+		}
+	}
+	return currentMappings
+}
+
+// AddDynamicDataKeys maps the raw and hashed keys of the first count data slots of the value whose length is stored at
+// lengthKey to its metadata
+func AddDynamicDataKeys(currentMappings map[common.Hash]ValueMetadata, lengthKey common.Hash, count int) map[common.Hash]ValueMetadata {
+	metadata, ok := currentMappings[lengthKey]
+	if !ok {
+		return currentMappings
+	}
+	for _, dataKey := range GetDynamicDataKeys(lengthKey, count) {
+		currentMappings[dataKey] = metadata
+		currentMappings[hashKey(dataKey)] = metadata
+	}
+	return currentMappings
+}
+
+func isShortDynamicValue(lengthSlot common.Hash) bool {
+	return lengthSlot[bytesPerSlot-1]&1 == 0
+}
+
+func getLongDynamicLength(lengthSlot common.Hash) *big.Int {
+	length := new(big.Int).SetBytes(lengthSlot.Bytes())
+	return length.Rsh(length, 1)
+}
@@ -0,0 +1,155 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage_test
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/makerdao/vulcanizedb/libraries/shared/storage"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dynamic storage values", func() {
+	var (
+		lengthKey = common.HexToHash("0x2")
+		// 40 byte strings are stored across 2 data slots, with 40 * 2 + 1 in their length slot
+		longValue      = strings.Repeat("vulcanize", 4) + "db!!"
+		longLengthSlot = common.BigToHash(big.NewInt(81))
+		longDataSlots  = []common.Hash{
+			common.BytesToHash([]byte(longValue[:32])),
+			common.BytesToHash(common.RightPadBytes([]byte(longValue[32:]), 32)),
+		}
+	)
+
+	Describe("DecodeDynamic", func() {
+		It("decodes a string stored in its length slot", func() {
+			lengthSlot := common.BytesToHash(append(common.RightPadBytes([]byte("abc"), 31), 6))
+
+			result, err := storage.DecodeDynamic(storage.String, lengthSlot, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal("abc"))
+		})
+
+		It("decodes bytes stored in its length slot", func() {
+			lengthSlot := common.BytesToHash(append(common.RightPadBytes([]byte{0xde, 0xad}, 31), 4))
+
+			result, err := storage.DecodeDynamic(storage.Bytes, lengthSlot, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal("0xdead"))
+		})
+
+		It("decodes an empty value", func() {
+			result, err := storage.DecodeDynamic(storage.String, common.Hash{}, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(""))
+		})
+
+		It("decodes a string stored across data slots", func() {
+			result, err := storage.DecodeDynamic(storage.String, longLengthSlot, longDataSlots)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(longValue))
+		})
+
+		It("returns an error if there aren't enough data slots for the length", func() {
+			_, err := storage.DecodeDynamic(storage.String, longLengthSlot, longDataSlots[:1])
+
+			Expect(err).To(MatchError(storage.ErrDynamicValueIncomplete{DataSlots: 1}))
+		})
+
+		It("returns an error if the length of a value stored in its length slot exceeds the slot", func() {
+			lengthSlot := common.BytesToHash([]byte{0xfe})
+
+			_, err := storage.DecodeDynamic(storage.String, lengthSlot, nil)
+
+			Expect(err).To(MatchError(storage.ErrDynamicValueMalformed{Slot: lengthSlot.Hex()}))
+		})
+
+		It("returns an error for a type that isn't dynamic", func() {
+			_, err := storage.DecodeDynamic(storage.Uint256, longLengthSlot, longDataSlots)
+
+			Expect(err).To(MatchError(storage.ErrUnknownValueType{Type: storage.Uint256}))
+		})
+	})
+
+	Describe("GetNumberOfDynamicDataSlots", func() {
+		It("returns zero for a value stored in its length slot", func() {
+			lengthSlot := common.BytesToHash(append(common.RightPadBytes([]byte("abc"), 31), 6))
+
+			Expect(storage.GetNumberOfDynamicDataSlots(lengthSlot)).To(Equal(0))
+		})
+
+		It("returns the number of slots needed for a longer value", func() {
+			Expect(storage.GetNumberOfDynamicDataSlots(longLengthSlot)).To(Equal(2))
+		})
+
+		It("returns an error for a value longer than the maximum number of data slots", func() {
+			length := storage.MaxDynamicDataSlots*32 + 1
+			lengthSlot := common.BigToHash(big.NewInt(int64(length*2 + 1)))
+
+			_, err := storage.GetNumberOfDynamicDataSlots(lengthSlot)
+
+			Expect(err).To(MatchError(storage.ErrDynamicValueTooLong{Length: big.NewInt(int64(length)).String()}))
+		})
+	})
+
+	It("returns consecutive data keys starting at the hash of the length key", func() {
+		start := crypto.Keccak256Hash(lengthKey.Bytes())
+
+		keys := storage.GetDynamicDataKeys(lengthKey, 2)
+
+		Expect(keys).To(Equal([]common.Hash{start, common.BigToHash(new(big.Int).Add(start.Big(), big.NewInt(1)))}))
+	})
+
+	It("records the length key of dynamic values on their metadata", func() {
+		stringMetadata := storage.ValueMetadata{Name: "name", Type: storage.String}
+		uintMetadata := storage.ValueMetadata{Name: "supply", Type: storage.Uint256}
+		mappings := map[common.Hash]storage.ValueMetadata{
+			lengthKey:               stringMetadata,
+			common.HexToHash("0x3"): uintMetadata,
+		}
+
+		result := storage.AddDynamicLengthKeys(mappings)
+
+		expectedMetadata := stringMetadata
+		expectedMetadata.LengthKey = lengthKey
+		Expect(result).To(Equal(map[common.Hash]storage.ValueMetadata{
+			lengthKey:               expectedMetadata,
+			common.HexToHash("0x3"): uintMetadata,
+		}))
+	})
+
+	It("maps the raw and hashed keys of a dynamic value's data slots to its metadata", func() {
+		metadata := storage.ValueMetadata{Name: "name", Type: storage.String, LengthKey: lengthKey}
+		mappings := map[common.Hash]storage.ValueMetadata{lengthKey: metadata}
+
+		result := storage.AddDynamicDataKeys(mappings, lengthKey, 2)
+
+		Expect(len(result)).To(Equal(5))
+		for _, dataKey := range storage.GetDynamicDataKeys(lengthKey, 2) {
+			Expect(result[dataKey]).To(Equal(metadata))
+			Expect(result[crypto.Keccak256Hash(dataKey.Bytes())]).To(Equal(metadata))
+		}
+	})
+})
@@ -51,3 +51,68 @@ type ErrKeyNotFound struct {
 func (e ErrKeyNotFound) Error() string {
 	return fmt.Sprintf("unknown storage key: %s", e.Key)
 }
+
+type ErrUnknownValueType struct {
+	Type ValueType
+}
+
+func (e ErrUnknownValueType) Error() string {
+	return fmt.Sprintf("can't decode unknown type: %d", e.Type)
+}
+
+type ErrValueOverflowsSlot struct {
+	Type ValueType
+}
+
+func (e ErrValueOverflowsSlot) Error() string {
+	return fmt.Sprintf("value of type %d overflows storage slot", e.Type)
+}
+
+type ErrPackedPositionMissing struct {
+	Position int
+}
+
+func (e ErrPackedPositionMissing) Error() string {
+	return fmt.Sprintf("packed slot metadata malformed: missing type at position %d", e.Position)
+}
+
+type ErrDynamicValueIncomplete struct {
+	DataSlots int
+}
+
+func (e ErrDynamicValueIncomplete) Error() string {
+	return fmt.Sprintf("dynamic value is longer than its %d data slots", e.DataSlots)
+}
+
+type ErrDynamicValueTooLong struct {
+	Length string
+}
+
+func (e ErrDynamicValueTooLong) Error() string {
+	return fmt.Sprintf("dynamic value of length %s exceeds %d data slots", e.Length, MaxDynamicDataSlots)
+}
+
+type ErrDynamicValueMalformed struct {
+	Slot string
+}
+
+func (e ErrDynamicValueMalformed) Error() string {
+	return fmt.Sprintf("slot %s doesn't hold a valid short string or bytes value", e.Slot)
+}
+
+type ErrDynamicValueBuffered struct {
+	Name        string
+	BlockHeight int
+}
+
+func (e ErrDynamicValueBuffered) Error() string {
+	return fmt.Sprintf("buffering %s until every storage diff from block %d has arrived", e.Name, e.BlockHeight)
+}
+
+type ErrUnsupportedStorageType struct {
+	Type string
+}
+
+func (e ErrUnsupportedStorageType) Error() string {
+	return fmt.Sprintf("unsupported storage layout type: %s", e.Type)
+}
@@ -0,0 +1,343 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Layout describes how a variable of some type is laid out in storage, so that the keys of all of its slots and their
+// metadata can be derived from the slot it's declared at. Layouts compose: a Mapping of a Struct with a DynamicArray
+// member derives the keys of every element of the array for every known key of the mapping.
+type Layout interface {
+	numberOfSlots() int64
+	// packable values are placed in the same slot as their neighbours if they fit, while other layouts always start a
+	// new slot
+	packable() bool
+	numberOfBytes() int
+	derive(slot common.Hash, name string, keys map[Key]string, mappings map[common.Hash]ValueMetadata) error
+}
+
+// DeriveKeys returns the metadata of every slot of a variable declared at the slot, keyed by the slot's key
+func DeriveKeys(slot common.Hash, name string, layout Layout) (map[common.Hash]ValueMetadata, error) {
+	mappings := make(map[common.Hash]ValueMetadata)
+	err := layout.derive(slot, name, nil, mappings)
+	return mappings, err
+}
+
+// KeyValues returns the keys of a mapping to derive slots for, given the keys of the mappings it's nested in
+type KeyValues func(keys map[Key]string) []string
+
+// KnownKeys returns the same keys regardless of the mappings a mapping is nested in
+func KnownKeys(values ...string) KeyValues {
+	return func(map[Key]string) []string { return values }
+}
+
+// ArrayLength returns the number of elements of a dynamic array to derive slots for, given the keys of the mappings
+// it's nested in
+type ArrayLength func(keys map[Key]string) int64
+
+// KnownLength returns the same length regardless of the mappings an array is nested in
+func KnownLength(length int64) ArrayLength {
+	return func(map[Key]string) int64 { return length }
+}
+
+type valueLayout struct {
+	valueType ValueType
+}
+
+// Value lays out a single value of the type, including String and Bytes values (whose data slots are added by
+// AddDynamicDataKeys once their length is known)
+func Value(valueType ValueType) Layout {
+	return valueLayout{valueType: valueType}
+}
+
+func (layout valueLayout) numberOfSlots() int64 {
+	return 1
+}
+
+func (layout valueLayout) packable() bool {
+	return !layout.valueType.IsDynamic() && layout.valueType != PackedSlot
+}
+
+func (layout valueLayout) numberOfBytes() int {
+	numberOfBytes, err := getNumberOfBytes(layout.valueType)
+	if err != nil || !layout.packable() {
+		return bytesPerSlot
+	}
+	return numberOfBytes
+}
+
+func (layout valueLayout) derive(slot common.Hash, name string, keys map[Key]string, mappings map[common.Hash]ValueMetadata) error {
+	if layout.valueType == PackedSlot {
+		return ErrUnknownValueType{Type: layout.valueType}
+	}
+	if !layout.valueType.IsDynamic() {
+		if _, err := getNumberOfBytes(layout.valueType); err != nil {
+			return err
+		}
+	}
+	mappings[slot] = GetValueMetadata(name, copyKeys(keys), layout.valueType)
+	return nil
+}
+
+type StructField struct {
+	Name   string
+	Layout Layout
+}
+
+// Field is a member of a struct. Its name is used as the name of the metadata of its slots.
+func Field(name string, layout Layout) StructField {
+	return StructField{Name: name, Layout: layout}
+}
+
+type fieldPlacement struct {
+	slot   int64
+	fields []StructField
+}
+
+type structLayout struct {
+	placements []fieldPlacement
+	slots      int64
+}
+
+// Struct lays out its fields in consecutive slots, packing neighbouring values that fit into one slot the way Solidity
+// does. A slot holding several fields is described by PackedSlot metadata named after the struct.
+func Struct(fields ...StructField) Layout {
+	var placements []fieldPlacement
+	var slot int64
+	offset := 0
+	for _, field := range fields {
+		if field.Layout.packable() {
+			size := field.Layout.numberOfBytes()
+			if offset+size > bytesPerSlot {
+				slot++
+				offset = 0
+			}
+			if offset > 0 {
+				last := &placements[len(placements)-1]
+				last.fields = append(last.fields, field)
+			} else {
+				placements = append(placements, fieldPlacement{slot: slot, fields: []StructField{field}})
+			}
+			offset += size
+			continue
+		}
+		if offset > 0 {
+			slot++
+			offset = 0
+		}
+		placements = append(placements, fieldPlacement{slot: slot, fields: []StructField{field}})
+		slot += field.Layout.numberOfSlots()
+	}
+	if offset > 0 {
+		slot++
+	}
+	return structLayout{placements: placements, slots: slot}
+}
+
+// PlacedField is a member of a struct at a slot (relative to the struct's) and byte offset within that slot
+type PlacedField struct {
+	StructField
+	Slot   int64
+	Offset int
+}
+
+// PlaceField places a struct member at the slot and offset given for it, e.g. by solc's storage layout
+func PlaceField(field StructField, slot int64, offset int) PlacedField {
+	return PlacedField{StructField: field, Slot: slot, Offset: offset}
+}
+
+// StructAt lays out its fields at the slots and offsets placing them, rather than packing them itself, and takes
+// numberOfSlots slots. Fields sharing a slot are described by PackedSlot metadata named after the struct, in offset
+// order.
+func StructAt(numberOfSlots int64, fields ...PlacedField) Layout {
+	sorted := make([]PlacedField, len(fields))
+	copy(sorted, fields)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Slot < sorted[j].Slot || (sorted[i].Slot == sorted[j].Slot && sorted[i].Offset < sorted[j].Offset)
+	})
+	var placements []fieldPlacement
+	for _, field := range sorted {
+		if len(placements) > 0 && placements[len(placements)-1].slot == field.Slot {
+			last := &placements[len(placements)-1]
+			last.fields = append(last.fields, field.StructField)
+			continue
+		}
+		placements = append(placements, fieldPlacement{slot: field.Slot, fields: []StructField{field.StructField}})
+	}
+	return structLayout{placements: placements, slots: numberOfSlots}
+}
+
+func (layout structLayout) numberOfSlots() int64 {
+	return layout.slots
+}
+
+func (layout structLayout) packable() bool {
+	return false
+}
+
+func (layout structLayout) numberOfBytes() int {
+	return bytesPerSlot
+}
+
+func (layout structLayout) derive(slot common.Hash, name string, keys map[Key]string, mappings map[common.Hash]ValueMetadata) error {
+	for _, placement := range layout.placements {
+		fieldSlot := GetIncrementedKey(slot, placement.slot)
+		if len(placement.fields) == 1 {
+			field := placement.fields[0]
+			if err := field.Layout.derive(fieldSlot, field.Name, keys, mappings); err != nil {
+				return err
+			}
+			continue
+		}
+		packedNames := make(map[int]string)
+		packedTypes := make(map[int]ValueType)
+		for position, field := range placement.fields {
+			value, ok := field.Layout.(valueLayout)
+			if !ok || !value.packable() {
+				return ErrUnknownValueType{Type: PackedSlot}
+			}
+			packedNames[position] = field.Name
+			packedTypes[position] = value.valueType
+			if _, err := getNumberOfBytes(packedTypes[position]); err != nil {
+				return err
+			}
+		}
+		mappings[fieldSlot] = GetValueMetadataForPackedSlot(name, copyKeys(keys), PackedSlot, packedNames, packedTypes)
+	}
+	return nil
+}
+
+type mappingLayout struct {
+	key    Key
+	values KeyValues
+	value  Layout
+}
+
+// Mapping lays out the value for each of the mapping's keys at keccak256(key . slot). Keys are hex, and are left padded
+// to 32 bytes like address and integer keys. The value's metadata records each key under the given Key.
+func Mapping(key Key, values KeyValues, value Layout) Layout {
+	return mappingLayout{key: key, values: values, value: value}
+}
+
+func (layout mappingLayout) numberOfSlots() int64 {
+	return 1
+}
+
+func (layout mappingLayout) packable() bool {
+	return false
+}
+
+func (layout mappingLayout) numberOfBytes() int {
+	return bytesPerSlot
+}
+
+func (layout mappingLayout) derive(slot common.Hash, name string, keys map[Key]string, mappings map[common.Hash]ValueMetadata) error {
+	for _, value := range layout.values(keys) {
+		paddedKey := common.LeftPadBytes(common.FromHex(value), bytesPerSlot)
+		valueSlot := crypto.Keccak256Hash(paddedKey, slot.Bytes())
+		err := layout.value.derive(valueSlot, name, withKey(keys, layout.key, value), mappings)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type dynamicArrayLayout struct {
+	index   Key
+	length  ArrayLength
+	element Layout
+}
+
+// DynamicArray lays out the array's length at its slot (as a Uint256 named after the array with a "_length" suffix), and
+// its elements from keccak256(slot). Values of up to 16 bytes are packed several to a slot, described by PackedSlot
+// metadata whose packed names are the elements' indexes. Other elements record their index under the given Key.
+func DynamicArray(index Key, length ArrayLength, element Layout) Layout {
+	return dynamicArrayLayout{index: index, length: length, element: element}
+}
+
+func (layout dynamicArrayLayout) numberOfSlots() int64 {
+	return 1
+}
+
+func (layout dynamicArrayLayout) packable() bool {
+	return false
+}
+
+func (layout dynamicArrayLayout) numberOfBytes() int {
+	return bytesPerSlot
+}
+
+func (layout dynamicArrayLayout) derive(slot common.Hash, name string, keys map[Key]string, mappings map[common.Hash]ValueMetadata) error {
+	mappings[slot] = GetValueMetadata(name+"_length", copyKeys(keys), Uint256)
+	dataSlot := crypto.Keccak256Hash(slot.Bytes())
+	length := layout.length(keys)
+
+	elementsPerSlot := 1
+	if layout.element.packable() {
+		elementsPerSlot = bytesPerSlot / layout.element.numberOfBytes()
+	}
+	if elementsPerSlot == 1 {
+		for i := int64(0); i < length; i++ {
+			elementSlot := GetIncrementedKey(dataSlot, i*layout.element.numberOfSlots())
+			err := layout.element.derive(elementSlot, name, withKey(keys, layout.index, strconv.FormatInt(i, 10)), mappings)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	elementType := layout.element.(valueLayout).valueType
+	for first := int64(0); first < length; first += int64(elementsPerSlot) {
+		packedNames := make(map[int]string)
+		packedTypes := make(map[int]ValueType)
+		for position := 0; position < elementsPerSlot && first+int64(position) < length; position++ {
+			packedNames[position] = strconv.FormatInt(first+int64(position), 10)
+			packedTypes[position] = elementType
+		}
+		elementSlot := GetIncrementedKey(dataSlot, first/int64(elementsPerSlot))
+		mappings[elementSlot] = GetValueMetadataForPackedSlot(name, copyKeys(keys), PackedSlot, packedNames, packedTypes)
+	}
+	return nil
+}
+
+func withKey(keys map[Key]string, key Key, value string) map[Key]string {
+	result := copyKeys(keys)
+	if result == nil {
+		result = make(map[Key]string)
+	}
+	result[key] = value
+	return result
+}
+
+func copyKeys(keys map[Key]string) map[Key]string {
+	if len(keys) == 0 {
+		return nil
+	}
+	result := make(map[Key]string, len(keys))
+	for k, v := range keys {
+		result[k] = v
+	}
+	return result
+}
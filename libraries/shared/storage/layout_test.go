@@ -0,0 +1,207 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage_test
+
+import (
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/makerdao/vulcanizedb/libraries/shared/storage"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Storage layouts", func() {
+	var (
+		slot      = common.HexToHash(storage.IndexTwo)
+		ilk       = "4554482d41000000000000000000000000000000000000000000000000000000"
+		guy       = "0x00000000000000000000000000000000000000aa"
+		paddedGuy = "00000000000000000000000000000000000000000000000000000000000000aa"
+		Ilk       = storage.Key("ilk")
+		Guy       = storage.Key("guy")
+		Index     = storage.Key("index")
+	)
+
+	It("derives the key of a value", func() {
+		mappings, err := storage.DeriveKeys(slot, "supply", storage.Value(storage.Uint256))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mappings).To(Equal(map[common.Hash]storage.ValueMetadata{
+			slot: storage.GetValueMetadata("supply", nil, storage.Uint256),
+		}))
+	})
+
+	It("returns an error for a value of unknown type", func() {
+		_, err := storage.DeriveKeys(slot, "supply", storage.Value(storage.ValueType(-1)))
+
+		Expect(err).To(MatchError(storage.ErrUnknownValueType{Type: storage.ValueType(-1)}))
+	})
+
+	Describe("Struct", func() {
+		It("packs neighbouring fields that fit into a slot", func() {
+			layout := storage.Struct(
+				storage.Field("quantity", storage.Value(storage.Uint256)),
+				storage.Field("expiry", storage.Value(storage.Uint48)),
+				storage.Field("owner", storage.Value(storage.Address)),
+				storage.Field("price", storage.Value(storage.Uint128)),
+			)
+
+			mappings, err := storage.DeriveKeys(slot, "item", layout)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mappings).To(Equal(map[common.Hash]storage.ValueMetadata{
+				slot: storage.GetValueMetadata("quantity", nil, storage.Uint256),
+				storage.GetIncrementedKey(slot, 1): storage.GetValueMetadataForPackedSlot("item", nil, storage.PackedSlot,
+					map[int]string{0: "expiry", 1: "owner"},
+					map[int]storage.ValueType{0: storage.Uint48, 1: storage.Address}),
+				storage.GetIncrementedKey(slot, 2): storage.GetValueMetadata("price", nil, storage.Uint128),
+			}))
+		})
+
+		It("starts a new slot after a field that isn't a value", func() {
+			layout := storage.Struct(
+				storage.Field("live", storage.Value(storage.Bool)),
+				storage.Field("name", storage.Value(storage.String)),
+				storage.Field("decimals", storage.Value(storage.Uint8)),
+				storage.Field("inner", storage.Struct(
+					storage.Field("first", storage.Value(storage.Uint256)),
+					storage.Field("second", storage.Value(storage.Uint256)),
+				)),
+				storage.Field("count", storage.Value(storage.Uint8)),
+			)
+
+			mappings, err := storage.DeriveKeys(slot, "token", layout)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mappings).To(Equal(map[common.Hash]storage.ValueMetadata{
+				slot:                               storage.GetValueMetadata("live", nil, storage.Bool),
+				storage.GetIncrementedKey(slot, 1): storage.GetValueMetadata("name", nil, storage.String),
+				storage.GetIncrementedKey(slot, 2): storage.GetValueMetadata("decimals", nil, storage.Uint8),
+				storage.GetIncrementedKey(slot, 3): storage.GetValueMetadata("first", nil, storage.Uint256),
+				storage.GetIncrementedKey(slot, 4): storage.GetValueMetadata("second", nil, storage.Uint256),
+				storage.GetIncrementedKey(slot, 5): storage.GetValueMetadata("count", nil, storage.Uint8),
+			}))
+		})
+	})
+
+	Describe("Mapping", func() {
+		It("derives the keys of a struct in a mapping", func() {
+			layout := storage.Mapping(Ilk, storage.KnownKeys(ilk), storage.Struct(
+				storage.Field("Art", storage.Value(storage.Uint256)),
+				storage.Field("rate", storage.Value(storage.Uint256)),
+			))
+
+			mappings, err := storage.DeriveKeys(slot, "ilks", layout)
+
+			Expect(err).NotTo(HaveOccurred())
+			artKey := storage.GetKeyForMapping(storage.IndexTwo, ilk)
+			keys := map[storage.Key]string{Ilk: ilk}
+			Expect(mappings).To(Equal(map[common.Hash]storage.ValueMetadata{
+				artKey:                               storage.GetValueMetadata("Art", keys, storage.Uint256),
+				storage.GetIncrementedKey(artKey, 1): storage.GetValueMetadata("rate", keys, storage.Uint256),
+			}))
+		})
+
+		It("pads keys to 32 bytes", func() {
+			layout := storage.Mapping(Guy, storage.KnownKeys(guy), storage.Value(storage.Uint256))
+
+			mappings, err := storage.DeriveKeys(slot, "balances", layout)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mappings).To(HaveKey(storage.GetKeyForMapping(storage.IndexTwo, paddedGuy)))
+		})
+
+		It("derives the keys of nested mappings from the keys of the mappings they're nested in", func() {
+			guysByIlk := map[string][]string{ilk: {guy}}
+			layout := storage.Mapping(Ilk, storage.KnownKeys(ilk, "01"),
+				storage.Mapping(Guy, func(keys map[storage.Key]string) []string { return guysByIlk[keys[Ilk]] },
+					storage.Value(storage.Uint256)))
+
+			mappings, err := storage.DeriveKeys(slot, "gem", layout)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mappings).To(Equal(map[common.Hash]storage.ValueMetadata{
+				storage.GetKeyForNestedMapping(storage.IndexTwo, ilk, paddedGuy): storage.GetValueMetadata("gem",
+					map[storage.Key]string{Ilk: ilk, Guy: guy}, storage.Uint256),
+			}))
+		})
+	})
+
+	Describe("DynamicArray", func() {
+		It("derives the keys of the length and elements of an array", func() {
+			layout := storage.DynamicArray(Index, storage.KnownLength(2), storage.Value(storage.Address))
+
+			mappings, err := storage.DeriveKeys(slot, "owners", layout)
+
+			Expect(err).NotTo(HaveOccurred())
+			dataKey := crypto.Keccak256Hash(slot.Bytes())
+			Expect(mappings).To(Equal(map[common.Hash]storage.ValueMetadata{
+				slot:    storage.GetValueMetadata("owners_length", nil, storage.Uint256),
+				dataKey: storage.GetValueMetadata("owners", map[storage.Key]string{Index: "0"}, storage.Address),
+				storage.GetIncrementedKey(dataKey, 1): storage.GetValueMetadata("owners",
+					map[storage.Key]string{Index: "1"}, storage.Address),
+			}))
+		})
+
+		It("packs small elements several to a slot", func() {
+			layout := storage.DynamicArray(Index, storage.KnownLength(33), storage.Value(storage.Uint8))
+
+			mappings, err := storage.DeriveKeys(slot, "decimals", layout)
+
+			Expect(err).NotTo(HaveOccurred())
+			dataKey := crypto.Keccak256Hash(slot.Bytes())
+			Expect(len(mappings)).To(Equal(3))
+			Expect(mappings[dataKey].Type).To(Equal(storage.PackedSlot))
+			Expect(len(mappings[dataKey].PackedTypes)).To(Equal(32))
+			Expect(mappings[dataKey].PackedNames[31]).To(Equal(strconv.Itoa(31)))
+			Expect(mappings[storage.GetIncrementedKey(dataKey, 1)]).To(Equal(storage.GetValueMetadataForPackedSlot("decimals",
+				nil, storage.PackedSlot, map[int]string{0: "32"}, map[int]storage.ValueType{0: storage.Uint8})))
+		})
+
+		It("derives the keys of structs spanning several slots", func() {
+			layout := storage.DynamicArray(Index, storage.KnownLength(2), storage.Struct(
+				storage.Field("amount", storage.Value(storage.Uint256)),
+				storage.Field("owner", storage.Value(storage.Address)),
+			))
+
+			mappings, err := storage.DeriveKeys(slot, "deposits", layout)
+
+			Expect(err).NotTo(HaveOccurred())
+			dataKey := crypto.Keccak256Hash(slot.Bytes())
+			Expect(mappings[storage.GetIncrementedKey(dataKey, 2)]).To(Equal(storage.GetValueMetadata("amount",
+				map[storage.Key]string{Index: "1"}, storage.Uint256)))
+			Expect(mappings[storage.GetIncrementedKey(dataKey, 3)]).To(Equal(storage.GetValueMetadata("owner",
+				map[storage.Key]string{Index: "1"}, storage.Address)))
+		})
+
+		It("derives the keys of arrays in a mapping", func() {
+			layout := storage.Mapping(Guy, storage.KnownKeys(guy),
+				storage.DynamicArray(Index, storage.KnownLength(1), storage.Value(storage.Uint256)))
+
+			mappings, err := storage.DeriveKeys(slot, "history", layout)
+
+			Expect(err).NotTo(HaveOccurred())
+			arrayKey := storage.GetKeyForMapping(storage.IndexTwo, paddedGuy)
+			Expect(mappings).To(Equal(map[common.Hash]storage.ValueMetadata{
+				arrayKey: storage.GetValueMetadata("history_length", map[storage.Key]string{Guy: guy}, storage.Uint256),
+				crypto.Keccak256Hash(arrayKey.Bytes()): storage.GetValueMetadata("history",
+					map[storage.Key]string{Guy: guy, Index: "0"}, storage.Uint256),
+			}))
+		})
+	})
+})
@@ -0,0 +1,299 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"encoding/json"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// IndexKey records the index of a dynamic array element in the metadata derived from a solc storage layout
+const IndexKey Key = "index"
+
+// SolcStorageLayout is the storageLayout output of solc (0.5.13 and later)
+type SolcStorageLayout struct {
+	Storage []SolcStorageEntry         `json:"storage"`
+	Types   map[string]SolcStorageType `json:"types"`
+}
+
+type SolcStorageEntry struct {
+	Label  string `json:"label"`
+	Offset int    `json:"offset"`
+	Slot   string `json:"slot"`
+	Type   string `json:"type"`
+}
+
+type SolcStorageType struct {
+	Encoding      string             `json:"encoding"`
+	Label         string             `json:"label"`
+	NumberOfBytes string             `json:"numberOfBytes"`
+	Key           string             `json:"key,omitempty"`
+	Value         string             `json:"value,omitempty"`
+	Base          string             `json:"base,omitempty"`
+	Members       []SolcStorageEntry `json:"members,omitempty"`
+}
+
+// KnownValues are the mapping keys and array lengths to derive a variable's slots for. Each row holds a key for each
+// of the mappings the variable is nested in, outermost first, followed by the length of the array they lead to (if
+// any). Keys are recorded in the metadata under the corresponding entry of Keys.
+type KnownValues struct {
+	Keys []Key
+	Rows [][]string
+}
+
+func ParseSolcStorageLayout(raw []byte) (SolcStorageLayout, error) {
+	var layout SolcStorageLayout
+	err := json.Unmarshal(raw, &layout)
+	return layout, err
+}
+
+// DeriveKeys returns the metadata of every slot of the contract's variables, keyed by the slot's key. Mappings and
+// dynamic arrays are derived for the known values of their variable, and variables without known values only derive
+// the slots that don't depend on them. Each variable's metadata is named after it, struct members' after the variable
+// and member (e.g. ilks_Art), and the metadata of a slot packing several variables after the variables. Variables of
+// unsupported types return ErrUnsupportedStorageType.
+func (layout SolcStorageLayout) DeriveKeys(known map[string]KnownValues) (map[common.Hash]ValueMetadata, error) {
+	mappings := make(map[common.Hash]ValueMetadata)
+	for _, entries := range layout.groupBySlot() {
+		slot, ok := new(big.Int).SetString(entries[0].Slot, 10)
+		if !ok {
+			return nil, ErrUnsupportedStorageType{Type: entries[0].Type}
+		}
+		slotKey := common.BigToHash(slot)
+
+		if len(entries) > 1 {
+			metadata, err := layout.packedMetadata(entries)
+			if err != nil {
+				return nil, err
+			}
+			mappings[slotKey] = metadata
+			continue
+		}
+
+		entry := entries[0]
+		variableLayout, err := layout.toLayout(entry.Type, entry.Label, known[entry.Label], 0)
+		if err != nil {
+			return nil, err
+		}
+		derived, err := DeriveKeys(slotKey, entry.Label, variableLayout)
+		if err != nil {
+			return nil, err
+		}
+		for key, metadata := range derived {
+			mappings[key] = metadata
+		}
+	}
+	return mappings, nil
+}
+
+func (layout SolcStorageLayout) groupBySlot() [][]SolcStorageEntry {
+	var groups [][]SolcStorageEntry
+	indexes := make(map[string]int)
+	for _, entry := range layout.Storage {
+		index, ok := indexes[entry.Slot]
+		if !ok {
+			indexes[entry.Slot] = len(groups)
+			groups = append(groups, []SolcStorageEntry{entry})
+			continue
+		}
+		groups[index] = append(groups[index], entry)
+	}
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool { return group[i].Offset < group[j].Offset })
+	}
+	return groups
+}
+
+func (layout SolcStorageLayout) packedMetadata(entries []SolcStorageEntry) (ValueMetadata, error) {
+	var labels []string
+	packedNames := make(map[int]string)
+	packedTypes := make(map[int]ValueType)
+	for position, entry := range entries {
+		valueType, err := layout.toValueType(entry.Type)
+		if err != nil {
+			return ValueMetadata{}, err
+		}
+		labels = append(labels, entry.Label)
+		packedNames[position] = entry.Label
+		packedTypes[position] = valueType
+	}
+	return GetValueMetadataForPackedSlot(strings.Join(labels, "_"), nil, PackedSlot, packedNames, packedTypes), nil
+}
+
+// Converts a solc type to a Layout. depth is the number of mappings the type is nested in.
+func (layout SolcStorageLayout) toLayout(typeID, name string, known KnownValues, depth int) (Layout, error) {
+	solcType, ok := layout.Types[typeID]
+	if !ok {
+		return nil, ErrUnsupportedStorageType{Type: typeID}
+	}
+	switch solcType.Encoding {
+	case "inplace":
+		if solcType.Members != nil {
+			return layout.toStruct(typeID, solcType, name, known, depth)
+		}
+		if solcType.Base != "" {
+			return nil, ErrUnsupportedStorageType{Type: typeID}
+		}
+		valueType, err := layout.toValueType(typeID)
+		if err != nil {
+			return nil, err
+		}
+		return Value(valueType), nil
+	case "bytes":
+		if solcType.Label == "string" {
+			return Value(String), nil
+		}
+		return Value(Bytes), nil
+	case "mapping":
+		// keys of dynamic types are hashed without padding, so their slots can't be derived from a Mapping
+		if keyType, ok := layout.Types[solcType.Key]; !ok || keyType.Encoding != "inplace" {
+			return nil, ErrUnsupportedStorageType{Type: typeID}
+		}
+		value, err := layout.toLayout(solcType.Value, name, known, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return Mapping(known.keyAt(depth), known.keysAt(depth), value), nil
+	case "dynamic_array":
+		element, err := layout.toLayout(solcType.Base, name, known, depth)
+		if err != nil {
+			return nil, err
+		}
+		return DynamicArray(IndexKey, known.lengthAt(depth), element), nil
+	default:
+		return nil, ErrUnsupportedStorageType{Type: typeID}
+	}
+}
+
+// Members are placed at the slot (relative to the struct's) and offset solc assigns them
+func (layout SolcStorageLayout) toStruct(typeID string, solcType SolcStorageType, name string, known KnownValues, depth int) (Layout, error) {
+	numberOfBytes, err := strconv.ParseInt(solcType.NumberOfBytes, 10, 64)
+	if err != nil {
+		return nil, ErrUnsupportedStorageType{Type: typeID}
+	}
+	var fields []PlacedField
+	for _, member := range solcType.Members {
+		slot, err := strconv.ParseInt(member.Slot, 10, 64)
+		if err != nil {
+			return nil, ErrUnsupportedStorageType{Type: typeID}
+		}
+		memberName := name + "_" + member.Label
+		memberLayout, err := layout.toLayout(member.Type, memberName, known, depth)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, PlaceField(Field(memberName, memberLayout), slot, member.Offset))
+	}
+	return StructAt(numberOfBytes/bytesPerSlot, fields...), nil
+}
+
+func (layout SolcStorageLayout) toValueType(typeID string) (ValueType, error) {
+	solcType, ok := layout.Types[typeID]
+	if !ok || solcType.Encoding != "inplace" || solcType.Members != nil || solcType.Base != "" {
+		return 0, ErrUnsupportedStorageType{Type: typeID}
+	}
+	label := solcType.Label
+	switch {
+	case label == "bool":
+		return Bool, nil
+	case label == "address" || label == "address payable" || strings.HasPrefix(label, "contract "):
+		return Address, nil
+	case strings.HasPrefix(label, "enum "):
+		size, err := strconv.Atoi(solcType.NumberOfBytes)
+		if err != nil {
+			return 0, ErrUnsupportedStorageType{Type: typeID}
+		}
+		return UintN(size * bitsPerByte), nil
+	case strings.HasPrefix(label, "uint"):
+		return parseSize(typeID, label, "uint", UintN)
+	case strings.HasPrefix(label, "int"):
+		return parseSize(typeID, label, "int", IntN)
+	case strings.HasPrefix(label, "bytes"):
+		return parseSize(typeID, label, "bytes", BytesN)
+	default:
+		return 0, ErrUnsupportedStorageType{Type: typeID}
+	}
+}
+
+func parseSize(typeID, label, prefix string, toValueType func(int) ValueType) (ValueType, error) {
+	size, err := strconv.Atoi(strings.TrimPrefix(label, prefix))
+	if err != nil {
+		return 0, ErrUnsupportedStorageType{Type: typeID}
+	}
+	return toValueType(size), nil
+}
+
+func (known KnownValues) keyAt(depth int) Key {
+	if depth < len(known.Keys) {
+		return known.Keys[depth]
+	}
+	return Key("key" + strconv.Itoa(depth))
+}
+
+// Keys at a depth are those of rows matching the keys of the mappings they're nested in
+func (known KnownValues) keysAt(depth int) KeyValues {
+	return func(keys map[Key]string) []string {
+		var values []string
+		seen := make(map[string]bool)
+		for _, row := range known.matchingRows(keys, depth) {
+			if depth < len(row) && !seen[row[depth]] {
+				seen[row[depth]] = true
+				values = append(values, row[depth])
+			}
+		}
+		return values
+	}
+}
+
+// The length of an array nested in depth mappings follows their keys in the row, and the longest matching row wins
+func (known KnownValues) lengthAt(depth int) ArrayLength {
+	return func(keys map[Key]string) int64 {
+		var length int64
+		for _, row := range known.matchingRows(keys, depth) {
+			if depth >= len(row) {
+				continue
+			}
+			rowLength, err := strconv.ParseInt(row[depth], 10, 64)
+			if err == nil && rowLength > length {
+				length = rowLength
+			}
+		}
+		return length
+	}
+}
+
+func (known KnownValues) matchingRows(keys map[Key]string, depth int) [][]string {
+	var rows [][]string
+	for _, row := range known.Rows {
+		matches := true
+		for i := 0; i < depth && i < len(row); i++ {
+			if keys[known.keyAt(i)] != row[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
@@ -0,0 +1,172 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage_test
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/makerdao/vulcanizedb/libraries/shared/storage"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Solc storage layouts", func() {
+	var (
+		rawLayout = []byte(`{
+			"storage": [
+				{"astId": 3, "contract": "Vat.sol:Vat", "label": "wards", "offset": 0, "slot": "0", "type": "t_mapping(t_address,t_uint256)"},
+				{"astId": 9, "contract": "Vat.sol:Vat", "label": "ilks", "offset": 0, "slot": "1", "type": "t_mapping(t_bytes32,t_struct(Ilk)6_storage)"},
+				{"astId": 11, "contract": "Vat.sol:Vat", "label": "owner", "offset": 0, "slot": "2", "type": "t_address"},
+				{"astId": 13, "contract": "Vat.sol:Vat", "label": "live", "offset": 20, "slot": "2", "type": "t_bool"},
+				{"astId": 15, "contract": "Vat.sol:Vat", "label": "name", "offset": 0, "slot": "3", "type": "t_string_storage"},
+				{"astId": 20, "contract": "Vat.sol:Vat", "label": "history", "offset": 0, "slot": "4", "type": "t_mapping(t_address,t_array(t_uint256)dyn_storage)"}
+			],
+			"types": {
+				"t_address": {"encoding": "inplace", "label": "address", "numberOfBytes": "20"},
+				"t_array(t_uint256)dyn_storage": {"base": "t_uint256", "encoding": "dynamic_array", "label": "uint256[]", "numberOfBytes": "32"},
+				"t_bool": {"encoding": "inplace", "label": "bool", "numberOfBytes": "1"},
+				"t_bytes32": {"encoding": "inplace", "label": "bytes32", "numberOfBytes": "32"},
+				"t_mapping(t_address,t_array(t_uint256)dyn_storage)": {"encoding": "mapping", "key": "t_address", "label": "mapping(address => uint256[])", "numberOfBytes": "32", "value": "t_array(t_uint256)dyn_storage"},
+				"t_mapping(t_address,t_uint256)": {"encoding": "mapping", "key": "t_address", "label": "mapping(address => uint256)", "numberOfBytes": "32", "value": "t_uint256"},
+				"t_mapping(t_bytes32,t_struct(Ilk)6_storage)": {"encoding": "mapping", "key": "t_bytes32", "label": "mapping(bytes32 => struct Vat.Ilk)", "numberOfBytes": "32", "value": "t_struct(Ilk)6_storage"},
+				"t_string_storage": {"encoding": "bytes", "label": "string", "numberOfBytes": "32"},
+				"t_struct(Ilk)6_storage": {"encoding": "inplace", "label": "struct Vat.Ilk", "members": [
+					{"astId": 4, "contract": "Vat.sol:Vat", "label": "Art", "offset": 0, "slot": "0", "type": "t_uint256"},
+					{"astId": 5, "contract": "Vat.sol:Vat", "label": "rate", "offset": 0, "slot": "1", "type": "t_uint256"}
+				], "numberOfBytes": "64"},
+				"t_uint256": {"encoding": "inplace", "label": "uint256", "numberOfBytes": "32"}
+			}
+		}`)
+		ilk   = "0x4554482d41000000000000000000000000000000000000000000000000000000"
+		guy   = "0x00000000000000000000000000000000000000aa"
+		other = "0x00000000000000000000000000000000000000bb"
+		Ilk   = storage.Key("ilk")
+		Guy   = storage.Key("guy")
+	)
+
+	It("derives the keys of each variable from its known values", func() {
+		layout, parseErr := storage.ParseSolcStorageLayout(rawLayout)
+		Expect(parseErr).NotTo(HaveOccurred())
+
+		mappings, err := layout.DeriveKeys(map[string]storage.KnownValues{
+			"wards":   {Keys: []storage.Key{Guy}, Rows: [][]string{{guy}, {other}}},
+			"ilks":    {Keys: []storage.Key{Ilk}, Rows: [][]string{{ilk}}},
+			"history": {Keys: []storage.Key{Guy, "length"}, Rows: [][]string{{guy, "1"}, {guy, "2"}, {other, "1"}}},
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		expected := make(map[common.Hash]storage.ValueMetadata)
+		for _, variable := range []struct {
+			slot   string
+			name   string
+			layout storage.Layout
+		}{
+			{slot: storage.IndexZero, name: "wards", layout: storage.Mapping(Guy, storage.KnownKeys(guy, other),
+				storage.Value(storage.Uint256))},
+			{slot: storage.IndexOne, name: "ilks", layout: storage.Mapping(Ilk, storage.KnownKeys(ilk), storage.Struct(
+				storage.Field("ilks_Art", storage.Value(storage.Uint256)),
+				storage.Field("ilks_rate", storage.Value(storage.Uint256)),
+			))},
+			{slot: storage.IndexThree, name: "name", layout: storage.Value(storage.String)},
+			{slot: storage.IndexFour, name: "history", layout: storage.Mapping(Guy, storage.KnownKeys(guy, other),
+				storage.DynamicArray(storage.IndexKey, func(keys map[storage.Key]string) int64 {
+					if keys[Guy] == guy {
+						return 2
+					}
+					return 1
+				}, storage.Value(storage.Uint256)))},
+		} {
+			derived, deriveErr := storage.DeriveKeys(common.HexToHash(variable.slot), variable.name, variable.layout)
+			Expect(deriveErr).NotTo(HaveOccurred())
+			for key, metadata := range derived {
+				expected[key] = metadata
+			}
+		}
+		expected[common.HexToHash(storage.IndexTwo)] = storage.GetValueMetadataForPackedSlot("owner_live", nil,
+			storage.PackedSlot, map[int]string{0: "owner", 1: "live"},
+			map[int]storage.ValueType{0: storage.Address, 1: storage.Bool})
+		Expect(mappings).To(Equal(expected))
+	})
+
+	It("only derives the keys that don't depend on unknown values", func() {
+		layout, parseErr := storage.ParseSolcStorageLayout(rawLayout)
+		Expect(parseErr).NotTo(HaveOccurred())
+
+		mappings, err := layout.DeriveKeys(nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mappings).To(HaveLen(2))
+		Expect(mappings).To(HaveKey(common.HexToHash(storage.IndexTwo)))
+		Expect(mappings).To(HaveKey(common.HexToHash(storage.IndexThree)))
+	})
+
+	It("places struct members at the slot and offset solc assigns them", func() {
+		layout := storage.SolcStorageLayout{
+			Storage: []storage.SolcStorageEntry{{Label: "urn", Slot: "5", Type: "t_struct(Urn)3_storage"}},
+			Types: map[string]storage.SolcStorageType{
+				"t_address": {Encoding: "inplace", Label: "address", NumberOfBytes: "20"},
+				"t_bool":    {Encoding: "inplace", Label: "bool", NumberOfBytes: "1"},
+				"t_struct(Urn)3_storage": {Encoding: "inplace", Label: "struct Vat.Urn", NumberOfBytes: "96", Members: []storage.SolcStorageEntry{
+					{Label: "ink", Slot: "0", Offset: 16, Type: "t_uint128"},
+					{Label: "art", Slot: "0", Offset: 0, Type: "t_uint128"},
+					{Label: "guy", Slot: "1", Offset: 0, Type: "t_address"},
+					{Label: "live", Slot: "2", Offset: 0, Type: "t_bool"},
+				}},
+				"t_uint128": {Encoding: "inplace", Label: "uint128", NumberOfBytes: "16"},
+			},
+		}
+
+		mappings, err := layout.DeriveKeys(nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mappings).To(Equal(map[common.Hash]storage.ValueMetadata{
+			common.HexToHash("0x5"): storage.GetValueMetadataForPackedSlot("urn", nil, storage.PackedSlot,
+				map[int]string{0: "urn_art", 1: "urn_ink"},
+				map[int]storage.ValueType{0: storage.Uint128, 1: storage.Uint128}),
+			common.HexToHash("0x6"): storage.GetValueMetadata("urn_guy", nil, storage.Address),
+			common.HexToHash("0x7"): storage.GetValueMetadata("urn_live", nil, storage.Bool),
+		}))
+	})
+
+	It("returns an error for a static array", func() {
+		layout := storage.SolcStorageLayout{
+			Storage: []storage.SolcStorageEntry{{Label: "prices", Slot: "0", Type: "t_array(t_uint256)3_storage"}},
+			Types: map[string]storage.SolcStorageType{
+				"t_array(t_uint256)3_storage": {Encoding: "inplace", Label: "uint256[3]", NumberOfBytes: "96", Base: "t_uint256"},
+				"t_uint256":                   {Encoding: "inplace", Label: "uint256", NumberOfBytes: "32"},
+			},
+		}
+
+		_, err := layout.DeriveKeys(nil)
+
+		Expect(err).To(MatchError(storage.ErrUnsupportedStorageType{Type: "t_array(t_uint256)3_storage"}))
+	})
+
+	It("returns an error for a mapping with string keys", func() {
+		layout := storage.SolcStorageLayout{
+			Storage: []storage.SolcStorageEntry{{Label: "names", Slot: "0", Type: "t_mapping(t_string_memory_ptr,t_uint256)"}},
+			Types: map[string]storage.SolcStorageType{
+				"t_mapping(t_string_memory_ptr,t_uint256)": {Encoding: "mapping", Key: "t_string_memory_ptr", Value: "t_uint256"},
+				"t_string_memory_ptr":                      {Encoding: "bytes", Label: "string"},
+				"t_uint256":                                {Encoding: "inplace", Label: "uint256", NumberOfBytes: "32"},
+			},
+		}
+
+		_, err := layout.DeriveKeys(nil)
+
+		Expect(err).To(MatchError(storage.ErrUnsupportedStorageType{Type: "t_mapping(t_string_memory_ptr,t_uint256)"}))
+	})
+})
@@ -16,7 +16,11 @@
 
 package storage
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
 
 type ValueType int
 
@@ -27,8 +31,85 @@ const (
 	Bytes32
 	Address
 	PackedSlot
+	Bool
+	String
+	Bytes
 )
 
+// Integers and fixed-size byte arrays of other widths are offset from these bases by their width (bits for integers,
+// bytes for byte arrays). Use UintN, IntN and BytesN to construct them.
+const (
+	uintBase  ValueType = 1000
+	intBase   ValueType = 2000
+	bytesBase ValueType = 3000
+)
+
+const (
+	Uint8  = uintBase + 8
+	Uint16 = uintBase + 16
+	Uint32 = uintBase + 32
+	Uint64 = uintBase + 64
+
+	Int8   = intBase + 8
+	Int16  = intBase + 16
+	Int32  = intBase + 32
+	Int48  = intBase + 48
+	Int64  = intBase + 64
+	Int128 = intBase + 128
+	Int256 = intBase + 256
+
+	Bytes1  = bytesBase + 1
+	Bytes2  = bytesBase + 2
+	Bytes4  = bytesBase + 4
+	Bytes8  = bytesBase + 8
+	Bytes16 = bytesBase + 16
+)
+
+// UintN returns the ValueType of an unsigned integer with the given number of bits
+func UintN(bits int) ValueType {
+	switch bits {
+	case 48:
+		return Uint48
+	case 128:
+		return Uint128
+	case 256:
+		return Uint256
+	default:
+		return uintBase + ValueType(bits)
+	}
+}
+
+// IntN returns the ValueType of a two's complement signed integer with the given number of bits
+func IntN(bits int) ValueType {
+	return intBase + ValueType(bits)
+}
+
+// BytesN returns the ValueType of a fixed-size byte array with the given number of bytes
+func BytesN(size int) ValueType {
+	if size == 32 {
+		return Bytes32
+	}
+	return bytesBase + ValueType(size)
+}
+
+// IsDynamic is true for the String and Bytes types, whose values longer than 31 bytes are stored across multiple slots
+func (valueType ValueType) IsDynamic() bool {
+	return valueType == String || valueType == Bytes
+}
+
+func (valueType ValueType) isUnsigned() bool {
+	return valueType == Uint48 || valueType == Uint128 || valueType == Uint256 ||
+		(valueType > uintBase && valueType < intBase)
+}
+
+func (valueType ValueType) isSigned() bool {
+	return valueType > intBase && valueType < bytesBase
+}
+
+func (valueType ValueType) isFixedBytes() bool {
+	return valueType == Bytes32 || (valueType > bytesBase && valueType < bytesBase+32)
+}
+
 type Key string
 
 type ValueMetadata struct {
@@ -37,6 +118,7 @@ type ValueMetadata struct {
 	Type        ValueType
 	PackedNames map[int]string    //zero indexed position in map => name of packed item
 	PackedTypes map[int]ValueType //zero indexed position in map => type of packed item
+	LengthKey   common.Hash       //key of the slot holding the length of a String or Bytes value, set for each of its slots
 }
 
 func GetValueMetadata(name string, keys map[Key]string, valueType ValueType) ValueMetadata {
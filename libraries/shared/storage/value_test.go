@@ -75,4 +75,21 @@ var _ = Describe("Storage value metadata getter", func() {
 			Expect(getMetadata).To(Panic())
 		})
 	})
+
+	Describe("value types of arbitrary width", func() {
+		It("returns the existing value types for the widths that have them", func() {
+			Expect(storage.UintN(48)).To(Equal(storage.Uint48))
+			Expect(storage.UintN(128)).To(Equal(storage.Uint128))
+			Expect(storage.UintN(256)).To(Equal(storage.Uint256))
+			Expect(storage.BytesN(32)).To(Equal(storage.Bytes32))
+		})
+
+		It("returns distinct value types for signed, unsigned and fixed bytes of the same width", func() {
+			Expect(storage.UintN(32)).To(Equal(storage.Uint32))
+			Expect(storage.IntN(32)).To(Equal(storage.Int32))
+			Expect(storage.BytesN(4)).To(Equal(storage.Bytes4))
+			Expect(storage.Uint32).NotTo(Equal(storage.Int32))
+			Expect(storage.UintN(8)).NotTo(Equal(storage.BytesN(8)))
+		})
+	})
 })
@@ -0,0 +1,61 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package streamer
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/sirupsen/logrus"
+)
+
+// NewHead is the part of a newHeads notification needed to tell whether it extends the stored chain
+type NewHead struct {
+	Number     *hexutil.Big `json:"number"`
+	Hash       common.Hash  `json:"hash"`
+	ParentHash common.Hash  `json:"parentHash"`
+}
+
+// Subscription is satisfied by *rpc.ClientSubscription
+type Subscription interface {
+	Err() <-chan error
+	Unsubscribe()
+}
+
+type IHeadStreamer interface {
+	Stream(chan NewHead) (Subscription, error)
+}
+
+type HeadStreamer struct {
+	client core.RpcClient
+}
+
+func NewHeadStreamer(client core.RpcClient) HeadStreamer {
+	return HeadStreamer{
+		client: client,
+	}
+}
+
+// Subscribes to the chain heads announced by the node. Requires a WebSocket or IPC connection.
+func (streamer HeadStreamer) Stream(headChan chan NewHead) (Subscription, error) {
+	logrus.Info("subscribing to new heads")
+	subscription, err := streamer.client.Subscribe("eth", headChan, "newHeads")
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
@@ -0,0 +1,36 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package streamer_test
+
+import (
+	"github.com/makerdao/vulcanizedb/libraries/shared/streamer"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Head Streamer", func() {
+	It("subscribes to new heads", func() {
+		client := &fakes.MockRpcClient{}
+		headStreamer := streamer.NewHeadStreamer(client)
+		headChan := make(chan streamer.NewHead)
+		_, err := headStreamer.Stream(headChan)
+		Expect(err).NotTo(HaveOccurred())
+
+		client.AssertSubscribeCalledWith("eth", headChan, []interface{}{"newHeads"})
+	})
+})
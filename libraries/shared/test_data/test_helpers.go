@@ -37,7 +37,7 @@ func CreateTestLog(headerID int64, db *postgres.DB) core.HeaderSyncLog {
 	insertLogsErr := headerSyncLogRepository.CreateHeaderSyncLogs(headerID, []types.Log{log})
 	Expect(insertLogsErr).NotTo(HaveOccurred())
 
-	headerSyncLogs, getLogsErr := headerSyncLogRepository.GetUntransformedHeaderSyncLogs()
+	headerSyncLogs, getLogsErr := headerSyncLogRepository.GetUntransformedHeaderSyncLogs(0, 0, 0)
 	Expect(getLogsErr).NotTo(HaveOccurred())
 	for _, headerSyncLog := range headerSyncLogs {
 		if headerSyncLog.Log.TxIndex == log.TxIndex {
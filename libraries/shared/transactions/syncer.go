@@ -17,6 +17,7 @@
 package transactions
 
 import (
+	"context"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/makerdao/vulcanizedb/pkg/core"
@@ -26,7 +27,8 @@ import (
 )
 
 type ITransactionsSyncer interface {
-	SyncTransactions(headerID int64, logs []types.Log) error
+	SyncTransactions(ctx context.Context, headerID int64, logs []types.Log) error
+	SyncReceipts(ctx context.Context, headerID int64, logs []types.Log) error
 }
 
 type TransactionsSyncer struct {
@@ -42,12 +44,12 @@ func NewTransactionsSyncer(db *postgres.DB, blockChain core.BlockChain) Transact
 	}
 }
 
-func (syncer TransactionsSyncer) SyncTransactions(headerID int64, logs []types.Log) error {
+func (syncer TransactionsSyncer) SyncTransactions(ctx context.Context, headerID int64, logs []types.Log) error {
 	transactionHashes := getUniqueTransactionHashes(logs)
 	if len(transactionHashes) < 1 {
 		return nil
 	}
-	transactions, transactionErr := syncer.BlockChain.GetTransactions(transactionHashes)
+	transactions, transactionErr := syncer.BlockChain.GetTransactions(ctx, transactionHashes)
 	if transactionErr != nil {
 		return transactionErr
 	}
@@ -58,6 +60,20 @@ func (syncer TransactionsSyncer) SyncTransactions(headerID int64, logs []types.L
 	return nil
 }
 
+// Fetch and persist the receipts of the transactions that emitted logs. The transactions must already have been
+// persisted with SyncTransactions.
+func (syncer TransactionsSyncer) SyncReceipts(ctx context.Context, headerID int64, logs []types.Log) error {
+	transactionHashes := getUniqueTransactionHashes(logs)
+	if len(transactionHashes) < 1 {
+		return nil
+	}
+	receipts, receiptsErr := syncer.BlockChain.GetTransactionReceipts(ctx, transactionHashes)
+	if receiptsErr != nil {
+		return receiptsErr
+	}
+	return syncer.Repository.CreateReceipts(headerID, receipts)
+}
+
 func getUniqueTransactionHashes(logs []types.Log) []common.Hash {
 	seen := make(map[common.Hash]struct{}, len(logs))
 	var result []common.Hash
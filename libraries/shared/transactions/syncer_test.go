@@ -17,6 +17,7 @@
 package transactions_test
 
 import (
+	"context"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/makerdao/vulcanizedb/libraries/shared/transactions"
 	"github.com/makerdao/vulcanizedb/pkg/core"
@@ -40,21 +41,21 @@ var _ = Describe("Transaction syncer", func() {
 	})
 
 	It("fetches transactions for logs", func() {
-		err := syncer.SyncTransactions(0, []types.Log{{TxHash: fakes.FakeHash}})
+		err := syncer.SyncTransactions(context.Background(), 0, []types.Log{{TxHash: fakes.FakeHash}})
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(blockChain.GetTransactionsCalled).To(BeTrue())
 	})
 
 	It("does not fetch transactions if no logs", func() {
-		err := syncer.SyncTransactions(0, []types.Log{})
+		err := syncer.SyncTransactions(context.Background(), 0, []types.Log{})
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(blockChain.GetTransactionsCalled).To(BeFalse())
 	})
 
 	It("only fetches transactions with unique hashes", func() {
-		err := syncer.SyncTransactions(0, []types.Log{{
+		err := syncer.SyncTransactions(context.Background(), 0, []types.Log{{
 			TxHash: fakes.FakeHash,
 		}, {
 			TxHash: fakes.FakeHash,
@@ -67,7 +68,7 @@ var _ = Describe("Transaction syncer", func() {
 	It("returns error if fetching transactions fails", func() {
 		blockChain.GetTransactionsError = fakes.FakeError
 
-		err := syncer.SyncTransactions(0, []types.Log{{TxHash: fakes.FakeHash}})
+		err := syncer.SyncTransactions(context.Background(), 0, []types.Log{{TxHash: fakes.FakeHash}})
 
 		Expect(err).To(HaveOccurred())
 		Expect(err).To(MatchError(fakes.FakeError))
@@ -78,7 +79,7 @@ var _ = Describe("Transaction syncer", func() {
 		mockHeaderRepository := fakes.NewMockHeaderRepository()
 		syncer.Repository = mockHeaderRepository
 
-		err := syncer.SyncTransactions(0, []types.Log{{TxHash: fakes.FakeHash}})
+		err := syncer.SyncTransactions(context.Background(), 0, []types.Log{{TxHash: fakes.FakeHash}})
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(mockHeaderRepository.CreateTransactionsCalled).To(BeTrue())
@@ -90,9 +91,50 @@ var _ = Describe("Transaction syncer", func() {
 		mockHeaderRepository.CreateTransactionsError = fakes.FakeError
 		syncer.Repository = mockHeaderRepository
 
-		err := syncer.SyncTransactions(0, []types.Log{{TxHash: fakes.FakeHash}})
+		err := syncer.SyncTransactions(context.Background(), 0, []types.Log{{TxHash: fakes.FakeHash}})
 
 		Expect(err).To(HaveOccurred())
 		Expect(err).To(MatchError(fakes.FakeError))
 	})
+
+	Describe("syncing receipts", func() {
+		It("fetches receipts for logs with unique transaction hashes", func() {
+			err := syncer.SyncReceipts(context.Background(), 0, []types.Log{{
+				TxHash: fakes.FakeHash,
+			}, {
+				TxHash: fakes.FakeHash,
+			}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(blockChain.GetTransactionReceiptsCalled).To(BeTrue())
+			Expect(blockChain.GetTransactionReceiptsPassedHashes).To(HaveLen(1))
+		})
+
+		It("does not fetch receipts if no logs", func() {
+			err := syncer.SyncReceipts(context.Background(), 0, []types.Log{})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(blockChain.GetTransactionReceiptsCalled).To(BeFalse())
+		})
+
+		It("returns error if fetching receipts fails", func() {
+			blockChain.GetTransactionReceiptsError = fakes.FakeError
+
+			err := syncer.SyncReceipts(context.Background(), 0, []types.Log{{TxHash: fakes.FakeHash}})
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+
+		It("passes receipts to repository for persistence", func() {
+			blockChain.Receipts = []core.Receipt{{}}
+			mockHeaderRepository := fakes.NewMockHeaderRepository()
+			mockHeaderRepository.CreateReceiptsError = fakes.FakeError
+			syncer.Repository = mockHeaderRepository
+
+			err := syncer.SyncReceipts(context.Background(), 0, []types.Log{{TxHash: fakes.FakeHash}})
+
+			Expect(mockHeaderRepository.CreateReceiptsCalled).To(BeTrue())
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+	})
 })
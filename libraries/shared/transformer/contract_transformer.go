@@ -17,6 +17,8 @@
 package transformer
 
 import (
+	"context"
+
 	"github.com/makerdao/vulcanizedb/pkg/config"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
@@ -24,7 +26,7 @@ import (
 
 type ContractTransformer interface {
 	Init() error
-	Execute() error
+	Execute(ctx context.Context) error
 	GetConfig() config.ContractConfig
 }
 
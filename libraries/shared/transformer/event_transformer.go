@@ -34,8 +34,26 @@ type EventTransformerConfig struct {
 	ContractAddresses   []string
 	ContractAbi         string
 	Topic               string
+	Topic1              []string // Optional; only logs with one of these topic1 values are watched
+	Topic2              []string // Optional; only logs with one of these topic2 values are watched
+	Topic3              []string // Optional; only logs with one of these topic3 values are watched
 	StartingBlockNumber int64
-	EndingBlockNumber   int64 // Set -1 for indefinite transformer
+	EndingBlockNumber   int64 // Set -1 (or leave unset) for indefinite transformer
+	SyncReceipts        bool  // Optional; also persist receipts for the transactions that emitted watched logs
+}
+
+// Transformers without a positive EndingBlockNumber watch logs indefinitely.
+func (config EventTransformerConfig) IsIndefinite() bool {
+	return config.EndingBlockNumber < 1
+}
+
+// Returns the topic1 to topic3 filters by position, with an empty filter for positions that match any topic.
+func (config EventTransformerConfig) TopicFilters() [][]common.Hash {
+	return [][]common.Hash{
+		HexStringsToHashes(config.Topic1),
+		HexStringsToHashes(config.Topic2),
+		HexStringsToHashes(config.Topic3),
+	}
 }
 
 func HexToInt64(byteString string) int64 {
@@ -54,3 +72,10 @@ func HexStringsToAddresses(strings []string) (addresses []common.Address) {
 	}
 	return
 }
+
+func HexStringsToHashes(strings []string) (hashes []common.Hash) {
+	for _, hexString := range strings {
+		hashes = append(hashes, common.HexToHash(hexString))
+	}
+	return
+}
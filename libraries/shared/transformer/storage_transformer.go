@@ -27,4 +27,10 @@ type StorageTransformer interface {
 	KeccakContractAddress() common.Hash
 }
 
+// OrphanedValuesDeleter is implemented by storage transformers that can delete the values they transformed from diffs
+// of blocks above afterBlockHeight that have since been orphaned
+type OrphanedValuesDeleter interface {
+	DeleteOrphanedValues(afterBlockHeight int64) error
+}
+
 type StorageTransformerInitializer func(db *postgres.DB) StorageTransformer
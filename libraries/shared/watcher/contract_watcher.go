@@ -21,13 +21,16 @@
 package watcher
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/makerdao/vulcanizedb/libraries/shared/transformer"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
 )
 
 type ContractWatcher struct {
@@ -64,9 +67,26 @@ func (watcher *ContractWatcher) AddTransformers(inits interface{}) error {
 	return nil
 }
 
-func (watcher *ContractWatcher) Execute() error {
+// Returns the added transformers that react to reorgs
+func (watcher *ContractWatcher) ReorgListeners() []transformer.ReorgListener {
+	var listeners []transformer.ReorgListener
 	for _, contractTransformer := range watcher.Transformers {
-		err := contractTransformer.Execute()
+		if listener, ok := contractTransformer.(transformer.ReorgListener); ok {
+			listeners = append(listeners, listener)
+		}
+	}
+	return listeners
+}
+
+// Executes each transformer in turn, stopping before the next one once ctx is cancelled
+func (watcher *ContractWatcher) Execute(ctx context.Context) error {
+	for _, contractTransformer := range watcher.Transformers {
+		if ctx.Err() != nil {
+			return nil
+		}
+		start := time.Now()
+		err := contractTransformer.Execute(ctx)
+		metrics.ObserveTransformerExecution(contractTransformer.GetConfig().Name, start, err)
 		if err != nil {
 			log.Error("Unable to execute transformer:", contractTransformer.GetConfig().Name, err)
 			return err
@@ -17,6 +17,8 @@
 package watcher
 
 import (
+	"context"
+
 	"github.com/makerdao/vulcanizedb/libraries/shared/chunker"
 	"github.com/makerdao/vulcanizedb/libraries/shared/constants"
 	"github.com/makerdao/vulcanizedb/libraries/shared/fetcher"
@@ -26,6 +28,7 @@ import (
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/makerdao/vulcanizedb/pkg/health"
 	"github.com/sirupsen/logrus"
 	"time"
 )
@@ -37,17 +40,27 @@ type EventWatcher struct {
 	LogExtractor                 logs.ILogExtractor
 	MaxConsecutiveUnexpectedErrs int
 	RetryInterval                time.Duration
+	reorgListeners               []transformer.ReorgListener
 }
 
-func NewEventWatcher(db *postgres.DB, bc core.BlockChain, maxConsecutiveUnexpectedErrs int, retryInterval time.Duration) EventWatcher {
+// Logs are only extracted for headers at least confirmations blocks below the chain head, unless fastThenConfirm is
+// set - in which case they are extracted immediately and re-checked once their header is confirmed. A non-zero
+// logRangeSize fetches logs for runs of up to that many contiguous headers at once, instead of header by header.
+// Persisted logs are delegated to transformers in batches of logBatchSize, or all at once if it is zero.
+func NewEventWatcher(db *postgres.DB, bc core.BlockChain, confirmations int64, fastThenConfirm bool, logRangeSize int64, logBatchSize int, maxConsecutiveUnexpectedErrs int, retryInterval time.Duration) EventWatcher {
 	extractor := &logs.LogExtractor{
+		BlockChain:               bc,
 		CheckedHeadersRepository: repositories.NewCheckedHeadersRepository(db),
 		CheckedLogsRepository:    repositories.NewCheckedLogsRepository(db),
+		Confirmations:            confirmations,
+		FastThenConfirm:          fastThenConfirm,
 		Fetcher:                  fetcher.NewLogFetcher(bc),
 		LogRepository:            repositories.NewHeaderSyncLogRepository(db),
+		RangeSize:                logRangeSize,
 		Syncer:                   transactions.NewTransactionsSyncer(db, bc),
 	}
 	logTransformer := &logs.LogDelegator{
+		BatchSize:     logBatchSize,
 		Chunker:       chunker.NewLogChunker(),
 		LogRepository: repositories.NewHeaderSyncLogRepository(db),
 	}
@@ -71,57 +84,94 @@ func (watcher *EventWatcher) AddTransformers(initializers []transformer.EventTra
 		if err != nil {
 			return err
 		}
+		if listener, ok := t.(transformer.ReorgListener); ok {
+			watcher.reorgListeners = append(watcher.reorgListeners, listener)
+		}
 	}
 	return nil
 }
 
-// Extracts and delegates watched log events.
-func (watcher *EventWatcher) Execute(recheckHeaders constants.TransformerExecution) error {
-	delegateErrsChan := make(chan error)
-	extractErrsChan := make(chan error)
-	defer close(delegateErrsChan)
-	defer close(extractErrsChan)
+// Returns the added transformers that react to reorgs
+func (watcher *EventWatcher) ReorgListeners() []transformer.ReorgListener {
+	return watcher.reorgListeners
+}
+
+// Extracts and delegates watched log events until ctx is cancelled or either fails with more than
+// MaxConsecutiveUnexpectedErrs consecutive unexpected errors. On cancellation, the extraction and delegation passes in
+// progress are allowed to finish before returning nil.
+func (watcher *EventWatcher) Execute(ctx context.Context, recheckHeaders constants.TransformerExecution) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	delegateErrsChan := make(chan error, 1)
+	extractErrsChan := make(chan error, 1)
 
-	go watcher.extractLogs(recheckHeaders, extractErrsChan)
-	go watcher.delegateLogs(delegateErrsChan)
+	go watcher.extractLogs(ctx, recheckHeaders, extractErrsChan)
+	go watcher.delegateLogs(ctx, delegateErrsChan)
 
-	for {
+	// wait for both to stop, stopping the other once one of them fails
+	var err error
+	for i := 0; i < 2; i++ {
 		select {
 		case delegateErr := <-delegateErrsChan:
-			logrus.Errorf("error delegating logs in event watcher: %s", delegateErr.Error())
-			return delegateErr
+			if delegateErr != nil && err == nil {
+				logrus.Errorf("error delegating logs in event watcher: %s", delegateErr.Error())
+				err = delegateErr
+				cancel()
+			}
 		case extractErr := <-extractErrsChan:
-			logrus.Errorf("error extracting logs in event watcher: %s", extractErr.Error())
-			return extractErr
+			if extractErr != nil && err == nil {
+				logrus.Errorf("error extracting logs in event watcher: %s", extractErr.Error())
+				err = extractErr
+				cancel()
+			}
 		}
 	}
+	return err
 }
 
-func (watcher *EventWatcher) extractLogs(recheckHeaders constants.TransformerExecution, errs chan error) {
-	call := func() error { return watcher.LogExtractor.ExtractLogs(recheckHeaders) }
-	watcher.withRetry(call, logs.ErrNoUncheckedHeaders, "extracting", errs)
+func (watcher *EventWatcher) extractLogs(ctx context.Context, recheckHeaders constants.TransformerExecution, errs chan error) {
+	call := func() error { return watcher.LogExtractor.ExtractLogs(ctx, recheckHeaders) }
+	watcher.withRetry(ctx, call, logs.ErrNoUncheckedHeaders, "extracting", health.ExtractLogs, errs)
 }
 
-func (watcher *EventWatcher) delegateLogs(errs chan error) {
-	watcher.withRetry(watcher.LogDelegator.DelegateLogs, logs.ErrNoLogs, "delegating", errs)
+func (watcher *EventWatcher) delegateLogs(ctx context.Context, errs chan error) {
+	watcher.withRetry(ctx, watcher.LogDelegator.DelegateLogs, logs.ErrNoLogs, "delegating", health.DelegateLogs, errs)
 }
 
-func (watcher *EventWatcher) withRetry(call func() error, expectedErr error, operation string, errs chan error) {
+// Repeats call until ctx is cancelled, sending nil to errs, or until there are too many consecutive unexpected
+// errors, sending the last one. Calls that succeed or return expectedErr are recorded as progress for component.
+func (watcher *EventWatcher) withRetry(ctx context.Context, call func() error, expectedErr error, operation, component string, errs chan error) {
 	consecutiveUnexpectedErrCount := 0
+	health.RecordProgress(component)
 	for {
+		if ctx.Err() != nil {
+			errs <- nil
+			return
+		}
 		err := call()
 		if err == nil {
 			consecutiveUnexpectedErrCount = 0
+			health.RecordProgress(component)
+			continue
+		}
+		if ctx.Err() != nil {
+			// errors from calls interrupted by the cancellation are expected
+			errs <- nil
+			return
+		}
+		if err == expectedErr {
+			health.RecordProgress(component)
 		} else {
-			if err != expectedErr {
-				consecutiveUnexpectedErrCount++
-				logrus.Errorf("error %s logs: %s", operation, err.Error())
-				if consecutiveUnexpectedErrCount > watcher.MaxConsecutiveUnexpectedErrs {
-					errs <- err
-					return
-				}
+			consecutiveUnexpectedErrCount++
+			logrus.Errorf("error %s logs: %s", operation, err.Error())
+			if consecutiveUnexpectedErrCount > watcher.MaxConsecutiveUnexpectedErrs {
+				errs <- err
+				return
 			}
-			time.Sleep(watcher.RetryInterval)
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(watcher.RetryInterval):
 		}
 	}
 }
@@ -17,6 +17,7 @@
 package watcher_test
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/makerdao/vulcanizedb/libraries/shared/transformer"
 	"github.com/makerdao/vulcanizedb/libraries/shared/watcher"
 	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/pkg/health"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -90,7 +92,7 @@ var _ = Describe("Event Watcher", func() {
 		It("extracts watched logs", func() {
 			extractor.ExtractLogsErrors = []error{nil, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 			Expect(extractor.ExtractLogsCount > 0).To(BeTrue())
@@ -99,7 +101,7 @@ var _ = Describe("Event Watcher", func() {
 		It("returns error if extracting logs fails", func() {
 			extractor.ExtractLogsErrors = []error{fakes.FakeError}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
@@ -108,7 +110,7 @@ var _ = Describe("Event Watcher", func() {
 			eventWatcher.MaxConsecutiveUnexpectedErrs = 1
 			extractor.ExtractLogsErrors = []error{fakes.FakeError, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 			Expect(extractor.ExtractLogsCount > 1).To(BeTrue())
@@ -118,7 +120,7 @@ var _ = Describe("Event Watcher", func() {
 			eventWatcher.MaxConsecutiveUnexpectedErrs = 1
 			extractor.ExtractLogsErrors = []error{fakes.FakeError, fakes.FakeError}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
@@ -126,7 +128,7 @@ var _ = Describe("Event Watcher", func() {
 		It("does not treat absence of unchecked headers as an unexpected error", func() {
 			extractor.ExtractLogsErrors = []error{logs.ErrNoUncheckedHeaders, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 		})
@@ -134,7 +136,7 @@ var _ = Describe("Event Watcher", func() {
 		It("extracts watched logs again if missing headers found", func() {
 			extractor.ExtractLogsErrors = []error{nil, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 			Expect(extractor.ExtractLogsCount > 1).To(BeTrue())
@@ -143,7 +145,7 @@ var _ = Describe("Event Watcher", func() {
 		It("returns error if extracting logs fails on subsequent run", func() {
 			extractor.ExtractLogsErrors = []error{nil, fakes.FakeError}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
@@ -151,7 +153,7 @@ var _ = Describe("Event Watcher", func() {
 		It("delegates untransformed logs", func() {
 			delegator.DelegateErrors = []error{nil, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 			Expect(delegator.DelegateCallCount > 0).To(BeTrue())
@@ -160,7 +162,7 @@ var _ = Describe("Event Watcher", func() {
 		It("returns error if delegating logs fails", func() {
 			delegator.DelegateErrors = []error{fakes.FakeError}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
@@ -169,7 +171,7 @@ var _ = Describe("Event Watcher", func() {
 			eventWatcher.MaxConsecutiveUnexpectedErrs = 1
 			delegator.DelegateErrors = []error{fakes.FakeError, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 			Expect(delegator.DelegateCallCount > 1).To(BeTrue())
@@ -179,7 +181,7 @@ var _ = Describe("Event Watcher", func() {
 			eventWatcher.MaxConsecutiveUnexpectedErrs = 1
 			delegator.DelegateErrors = []error{fakes.FakeError, fakes.FakeError}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
@@ -187,7 +189,7 @@ var _ = Describe("Event Watcher", func() {
 		It("does not treat absence of unchecked logs as an unexpected error", func() {
 			delegator.DelegateErrors = []error{logs.ErrNoLogs, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 		})
@@ -195,16 +197,60 @@ var _ = Describe("Event Watcher", func() {
 		It("delegates logs again if untransformed logs found", func() {
 			delegator.DelegateErrors = []error{nil, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 			Expect(delegator.DelegateCallCount > 1).To(BeTrue())
 		})
 
+		It("stops extracting and delegating once the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err := eventWatcher.Execute(ctx, constants.HeaderUnchecked)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(extractor.ExtractLogsCount).To(BeZero())
+			Expect(delegator.DelegateCallCount).To(BeZero())
+		})
+
+		It("returns nil if the context is cancelled while retrying", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			eventWatcher.MaxConsecutiveUnexpectedErrs = 100
+			eventWatcher.RetryInterval = time.Hour
+			extractor.ExtractLogsErrors = []error{fakes.FakeError}
+			time.AfterFunc(10*time.Millisecond, cancel)
+
+			err := eventWatcher.Execute(ctx, constants.HeaderUnchecked)
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("stops delegating logs if extracting logs fails", func() {
+			extractor.ExtractLogsErrors = []error{fakes.FakeError}
+			eventWatcher.RetryInterval = time.Hour
+
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+
+		It("records progress for extracting and delegating logs", func() {
+			start := time.Now()
+			extractor.ExtractLogsErrors = []error{logs.ErrNoUncheckedHeaders, errExecuteClosed}
+
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
+
+			Expect(err).To(MatchError(errExecuteClosed))
+			lastProgress := health.DefaultProgressTracker.LastProgress()
+			Expect(lastProgress[health.ExtractLogs]).To(BeTemporally(">=", start))
+			Expect(lastProgress[health.DelegateLogs]).To(BeTemporally(">=", start))
+		})
+
 		It("returns error if delegating logs fails on subsequent run", func() {
 			delegator.DelegateErrors = []error{nil, fakes.FakeError}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
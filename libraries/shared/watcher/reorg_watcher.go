@@ -0,0 +1,104 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package watcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/makerdao/vulcanizedb/libraries/shared/transformer"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/sirupsen/logrus"
+)
+
+// ReorgWatcher delivers the reorgs recorded by headerSync to transformers implementing transformer.ReorgListener,
+// polling for new reorgs every PollingInterval. Reorgs recorded before the watcher starts are not delivered. A reorg a
+// listener fails on is delivered to that listener again on the next poll.
+type ReorgWatcher struct {
+	PollingInterval time.Duration
+	ReorgRepository datastore.ReorgRepository
+	listeners       []transformer.ReorgListener
+	lastReorgIDs    []int64 // ID of the last reorg delivered to each listener
+}
+
+func NewReorgWatcher(db *postgres.DB, pollingInterval time.Duration) ReorgWatcher {
+	return ReorgWatcher{
+		PollingInterval: pollingInterval,
+		ReorgRepository: repositories.NewReorgRepository(db),
+	}
+}
+
+func (watcher *ReorgWatcher) AddListeners(listeners []transformer.ReorgListener) {
+	watcher.listeners = append(watcher.listeners, listeners...)
+}
+
+// Delivers new reorgs to the listeners until ctx is cancelled. Returns an error if the last reorg recorded before
+// starting can't be found.
+func (watcher *ReorgWatcher) Execute(ctx context.Context) error {
+	startingID, err := watcher.ReorgRepository.GetLatestReorgID()
+	if err != nil {
+		return err
+	}
+	watcher.lastReorgIDs = make([]int64, len(watcher.listeners))
+	for i := range watcher.lastReorgIDs {
+		watcher.lastReorgIDs[i] = startingID
+	}
+
+	ticker := time.NewTicker(watcher.PollingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			watcher.deliverReorgs()
+		}
+	}
+}
+
+func (watcher *ReorgWatcher) deliverReorgs() {
+	if len(watcher.listeners) == 0 {
+		return
+	}
+	fromID := watcher.lastReorgIDs[0]
+	for _, lastReorgID := range watcher.lastReorgIDs {
+		if lastReorgID < fromID {
+			fromID = lastReorgID
+		}
+	}
+	reorgs, err := watcher.ReorgRepository.GetReorgsAfter(fromID)
+	if err != nil {
+		logrus.Errorf("error getting reorgs: %s", err.Error())
+		return
+	}
+
+	for i, listener := range watcher.listeners {
+		for _, reorg := range reorgs {
+			if reorg.ID <= watcher.lastReorgIDs[i] {
+				continue
+			}
+			handleErr := listener.HandleReorg(reorg)
+			if handleErr != nil {
+				logrus.Errorf("error handling reorg %d: %s", reorg.ID, handleErr.Error())
+				break
+			}
+			watcher.lastReorgIDs[i] = reorg.ID
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package watcher_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/makerdao/vulcanizedb/libraries/shared/mocks"
+	"github.com/makerdao/vulcanizedb/libraries/shared/transformer"
+	"github.com/makerdao/vulcanizedb/libraries/shared/watcher"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reorg Watcher", func() {
+	var (
+		reorgRepository *fakes.MockReorgRepository
+		listener        *mocks.MockReorgListener
+		reorgWatcher    *watcher.ReorgWatcher
+		ctx             context.Context
+		cancel          context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		reorgRepository = &fakes.MockReorgRepository{}
+		listener = &mocks.MockReorgListener{}
+		reorgWatcher = &watcher.ReorgWatcher{
+			PollingInterval: time.Millisecond,
+			ReorgRepository: reorgRepository,
+		}
+		reorgWatcher.AddListeners([]transformer.ReorgListener{listener})
+		ctx, cancel = context.WithCancel(context.Background())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("delivers reorgs recorded after the last one recorded before starting", func() {
+		reorgRepository.GetLatestReorgIDReturnID = 1
+		reorgRepository.GetReorgsAfterReturnReorgs = []core.Reorg{{ID: 1}, {ID: 2}, {ID: 3}}
+
+		go reorgWatcher.Execute(ctx)
+
+		Eventually(listener.HandledReorgIDs).Should(Equal([]int64{2, 3}))
+		Consistently(listener.HandledReorgIDs).Should(Equal([]int64{2, 3}))
+	})
+
+	It("delivers a reorg again to a listener that failed on it", func() {
+		listener.HandleReorgErr = fakes.FakeError
+		reorgRepository.GetReorgsAfterReturnReorgs = []core.Reorg{{ID: 1}, {ID: 2}}
+
+		go reorgWatcher.Execute(ctx)
+
+		Eventually(func() int { return len(listener.HandledReorgIDs()) }).Should(BeNumerically(">", 1))
+		Expect(listener.HandledReorgIDs()).NotTo(ContainElement(int64(2)))
+	})
+
+	It("returns an error if getting the last reorg fails", func() {
+		reorgRepository.GetLatestReorgIDReturnError = fakes.FakeError
+
+		err := reorgWatcher.Execute(ctx)
+
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+
+	It("returns nil once ctx is cancelled", func() {
+		cancel()
+
+		err := reorgWatcher.Execute(ctx)
+
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
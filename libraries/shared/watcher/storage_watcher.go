@@ -17,6 +17,7 @@
 package watcher
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"time"
@@ -28,6 +29,8 @@ import (
 	"github.com/makerdao/vulcanizedb/pkg/datastore"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/makerdao/vulcanizedb/pkg/health"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
 	"github.com/sirupsen/logrus"
 )
 
@@ -44,9 +47,13 @@ func (e ErrHeaderMismatch) Error() string {
 	return fmt.Sprintf("db header hash (%s) doesn't match diff header hash (%s)", e.dbHash, e.diffHash)
 }
 
+// DefaultFinalityDepth is the number of blocks below the most recent header after which a diff whose block hash doesn't
+// match the header at its height is from an orphaned block
+const DefaultFinalityDepth = 15
+
 type IStorageWatcher interface {
 	AddTransformers(initializers []transformer.StorageTransformerInitializer)
-	Execute(queueRecheckInterval time.Duration) error
+	Execute(ctx context.Context, queueRecheckInterval time.Duration) error
 }
 
 type StorageWatcher struct {
@@ -56,6 +63,8 @@ type StorageWatcher struct {
 	HeaderRepository          datastore.HeaderRepository
 	StorageDiffRepository     datastore.StorageDiffRepository
 	KeccakAddressTransformers map[common.Hash]transformer.StorageTransformer // keccak hash of an address => transformer
+	FinalityDepth             int64
+	orphansCheckedHeight      *int64 // final block height up to which diffs have been checked for orphans
 }
 
 func NewStorageWatcher(fetcher fetcher.IStorageFetcher, db *postgres.DB) StorageWatcher {
@@ -70,6 +79,8 @@ func NewStorageWatcher(fetcher fetcher.IStorageFetcher, db *postgres.DB) Storage
 		HeaderRepository:          headerRepository,
 		StorageDiffRepository:     storageDiffRepository,
 		KeccakAddressTransformers: transformers,
+		FinalityDepth:             DefaultFinalityDepth,
+		orphansCheckedHeight:      new(int64),
 	}
 }
 
@@ -80,23 +91,40 @@ func (storageWatcher StorageWatcher) AddTransformers(initializers []transformer.
 	}
 }
 
-func (storageWatcher StorageWatcher) Execute(queueRecheckInterval time.Duration) error {
+// Returns the added transformers that react to reorgs
+func (storageWatcher StorageWatcher) ReorgListeners() []transformer.ReorgListener {
+	var listeners []transformer.ReorgListener
+	for _, storageTransformer := range storageWatcher.KeccakAddressTransformers {
+		if listener, ok := storageTransformer.(transformer.ReorgListener); ok {
+			listeners = append(listeners, listener)
+		}
+	}
+	return listeners
+}
+
+// Transforms fetched storage diffs, periodically retrying queued diffs, until fetching diffs fails or ctx is cancelled.
+// On cancellation, the diff or queue being processed is finished before returning nil.
+func (storageWatcher StorageWatcher) Execute(ctx context.Context, queueRecheckInterval time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	ticker := time.NewTicker(queueRecheckInterval)
+	defer ticker.Stop()
 	diffsChan := make(chan storage.RawDiff)
 	errsChan := make(chan error)
 
-	defer close(diffsChan)
-	defer close(errsChan)
-
-	go storageWatcher.StorageFetcher.FetchStorageDiffs(diffsChan, errsChan)
+	go storageWatcher.StorageFetcher.FetchStorageDiffs(ctx, diffsChan, errsChan)
+	health.RecordProgress(health.StorageDiffs)
 
 	for {
 		select {
+		case <-ctx.Done():
+			return nil
 		case fetchErr := <-errsChan:
 			logrus.Warnf("error fetching storage diffs: %s", fetchErr.Error())
 			return fetchErr
 		case diff := <-diffsChan:
 			storageWatcher.processRow(diff)
+			health.RecordProgress(health.StorageDiffs)
 		case <-ticker.C:
 			storageWatcher.processQueue()
 		}
@@ -134,9 +162,9 @@ func (storageWatcher StorageWatcher) processRow(rawDiff storage.RawDiff) {
 	}
 	persistedDiff.HeaderID = headerID
 
-	executeErr := storageTransformer.Execute(persistedDiff)
+	executeErr := executeStorageTransformer(storageTransformer, persistedDiff)
 	if executeErr != nil {
-		if isKeyNotFoundErr(executeErr) {
+		if isKeyNotFoundErr(executeErr) || isDynamicValueBufferedErr(executeErr) {
 			logrus.Tracef("error executing storage transformer: %s", executeErr.Error())
 		} else {
 			logrus.Infof("error executing storage transformer: %s", executeErr.Error())
@@ -146,6 +174,8 @@ func (storageWatcher StorageWatcher) processRow(rawDiff storage.RawDiff) {
 }
 
 func (storageWatcher StorageWatcher) processQueue() {
+	storageWatcher.handleOrphanedDiffs()
+
 	diffs, fetchErr := storageWatcher.Queue.GetAll()
 	if fetchErr != nil {
 		logrus.Infof("error getting queued storage: %s", fetchErr.Error())
@@ -165,9 +195,9 @@ func (storageWatcher StorageWatcher) processQueue() {
 		}
 		diff.HeaderID = headerID
 
-		executeErr := storageTransformer.Execute(diff)
+		executeErr := executeStorageTransformer(storageTransformer, diff)
 		if executeErr != nil {
-			if isKeyNotFoundErr(executeErr) {
+			if isKeyNotFoundErr(executeErr) || isDynamicValueBufferedErr(executeErr) {
 				logrus.Tracef("error executing storage transformer: %s", executeErr.Error())
 			} else {
 				logrus.Infof("error executing storage transformer: %s", executeErr.Error())
@@ -179,6 +209,48 @@ func (storageWatcher StorageWatcher) processQueue() {
 	}
 }
 
+// Diffs whose block hash doesn't match the header at their height once it's final are from orphaned blocks, and would
+// otherwise be retried forever. They're dropped from the queue, and the values transformed from them are deleted. Only
+// heights that became final since the last successful pass (and queued diffs) are checked, so a pass that fails is
+// retried from the same height on the next one.
+func (storageWatcher StorageWatcher) handleOrphanedDiffs() {
+	mostRecentBlockNumber, getHeaderErr := storageWatcher.HeaderRepository.GetMostRecentHeaderBlockNumber()
+	if getHeaderErr != nil {
+		logrus.Infof("error getting most recent header: %s", getHeaderErr.Error())
+		return
+	}
+	finalBlockHeight := mostRecentBlockNumber - storageWatcher.FinalityDepth
+	if finalBlockHeight < 0 {
+		return
+	}
+	checkedHeight := *storageWatcher.orphansCheckedHeight
+
+	orphaned, markErr := storageWatcher.StorageDiffRepository.MarkOrphanedDiffs(checkedHeight, finalBlockHeight)
+	if markErr != nil {
+		logrus.Infof("error marking orphaned storage diffs: %s", markErr.Error())
+		return
+	}
+	if orphaned > 0 {
+		logrus.Infof("marked %d storage diffs from orphaned blocks", orphaned)
+	}
+
+	deleted := true
+	for _, storageTransformer := range storageWatcher.KeccakAddressTransformers {
+		deleter, ok := storageTransformer.(transformer.OrphanedValuesDeleter)
+		if !ok {
+			continue
+		}
+		deleteErr := deleter.DeleteOrphanedValues(checkedHeight)
+		if deleteErr != nil {
+			logrus.Infof("error deleting orphaned storage values: %s", deleteErr.Error())
+			deleted = false
+		}
+	}
+	if deleted && finalBlockHeight > checkedHeight {
+		*storageWatcher.orphansCheckedHeight = finalBlockHeight
+	}
+}
+
 func (storageWatcher StorageWatcher) deleteRow(diffID int64) {
 	deleteErr := storageWatcher.Queue.Delete(diffID)
 	if deleteErr != nil {
@@ -204,6 +276,18 @@ func (storageWatcher StorageWatcher) getHeaderID(diff storage.PersistedDiff) (in
 	return header.Id, nil
 }
 
+// Storage transformers are identified in metrics by the keccak hash of their contract's address
+func executeStorageTransformer(storageTransformer transformer.StorageTransformer, diff storage.PersistedDiff) error {
+	start := time.Now()
+	err := storageTransformer.Execute(diff)
+	metrics.ObserveTransformerExecution(storageTransformer.KeccakContractAddress().Hex(), start, err)
+	return err
+}
+
 func isKeyNotFoundErr(err error) bool {
 	return reflect.TypeOf(err) == reflect.TypeOf(storage.ErrKeyNotFound{})
 }
+
+func isDynamicValueBufferedErr(err error) bool {
+	return reflect.TypeOf(err) == reflect.TypeOf(storage.ErrDynamicValueBuffered{})
+}
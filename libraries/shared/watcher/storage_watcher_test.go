@@ -17,6 +17,7 @@
 package watcher_test
 
 import (
+	"context"
 	"io/ioutil"
 	"math/rand"
 	"os"
@@ -30,6 +31,7 @@ import (
 	"github.com/makerdao/vulcanizedb/libraries/shared/watcher"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
 	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/pkg/health"
 	"github.com/makerdao/vulcanizedb/test_config"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -81,7 +83,7 @@ var _ = Describe("Storage Watcher", func() {
 			defer os.Remove(tempFile.Name())
 			logrus.SetOutput(tempFile)
 
-			err := storageWatcher.Execute(time.Hour)
+			err := storageWatcher.Execute(context.Background(), time.Hour)
 
 			Expect(err).To(MatchError(fakes.FakeError))
 			logContent, readErr := ioutil.ReadFile(tempFile.Name())
@@ -89,6 +91,15 @@ var _ = Describe("Storage Watcher", func() {
 			Expect(string(logContent)).To(ContainSubstring(fakes.FakeError.Error()))
 		})
 
+		It("returns nil once the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err := storageWatcher.Execute(ctx, time.Hour)
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
 		Describe("transforming new raw storage diffs", func() {
 			var (
 				mockStorageDiffRepository *fakes.MockStorageDiffRepository
@@ -125,7 +136,7 @@ var _ = Describe("Storage Watcher", func() {
 			})
 
 			It("writes raw diff before processing", func(done Done) {
-				go storageWatcher.Execute(time.Hour)
+				go storageWatcher.Execute(context.Background(), time.Hour)
 
 				Eventually(func() []storage.RawDiff {
 					return mockStorageDiffRepository.CreatePassedRawDiffs
@@ -136,7 +147,7 @@ var _ = Describe("Storage Watcher", func() {
 			It("discards raw diff if it's already been persisted", func(done Done) {
 				mockStorageDiffRepository.CreateReturnError = repositories.ErrDuplicateDiff
 
-				go storageWatcher.Execute(time.Hour)
+				go storageWatcher.Execute(context.Background(), time.Hour)
 
 				Consistently(func() storage.PersistedDiff {
 					return mockTransformer.PassedDiff
@@ -151,7 +162,7 @@ var _ = Describe("Storage Watcher", func() {
 				defer os.Remove(tempFile.Name())
 				logrus.SetOutput(tempFile)
 
-				go storageWatcher.Execute(time.Hour)
+				go storageWatcher.Execute(context.Background(), time.Hour)
 
 				Eventually(func() (string, error) {
 					logContent, err := ioutil.ReadFile(tempFile.Name())
@@ -163,7 +174,7 @@ var _ = Describe("Storage Watcher", func() {
 			Describe("when getting header succeeds", func() {
 				It("executes transformer for diff", func(done Done) {
 					go func() {
-						err := storageWatcher.Execute(time.Hour)
+						err := storageWatcher.Execute(context.Background(), time.Hour)
 						Expect(err).NotTo(HaveOccurred())
 					}()
 
@@ -173,11 +184,22 @@ var _ = Describe("Storage Watcher", func() {
 					close(done)
 				})
 
+				It("records progress for storage diffs", func(done Done) {
+					start := time.Now()
+
+					go storageWatcher.Execute(context.Background(), time.Hour)
+
+					Eventually(func() time.Time {
+						return health.DefaultProgressTracker.LastProgress()[health.StorageDiffs]
+					}).Should(BeTemporally(">=", start))
+					close(done)
+				})
+
 				It("recognizes header match even if hash hex missing 0x prefix", func(done Done) {
 					mockHeaderRepository.GetHeaderReturnHash = fakeBlockHash.Hex()[2:]
 
 					go func() {
-						err := storageWatcher.Execute(time.Hour)
+						err := storageWatcher.Execute(context.Background(), time.Hour)
 						Expect(err).NotTo(HaveOccurred())
 					}()
 
@@ -196,7 +218,7 @@ var _ = Describe("Storage Watcher", func() {
 						logrus.SetOutput(tempFile)
 
 						go func() {
-							err := storageWatcher.Execute(time.Hour)
+							err := storageWatcher.Execute(context.Background(), time.Hour)
 							Expect(err).NotTo(HaveOccurred())
 						}()
 
@@ -211,7 +233,7 @@ var _ = Describe("Storage Watcher", func() {
 						mockTransformer.ExecuteErr = fakes.FakeError
 
 						go func() {
-							err := storageWatcher.Execute(time.Hour)
+							err := storageWatcher.Execute(context.Background(), time.Hour)
 							Expect(err).NotTo(HaveOccurred())
 						}()
 
@@ -230,7 +252,7 @@ var _ = Describe("Storage Watcher", func() {
 						logrus.SetOutput(tempFile)
 
 						go func() {
-							err := storageWatcher.Execute(time.Hour)
+							err := storageWatcher.Execute(context.Background(), time.Hour)
 							Expect(err).NotTo(HaveOccurred())
 						}()
 
@@ -248,7 +270,7 @@ var _ = Describe("Storage Watcher", func() {
 					mockHeaderRepository.GetHeaderError = fakes.FakeError
 
 					go func() {
-						err := storageWatcher.Execute(time.Hour)
+						err := storageWatcher.Execute(context.Background(), time.Hour)
 						Expect(err).NotTo(HaveOccurred())
 					}()
 
@@ -263,7 +285,7 @@ var _ = Describe("Storage Watcher", func() {
 					mockHeaderRepository.GetHeaderReturnHash = wrongHash
 
 					go func() {
-						err := storageWatcher.Execute(time.Hour)
+						err := storageWatcher.Execute(context.Background(), time.Hour)
 						Expect(err).NotTo(HaveOccurred())
 					}()
 
@@ -305,7 +327,7 @@ var _ = Describe("Storage Watcher", func() {
 				Describe("when getting header succeeds", func() {
 					It("executes transformer for storage diff", func(done Done) {
 						go func() {
-							err := storageWatcher.Execute(time.Nanosecond)
+							err := storageWatcher.Execute(context.Background(), time.Nanosecond)
 							Expect(err).NotTo(HaveOccurred())
 						}()
 
@@ -318,7 +340,7 @@ var _ = Describe("Storage Watcher", func() {
 					Describe("when transformer execution successful", func() {
 						It("deletes diff from queue", func(done Done) {
 							go func() {
-								err := storageWatcher.Execute(time.Nanosecond)
+								err := storageWatcher.Execute(context.Background(), time.Nanosecond)
 								Expect(err).NotTo(HaveOccurred())
 							}()
 
@@ -336,7 +358,7 @@ var _ = Describe("Storage Watcher", func() {
 							logrus.SetOutput(tempFile)
 
 							go func() {
-								err := storageWatcher.Execute(time.Nanosecond)
+								err := storageWatcher.Execute(context.Background(), time.Nanosecond)
 								Expect(err).NotTo(HaveOccurred())
 							}()
 
@@ -360,7 +382,7 @@ var _ = Describe("Storage Watcher", func() {
 							logrus.SetOutput(tempFile)
 
 							go func() {
-								err := storageWatcher.Execute(time.Nanosecond)
+								err := storageWatcher.Execute(context.Background(), time.Nanosecond)
 								Expect(err).NotTo(HaveOccurred())
 							}()
 
@@ -373,7 +395,7 @@ var _ = Describe("Storage Watcher", func() {
 
 						It("does not delete diff from queue", func(done Done) {
 							go func() {
-								err := storageWatcher.Execute(time.Nanosecond)
+								err := storageWatcher.Execute(context.Background(), time.Nanosecond)
 								Expect(err).NotTo(HaveOccurred())
 							}()
 
@@ -389,7 +411,7 @@ var _ = Describe("Storage Watcher", func() {
 					It("does not delete diff from queue", func(done Done) {
 						mockHeaderRepository.GetHeaderError = fakes.FakeError
 						go func() {
-							err := storageWatcher.Execute(time.Nanosecond)
+							err := storageWatcher.Execute(context.Background(), time.Nanosecond)
 							Expect(err).NotTo(HaveOccurred())
 						}()
 
@@ -401,6 +423,143 @@ var _ = Describe("Storage Watcher", func() {
 				})
 			})
 
+			Describe("marking orphaned diffs", func() {
+				var mockStorageDiffRepository *fakes.MockStorageDiffRepository
+
+				BeforeEach(func() {
+					mockStorageDiffRepository = &fakes.MockStorageDiffRepository{}
+					storageWatcher.StorageDiffRepository = mockStorageDiffRepository
+					mockHeaderRepository.MostRecentHeaderBlockNumber = 100
+				})
+
+				It("marks mismatched diffs below the finality depth as orphaned", func(done Done) {
+					storageWatcher.FinalityDepth = 10
+
+					go func() {
+						err := storageWatcher.Execute(context.Background(), time.Nanosecond)
+						Expect(err).NotTo(HaveOccurred())
+					}()
+
+					Eventually(func() int64 {
+						return mockStorageDiffRepository.MarkOrphanedPassedHeight
+					}).Should(Equal(int64(90)))
+					close(done)
+				})
+
+				It("only checks heights above those already checked once final", func(done Done) {
+					storageWatcher.FinalityDepth = 10
+
+					go func() {
+						err := storageWatcher.Execute(context.Background(), time.Nanosecond)
+						Expect(err).NotTo(HaveOccurred())
+					}()
+
+					Eventually(func() int64 {
+						return mockStorageDiffRepository.MarkOrphanedPassedAfterHeight
+					}).Should(Equal(int64(90)))
+					Eventually(func() int64 {
+						return mockTransformer.DeleteOrphanedValuesPassedAfter
+					}).Should(Equal(int64(90)))
+					close(done)
+				})
+
+				It("checks the same heights again if deleting orphaned values fails", func(done Done) {
+					storageWatcher.FinalityDepth = 10
+					mockTransformer.DeleteOrphanedValuesErr = fakes.FakeError
+
+					go func() {
+						err := storageWatcher.Execute(context.Background(), time.Nanosecond)
+						Expect(err).NotTo(HaveOccurred())
+					}()
+
+					Eventually(func() bool {
+						return mockTransformer.DeleteOrphanedValuesCalled
+					}).Should(BeTrue())
+					Consistently(func() int64 {
+						return mockStorageDiffRepository.MarkOrphanedPassedAfterHeight
+					}).Should(BeZero())
+					close(done)
+				})
+
+				It("deletes values created from orphaned diffs", func(done Done) {
+					mockStorageDiffRepository.MarkOrphanedReturnCount = 1
+
+					go func() {
+						err := storageWatcher.Execute(context.Background(), time.Nanosecond)
+						Expect(err).NotTo(HaveOccurred())
+					}()
+
+					Eventually(func() bool {
+						return mockTransformer.DeleteOrphanedValuesCalled
+					}).Should(BeTrue())
+					close(done)
+				})
+
+				It("deletes values created from orphaned diffs even if none were newly orphaned", func(done Done) {
+					go func() {
+						err := storageWatcher.Execute(context.Background(), time.Nanosecond)
+						Expect(err).NotTo(HaveOccurred())
+					}()
+
+					Eventually(func() bool {
+						return mockTransformer.DeleteOrphanedValuesCalled
+					}).Should(BeTrue())
+					close(done)
+				})
+
+				It("logs error if deleting orphaned values fails", func(done Done) {
+					mockTransformer.DeleteOrphanedValuesErr = fakes.FakeError
+					tempFile, fileErr := ioutil.TempFile("", "log")
+					Expect(fileErr).NotTo(HaveOccurred())
+					defer os.Remove(tempFile.Name())
+					logrus.SetOutput(tempFile)
+
+					go func() {
+						err := storageWatcher.Execute(context.Background(), time.Nanosecond)
+						Expect(err).NotTo(HaveOccurred())
+					}()
+
+					Eventually(func() (string, error) {
+						logContent, readErr := ioutil.ReadFile(tempFile.Name())
+						return string(logContent), readErr
+					}).Should(ContainSubstring(fakes.FakeError.Error()))
+					close(done)
+				})
+
+				It("doesn't mark diffs until the chain is deeper than the finality depth", func(done Done) {
+					storageWatcher.FinalityDepth = 101
+
+					go func() {
+						err := storageWatcher.Execute(context.Background(), time.Nanosecond)
+						Expect(err).NotTo(HaveOccurred())
+					}()
+
+					Consistently(func() int64 {
+						return mockStorageDiffRepository.MarkOrphanedPassedHeight
+					}).Should(BeZero())
+					close(done)
+				})
+
+				It("logs error if marking orphaned diffs fails", func(done Done) {
+					mockStorageDiffRepository.MarkOrphanedReturnError = fakes.FakeError
+					tempFile, fileErr := ioutil.TempFile("", "log")
+					Expect(fileErr).NotTo(HaveOccurred())
+					defer os.Remove(tempFile.Name())
+					logrus.SetOutput(tempFile)
+
+					go func() {
+						err := storageWatcher.Execute(context.Background(), time.Nanosecond)
+						Expect(err).NotTo(HaveOccurred())
+					}()
+
+					Eventually(func() (string, error) {
+						logContent, readErr := ioutil.ReadFile(tempFile.Name())
+						return string(logContent), readErr
+					}).Should(ContainSubstring(fakes.FakeError.Error()))
+					close(done)
+				})
+			})
+
 			Describe("when contract not recognized", func() {
 				It("deletes obsolete diff from queue", func(done Done) {
 					obsoleteDiff := storage.PersistedDiff{
@@ -412,7 +571,7 @@ var _ = Describe("Storage Watcher", func() {
 					mockQueue.DiffsToReturn = []storage.PersistedDiff{obsoleteDiff}
 
 					go func() {
-						err := storageWatcher.Execute(time.Nanosecond)
+						err := storageWatcher.Execute(context.Background(), time.Nanosecond)
 						Expect(err).NotTo(HaveOccurred())
 					}()
 
@@ -437,7 +596,7 @@ var _ = Describe("Storage Watcher", func() {
 					logrus.SetOutput(tempFile)
 
 					go func() {
-						err := storageWatcher.Execute(time.Nanosecond)
+						err := storageWatcher.Execute(context.Background(), time.Nanosecond)
 						Expect(err).NotTo(HaveOccurred())
 					}()
 
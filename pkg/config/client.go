@@ -16,6 +16,78 @@
 
 package config
 
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// Client configures the connection to an Ethereum node. RPCURL is an http(s):// or ws(s):// URL; if it's empty,
+// IPCPath (which may also be a URL) is used. If RPCURLs is set, requests are spread across those nodes instead, which
+// are health checked every HealthCheckInterval and not used while their chain head is more than MaxHeadLag blocks behind.
 type Client struct {
-	IPCPath string
+	IPCPath             string
+	RPCURL              string
+	RPCURLs             []string
+	Headers             map[string]string
+	Timeout             time.Duration
+	TLS                 TLS
+	MaxHeadLag          int64
+	HealthCheckInterval time.Duration
+}
+
+type TLS struct {
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+}
+
+var ErrIncompleteClientCertificate = errors.New("tls certFile and keyFile must be set together")
+
+func (client Client) Endpoint() string {
+	if client.RPCURL != "" {
+		return client.RPCURL
+	}
+	return client.IPCPath
+}
+
+func (client Client) Endpoints() []string {
+	if len(client.RPCURLs) > 0 {
+		return client.RPCURLs
+	}
+	return []string{client.Endpoint()}
+}
+
+// TLSConfig builds the TLS configuration for HTTPS and WSS endpoints; it's nil if no TLS options are set.
+func (client Client) TLSConfig() (*tls.Config, error) {
+	if client.TLS == (TLS{}) {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: client.TLS.InsecureSkipVerify}
+	if client.TLS.CAFile != "" {
+		caPEM, readErr := ioutil.ReadFile(client.TLS.CAFile)
+		if readErr != nil {
+			return nil, fmt.Errorf("error reading tls caFile: %s", readErr.Error())
+		}
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in tls caFile %s", client.TLS.CAFile)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	if client.TLS.CertFile != "" || client.TLS.KeyFile != "" {
+		if client.TLS.CertFile == "" || client.TLS.KeyFile == "" {
+			return nil, ErrIncompleteClientCertificate
+		}
+		certificate, loadErr := tls.LoadX509KeyPair(client.TLS.CertFile, client.TLS.KeyFile)
+		if loadErr != nil {
+			return nil, fmt.Errorf("error loading tls client certificate: %s", loadErr.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return tlsConfig, nil
 }
@@ -0,0 +1,102 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config_test
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/makerdao/vulcanizedb/pkg/config"
+)
+
+var _ = Describe("Client config", func() {
+	Describe("Endpoint", func() {
+		It("uses the RPC URL if it's set", func() {
+			client := config.Client{IPCPath: "/geth.ipc", RPCURL: "wss://node.example.com"}
+			Expect(client.Endpoint()).To(Equal("wss://node.example.com"))
+		})
+
+		It("falls back to the IPC path", func() {
+			client := config.Client{IPCPath: "/geth.ipc"}
+			Expect(client.Endpoint()).To(Equal("/geth.ipc"))
+		})
+	})
+
+	Describe("Endpoints", func() {
+		It("uses the RPC URLs if they're set", func() {
+			client := config.Client{RPCURL: "wss://node.example.com", RPCURLs: []string{"https://a.example.com", "https://b.example.com"}}
+			Expect(client.Endpoints()).To(Equal([]string{"https://a.example.com", "https://b.example.com"}))
+		})
+
+		It("falls back to the single endpoint", func() {
+			client := config.Client{IPCPath: "/geth.ipc"}
+			Expect(client.Endpoints()).To(Equal([]string{"/geth.ipc"}))
+		})
+	})
+
+	Describe("TLSConfig", func() {
+		It("returns nil if no TLS options are set", func() {
+			tlsConfig, err := config.Client{}.TLSConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tlsConfig).To(BeNil())
+		})
+
+		It("sets InsecureSkipVerify", func() {
+			client := config.Client{TLS: config.TLS{InsecureSkipVerify: true}}
+			tlsConfig, err := client.TLSConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tlsConfig.InsecureSkipVerify).To(BeTrue())
+		})
+
+		It("trusts certificates in the CA file", func() {
+			server := httptest.NewTLSServer(http.NotFoundHandler())
+			defer server.Close()
+			caFile, fileErr := ioutil.TempFile("", "ca*.pem")
+			Expect(fileErr).NotTo(HaveOccurred())
+			defer os.Remove(caFile.Name())
+			Expect(pem.Encode(caFile, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})).To(Succeed())
+			Expect(caFile.Close()).To(Succeed())
+
+			client := config.Client{TLS: config.TLS{CAFile: caFile.Name()}}
+			tlsConfig, err := client.TLSConfig()
+			Expect(err).NotTo(HaveOccurred())
+
+			httpClient := http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+			resp, getErr := httpClient.Get(server.URL)
+			Expect(getErr).NotTo(HaveOccurred())
+			resp.Body.Close()
+		})
+
+		It("returns an error if the CA file can't be read", func() {
+			client := config.Client{TLS: config.TLS{CAFile: "/nonexistent/ca.pem"}}
+			_, err := client.TLSConfig()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns an error if only one of the certificate and key files is set", func() {
+			client := config.Client{TLS: config.TLS{CertFile: "client.pem"}}
+			_, err := client.TLSConfig()
+			Expect(err).To(MatchError(config.ErrIncompleteClientCertificate))
+		})
+	})
+})
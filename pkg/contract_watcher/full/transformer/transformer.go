@@ -17,6 +17,7 @@
 package transformer
 
 import (
+	"context"
 	"errors"
 
 	"github.com/sirupsen/logrus"
@@ -110,7 +111,7 @@ func (tr *Transformer) Init() error {
 
 		// Get contract name if it has one
 		var name = new(string)
-		pollingErr := tr.Poller.FetchContractData(tr.Parser.Abi(), contractAddr, "name", nil, name, tr.LastBlock)
+		pollingErr := tr.Poller.FetchContractData(context.Background(), tr.Parser.Abi(), contractAddr, "name", nil, name, tr.LastBlock)
 		if pollingErr != nil {
 			// can't return this error because "name" might not exist on the contract
 			logrus.Warnf("error fetching contract data: %s", pollingErr.Error())
@@ -175,7 +176,7 @@ func (tr *Transformer) Init() error {
 // Uses converter to convert logs into custom log type
 // Persists converted logs into custom postgres tables
 // Calls selected methods, using token holder address generated during event log conversion
-func (tr *Transformer) Execute() error {
+func (tr *Transformer) Execute(ctx context.Context) error {
 	if len(tr.Contracts) == 0 {
 		return errors.New("error: transformer has no initialized contracts to work with")
 	}
@@ -214,7 +215,7 @@ func (tr *Transformer) Execute() error {
 		// After persisting all watched event logs
 		// poller polls select contract methods
 		// and persists the results into custom pg tables
-		if err := tr.Poller.PollContract(*con, tr.LastBlock); err != nil {
+		if err := tr.Poller.PollContract(ctx, *con, tr.LastBlock); err != nil {
 			return err
 		}
 	}
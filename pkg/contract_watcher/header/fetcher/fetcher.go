@@ -17,6 +17,7 @@
 package fetcher
 
 import (
+	"context"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -26,7 +27,7 @@ import (
 
 // Fetcher is the fetching interface
 type Fetcher interface {
-	FetchLogs(contractAddresses []string, topics []common.Hash, missingHeader core.Header) ([]types.Log, error)
+	FetchLogs(ctx context.Context, contractAddresses []string, topics []common.Hash, missingHeader core.Header) ([]types.Log, error)
 }
 
 type fetcher struct {
@@ -41,7 +42,7 @@ func NewFetcher(blockchain core.BlockChain) Fetcher {
 }
 
 // FetchLogs checks all topic0s, on all addresses, fetching matching logs for the given header
-func (fetcher *fetcher) FetchLogs(contractAddresses []string, topic0s []common.Hash, header core.Header) ([]types.Log, error) {
+func (fetcher *fetcher) FetchLogs(ctx context.Context, contractAddresses []string, topic0s []common.Hash, header core.Header) ([]types.Log, error) {
 	addresses := hexStringsToAddresses(contractAddresses)
 	blockHash := common.HexToHash(header.Hash)
 	query := ethereum.FilterQuery{
@@ -51,7 +52,7 @@ func (fetcher *fetcher) FetchLogs(contractAddresses []string, topic0s []common.H
 		Topics: [][]common.Hash{topic0s},
 	}
 
-	logs, err := fetcher.blockChain.GetEthLogsWithCustomQuery(query)
+	logs, err := fetcher.blockChain.GetEthLogsWithCustomQuery(ctx, query)
 	if err != nil {
 		// TODO review aggregate fetching error handling
 		return []types.Log{}, err
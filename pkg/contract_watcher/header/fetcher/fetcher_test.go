@@ -17,6 +17,8 @@
 package fetcher_test
 
 import (
+	"context"
+
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	. "github.com/onsi/ginkgo"
@@ -37,7 +39,7 @@ var _ = Describe("Fetcher", func() {
 			addresses := []string{"0xfakeAddress", "0xanotherFakeAddress"}
 			topicZeros := [][]common.Hash{{common.BytesToHash([]byte{1, 2, 3, 4, 5})}}
 
-			_, err := fetcher.FetchLogs(addresses, []common.Hash{common.BytesToHash([]byte{1, 2, 3, 4, 5})}, header)
+			_, err := fetcher.FetchLogs(context.Background(), addresses, []common.Hash{common.BytesToHash([]byte{1, 2, 3, 4, 5})}, header)
 
 			address1 := common.HexToAddress("0xfakeAddress")
 			address2 := common.HexToAddress("0xanotherFakeAddress")
@@ -57,7 +59,7 @@ var _ = Describe("Fetcher", func() {
 			blockChain.SetGetEthLogsWithCustomQueryErr(fakes.FakeError)
 			fetcher := fetcher.NewFetcher(blockChain)
 
-			_, err := fetcher.FetchLogs([]string{}, []common.Hash{}, core.Header{})
+			_, err := fetcher.FetchLogs(context.Background(), []string{}, []common.Hash{}, core.Header{})
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -17,6 +17,7 @@
 package transformer
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -142,7 +143,7 @@ func (tr *Transformer) Init() error {
 
 		// Get contract name if it has one
 		var name = new(string)
-		pollingErr := tr.Poller.FetchContractData(tr.Parser.Abi(), contractAddr, "name", nil, name, -1)
+		pollingErr := tr.Poller.FetchContractData(context.Background(), tr.Parser.Abi(), contractAddr, "name", nil, name, -1)
 		if pollingErr != nil {
 			// can't return this error because "name" might not exist on the contract
 			logrus.Warnf("error fetching contract data: %s", pollingErr.Error())
@@ -211,7 +212,7 @@ func (tr *Transformer) Init() error {
 }
 
 // Execute runs the transformation processes
-func (tr *Transformer) Execute() error {
+func (tr *Transformer) Execute(ctx context.Context) error {
 	if len(tr.Contracts) == 0 {
 		return errors.New("error: transformer has no initialized contracts")
 	}
@@ -231,7 +232,7 @@ func (tr *Transformer) Execute() error {
 		// Map to sort batch fetched logs by which contract they belong to, for post fetch processing
 		sortedLogs := make(map[string][]gethTypes.Log)
 		// And fetch all event logs across contracts at this header
-		allLogs, fetchErr := tr.Fetcher.FetchLogs(tr.contractAddresses, tr.eventFilters, header)
+		allLogs, fetchErr := tr.Fetcher.FetchLogs(ctx, tr.contractAddresses, tr.eventFilters, header)
 		if fetchErr != nil {
 			return fmt.Errorf("error fetching logs: %s", fetchErr.Error())
 		}
@@ -243,7 +244,7 @@ func (tr *Transformer) Execute() error {
 			if markCheckedErr != nil {
 				return fmt.Errorf("error marking header checked: %s", markCheckedErr.Error())
 			}
-			pollingErr := tr.methodPolling(header, tr.sortedMethodIds)
+			pollingErr := tr.methodPolling(ctx, header, tr.sortedMethodIds)
 			if pollingErr != nil {
 				return fmt.Errorf("error polling methods: %s", pollingErr.Error())
 			}
@@ -293,7 +294,7 @@ func (tr *Transformer) Execute() error {
 		}
 
 		// Poll contracts at this block height
-		pollingErr := tr.methodPolling(header, tr.sortedMethodIds)
+		pollingErr := tr.methodPolling(ctx, header, tr.sortedMethodIds)
 		if pollingErr != nil {
 			return fmt.Errorf("error polling methods: %s", pollingErr.Error())
 		}
@@ -305,7 +306,7 @@ func (tr *Transformer) Execute() error {
 }
 
 // Used to poll contract methods at a given header
-func (tr *Transformer) methodPolling(header core.Header, sortedMethodIds map[string][]string) error {
+func (tr *Transformer) methodPolling(ctx context.Context, header core.Header, sortedMethodIds map[string][]string) error {
 	for _, con := range tr.Contracts {
 		// Skip method polling processes if no methods are specified
 		// Also don't try to poll methods below this contract's specified starting block
@@ -315,7 +316,7 @@ func (tr *Transformer) methodPolling(header core.Header, sortedMethodIds map[str
 		}
 
 		// Poll all methods for this contract at this header
-		pollingErr := tr.Poller.PollContractAt(*con, header.BlockNumber)
+		pollingErr := tr.Poller.PollContractAt(ctx, *con, header.BlockNumber)
 		if pollingErr != nil {
 			return fmt.Errorf("error polling contract %s: %s", con.Address, pollingErr.Error())
 		}
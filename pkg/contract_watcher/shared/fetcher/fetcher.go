@@ -17,6 +17,7 @@
 package fetcher
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math/big"
@@ -31,11 +32,11 @@ import (
 
 // FetcherInterface is the interface definition for a fetcher
 type FetcherInterface interface {
-	FetchBigInt(method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (big.Int, error)
-	FetchBool(method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (bool, error)
-	FetchAddress(method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (common.Address, error)
-	FetchString(method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (string, error)
-	FetchHash(method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (common.Hash, error)
+	FetchBigInt(ctx context.Context, method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (big.Int, error)
+	FetchBool(ctx context.Context, method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (bool, error)
+	FetchAddress(ctx context.Context, method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (common.Address, error)
+	FetchString(ctx context.Context, method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (string, error)
+	FetchHash(ctx context.Context, method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (common.Hash, error)
 }
 
 // Used to create a new Fetcher error for a given error and fetch method
@@ -64,9 +65,9 @@ func (fe *fetcherError) Error() string {
 //  Generic Fetcher methods used by Getters to call contract methods
 
 // FetchBigInt is the method used to fetch big.Int value from contract
-func (f Fetcher) FetchBigInt(method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (big.Int, error) {
+func (f Fetcher) FetchBigInt(ctx context.Context, method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (big.Int, error) {
 	var result = new(big.Int)
-	err := f.BlockChain.FetchContractData(contractAbi, contractAddress, method, methodArgs, &result, blockNumber)
+	err := f.BlockChain.FetchContractData(ctx, contractAbi, contractAddress, method, methodArgs, &result, blockNumber)
 
 	if err != nil {
 		return *result, newFetcherError(err, method)
@@ -76,9 +77,9 @@ func (f Fetcher) FetchBigInt(method, contractAbi, contractAddress string, blockN
 }
 
 // FetchBool is the method used to fetch bool value from contract
-func (f Fetcher) FetchBool(method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (bool, error) {
+func (f Fetcher) FetchBool(ctx context.Context, method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (bool, error) {
 	var result = new(bool)
-	err := f.BlockChain.FetchContractData(contractAbi, contractAddress, method, methodArgs, &result, blockNumber)
+	err := f.BlockChain.FetchContractData(ctx, contractAbi, contractAddress, method, methodArgs, &result, blockNumber)
 
 	if err != nil {
 		return *result, newFetcherError(err, method)
@@ -88,9 +89,9 @@ func (f Fetcher) FetchBool(method, contractAbi, contractAddress string, blockNum
 }
 
 // FetchAddress is the method used to fetch address value from contract
-func (f Fetcher) FetchAddress(method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (common.Address, error) {
+func (f Fetcher) FetchAddress(ctx context.Context, method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (common.Address, error) {
 	var result = new(common.Address)
-	err := f.BlockChain.FetchContractData(contractAbi, contractAddress, method, methodArgs, &result, blockNumber)
+	err := f.BlockChain.FetchContractData(ctx, contractAbi, contractAddress, method, methodArgs, &result, blockNumber)
 
 	if err != nil {
 		return *result, newFetcherError(err, method)
@@ -100,9 +101,9 @@ func (f Fetcher) FetchAddress(method, contractAbi, contractAddress string, block
 }
 
 // FetchString is the method used to fetch string value from contract
-func (f Fetcher) FetchString(method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (string, error) {
+func (f Fetcher) FetchString(ctx context.Context, method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (string, error) {
 	var result = new(string)
-	err := f.BlockChain.FetchContractData(contractAbi, contractAddress, method, methodArgs, &result, blockNumber)
+	err := f.BlockChain.FetchContractData(ctx, contractAbi, contractAddress, method, methodArgs, &result, blockNumber)
 
 	if err != nil {
 		return *result, newFetcherError(err, method)
@@ -112,9 +113,9 @@ func (f Fetcher) FetchString(method, contractAbi, contractAddress string, blockN
 }
 
 // FetchHash is the method used to fetch hash value from contract
-func (f Fetcher) FetchHash(method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (common.Hash, error) {
+func (f Fetcher) FetchHash(ctx context.Context, method, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (common.Hash, error) {
 	var result = new(common.Hash)
-	err := f.BlockChain.FetchContractData(contractAbi, contractAddress, method, methodArgs, &result, blockNumber)
+	err := f.BlockChain.FetchContractData(ctx, contractAbi, contractAddress, method, methodArgs, &result, blockNumber)
 
 	if err != nil {
 		return *result, newFetcherError(err, method)
@@ -17,6 +17,8 @@
 package getter_test
 
 import (
+	"context"
+
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 	. "github.com/onsi/ginkgo"
@@ -47,7 +49,7 @@ var _ = Describe("Interface Getter", func() {
 			transactionConverter := rpc2.NewRpcTransactionConverter(ethClient)
 			blockChain := eth.NewBlockChain(blockChainClient, rpcClient, node, transactionConverter)
 			interfaceGetter := getter.NewInterfaceGetter(blockChain)
-			abi, err := interfaceGetter.GetABI(constants.PublicResolverAddress, blockNumber)
+			abi, err := interfaceGetter.GetABI(context.Background(), constants.PublicResolverAddress, blockNumber)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(abi).To(Equal(expectedABI))
 			_, err = eth.ParseAbi(abi)
@@ -17,6 +17,7 @@
 package getter
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/constants"
@@ -26,7 +27,7 @@ import (
 
 // InterfaceGetter is used to derive the interface of a contract
 type InterfaceGetter interface {
-	GetABI(resolverAddr string, blockNumber int64) (string, error)
+	GetABI(ctx context.Context, resolverAddr string, blockNumber int64) (string, error)
 	GetBlockChain() core.BlockChain
 }
 
@@ -44,11 +45,11 @@ func NewInterfaceGetter(blockChain core.BlockChain) InterfaceGetter {
 }
 
 // GetABI is used to construct a custom ABI based on the results from calling supportsInterface
-func (g *interfaceGetter) GetABI(resolverAddr string, blockNumber int64) (string, error) {
+func (g *interfaceGetter) GetABI(ctx context.Context, resolverAddr string, blockNumber int64) (string, error) {
 	a := constants.SupportsInterfaceABI
 	args := make([]interface{}, 1)
 	args[0] = constants.MetaSig.Bytes()
-	supports, err := g.getSupportsInterface(a, resolverAddr, blockNumber, args)
+	supports, err := g.getSupportsInterface(ctx, a, resolverAddr, blockNumber, args)
 	if err != nil {
 		return "", fmt.Errorf("call to getSupportsInterface failed: %v", err)
 	}
@@ -58,42 +59,42 @@ func (g *interfaceGetter) GetABI(resolverAddr string, blockNumber int64) (string
 
 	abiStr := `[`
 	args[0] = constants.AddrChangeSig.Bytes()
-	supports, err = g.getSupportsInterface(a, resolverAddr, blockNumber, args)
+	supports, err = g.getSupportsInterface(ctx, a, resolverAddr, blockNumber, args)
 	if err == nil && supports {
 		abiStr += constants.AddrChangeInterface + ","
 	}
 	args[0] = constants.NameChangeSig.Bytes()
-	supports, err = g.getSupportsInterface(a, resolverAddr, blockNumber, args)
+	supports, err = g.getSupportsInterface(ctx, a, resolverAddr, blockNumber, args)
 	if err == nil && supports {
 		abiStr += constants.NameChangeInterface + ","
 	}
 	args[0] = constants.ContentChangeSig.Bytes()
-	supports, err = g.getSupportsInterface(a, resolverAddr, blockNumber, args)
+	supports, err = g.getSupportsInterface(ctx, a, resolverAddr, blockNumber, args)
 	if err == nil && supports {
 		abiStr += constants.ContentChangeInterface + ","
 	}
 	args[0] = constants.AbiChangeSig.Bytes()
-	supports, err = g.getSupportsInterface(a, resolverAddr, blockNumber, args)
+	supports, err = g.getSupportsInterface(ctx, a, resolverAddr, blockNumber, args)
 	if err == nil && supports {
 		abiStr += constants.AbiChangeInterface + ","
 	}
 	args[0] = constants.PubkeyChangeSig.Bytes()
-	supports, err = g.getSupportsInterface(a, resolverAddr, blockNumber, args)
+	supports, err = g.getSupportsInterface(ctx, a, resolverAddr, blockNumber, args)
 	if err == nil && supports {
 		abiStr += constants.PubkeyChangeInterface + ","
 	}
 	args[0] = constants.ContentHashChangeSig.Bytes()
-	supports, err = g.getSupportsInterface(a, resolverAddr, blockNumber, args)
+	supports, err = g.getSupportsInterface(ctx, a, resolverAddr, blockNumber, args)
 	if err == nil && supports {
 		abiStr += constants.ContenthashChangeInterface + ","
 	}
 	args[0] = constants.MultihashChangeSig.Bytes()
-	supports, err = g.getSupportsInterface(a, resolverAddr, blockNumber, args)
+	supports, err = g.getSupportsInterface(ctx, a, resolverAddr, blockNumber, args)
 	if err == nil && supports {
 		abiStr += constants.MultihashChangeInterface + ","
 	}
 	args[0] = constants.TextChangeSig.Bytes()
-	supports, err = g.getSupportsInterface(a, resolverAddr, blockNumber, args)
+	supports, err = g.getSupportsInterface(ctx, a, resolverAddr, blockNumber, args)
 	if err == nil && supports {
 		abiStr += constants.TextChangeInterface + ","
 	}
@@ -103,8 +104,8 @@ func (g *interfaceGetter) GetABI(resolverAddr string, blockNumber int64) (string
 }
 
 // Use this method to check whether or not a contract supports a given method/event interface
-func (g *interfaceGetter) getSupportsInterface(contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (bool, error) {
-	return g.Fetcher.FetchBool("supportsInterface", contractAbi, contractAddress, blockNumber, methodArgs)
+func (g *interfaceGetter) getSupportsInterface(ctx context.Context, contractAbi, contractAddress string, blockNumber int64, methodArgs []interface{}) (bool, error) {
+	return g.Fetcher.FetchBool(ctx, "supportsInterface", contractAbi, contractAddress, blockNumber, methodArgs)
 }
 
 // GetBlockChain is a method to retrieve the Getter's blockchain
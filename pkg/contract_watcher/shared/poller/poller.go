@@ -17,6 +17,7 @@
 package poller
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -35,9 +36,9 @@ import (
 
 // Poller is the interface for polling public contract methods
 type Poller interface {
-	PollContract(con contract.Contract, lastBlock int64) error
-	PollContractAt(con contract.Contract, blockNumber int64) error
-	FetchContractData(contractAbi, contractAddress, method string, methodArgs []interface{}, result interface{}, blockNumber int64) error
+	PollContract(ctx context.Context, con contract.Contract, lastBlock int64) error
+	PollContractAt(ctx context.Context, con contract.Contract, blockNumber int64) error
+	FetchContractData(ctx context.Context, contractAbi, contractAddress, method string, methodArgs []interface{}, result interface{}, blockNumber int64) error
 }
 
 type poller struct {
@@ -55,9 +56,9 @@ func NewPoller(blockChain core.BlockChain, db *postgres.DB, mode types.Mode) Pol
 }
 
 // PollContract polls a contract's public methods from the contracts starting block to specified last block
-func (p *poller) PollContract(con contract.Contract, lastBlock int64) error {
+func (p *poller) PollContract(ctx context.Context, con contract.Contract, lastBlock int64) error {
 	for i := con.StartingBlock; i <= lastBlock; i++ {
-		if err := p.PollContractAt(con, i); err != nil {
+		if err := p.PollContractAt(ctx, con, i); err != nil {
 			return err
 		}
 	}
@@ -66,20 +67,20 @@ func (p *poller) PollContract(con contract.Contract, lastBlock int64) error {
 }
 
 // PollContractAt polls a contract's public getter methods at the specified block height
-func (p *poller) PollContractAt(con contract.Contract, blockNumber int64) error {
+func (p *poller) PollContractAt(ctx context.Context, con contract.Contract, blockNumber int64) error {
 	p.contract = con
 	for _, m := range con.Methods {
 		switch len(m.Args) {
 		case 0:
-			if err := p.pollNoArgAt(m, blockNumber); err != nil {
+			if err := p.pollNoArgAt(ctx, m, blockNumber); err != nil {
 				return err
 			}
 		case 1:
-			if err := p.pollSingleArgAt(m, blockNumber); err != nil {
+			if err := p.pollSingleArgAt(ctx, m, blockNumber); err != nil {
 				return err
 			}
 		case 2:
-			if err := p.pollDoubleArgAt(m, blockNumber); err != nil {
+			if err := p.pollDoubleArgAt(ctx, m, blockNumber); err != nil {
 				return err
 			}
 		default:
@@ -91,7 +92,7 @@ func (p *poller) PollContractAt(con contract.Contract, blockNumber int64) error
 	return nil
 }
 
-func (p *poller) pollNoArgAt(m types.Method, bn int64) error {
+func (p *poller) pollNoArgAt(ctx context.Context, m types.Method, bn int64) error {
 	result := types.Result{
 		Block:  bn,
 		Method: m,
@@ -100,7 +101,7 @@ func (p *poller) pollNoArgAt(m types.Method, bn int64) error {
 	}
 
 	var out interface{}
-	err := p.bc.FetchContractData(p.contract.Abi, p.contract.Address, m.Name, nil, &out, bn)
+	err := p.bc.FetchContractData(ctx, p.contract.Abi, p.contract.Address, m.Name, nil, &out, bn)
 	if err != nil {
 		return fmt.Errorf("poller error calling 0 argument method\r\nblock: %d, method: %s, contract: %s\r\nerr: %v", bn, m.Name, p.contract.Address, err)
 	}
@@ -123,7 +124,7 @@ func (p *poller) pollNoArgAt(m types.Method, bn int64) error {
 }
 
 // Use token holder address to poll methods that take 1 address argument (e.g. balanceOf)
-func (p *poller) pollSingleArgAt(m types.Method, bn int64) error {
+func (p *poller) pollSingleArgAt(ctx context.Context, m types.Method, bn int64) error {
 	result := types.Result{
 		Block:  bn,
 		Method: m,
@@ -150,7 +151,7 @@ func (p *poller) pollSingleArgAt(m types.Method, bn int64) error {
 		strIn := []interface{}{contract.StringifyArg(arg)}
 
 		var out interface{}
-		err := p.bc.FetchContractData(p.contract.Abi, p.contract.Address, m.Name, in, &out, bn)
+		err := p.bc.FetchContractData(ctx, p.contract.Abi, p.contract.Address, m.Name, in, &out, bn)
 		if err != nil {
 			return fmt.Errorf("poller error calling 1 argument method\r\nblock: %d, method: %s, contract: %s\r\nerr: %v", bn, m.Name, p.contract.Address, err)
 		}
@@ -175,7 +176,7 @@ func (p *poller) pollSingleArgAt(m types.Method, bn int64) error {
 }
 
 // Use token holder address to poll methods that take 2 address arguments (e.g. allowance)
-func (p *poller) pollDoubleArgAt(m types.Method, bn int64) error {
+func (p *poller) pollDoubleArgAt(ctx context.Context, m types.Method, bn int64) error {
 	result := types.Result{
 		Block:  bn,
 		Method: m,
@@ -214,7 +215,7 @@ func (p *poller) pollDoubleArgAt(m types.Method, bn int64) error {
 			strIn := []interface{}{contract.StringifyArg(arg1), contract.StringifyArg(arg2)}
 
 			var out interface{}
-			err := p.bc.FetchContractData(p.contract.Abi, p.contract.Address, m.Name, in, &out, bn)
+			err := p.bc.FetchContractData(ctx, p.contract.Abi, p.contract.Address, m.Name, in, &out, bn)
 			if err != nil {
 				return fmt.Errorf("poller error calling 2 argument method\r\nblock: %d, method: %s, contract: %s\r\nerr: %v", bn, m.Name, p.contract.Address, err)
 			}
@@ -239,8 +240,8 @@ func (p *poller) pollDoubleArgAt(m types.Method, bn int64) error {
 }
 
 // FetchContractData is just a wrapper around the poller blockchain's FetchContractData method
-func (p *poller) FetchContractData(contractAbi, contractAddress, method string, methodArgs []interface{}, result interface{}, blockNumber int64) error {
-	return p.bc.FetchContractData(contractAbi, contractAddress, method, methodArgs, result, blockNumber)
+func (p *poller) FetchContractData(ctx context.Context, contractAbi, contractAddress, method string, methodArgs []interface{}, result interface{}, blockNumber int64) error {
+	return p.bc.FetchContractData(ctx, contractAbi, contractAddress, method, methodArgs, result, blockNumber)
 }
 
 // This is used to cache a method return value if method piping is turned on
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"context"
 	"github.com/ethereum/go-ethereum/common"
 	"math/big"
 
@@ -27,20 +28,21 @@ import (
 type BlockChain interface {
 	ContractDataFetcher
 	AccountDataFetcher
-	GetBlockByNumber(blockNumber int64) (Block, error)
-	GetEthLogsWithCustomQuery(query ethereum.FilterQuery) ([]types.Log, error)
-	GetHeaderByNumber(blockNumber int64) (Header, error)
-	GetHeadersByNumbers(blockNumbers []int64) ([]Header, error)
-	GetFullSyncLogs(contract Contract, startingBlockNumber *big.Int, endingBlockNumber *big.Int) ([]FullSyncLog, error)
-	GetTransactions(transactionHashes []common.Hash) ([]TransactionModel, error)
-	LastBlock() (*big.Int, error)
+	GetBlockByNumber(ctx context.Context, blockNumber int64) (Block, error)
+	GetEthLogsWithCustomQuery(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+	GetHeaderByNumber(ctx context.Context, blockNumber int64) (Header, error)
+	GetHeadersByNumbers(ctx context.Context, blockNumbers []int64) ([]Header, error)
+	GetFullSyncLogs(ctx context.Context, contract Contract, startingBlockNumber *big.Int, endingBlockNumber *big.Int) ([]FullSyncLog, error)
+	GetTransactions(ctx context.Context, transactionHashes []common.Hash) ([]TransactionModel, error)
+	GetTransactionReceipts(ctx context.Context, transactionHashes []common.Hash) ([]Receipt, error)
+	LastBlock(ctx context.Context) (*big.Int, error)
 	Node() Node
 }
 
 type ContractDataFetcher interface {
-	FetchContractData(abiJSON string, address string, method string, methodArgs []interface{}, result interface{}, blockNumber int64) error
+	FetchContractData(ctx context.Context, abiJSON string, address string, method string, methodArgs []interface{}, result interface{}, blockNumber int64) error
 }
 
 type AccountDataFetcher interface {
-	GetAccountBalance(address common.Address, blockNumber *big.Int) (*big.Int, error)
+	GetAccountBalance(ctx context.Context, address common.Address, blockNumber *big.Int) (*big.Int, error)
 }
@@ -26,6 +26,7 @@ type Header struct {
 	Id          int64
 	BlockNumber int64 `db:"block_number"`
 	Hash        string
+	ParentHash  string `db:"parent_hash"`
 	Raw         []byte
 	Timestamp   string `db:"block_timestamp"`
 }
@@ -28,8 +28,9 @@ type FullSyncLog struct {
 }
 
 type HeaderSyncLog struct {
-	ID          int64
-	HeaderID    int64 `db:"header_id"`
-	Log         types.Log
-	Transformed bool
+	ID            int64
+	HeaderID      int64 `db:"header_id"`
+	Log           types.Log
+	Transformed   bool
+	TransformedBy []string // Names of the transformers that have already processed the log
 }
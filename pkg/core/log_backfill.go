@@ -0,0 +1,27 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+// A range of headers that were checked before a contract address + topics were watched, and so still need to be checked
+// for its logs. NextBlockNumber advances as the range is backfilled.
+type LogBackfill struct {
+	ContractAddress   string `db:"contract_address"`
+	TopicZero         string `db:"topic_zero"`
+	TopicFilters      string `db:"topic_filters"`
+	NextBlockNumber   int64  `db:"backfill_next_block"`
+	EndingBlockNumber int64  `db:"backfill_ending_block"`
+}
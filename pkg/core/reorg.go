@@ -0,0 +1,55 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+// Reorg describes a chain reorganization: the headers that were orphaned
+// and the canonical headers replacing them, both ordered from highest to lowest block.
+type Reorg struct {
+	ID             int64
+	CommonAncestor int64 `db:"common_ancestor"`
+	OldHeaders     []Header
+	NewHeaders     []Header
+}
+
+func (reorg Reorg) Depth() int64 {
+	return int64(len(reorg.OldHeaders))
+}
+
+// Returns the block numbers of the orphaned headers, from highest to lowest
+func (reorg Reorg) BlockNumbers() []int64 {
+	var blockNumbers []int64
+	for _, header := range reorg.OldHeaders {
+		blockNumbers = append(blockNumbers, header.BlockNumber)
+	}
+	return blockNumbers
+}
+
+func (reorg Reorg) OldHashes() []string {
+	return getHashes(reorg.OldHeaders)
+}
+
+func (reorg Reorg) NewHashes() []string {
+	return getHashes(reorg.NewHeaders)
+}
+
+func getHashes(headers []Header) []string {
+	var hashes []string
+	for _, header := range headers {
+		hashes = append(hashes, header.Hash)
+	}
+	return hashes
+}
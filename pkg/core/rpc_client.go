@@ -25,7 +25,7 @@ import (
 
 type RpcClient interface {
 	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
-	BatchCall(batch []client.BatchElem) error
+	BatchCallContext(ctx context.Context, batch []client.BatchElem) error
 	IpcPath() string
 	SupportedModules() (map[string]string, error)
 	Subscribe(namespace string, payloadChan interface{}, args ...interface{}) (*rpc.ClientSubscription, error)
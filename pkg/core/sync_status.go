@@ -0,0 +1,28 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+// Snapshot of how far the synced data and its transformation lag behind, for monitoring
+type SyncStatus struct {
+	HighestHeaderBlockNumber int64 `db:"highest_header_block_number"`
+	MissingHeaders           int64 `db:"missing_headers"`
+	UncheckedHeaders         int64 `db:"unchecked_headers"`
+	UntransformedLogs        int64 `db:"untransformed_logs"`
+	QueuedStorageDiffs       int64 `db:"queued_storage_diffs"`
+	HighestBlockNumber       int64 `db:"highest_block_number"`
+	MissingBlocks            int64 `db:"missing_blocks"`
+}
@@ -17,8 +17,10 @@
 package repositories
 
 import (
+	"github.com/lib/pq"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/utils"
 )
 
 const (
@@ -39,12 +41,77 @@ func (repo CheckedHeadersRepository) MarkHeaderChecked(headerID int64) error {
 	return err
 }
 
-// Zero out check count for headers with block number >= startingBlockNumber
-func (repo CheckedHeadersRepository) MarkHeadersUnchecked(startingBlockNumber int64) error {
-	_, err := repo.db.Exec(`UPDATE public.headers SET check_count = 0 WHERE block_number >= $1`, startingBlockNumber)
+// Increment check_count for every passed header in a single transaction
+func (repo CheckedHeadersRepository) MarkHeadersChecked(headerIDs []int64) error {
+	tx, beginErr := repo.db.Beginx()
+	if beginErr != nil {
+		return postgres.ErrBeginTransactionFailed(beginErr)
+	}
+	for _, headerID := range headerIDs {
+		_, updateErr := tx.Exec(insertCheckedHeaderQuery, headerID)
+		if updateErr != nil {
+			utils.RollbackAndLogFailure(tx, updateErr, "checked headers")
+			return updateErr
+		}
+	}
+	return tx.Commit()
+}
+
+// Zero out check count for headers with block number >= startingBlockNumber and <= endingBlockNumber, or with no upper
+// bound if endingBlockNumber is -1
+func (repo CheckedHeadersRepository) MarkHeadersUnchecked(startingBlockNumber, endingBlockNumber int64) error {
+	if endingBlockNumber == -1 {
+		_, err := repo.db.Exec(`UPDATE public.headers SET check_count = 0, checked_unconfirmed = FALSE
+			WHERE block_number >= $1`, startingBlockNumber)
+		return err
+	}
+	_, err := repo.db.Exec(`UPDATE public.headers SET check_count = 0, checked_unconfirmed = FALSE
+		WHERE block_number >= $1 AND block_number <= $2`, startingBlockNumber, endingBlockNumber)
+	return err
+}
+
+// Record that the headers are being checked before they have the required confirmations, so that they're re-checked
+// once they do (even after a restart)
+func (repo CheckedHeadersRepository) MarkHeadersUnconfirmed(headerIDs []int64) error {
+	_, err := repo.db.Exec(`UPDATE public.headers SET checked_unconfirmed = TRUE WHERE id = ANY($1::INTEGER[])`,
+		pq.Array(headerIDs))
+	return err
+}
+
+// Record that the headers have been re-checked with the required confirmations
+func (repo CheckedHeadersRepository) MarkHeadersConfirmed(headerIDs []int64) error {
+	_, err := repo.db.Exec(`UPDATE public.headers SET checked_unconfirmed = FALSE WHERE id = ANY($1::INTEGER[])`,
+		pq.Array(headerIDs))
 	return err
 }
 
+// Return headers between the passed block numbers (inclusive) that were checked before they had the required
+// confirmations, in block order
+func (repo CheckedHeadersRepository) UnconfirmedHeaders(startingBlockNumber, endingBlockNumber int64) ([]core.Header, error) {
+	var result []core.Header
+	err := repo.db.Select(&result, `SELECT id, block_number, hash
+		FROM public.headers
+		WHERE checked_unconfirmed IS TRUE
+		AND block_number >= $1
+		AND block_number <= $2
+		AND eth_node_id = $3
+		ORDER BY block_number`, startingBlockNumber, endingBlockNumber, repo.db.NodeID)
+	return result, err
+}
+
+// Return headers between the passed block numbers (inclusive) that have been checked at least once, in block order
+func (repo CheckedHeadersRepository) CheckedHeaders(startingBlockNumber, endingBlockNumber int64) ([]core.Header, error) {
+	var result []core.Header
+	err := repo.db.Select(&result, `SELECT id, block_number, hash
+		FROM public.headers
+		WHERE check_count > 0
+		AND block_number >= $1
+		AND block_number <= $2
+		AND eth_node_id = $3
+		ORDER BY block_number`, startingBlockNumber, endingBlockNumber, repo.db.NodeID)
+	return result, err
+}
+
 // Return header if check_count  < passed checkCount
 func (repo CheckedHeadersRepository) UncheckedHeaders(startingBlockNumber, endingBlockNumber, checkCount int64) ([]core.Header, error) {
 	var result []core.Header
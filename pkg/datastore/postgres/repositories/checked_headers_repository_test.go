@@ -17,6 +17,7 @@
 package repositories_test
 
 import (
+	"github.com/lib/pq"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
@@ -78,6 +79,42 @@ var _ = Describe("Checked Headers repository", func() {
 		})
 	})
 
+	Describe("MarkHeadersChecked", func() {
+		It("increments check count for every passed header", func() {
+			headerRepository := repositories.NewHeaderRepository(db)
+			blockNumber := rand.Int63()
+			headerIDOne, headerOneErr := headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(blockNumber))
+			Expect(headerOneErr).NotTo(HaveOccurred())
+			headerIDTwo, headerTwoErr := headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(blockNumber + 1))
+			Expect(headerTwoErr).NotTo(HaveOccurred())
+
+			err := repo.MarkHeadersChecked([]int64{headerIDOne, headerIDTwo})
+
+			Expect(err).NotTo(HaveOccurred())
+			var checkedCounts []int
+			fetchErr := db.Select(&checkedCounts, `SELECT check_count FROM public.headers WHERE id = ANY($1::INTEGER[])`,
+				pq.Array([]int64{headerIDOne, headerIDTwo}))
+			Expect(fetchErr).NotTo(HaveOccurred())
+			Expect(checkedCounts).To(Equal([]int{1, 1}))
+		})
+
+		It("does not increment any check counts if one update fails", func() {
+			headerRepository := repositories.NewHeaderRepository(db)
+			headerID, headerErr := headerRepository.CreateOrUpdateHeader(fakes.FakeHeader)
+			Expect(headerErr).NotTo(HaveOccurred())
+
+			err := repo.MarkHeadersChecked([]int64{headerID, -1, 0})
+			Expect(err).NotTo(HaveOccurred())
+			err = repo.MarkHeadersChecked([]int64{headerID, int64(1) << 40})
+
+			Expect(err).To(HaveOccurred())
+			var checkedCount int
+			fetchErr := db.Get(&checkedCount, `SELECT check_count FROM public.headers WHERE id = $1`, headerID)
+			Expect(fetchErr).NotTo(HaveOccurred())
+			Expect(checkedCount).To(Equal(1))
+		})
+	})
+
 	Describe("MarkHeadersUnchecked", func() {
 		It("removes rows for headers <= starting block number", func() {
 			blockNumberOne := rand.Int63()
@@ -103,7 +140,7 @@ var _ = Describe("Checked Headers repository", func() {
 			Expect(markHeaderThreeCheckedErr).NotTo(HaveOccurred())
 
 			// mark headers unchecked since blockNumberTwo
-			err := repo.MarkHeadersUnchecked(blockNumberTwo)
+			err := repo.MarkHeadersUnchecked(blockNumberTwo, -1)
 
 			Expect(err).NotTo(HaveOccurred())
 			var headerOneCheckCount, headerTwoCheckCount, headerThreeCheckCount int
@@ -117,6 +154,107 @@ var _ = Describe("Checked Headers repository", func() {
 			Expect(getHeaderThreeErr).NotTo(HaveOccurred())
 			Expect(headerThreeCheckCount).To(BeZero())
 		})
+
+		It("leaves headers after the ending block number checked", func() {
+			blockNumberOne := rand.Int63()
+			blockNumberTwo := blockNumberOne + 1
+			headerRepository := repositories.NewHeaderRepository(db)
+			headerIdOne, insertHeaderOneErr := headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(blockNumberOne))
+			Expect(insertHeaderOneErr).NotTo(HaveOccurred())
+			headerIdTwo, insertHeaderTwoErr := headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(blockNumberTwo))
+			Expect(insertHeaderTwoErr).NotTo(HaveOccurred())
+			markCheckedErr := repo.MarkHeadersChecked([]int64{headerIdOne, headerIdTwo})
+			Expect(markCheckedErr).NotTo(HaveOccurred())
+
+			err := repo.MarkHeadersUnchecked(blockNumberOne, blockNumberOne)
+
+			Expect(err).NotTo(HaveOccurred())
+			var headerOneCheckCount, headerTwoCheckCount int
+			getHeaderOneErr := db.Get(&headerOneCheckCount, `SELECT check_count FROM public.headers WHERE id = $1`, headerIdOne)
+			Expect(getHeaderOneErr).NotTo(HaveOccurred())
+			Expect(headerOneCheckCount).To(BeZero())
+			getHeaderTwoErr := db.Get(&headerTwoCheckCount, `SELECT check_count FROM public.headers WHERE id = $1`, headerIdTwo)
+			Expect(getHeaderTwoErr).NotTo(HaveOccurred())
+			Expect(headerTwoCheckCount).To(Equal(1))
+		})
+	})
+
+	Describe("CheckedHeaders", func() {
+		It("returns checked headers in the block range in block order", func() {
+			blockNumber := rand.Int63()
+			headerRepository := repositories.NewHeaderRepository(db)
+			var headerIDs []int64
+			for i := int64(0); i < 4; i++ {
+				headerID, insertErr := headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(blockNumber + 3 - i))
+				Expect(insertErr).NotTo(HaveOccurred())
+				headerIDs = append(headerIDs, headerID)
+			}
+			// check headers at blockNumber+3, blockNumber+2 and blockNumber
+			markCheckedErr := repo.MarkHeadersChecked([]int64{headerIDs[0], headerIDs[1], headerIDs[3]})
+			Expect(markCheckedErr).NotTo(HaveOccurred())
+
+			headers, err := repo.CheckedHeaders(blockNumber, blockNumber+2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(headers)).To(Equal(2))
+			Expect(headers[0].BlockNumber).To(Equal(blockNumber))
+			Expect(headers[1].BlockNumber).To(Equal(blockNumber + 2))
+		})
+	})
+
+	Describe("UnconfirmedHeaders", func() {
+		var (
+			blockNumber int64
+			headerIDs   []int64
+		)
+
+		BeforeEach(func() {
+			blockNumber = rand.Int63()
+			headerIDs = nil
+			headerRepository := repositories.NewHeaderRepository(db)
+			for i := int64(0); i < 3; i++ {
+				headerID, insertErr := headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(blockNumber + 2 - i))
+				Expect(insertErr).NotTo(HaveOccurred())
+				headerIDs = append(headerIDs, headerID)
+			}
+		})
+
+		It("returns headers in the block range marked unconfirmed, in block order", func() {
+			markErr := repo.MarkHeadersUnconfirmed(headerIDs)
+			Expect(markErr).NotTo(HaveOccurred())
+
+			headers, err := repo.UnconfirmedHeaders(blockNumber, blockNumber+1)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(headers)).To(Equal(2))
+			Expect(headers[0].Id).To(Equal(headerIDs[2]))
+			Expect(headers[1].Id).To(Equal(headerIDs[1]))
+		})
+
+		It("excludes headers marked confirmed", func() {
+			markErr := repo.MarkHeadersUnconfirmed(headerIDs)
+			Expect(markErr).NotTo(HaveOccurred())
+			confirmErr := repo.MarkHeadersConfirmed([]int64{headerIDs[2]})
+			Expect(confirmErr).NotTo(HaveOccurred())
+
+			headers, err := repo.UnconfirmedHeaders(blockNumber, blockNumber+2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(headers)).To(Equal(2))
+			Expect(headers[0].Id).To(Equal(headerIDs[1]))
+		})
+
+		It("excludes headers marked unchecked", func() {
+			markErr := repo.MarkHeadersUnconfirmed(headerIDs)
+			Expect(markErr).NotTo(HaveOccurred())
+			uncheckErr := repo.MarkHeadersUnchecked(blockNumber, -1)
+			Expect(uncheckErr).NotTo(HaveOccurred())
+
+			headers, err := repo.UnconfirmedHeaders(blockNumber, blockNumber+2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(headers).To(BeEmpty())
+		})
 	})
 
 	Describe("UncheckedHeaders", func() {
@@ -17,6 +17,10 @@
 package repositories
 
 import (
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
 	"github.com/sirupsen/logrus"
 )
@@ -29,34 +33,58 @@ func NewCheckedLogsRepository(db *postgres.DB) CheckedLogsRepository {
 	return CheckedLogsRepository{db: db}
 }
 
-// Return whether a given address + topic0 has been fetched on a previous run of vDB
-func (repository CheckedLogsRepository) AlreadyWatchingLog(addresses []string, topic0 string) (bool, error) {
+// Return whether every address has been fetched with the given topic0 and topic filters on a previous run of vDB.
+// Logs fetched without topic filters cover any filters for the same address + topic0.
+func (repository CheckedLogsRepository) AlreadyWatchingLog(addresses []string, topic0, topicFilters string) (bool, error) {
 	for _, address := range addresses {
-		var addressExists bool
-		getAddressExistsErr := repository.db.Get(&addressExists, `SELECT EXISTS(SELECT 1 FROM public.watched_logs WHERE contract_address = $1)`, address)
-		if getAddressExistsErr != nil {
-			return false, getAddressExistsErr
+		var logWatched bool
+		getLogWatchedErr := repository.db.Get(&logWatched, `SELECT EXISTS(SELECT 1 FROM public.watched_logs
+			WHERE contract_address = $1 AND topic_zero = $2 AND topic_filters IN ('', $3))`, address, topic0, topicFilters)
+		if getLogWatchedErr != nil {
+			return false, getLogWatchedErr
 		}
-		if !addressExists {
+		if !logWatched {
 			return false, nil
 		}
 	}
-	var topicZeroExists bool
-	getTopicZeroExistsErr := repository.db.Get(&topicZeroExists, `SELECT EXISTS(SELECT 1 FROM public.watched_logs WHERE topic_zero = $1)`, topic0)
-	if getTopicZeroExistsErr != nil {
-		return false, getTopicZeroExistsErr
-	}
-	return topicZeroExists, nil
+	return true, nil
 }
 
-// Persist that a given address + topic0 has is being fetched on this run of vDB
-func (repository CheckedLogsRepository) MarkLogWatched(addresses []string, topic0 string) error {
+// Persist that the given addresses + topic0 + topic filters are being fetched on this run of vDB. Headers in the block
+// range that have already been checked are recorded as needing a backfill for any address not watched before with
+// these (or no) topic filters. An endingBlockNumber of -1 leaves the block range open-ended.
+func (repository CheckedLogsRepository) MarkLogWatched(addresses []string, topic0, topicFilters string, startingBlockNumber, endingBlockNumber int64) error {
 	tx, txErr := repository.db.Beginx()
 	if txErr != nil {
 		return txErr
 	}
+	var lastCheckedBlock, backfillNextBlock, backfillEndingBlock sql.NullInt64
+	getErr := tx.Get(&lastCheckedBlock, `SELECT MAX(block_number) FROM public.headers
+		WHERE check_count > 0 AND eth_node_id = $1`, repository.db.NodeID)
+	if getErr != nil {
+		rollbackErr := tx.Rollback()
+		if rollbackErr != nil {
+			logrus.Errorf("error rolling back transaction inserting checked logs: %s", rollbackErr.Error())
+		}
+		return getErr
+	}
+	if lastCheckedBlock.Valid {
+		lastBackfillBlock := lastCheckedBlock.Int64
+		if endingBlockNumber != -1 && endingBlockNumber < lastBackfillBlock {
+			lastBackfillBlock = endingBlockNumber
+		}
+		if lastBackfillBlock >= startingBlockNumber {
+			backfillNextBlock = sql.NullInt64{Int64: startingBlockNumber, Valid: true}
+			backfillEndingBlock = sql.NullInt64{Int64: lastBackfillBlock, Valid: true}
+		}
+	}
 	for _, address := range addresses {
-		_, insertErr := tx.Exec(`INSERT INTO public.watched_logs (contract_address, topic_zero) VALUES ($1, $2)`, address, topic0)
+		_, insertErr := tx.Exec(`INSERT INTO public.watched_logs
+				(contract_address, topic_zero, topic_filters, backfill_next_block, backfill_ending_block)
+			SELECT $1::VARCHAR, $2::VARCHAR, $3::TEXT, $4::BIGINT, $5::BIGINT
+			WHERE NOT EXISTS (SELECT 1 FROM public.watched_logs
+				WHERE contract_address = $1 AND topic_zero = $2 AND topic_filters IN ('', $3))`,
+			address, topic0, topicFilters, backfillNextBlock, backfillEndingBlock)
 		if insertErr != nil {
 			rollbackErr := tx.Rollback()
 			if rollbackErr != nil {
@@ -67,3 +95,26 @@ func (repository CheckedLogsRepository) MarkLogWatched(addresses []string, topic
 	}
 	return tx.Commit()
 }
+
+// Return the watched addresses + topics that still have headers to backfill, ordered by the next block to check
+func (repository CheckedLogsRepository) GetLogBackfills() ([]core.LogBackfill, error) {
+	var backfills []core.LogBackfill
+	err := repository.db.Select(&backfills, `SELECT contract_address, topic_zero, topic_filters, backfill_next_block,
+			backfill_ending_block
+		FROM public.watched_logs
+		WHERE backfill_next_block IS NOT NULL
+		ORDER BY backfill_next_block, topic_zero, id`)
+	return backfills, err
+}
+
+// Record that headers before nextBlockNumber have been backfilled for the given addresses + topic0 + topic filters,
+// clearing the backfill once nextBlockNumber passes its ending block
+func (repository CheckedLogsRepository) UpdateLogBackfill(addresses []string, topic0, topicFilters string, nextBlockNumber int64) error {
+	_, err := repository.db.Exec(`UPDATE public.watched_logs
+		SET backfill_next_block   = CASE WHEN $4::BIGINT > backfill_ending_block THEN NULL ELSE $4::BIGINT END,
+			backfill_ending_block = CASE WHEN $4::BIGINT > backfill_ending_block THEN NULL ELSE backfill_ending_block END
+		WHERE contract_address = ANY($1::VARCHAR[]) AND topic_zero = $2 AND topic_filters = $3
+			AND backfill_next_block IS NOT NULL`,
+		pq.Array(addresses), topic0, topicFilters, nextBlockNumber)
+	return err
+}
@@ -17,7 +17,10 @@
 package repositories_test
 
 import (
+	"math/rand"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
@@ -33,7 +36,9 @@ var _ = Describe("Checked logs repository", func() {
 		fakeAddress   = fakes.FakeAddress.Hex()
 		fakeAddresses = []string{fakeAddress}
 		fakeTopicZero = fakes.FakeHash.Hex()
-		repository    datastore.CheckedLogsRepository
+		// topic1 filter in the form encoded by the log extractor
+		fakeTopicFilters = `[["0x0000000000000000000000000000000000000000000000000000000000000001"]]`
+		repository       datastore.CheckedLogsRepository
 	)
 
 	BeforeEach(func() {
@@ -52,13 +57,13 @@ var _ = Describe("Checked logs repository", func() {
 			_, insertErr := db.Exec(`INSERT INTO public.watched_logs (contract_address, topic_zero) VALUES ($1, $2)`, fakeAddress, fakeTopicZero)
 			Expect(insertErr).NotTo(HaveOccurred())
 
-			hasBeenChecked, err := repository.AlreadyWatchingLog(fakeAddresses, fakeTopicZero)
+			hasBeenChecked, err := repository.AlreadyWatchingLog(fakeAddresses, fakeTopicZero, "")
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(hasBeenChecked).To(BeTrue())
 		})
 
-		It("returns true if addresses and topic0 were fetched because of a combination of other transformers", func() {
+		It("returns false if addresses and topic0 were only watched in combination with other transformers", func() {
 			anotherFakeAddress := common.HexToAddress("0x" + fakes.RandomString(40)).Hex()
 			anotherFakeTopicZero := common.HexToHash("0x" + fakes.RandomString(64)).Hex()
 			// insert row with matching address but different topic0
@@ -68,10 +73,10 @@ var _ = Describe("Checked logs repository", func() {
 			_, insertTwoErr := db.Exec(`INSERT INTO public.watched_logs (contract_address, topic_zero) VALUES ($1, $2)`, anotherFakeAddress, fakeTopicZero)
 			Expect(insertTwoErr).NotTo(HaveOccurred())
 
-			hasBeenChecked, err := repository.AlreadyWatchingLog(fakeAddresses, fakeTopicZero)
+			hasBeenChecked, err := repository.AlreadyWatchingLog(fakeAddresses, fakeTopicZero, "")
 
 			Expect(err).NotTo(HaveOccurred())
-			Expect(hasBeenChecked).To(BeTrue())
+			Expect(hasBeenChecked).To(BeFalse())
 		})
 
 		It("returns false if any address has not been checked", func() {
@@ -79,7 +84,28 @@ var _ = Describe("Checked logs repository", func() {
 			_, insertErr := db.Exec(`INSERT INTO public.watched_logs (contract_address, topic_zero) VALUES ($1, $2)`, fakeAddress, fakeTopicZero)
 			Expect(insertErr).NotTo(HaveOccurred())
 
-			hasBeenChecked, err := repository.AlreadyWatchingLog(append(fakeAddresses, anotherFakeAddress), fakeTopicZero)
+			hasBeenChecked, err := repository.AlreadyWatchingLog(append(fakeAddresses, anotherFakeAddress), fakeTopicZero, "")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasBeenChecked).To(BeFalse())
+		})
+
+		It("returns true if the address and topic0 were watched without topic filters", func() {
+			_, insertErr := db.Exec(`INSERT INTO public.watched_logs (contract_address, topic_zero) VALUES ($1, $2)`, fakeAddress, fakeTopicZero)
+			Expect(insertErr).NotTo(HaveOccurred())
+
+			hasBeenChecked, err := repository.AlreadyWatchingLog(fakeAddresses, fakeTopicZero, fakeTopicFilters)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasBeenChecked).To(BeTrue())
+		})
+
+		It("returns false if the address and topic0 were only watched with other topic filters", func() {
+			_, insertErr := db.Exec(`INSERT INTO public.watched_logs (contract_address, topic_zero, topic_filters)
+				VALUES ($1, $2, $3)`, fakeAddress, fakeTopicZero, fakeTopicFilters)
+			Expect(insertErr).NotTo(HaveOccurred())
+
+			hasBeenChecked, err := repository.AlreadyWatchingLog(fakeAddresses, fakeTopicZero, "")
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(hasBeenChecked).To(BeFalse())
@@ -90,7 +116,7 @@ var _ = Describe("Checked logs repository", func() {
 			_, insertErr := db.Exec(`INSERT INTO public.watched_logs (contract_address, topic_zero) VALUES ($1, $2)`, fakeAddress, anotherFakeTopicZero)
 			Expect(insertErr).NotTo(HaveOccurred())
 
-			hasBeenChecked, err := repository.AlreadyWatchingLog(fakeAddresses, fakeTopicZero)
+			hasBeenChecked, err := repository.AlreadyWatchingLog(fakeAddresses, fakeTopicZero, "")
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(hasBeenChecked).To(BeFalse())
@@ -100,7 +126,7 @@ var _ = Describe("Checked logs repository", func() {
 	Describe("MarkLogWatched", func() {
 		It("adds a row for all of transformer's addresses + topic0", func() {
 			anotherFakeAddress := common.HexToAddress("0x" + fakes.RandomString(40)).Hex()
-			err := repository.MarkLogWatched(append(fakeAddresses, anotherFakeAddress), fakeTopicZero)
+			err := repository.MarkLogWatched(append(fakeAddresses, anotherFakeAddress), fakeTopicZero, "", 0, -1)
 
 			Expect(err).NotTo(HaveOccurred())
 			var comboOneExists, comboTwoExists bool
@@ -111,5 +137,135 @@ var _ = Describe("Checked logs repository", func() {
 			Expect(getComboTwoErr).NotTo(HaveOccurred())
 			Expect(comboTwoExists).To(BeTrue())
 		})
+
+		It("does not duplicate addresses + topic0 already watched", func() {
+			firstErr := repository.MarkLogWatched(fakeAddresses, fakeTopicZero, "", 0, -1)
+			Expect(firstErr).NotTo(HaveOccurred())
+
+			err := repository.MarkLogWatched(fakeAddresses, fakeTopicZero, "", 0, -1)
+
+			Expect(err).NotTo(HaveOccurred())
+			var count int
+			getErr := db.Get(&count, `SELECT COUNT(*) FROM public.watched_logs`)
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(count).To(Equal(1))
+		})
+
+		It("adds a row for addresses + topic0 only watched with other topic filters", func() {
+			firstErr := repository.MarkLogWatched(fakeAddresses, fakeTopicZero, fakeTopicFilters, 0, -1)
+			Expect(firstErr).NotTo(HaveOccurred())
+
+			err := repository.MarkLogWatched(fakeAddresses, fakeTopicZero, "", 0, -1)
+
+			Expect(err).NotTo(HaveOccurred())
+			var topicFilters []string
+			getErr := db.Select(&topicFilters, `SELECT topic_filters FROM public.watched_logs ORDER BY id`)
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(topicFilters).To(Equal([]string{fakeTopicFilters, ""}))
+		})
+
+		Describe("when headers have been checked", func() {
+			var lastCheckedBlock int64
+
+			BeforeEach(func() {
+				lastCheckedBlock = rand.Int63n(1000) + 100
+				headerRepository := repositories.NewHeaderRepository(db)
+				checkedHeaderID, checkedErr := headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(lastCheckedBlock))
+				Expect(checkedErr).NotTo(HaveOccurred())
+				_, uncheckedErr := headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(lastCheckedBlock + 1))
+				Expect(uncheckedErr).NotTo(HaveOccurred())
+				markErr := repositories.NewCheckedHeadersRepository(db).MarkHeaderChecked(checkedHeaderID)
+				Expect(markErr).NotTo(HaveOccurred())
+			})
+
+			It("records a backfill from the starting block through the last checked header", func() {
+				err := repository.MarkLogWatched(fakeAddresses, fakeTopicZero, "", 10, -1)
+
+				Expect(err).NotTo(HaveOccurred())
+				backfills, getErr := repository.GetLogBackfills()
+				Expect(getErr).NotTo(HaveOccurred())
+				Expect(backfills).To(Equal([]core.LogBackfill{{
+					ContractAddress:   fakeAddress,
+					TopicZero:         fakeTopicZero,
+					NextBlockNumber:   10,
+					EndingBlockNumber: lastCheckedBlock,
+				}}))
+			})
+
+			It("does not record a backfill past the ending block", func() {
+				err := repository.MarkLogWatched(fakeAddresses, fakeTopicZero, "", 10, 20)
+
+				Expect(err).NotTo(HaveOccurred())
+				backfills, getErr := repository.GetLogBackfills()
+				Expect(getErr).NotTo(HaveOccurred())
+				Expect(backfills[0].EndingBlockNumber).To(Equal(int64(20)))
+			})
+
+			It("does not record a backfill if the starting block is after the last checked header", func() {
+				err := repository.MarkLogWatched(fakeAddresses, fakeTopicZero, "", lastCheckedBlock+1, -1)
+
+				Expect(err).NotTo(HaveOccurred())
+				backfills, getErr := repository.GetLogBackfills()
+				Expect(getErr).NotTo(HaveOccurred())
+				Expect(backfills).To(BeEmpty())
+			})
+		})
+
+		It("does not record a backfill if no headers have been checked", func() {
+			err := repository.MarkLogWatched(fakeAddresses, fakeTopicZero, "", 0, -1)
+
+			Expect(err).NotTo(HaveOccurred())
+			backfills, getErr := repository.GetLogBackfills()
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(backfills).To(BeEmpty())
+		})
+	})
+
+	Describe("UpdateLogBackfill", func() {
+		BeforeEach(func() {
+			_, insertErr := db.Exec(`INSERT INTO public.watched_logs
+				(contract_address, topic_zero, backfill_next_block, backfill_ending_block) VALUES ($1, $2, 10, 100)`,
+				fakeAddress, fakeTopicZero)
+			Expect(insertErr).NotTo(HaveOccurred())
+		})
+
+		It("advances the backfill to the next block", func() {
+			err := repository.UpdateLogBackfill(fakeAddresses, fakeTopicZero, "", 50)
+
+			Expect(err).NotTo(HaveOccurred())
+			backfills, getErr := repository.GetLogBackfills()
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(backfills[0].NextBlockNumber).To(Equal(int64(50)))
+			Expect(backfills[0].EndingBlockNumber).To(Equal(int64(100)))
+		})
+
+		It("clears the backfill once it passes the ending block", func() {
+			err := repository.UpdateLogBackfill(fakeAddresses, fakeTopicZero, "", 101)
+
+			Expect(err).NotTo(HaveOccurred())
+			backfills, getErr := repository.GetLogBackfills()
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(backfills).To(BeEmpty())
+		})
+
+		It("does not update backfills for other topic filters", func() {
+			err := repository.UpdateLogBackfill(fakeAddresses, fakeTopicZero, fakeTopicFilters, 101)
+
+			Expect(err).NotTo(HaveOccurred())
+			backfills, getErr := repository.GetLogBackfills()
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(len(backfills)).To(Equal(1))
+		})
+
+		It("does not update backfills for other topics", func() {
+			anotherFakeTopicZero := common.HexToHash("0x" + fakes.RandomString(64)).Hex()
+
+			err := repository.UpdateLogBackfill(fakeAddresses, anotherFakeTopicZero, "", 101)
+
+			Expect(err).NotTo(HaveOccurred())
+			backfills, getErr := repository.GetLogBackfills()
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(len(backfills)).To(Equal(1))
+		})
 	})
 })
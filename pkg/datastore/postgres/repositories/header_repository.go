@@ -21,13 +21,18 @@ import (
 	"errors"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/utils"
 	"github.com/sirupsen/logrus"
 )
 
 var ErrValidHeaderExists = errors.New("valid header already exists")
 
+const insertHeaderQuery = `INSERT INTO public.headers (block_number, hash, parent_hash, block_timestamp, raw, eth_node_id)
+		VALUES ($1, $2, NULLIF($3, ''), $4::NUMERIC, $5, $6) ON CONFLICT DO NOTHING RETURNING id`
+
 type HeaderRepository struct {
 	database *postgres.DB
 }
@@ -51,6 +56,34 @@ func (repository HeaderRepository) CreateOrUpdateHeader(header core.Header) (int
 	return 0, ErrValidHeaderExists
 }
 
+// Inserts headers in a single statement, skipping any that are already stored. Intended for backfilling block numbers
+// with no stored header; use CreateOrUpdateHeader to replace headers that may have changed.
+func (repository HeaderRepository) CreateHeaders(headers []core.Header) error {
+	if len(headers) == 0 {
+		return nil
+	}
+	var blockNumbers []int64
+	var hashes, parentHashes, timestamps, raws []string
+	for _, header := range headers {
+		blockNumbers = append(blockNumbers, header.BlockNumber)
+		hashes = append(hashes, header.Hash)
+		parentHashes = append(parentHashes, header.ParentHash)
+		timestamps = append(timestamps, header.Timestamp)
+		raws = append(raws, string(header.Raw))
+	}
+	_, err := repository.database.Exec(`INSERT INTO public.headers (block_number, hash, parent_hash, block_timestamp, raw, eth_node_id)
+		SELECT block_number, hash, NULLIF(parent_hash, ''), NULLIF(block_timestamp, '')::NUMERIC, NULLIF(raw, '')::JSONB, $6
+		FROM UNNEST($1::BIGINT[], $2::VARCHAR[], $3::VARCHAR[], $4::TEXT[], $5::TEXT[])
+			AS new_headers (block_number, hash, parent_hash, block_timestamp, raw)
+		ON CONFLICT DO NOTHING`,
+		pq.Array(blockNumbers), pq.Array(hashes), pq.Array(parentHashes), pq.Array(timestamps), pq.Array(raws),
+		repository.database.NodeID)
+	if err != nil {
+		logrus.Error("CreateHeaders: error inserting headers: ", err)
+	}
+	return err
+}
+
 func (repository HeaderRepository) CreateTransactions(headerID int64, transactions []core.TransactionModel) error {
 	for _, transaction := range transactions {
 		_, err := repository.database.Exec(`INSERT INTO public.header_sync_transactions
@@ -66,6 +99,30 @@ func (repository HeaderRepository) CreateTransactions(headerID int64, transactio
 	return nil
 }
 
+// Persist receipts for transactions already stored by CreateTransactions, in a single transaction
+func (repository HeaderRepository) CreateReceipts(headerID int64, receipts []core.Receipt) error {
+	tx, beginErr := repository.database.Beginx()
+	if beginErr != nil {
+		return postgres.ErrBeginTransactionFailed(beginErr)
+	}
+	receiptRepository := HeaderSyncReceiptRepository{}
+	for _, receipt := range receipts {
+		var transactionID int64
+		getTransactionErr := tx.Get(&transactionID,
+			`SELECT id FROM public.header_sync_transactions WHERE hash = $1`, receipt.TxHash)
+		if getTransactionErr != nil {
+			utils.RollbackAndLogFailure(tx, getTransactionErr, "header sync receipts")
+			return getTransactionErr
+		}
+		_, createReceiptErr := receiptRepository.CreateHeaderSyncReceiptInTx(headerID, transactionID, receipt, tx)
+		if createReceiptErr != nil {
+			utils.RollbackAndLogFailure(tx, createReceiptErr, "header sync receipts")
+			return createReceiptErr
+		}
+	}
+	return tx.Commit()
+}
+
 func (repository HeaderRepository) CreateTransactionInTx(tx *sqlx.Tx, headerID int64, transaction core.TransactionModel) (int64, error) {
 	var txId int64
 	err := tx.QueryRowx(`INSERT INTO public.header_sync_transactions
@@ -86,11 +143,20 @@ func (repository HeaderRepository) CreateTransactionInTx(tx *sqlx.Tx, headerID i
 
 func (repository HeaderRepository) GetHeader(blockNumber int64) (core.Header, error) {
 	var header core.Header
-	err := repository.database.Get(&header, `SELECT id, block_number, hash, raw, block_timestamp FROM headers WHERE block_number = $1 AND eth_node_id = $2`,
+	err := repository.database.Get(&header, `SELECT id, block_number, hash, COALESCE(parent_hash, '') AS parent_hash, raw, block_timestamp
+		FROM headers WHERE block_number = $1 AND eth_node_id = $2`,
 		blockNumber, repository.database.NodeID)
 	return header, err
 }
 
+// Returns 0 if no headers have been synced
+func (repository HeaderRepository) GetMostRecentHeaderBlockNumber() (int64, error) {
+	var blockNumber int64
+	err := repository.database.Get(&blockNumber, `SELECT COALESCE(MAX(block_number), 0) FROM public.headers
+		WHERE eth_node_id = $1`, repository.database.NodeID)
+	return blockNumber, err
+}
+
 func (repository HeaderRepository) MissingBlockNumbers(startingBlockNumber, endingBlockNumber int64) ([]int64, error) {
 	numbers := make([]int64, 0)
 	err := repository.database.Select(&numbers,
@@ -109,6 +175,27 @@ func (repository HeaderRepository) MissingBlockNumbers(startingBlockNumber, endi
 	return numbers, nil
 }
 
+// Returns the block numbers of headers whose parent hash does not match the hash of the header stored one block below
+func (repository HeaderRepository) BrokenLinkBlockNumbers(startingBlockNumber, endingBlockNumber int64) ([]int64, error) {
+	numbers := make([]int64, 0)
+	err := repository.database.Select(&numbers,
+		`SELECT DISTINCT child.block_number
+			FROM public.headers AS child
+			JOIN public.headers AS parent
+				ON parent.block_number = child.block_number - 1 AND parent.eth_node_id = child.eth_node_id
+			WHERE child.block_number BETWEEN $1 AND $2
+				AND child.eth_node_id = $3
+				AND child.parent_hash <> parent.hash
+			ORDER BY child.block_number`,
+		startingBlockNumber, endingBlockNumber, repository.database.NodeID)
+	if err != nil {
+		logrus.Errorf("BrokenLinkBlockNumbers failed to get blocks between %v - %v for node %v",
+			startingBlockNumber, endingBlockNumber, repository.database.NodeID)
+		return []int64{}, err
+	}
+	return numbers, nil
+}
+
 func headerMustBeReplaced(hash string, header core.Header) bool {
 	return hash != header.Hash
 }
@@ -129,10 +216,8 @@ func (repository HeaderRepository) getHeaderHash(header core.Header) (string, er
 // Otherwise should not occur since only called in CreateOrUpdateHeader
 func (repository HeaderRepository) InternalInsertHeader(header core.Header) (int64, error) {
 	var headerId int64
-	row := repository.database.QueryRowx(
-		`INSERT INTO public.headers (block_number, hash, block_timestamp, raw, eth_node_id)
-		VALUES ($1, $2, $3::NUMERIC, $4, $5) ON CONFLICT DO NOTHING RETURNING id`,
-		header.BlockNumber, header.Hash, header.Timestamp, header.Raw, repository.database.NodeID)
+	row := repository.database.QueryRowx(insertHeaderQuery,
+		header.BlockNumber, header.Hash, header.ParentHash, header.Timestamp, header.Raw, repository.database.NodeID)
 	err := row.Scan(&headerId)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -71,6 +71,18 @@ var _ = Describe("Block header repository", func() {
 			Expect(dbHeader.Timestamp).To(Equal(header.Timestamp))
 		})
 
+		It("adds parent hash to header", func() {
+			header.ParentHash = common.BytesToHash([]byte{5, 4, 3, 2, 1}).Hex()
+
+			_, err = repo.CreateOrUpdateHeader(header)
+
+			Expect(err).NotTo(HaveOccurred())
+			var parentHash string
+			err = db.Get(&parentHash, `SELECT parent_hash FROM public.headers WHERE block_number = $1`, header.BlockNumber)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parentHash).To(Equal(header.ParentHash))
+		})
+
 		It("adds node data to header", func() {
 			_, err = repo.CreateOrUpdateHeader(header)
 			Expect(err).NotTo(HaveOccurred())
@@ -186,6 +198,53 @@ var _ = Describe("Block header repository", func() {
 		})
 	})
 
+	Describe("creating headers in bulk", func() {
+		It("adds every header", func() {
+			headerTwo := header
+			headerTwo.BlockNumber = header.BlockNumber + 1
+			headerTwo.Hash = common.BytesToHash([]byte{5, 4, 3, 2, 1}).Hex()
+			headerTwo.ParentHash = header.Hash
+
+			err = repo.CreateHeaders([]core.Header{header, headerTwo})
+
+			Expect(err).NotTo(HaveOccurred())
+			var dbHeaders []core.Header
+			err = db.Select(&dbHeaders, `SELECT block_number, hash, COALESCE(parent_hash, '') AS parent_hash, raw,
+				block_timestamp FROM public.headers ORDER BY block_number`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(dbHeaders)).To(Equal(2))
+			Expect(dbHeaders[0].BlockNumber).To(Equal(header.BlockNumber))
+			Expect(dbHeaders[0].Hash).To(Equal(header.Hash))
+			Expect(dbHeaders[0].Raw).To(MatchJSON(header.Raw))
+			Expect(dbHeaders[0].Timestamp).To(Equal(header.Timestamp))
+			Expect(dbHeaders[1].BlockNumber).To(Equal(headerTwo.BlockNumber))
+			Expect(dbHeaders[1].ParentHash).To(Equal(header.Hash))
+		})
+
+		It("adds node data to headers", func() {
+			err = repo.CreateHeaders([]core.Header{header})
+
+			Expect(err).NotTo(HaveOccurred())
+			var ethNodeID int64
+			err = db.Get(&ethNodeID, `SELECT eth_node_id FROM public.headers WHERE block_number = $1`, header.BlockNumber)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ethNodeID).To(Equal(db.NodeID))
+		})
+
+		It("skips headers that already exist", func() {
+			_, err = repo.CreateOrUpdateHeader(header)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.CreateHeaders([]core.Header{header})
+
+			Expect(err).NotTo(HaveOccurred())
+			var count int
+			err = db.Get(&count, `SELECT COUNT(*) FROM public.headers WHERE block_number = $1`, header.BlockNumber)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(1))
+		})
+	})
+
 	Describe("creating a receipt", func() {
 		It("adds a receipt in a tx", func() {
 			headerID, err := repo.CreateOrUpdateHeader(header)
@@ -326,6 +385,60 @@ var _ = Describe("Block header repository", func() {
 		})
 	})
 
+	Describe("creating receipts", func() {
+		var (
+			headerID    int64
+			transaction core.TransactionModel
+		)
+
+		BeforeEach(func() {
+			var err error
+			headerID, err = repo.CreateOrUpdateHeader(header)
+			Expect(err).NotTo(HaveOccurred())
+			transaction = core.TransactionModel{
+				Data:  []byte{},
+				From:  common.HexToAddress("0x1234").Hex(),
+				Hash:  common.HexToHash("0x9876").Hex(),
+				Raw:   []byte{},
+				To:    common.HexToAddress("0x5678").Hex(),
+				Value: "0",
+			}
+			insertErr := repo.CreateTransactions(headerID, []core.TransactionModel{transaction})
+			Expect(insertErr).NotTo(HaveOccurred())
+		})
+
+		It("adds receipts associated with their transactions", func() {
+			receipt := core.Receipt{
+				ContractAddress:   common.HexToAddress("0xabcd").Hex(),
+				CumulativeGasUsed: 100,
+				GasUsed:           50,
+				StateRoot:         "",
+				Status:            1,
+				TxHash:            transaction.Hash,
+				Rlp:               []byte{1, 2, 3},
+			}
+
+			createErr := repo.CreateReceipts(headerID, []core.Receipt{receipt})
+			Expect(createErr).NotTo(HaveOccurred())
+
+			var dbReceipt core.Receipt
+			err = db.Get(&dbReceipt, `SELECT cumulative_gas_used, gas_used, state_root, status, tx_hash, rlp
+				FROM public.header_sync_receipts
+				JOIN public.header_sync_transactions ON header_sync_transactions.id = header_sync_receipts.transaction_id
+				WHERE header_sync_receipts.header_id = $1 AND header_sync_transactions.hash = $2`, headerID, transaction.Hash)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dbReceipt.GasUsed).To(Equal(receipt.GasUsed))
+			Expect(dbReceipt.Status).To(Equal(receipt.Status))
+			Expect(dbReceipt.Rlp).To(Equal(receipt.Rlp))
+		})
+
+		It("returns an error if the receipt's transaction hasn't been persisted", func() {
+			createErr := repo.CreateReceipts(headerID, []core.Receipt{{TxHash: common.HexToHash("0x5432").Hex()}})
+
+			Expect(createErr).To(MatchError(sql.ErrNoRows))
+		})
+	})
+
 	Describe("creating a transaction in a sqlx tx", func() {
 		It("adds a transaction", func() {
 			headerID, err := repo.CreateOrUpdateHeader(header)
@@ -427,6 +540,37 @@ var _ = Describe("Block header repository", func() {
 		})
 	})
 
+	Describe("Getting the most recent header block number", func() {
+		It("returns the highest block number of the node's headers", func() {
+			_, err = repo.CreateOrUpdateHeader(header)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = repo.CreateOrUpdateHeader(core.Header{
+				BlockNumber: header.BlockNumber - 1,
+				Raw:         rawHeader,
+				Timestamp:   timestamp,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			blockNumber, err := repo.GetMostRecentHeaderBlockNumber()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(blockNumber).To(Equal(header.BlockNumber))
+		})
+
+		It("returns zero if the node has no headers", func() {
+			_, err = repo.CreateOrUpdateHeader(header)
+			Expect(err).NotTo(HaveOccurred())
+
+			dbTwo := test_config.NewTestDB(test_config.NewTestNode())
+			repoTwo := repositories.NewHeaderRepository(dbTwo)
+
+			blockNumber, err := repoTwo.GetMostRecentHeaderBlockNumber()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(blockNumber).To(BeZero())
+		})
+	})
+
 	Describe("Getting missing headers", func() {
 		It("returns block numbers for headers not in the database", func() {
 			_, err = repo.CreateOrUpdateHeader(core.Header{
@@ -487,4 +631,66 @@ var _ = Describe("Block header repository", func() {
 			Expect(missingBlockNumbers).To(ConsistOf([]int64{1, 2, 3, 4, 5}))
 		})
 	})
+	Describe("Getting broken header links", func() {
+		var parentHash, orphanedParentHash string
+
+		BeforeEach(func() {
+			parentHash = common.BytesToHash([]byte{1}).Hex()
+			orphanedParentHash = common.BytesToHash([]byte{2}).Hex()
+			_, err = repo.CreateOrUpdateHeader(core.Header{
+				BlockNumber: 1,
+				Hash:        parentHash,
+				Raw:         rawHeader,
+				Timestamp:   timestamp,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns block numbers for headers whose parent hash does not match the stored parent", func() {
+			_, err = repo.CreateOrUpdateHeader(core.Header{
+				BlockNumber: 2,
+				Hash:        common.BytesToHash([]byte{3}).Hex(),
+				ParentHash:  orphanedParentHash,
+				Raw:         rawHeader,
+				Timestamp:   timestamp,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			brokenLinks, err := repo.BrokenLinkBlockNumbers(1, 2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(brokenLinks).To(ConsistOf(int64(2)))
+		})
+
+		It("does not return headers linked to the stored parent", func() {
+			_, err = repo.CreateOrUpdateHeader(core.Header{
+				BlockNumber: 2,
+				Hash:        common.BytesToHash([]byte{3}).Hex(),
+				ParentHash:  parentHash,
+				Raw:         rawHeader,
+				Timestamp:   timestamp,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			brokenLinks, err := repo.BrokenLinkBlockNumbers(1, 2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(brokenLinks).To(BeEmpty())
+		})
+
+		It("does not return headers without a stored parent hash", func() {
+			_, err = repo.CreateOrUpdateHeader(core.Header{
+				BlockNumber: 2,
+				Hash:        common.BytesToHash([]byte{3}).Hex(),
+				Raw:         rawHeader,
+				Timestamp:   timestamp,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			brokenLinks, err := repo.BrokenLinkBlockNumbers(1, 2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(brokenLinks).To(BeEmpty())
+		})
+	})
 })
@@ -25,12 +25,17 @@ import (
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
 	"github.com/sirupsen/logrus"
+	"strings"
 )
 
 const insertHeaderSyncLogQuery = `INSERT INTO header_sync_logs
 		(header_id, address, topics, data, block_number, block_hash, tx_index, tx_hash, log_index, raw)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT DO NOTHING`
 
+const insertHeaderSyncLogTransformationsQuery = `INSERT INTO public.header_sync_log_transformations
+		(log_id, transformer_name)
+		SELECT UNNEST($1::INTEGER[]), $2 ON CONFLICT DO NOTHING`
+
 type HeaderSyncLogRepository struct {
 	db *postgres.DB
 }
@@ -42,25 +47,38 @@ func NewHeaderSyncLogRepository(db *postgres.DB) HeaderSyncLogRepository {
 }
 
 type headerSyncLog struct {
-	ID          int64
-	HeaderID    int64 `db:"header_id"`
-	Address     int64
-	Topics      pq.ByteaArray
-	Data        []byte
-	BlockNumber uint64 `db:"block_number"`
-	BlockHash   string `db:"block_hash"`
-	TxHash      string `db:"tx_hash"`
-	TxIndex     uint   `db:"tx_index"`
-	LogIndex    uint   `db:"log_index"`
-	Transformed bool
-	Raw         []byte
+	ID            int64
+	HeaderID      int64 `db:"header_id"`
+	Address       string
+	Topics        pq.ByteaArray
+	Data          []byte
+	BlockNumber   uint64 `db:"block_number"`
+	BlockHash     string `db:"block_hash"`
+	TxHash        string `db:"tx_hash"`
+	TxIndex       uint   `db:"tx_index"`
+	LogIndex      uint   `db:"log_index"`
+	Transformed   bool
+	TransformedBy pq.StringArray `db:"transformed_by"`
 }
 
-func (repo HeaderSyncLogRepository) GetUntransformedHeaderSyncLogs() ([]core.HeaderSyncLog, error) {
-	rows, queryErr := repo.db.Queryx(`SELECT * FROM public.header_sync_logs WHERE transformed = false`)
+// Returns up to limit untransformed logs ordered by block number and id, starting after the passed block number and
+// log id. Callers page through all untransformed logs by passing the block number and id of the last log returned.
+// A limit of 0 returns every remaining log.
+func (repo HeaderSyncLogRepository) GetUntransformedHeaderSyncLogs(afterBlockNumber, afterID int64, limit int) ([]core.HeaderSyncLog, error) {
+	rows, queryErr := repo.db.Queryx(`SELECT logs.id, logs.header_id, addresses.address, logs.topics, logs.data,
+			logs.block_number, logs.block_hash, logs.tx_hash, logs.tx_index, logs.log_index, logs.transformed,
+			ARRAY(SELECT transformer_name FROM public.header_sync_log_transformations
+				WHERE log_id = logs.id ORDER BY transformer_name) AS transformed_by
+		FROM public.header_sync_logs AS logs
+			JOIN public.addresses ON addresses.id = logs.address
+		WHERE logs.transformed IS FALSE
+			AND (logs.block_number, logs.id) > ($1, $2)
+		ORDER BY logs.block_number, logs.id
+		LIMIT NULLIF($3, 0)`, afterBlockNumber, afterID, limit)
 	if queryErr != nil {
 		return nil, queryErr
 	}
+	defer rows.Close()
 
 	var results []core.HeaderSyncLog
 	for rows.Next() {
@@ -73,12 +91,8 @@ func (repo HeaderSyncLogRepository) GetUntransformedHeaderSyncLogs() ([]core.Hea
 		for _, topic := range rawLog.Topics {
 			logTopics = append(logTopics, common.BytesToHash(topic))
 		}
-		address, addrErr := repository.GetAddressById(repo.db, rawLog.Address)
-		if addrErr != nil {
-			return nil, addrErr
-		}
 		reconstructedLog := types.Log{
-			Address:     common.HexToAddress(address),
+			Address:     common.HexToAddress(rawLog.Address),
 			Topics:      logTopics,
 			Data:        rawLog.Data,
 			BlockNumber: rawLog.BlockNumber,
@@ -96,11 +110,99 @@ func (repo HeaderSyncLogRepository) GetUntransformedHeaderSyncLogs() ([]core.Hea
 			Log:         reconstructedLog,
 			Transformed: rawLog.Transformed,
 		}
-		// TODO: Consider returning each result async to avoid keeping large result sets in memory
+		if len(rawLog.TransformedBy) > 0 {
+			result.TransformedBy = rawLog.TransformedBy
+		}
 		results = append(results, result)
 	}
 
-	return results, nil
+	return results, rows.Err()
+}
+
+// Records that the named transformer has processed the logs with the passed ids.
+func (repo HeaderSyncLogRepository) CreateHeaderSyncLogTransformations(transformerName string, logIDs []int64) error {
+	if len(logIDs) < 1 {
+		return nil
+	}
+	_, err := repo.db.Exec(insertHeaderSyncLogTransformationsQuery, pq.Array(logIDs), transformerName)
+	return err
+}
+
+// Marks the logs with the passed ids as processed by every transformer they are relevant to, so they are no longer
+// returned as untransformed.
+func (repo HeaderSyncLogRepository) MarkHeaderSyncLogsTransformed(logIDs []int64) error {
+	if len(logIDs) < 1 {
+		return nil
+	}
+	_, err := repo.db.Exec(`UPDATE public.header_sync_logs SET transformed = true WHERE id = ANY($1::INTEGER[])`,
+		pq.Array(logIDs))
+	return err
+}
+
+// Returns the names of the passed transformers that have not been registered with RegisterHeaderSyncLogTransformers.
+// If no transformers have been registered at all (e.g. on the first run after transformations started being tracked),
+// logs already marked transformed are assumed to have been processed by the passed transformers, so none are new.
+func (repo HeaderSyncLogRepository) GetNewHeaderSyncLogTransformers(transformerNames []string) ([]string, error) {
+	var newNames []string
+	err := repo.db.Select(&newNames, `SELECT name FROM UNNEST($1::TEXT[]) AS name
+		WHERE EXISTS (SELECT 1 FROM public.header_sync_log_transformers)
+			AND NOT EXISTS (SELECT 1 FROM public.header_sync_log_transformers WHERE transformer_name = name)`,
+		pq.Array(transformerNames))
+	return newNames, err
+}
+
+// Records that logs have been delegated to the named transformers, so they are no longer new on later runs.
+func (repo HeaderSyncLogRepository) RegisterHeaderSyncLogTransformers(transformerNames []string) error {
+	_, err := repo.db.Exec(`INSERT INTO public.header_sync_log_transformers (transformer_name)
+		SELECT UNNEST($1::TEXT[]) ON CONFLICT DO NOTHING`, pq.Array(transformerNames))
+	return err
+}
+
+// Marks the logs with the passed ids as untransformed, so they are returned as untransformed again.
+func (repo HeaderSyncLogRepository) MarkHeaderSyncLogsUntransformed(logIDs []int64) error {
+	if len(logIDs) < 1 {
+		return nil
+	}
+	_, err := repo.db.Exec(`UPDATE public.header_sync_logs SET transformed = false WHERE id = ANY($1::INTEGER[])`,
+		pq.Array(logIDs))
+	return err
+}
+
+// Marks logs emitted with topic0 by any of the passed contract addresses as untransformed if the named transformer
+// has not processed them yet, so that they are delegated again to a newly added transformer. topicFilters holds the
+// transformer's topic1 to topic3 filters by position; logs must match each non-empty filter. Returns the number of
+// logs marked.
+func (repo HeaderSyncLogRepository) MarkHeaderSyncLogsUntransformedBy(transformerName string, addresses []string, topic0 common.Hash, topicFilters [][]common.Hash) (int64, error) {
+	var lowerCaseAddresses []string
+	for _, address := range addresses {
+		lowerCaseAddresses = append(lowerCaseAddresses, strings.ToLower(address))
+	}
+	filters := make([]interface{}, 3)
+	for position := range filters {
+		filter := make([][]byte, 0)
+		if position < len(topicFilters) {
+			for _, topic := range topicFilters[position] {
+				filter = append(filter, topic.Bytes())
+			}
+		}
+		filters[position] = pq.Array(filter)
+	}
+	result, err := repo.db.Exec(`UPDATE public.header_sync_logs AS logs SET transformed = false
+		FROM public.addresses
+		WHERE addresses.id = logs.address
+			AND logs.transformed IS TRUE
+			AND LOWER(addresses.address) = ANY($1::TEXT[])
+			AND logs.topics[1] = $2
+			AND (CARDINALITY($4::BYTEA[]) = 0 OR logs.topics[2] = ANY($4::BYTEA[]))
+			AND (CARDINALITY($5::BYTEA[]) = 0 OR logs.topics[3] = ANY($5::BYTEA[]))
+			AND (CARDINALITY($6::BYTEA[]) = 0 OR logs.topics[4] = ANY($6::BYTEA[]))
+			AND NOT EXISTS (SELECT 1 FROM public.header_sync_log_transformations
+				WHERE log_id = logs.id AND transformer_name = $3)`,
+		pq.Array(lowerCaseAddresses), topic0.Bytes(), transformerName, filters[0], filters[1], filters[2])
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 func (repo HeaderSyncLogRepository) CreateHeaderSyncLogs(headerID int64, logs []types.Log) error {
@@ -29,6 +29,7 @@ import (
 	"github.com/makerdao/vulcanizedb/test_config"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"strings"
 )
 
 var _ = Describe("Header sync log repository", func() {
@@ -166,7 +167,7 @@ var _ = Describe("Header sync log repository", func() {
 	Describe("GetUntransformedHeaderSyncLogs", func() {
 		Describe("when there are no logs", func() {
 			It("returns empty collection", func() {
-				result, err := repo.GetUntransformedHeaderSyncLogs()
+				result, err := repo.GetUntransformedHeaderSyncLogs(0, 0, 0)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(result)).To(BeZero())
 			})
@@ -187,7 +188,7 @@ var _ = Describe("Header sync log repository", func() {
 			})
 
 			It("returns persisted logs", func() {
-				result, err := repo.GetUntransformedHeaderSyncLogs()
+				result, err := repo.GetUntransformedHeaderSyncLogs(0, 0, 0)
 
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(result)).To(Equal(2))
@@ -200,22 +201,248 @@ var _ = Describe("Header sync log repository", func() {
 				_, insertErr := db.Exec(`UPDATE public.header_sync_logs SET transformed = true WHERE tx_hash = $1`, log1.TxHash.Hex())
 				Expect(insertErr).NotTo(HaveOccurred())
 
-				result, err := repo.GetUntransformedHeaderSyncLogs()
+				result, err := repo.GetUntransformedHeaderSyncLogs(0, 0, 0)
 
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(result)).To(Equal(1))
 				Expect(result[0].Log).To(Equal(log2))
 			})
 
+			It("returns at most limit logs", func() {
+				result, err := repo.GetUntransformedHeaderSyncLogs(0, 0, 1)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(result)).To(Equal(1))
+			})
+
+			It("returns logs after the passed block number and log id", func() {
+				firstPage, firstErr := repo.GetUntransformedHeaderSyncLogs(0, 0, 1)
+				Expect(firstErr).NotTo(HaveOccurred())
+				lastLog := firstPage[0]
+
+				secondPage, secondErr := repo.GetUntransformedHeaderSyncLogs(int64(lastLog.Log.BlockNumber), lastLog.ID, 1)
+
+				Expect(secondErr).NotTo(HaveOccurred())
+				Expect(len(secondPage)).To(Equal(1))
+				Expect(secondPage[0].ID).To(BeNumerically(">", lastLog.ID))
+			})
+
+			It("returns logs in block order", func() {
+				earlierLog := test_data.GenericTestLog()
+				earlierLog.BlockNumber = log1.BlockNumber - 1
+				test_data.CreateMatchingTx(earlierLog, headerID, headerRepository)
+				logsErr := repo.CreateHeaderSyncLogs(headerID, []types.Log{earlierLog})
+				Expect(logsErr).NotTo(HaveOccurred())
+
+				result, err := repo.GetUntransformedHeaderSyncLogs(0, 0, 0)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(result)).To(Equal(3))
+				Expect(result[0].Log).To(Equal(earlierLog))
+			})
+
+			It("returns the names of transformers that processed each log", func() {
+				firstPage, firstErr := repo.GetUntransformedHeaderSyncLogs(0, 0, 1)
+				Expect(firstErr).NotTo(HaveOccurred())
+				createErr := repo.CreateHeaderSyncLogTransformations("transformer", []int64{firstPage[0].ID})
+				Expect(createErr).NotTo(HaveOccurred())
+
+				result, err := repo.GetUntransformedHeaderSyncLogs(0, 0, 0)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result[0].TransformedBy).To(Equal([]string{"transformer"}))
+				Expect(result[1].TransformedBy).To(BeNil())
+			})
+
 			It("returns empty collection if all logs transformed", func() {
 				_, insertErr := db.Exec(`UPDATE public.header_sync_logs SET transformed = true WHERE header_id = $1`, headerID)
 				Expect(insertErr).NotTo(HaveOccurred())
 
-				result, err := repo.GetUntransformedHeaderSyncLogs()
+				result, err := repo.GetUntransformedHeaderSyncLogs(0, 0, 0)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(result)).To(BeZero())
+			})
+		})
+	})
+
+	Describe("header sync log transformations", func() {
+		var (
+			log      types.Log
+			logID    int64
+			topic0   common.Hash
+			address  string
+			allNames = []string{"transformer", "other transformer"}
+		)
+
+		BeforeEach(func() {
+			log = test_data.GenericTestLog()
+			test_data.CreateMatchingTx(log, headerID, headerRepository)
+			logsErr := repo.CreateHeaderSyncLogs(headerID, []types.Log{log})
+			Expect(logsErr).NotTo(HaveOccurred())
+			getErr := db.Get(&logID, `SELECT id FROM public.header_sync_logs`)
+			Expect(getErr).NotTo(HaveOccurred())
+			topic0 = log.Topics[0]
+			address = log.Address.Hex()
+		})
+
+		Describe("CreateHeaderSyncLogTransformations", func() {
+			It("records that the transformer processed the logs", func() {
+				err := repo.CreateHeaderSyncLogTransformations(allNames[0], []int64{logID})
 
 				Expect(err).NotTo(HaveOccurred())
+				var names []string
+				getErr := db.Select(&names, `SELECT transformer_name FROM public.header_sync_log_transformations
+					WHERE log_id = $1`, logID)
+				Expect(getErr).NotTo(HaveOccurred())
+				Expect(names).To(ConsistOf(allNames[0]))
+			})
+
+			It("does not duplicate existing records", func() {
+				firstErr := repo.CreateHeaderSyncLogTransformations(allNames[0], []int64{logID})
+				Expect(firstErr).NotTo(HaveOccurred())
+
+				err := repo.CreateHeaderSyncLogTransformations(allNames[0], []int64{logID})
+
+				Expect(err).NotTo(HaveOccurred())
+				var count int
+				getErr := db.Get(&count, `SELECT COUNT(*) FROM public.header_sync_log_transformations`)
+				Expect(getErr).NotTo(HaveOccurred())
+				Expect(count).To(Equal(1))
+			})
+		})
+
+		Describe("MarkHeaderSyncLogsTransformed", func() {
+			It("excludes the logs from untransformed logs", func() {
+				err := repo.MarkHeaderSyncLogsTransformed([]int64{logID})
+
+				Expect(err).NotTo(HaveOccurred())
+				result, getErr := repo.GetUntransformedHeaderSyncLogs(0, 0, 0)
+				Expect(getErr).NotTo(HaveOccurred())
 				Expect(len(result)).To(BeZero())
 			})
 		})
+
+		Describe("MarkHeaderSyncLogsUntransformed", func() {
+			It("includes the logs in untransformed logs again", func() {
+				markErr := repo.MarkHeaderSyncLogsTransformed([]int64{logID})
+				Expect(markErr).NotTo(HaveOccurred())
+
+				err := repo.MarkHeaderSyncLogsUntransformed([]int64{logID})
+
+				Expect(err).NotTo(HaveOccurred())
+				result, getErr := repo.GetUntransformedHeaderSyncLogs(0, 0, 0)
+				Expect(getErr).NotTo(HaveOccurred())
+				Expect(len(result)).To(Equal(1))
+			})
+		})
+
+		Describe("GetNewHeaderSyncLogTransformers", func() {
+			It("returns transformers that have not been registered", func() {
+				registerErr := repo.RegisterHeaderSyncLogTransformers(allNames[:1])
+				Expect(registerErr).NotTo(HaveOccurred())
+
+				newNames, err := repo.GetNewHeaderSyncLogTransformers(allNames)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(newNames).To(Equal(allNames[1:]))
+			})
+
+			It("returns no transformers if none have been registered yet", func() {
+				newNames, err := repo.GetNewHeaderSyncLogTransformers(allNames)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(newNames).To(BeEmpty())
+			})
+		})
+
+		Describe("RegisterHeaderSyncLogTransformers", func() {
+			It("does not duplicate registered transformers", func() {
+				firstErr := repo.RegisterHeaderSyncLogTransformers(allNames)
+				Expect(firstErr).NotTo(HaveOccurred())
+
+				err := repo.RegisterHeaderSyncLogTransformers(allNames)
+
+				Expect(err).NotTo(HaveOccurred())
+				var count int
+				getErr := db.Get(&count, `SELECT COUNT(*) FROM public.header_sync_log_transformers`)
+				Expect(getErr).NotTo(HaveOccurred())
+				Expect(count).To(Equal(len(allNames)))
+			})
+		})
+
+		Describe("MarkHeaderSyncLogsUntransformedBy", func() {
+			BeforeEach(func() {
+				createErr := repo.CreateHeaderSyncLogTransformations(allNames[0], []int64{logID})
+				Expect(createErr).NotTo(HaveOccurred())
+				markErr := repo.MarkHeaderSyncLogsTransformed([]int64{logID})
+				Expect(markErr).NotTo(HaveOccurred())
+			})
+
+			It("marks matching logs untransformed if the transformer has not processed them", func() {
+				count, err := repo.MarkHeaderSyncLogsUntransformedBy(allNames[1], []string{address}, topic0, nil)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(count).To(Equal(int64(1)))
+				result, getErr := repo.GetUntransformedHeaderSyncLogs(0, 0, 0)
+				Expect(getErr).NotTo(HaveOccurred())
+				Expect(len(result)).To(Equal(1))
+				Expect(result[0].TransformedBy).To(Equal([]string{allNames[0]}))
+			})
+
+			It("ignores address case", func() {
+				count, err := repo.MarkHeaderSyncLogsUntransformedBy(allNames[1], []string{strings.ToUpper(address)},
+					topic0, nil)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(count).To(Equal(int64(1)))
+			})
+
+			It("does not mark logs the transformer already processed", func() {
+				count, err := repo.MarkHeaderSyncLogsUntransformedBy(allNames[0], []string{address}, topic0, nil)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(count).To(BeZero())
+			})
+
+			It("does not mark logs from other contracts", func() {
+				count, err := repo.MarkHeaderSyncLogsUntransformedBy(allNames[1], []string{fakes.FakeAddress.Hex()},
+					topic0, nil)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(count).To(BeZero())
+			})
+
+			It("marks logs matching the transformer's topic filters", func() {
+				count, err := repo.MarkHeaderSyncLogsUntransformedBy(allNames[1], []string{address}, topic0,
+					[][]common.Hash{{fakes.FakeHash, log.Topics[1]}, {}, {}})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(count).To(Equal(int64(1)))
+			})
+
+			It("does not mark logs excluded by the transformer's topic filters", func() {
+				count, err := repo.MarkHeaderSyncLogsUntransformedBy(allNames[1], []string{address}, topic0,
+					[][]common.Hash{{fakes.FakeHash}, {}, {}})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(count).To(BeZero())
+			})
+
+			It("does not mark logs without a topic the transformer filters on", func() {
+				count, err := repo.MarkHeaderSyncLogsUntransformedBy(allNames[1], []string{address}, topic0,
+					[][]common.Hash{{}, {fakes.FakeHash}, {}})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(count).To(BeZero())
+			})
+
+			It("does not mark logs with other topics", func() {
+				count, err := repo.MarkHeaderSyncLogsUntransformedBy(allNames[1], []string{address}, fakes.FakeHash, nil)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(count).To(BeZero())
+			})
+		})
 	})
 })
@@ -0,0 +1,115 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/utils"
+)
+
+type ReorgRepository struct {
+	db *postgres.DB
+}
+
+func NewReorgRepository(db *postgres.DB) ReorgRepository {
+	return ReorgRepository{db: db}
+}
+
+// Replaces every orphaned header with its canonical counterpart and records the reorg, in a single transaction.
+// Data derived from the orphaned headers is removed by the cascading deletes on headers.
+func (repo ReorgRepository) CreateReorg(reorg core.Reorg) (int64, error) {
+	tx, beginErr := repo.db.Beginx()
+	if beginErr != nil {
+		return 0, postgres.ErrBeginTransactionFailed(beginErr)
+	}
+
+	for _, oldHeader := range reorg.OldHeaders {
+		_, deleteErr := tx.Exec(`DELETE FROM public.headers WHERE block_number = $1 AND hash = $2 AND eth_node_id = $3`,
+			oldHeader.BlockNumber, oldHeader.Hash, repo.db.NodeID)
+		if deleteErr != nil {
+			utils.RollbackAndLogFailure(tx, deleteErr, "headers")
+			return 0, postgres.ErrDBDeleteFailed(deleteErr)
+		}
+	}
+
+	for _, newHeader := range reorg.NewHeaders {
+		var headerID int64
+		insertErr := tx.QueryRowx(insertHeaderQuery, newHeader.BlockNumber, newHeader.Hash, newHeader.ParentHash,
+			newHeader.Timestamp, newHeader.Raw, repo.db.NodeID).Scan(&headerID)
+		if insertErr != nil && insertErr != sql.ErrNoRows {
+			utils.RollbackAndLogFailure(tx, insertErr, "headers")
+			return 0, postgres.ErrDBInsertFailed(insertErr)
+		}
+	}
+
+	var reorgID int64
+	reorgErr := tx.QueryRowx(`INSERT INTO public.reorgs
+		(common_ancestor, depth, block_numbers, old_hashes, new_hashes, eth_node_id)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`, reorg.CommonAncestor, reorg.Depth(),
+		pq.Int64Array(reorg.BlockNumbers()), pq.StringArray(reorg.OldHashes()), pq.StringArray(reorg.NewHashes()),
+		repo.db.NodeID).Scan(&reorgID)
+	if reorgErr != nil {
+		utils.RollbackAndLogFailure(tx, reorgErr, "reorgs")
+		return 0, postgres.ErrDBInsertFailed(reorgErr)
+	}
+
+	return reorgID, tx.Commit()
+}
+
+// Returns the ID of the last reorg recorded for the node, or 0 if there are none
+func (repo ReorgRepository) GetLatestReorgID() (int64, error) {
+	var reorgID sql.NullInt64
+	err := repo.db.Get(&reorgID, `SELECT MAX(id) FROM public.reorgs WHERE eth_node_id = $1`, repo.db.NodeID)
+	return reorgID.Int64, err
+}
+
+// Returns the reorgs recorded for the node after the one with the ID, in the order they were recorded. Headers of
+// reorgs recorded before block numbers were stored only have their hashes set.
+func (repo ReorgRepository) GetReorgsAfter(reorgID int64) ([]core.Reorg, error) {
+	var rows []struct {
+		ID             int64
+		CommonAncestor int64          `db:"common_ancestor"`
+		BlockNumbers   pq.Int64Array  `db:"block_numbers"`
+		OldHashes      pq.StringArray `db:"old_hashes"`
+		NewHashes      pq.StringArray `db:"new_hashes"`
+	}
+	err := repo.db.Select(&rows, `SELECT id, common_ancestor, block_numbers, old_hashes, new_hashes
+		FROM public.reorgs WHERE id > $1 AND eth_node_id = $2 ORDER BY id`, reorgID, repo.db.NodeID)
+	if err != nil {
+		return nil, err
+	}
+	reorgs := make([]core.Reorg, 0, len(rows))
+	for _, row := range rows {
+		reorg := core.Reorg{ID: row.ID, CommonAncestor: row.CommonAncestor}
+		for i := range row.OldHashes {
+			oldHeader := core.Header{Hash: row.OldHashes[i]}
+			newHeader := core.Header{Hash: row.NewHashes[i]}
+			if i < len(row.BlockNumbers) {
+				oldHeader.BlockNumber = row.BlockNumbers[i]
+				newHeader.BlockNumber = row.BlockNumbers[i]
+			}
+			reorg.OldHeaders = append(reorg.OldHeaders, oldHeader)
+			reorg.NewHeaders = append(reorg.NewHeaders, newHeader)
+		}
+		reorgs = append(reorgs, reorg)
+	}
+	return reorgs, nil
+}
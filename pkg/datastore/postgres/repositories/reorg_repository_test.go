@@ -0,0 +1,125 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repositories_test
+
+import (
+	"math/rand"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lib/pq"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/test_config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reorg repository", func() {
+	var (
+		db               *postgres.DB
+		repo             datastore.ReorgRepository
+		headerRepository repositories.HeaderRepository
+		blockNumber      int64
+		oldHeader        core.Header
+		newHeader        core.Header
+	)
+
+	BeforeEach(func() {
+		db = test_config.NewTestDB(test_config.NewTestNode())
+		test_config.CleanTestDB(db)
+		repo = repositories.NewReorgRepository(db)
+		headerRepository = repositories.NewHeaderRepository(db)
+		blockNumber = rand.Int63()
+		oldHeader = fakes.GetFakeHeader(blockNumber)
+		newHeader = fakes.GetFakeHeader(blockNumber)
+		newHeader.Hash = common.BytesToHash([]byte{5, 4, 3, 2, 1}).Hex()
+	})
+
+	AfterEach(func() {
+		closeErr := db.Close()
+		Expect(closeErr).NotTo(HaveOccurred())
+	})
+
+	Describe("CreateReorg", func() {
+		It("replaces orphaned headers with canonical headers", func() {
+			_, insertErr := headerRepository.CreateOrUpdateHeader(oldHeader)
+			Expect(insertErr).NotTo(HaveOccurred())
+
+			_, err := repo.CreateReorg(core.Reorg{
+				CommonAncestor: blockNumber - 1,
+				OldHeaders:     []core.Header{oldHeader},
+				NewHeaders:     []core.Header{newHeader},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			var hashes []string
+			getErr := db.Select(&hashes, `SELECT hash FROM public.headers WHERE block_number = $1`, blockNumber)
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(hashes).To(ConsistOf(newHeader.Hash))
+		})
+
+		It("records the reorg", func() {
+			_, insertErr := headerRepository.CreateOrUpdateHeader(oldHeader)
+			Expect(insertErr).NotTo(HaveOccurred())
+
+			reorgID, err := repo.CreateReorg(core.Reorg{
+				CommonAncestor: blockNumber - 1,
+				OldHeaders:     []core.Header{oldHeader},
+				NewHeaders:     []core.Header{newHeader},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			var dbReorg struct {
+				CommonAncestor int64          `db:"common_ancestor"`
+				Depth          int            `db:"depth"`
+				OldHashes      pq.StringArray `db:"old_hashes"`
+				NewHashes      pq.StringArray `db:"new_hashes"`
+				EthNodeID      int64          `db:"eth_node_id"`
+			}
+			getErr := db.Get(&dbReorg, `SELECT common_ancestor, depth, old_hashes, new_hashes, eth_node_id
+				FROM public.reorgs WHERE id = $1`, reorgID)
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(dbReorg.CommonAncestor).To(Equal(blockNumber - 1))
+			Expect(dbReorg.Depth).To(Equal(1))
+			Expect([]string(dbReorg.OldHashes)).To(ConsistOf(oldHeader.Hash))
+			Expect([]string(dbReorg.NewHashes)).To(ConsistOf(newHeader.Hash))
+			Expect(dbReorg.EthNodeID).To(Equal(db.NodeID))
+		})
+
+		It("does not fail if a canonical header has already been stored", func() {
+			_, insertOldErr := headerRepository.CreateOrUpdateHeader(oldHeader)
+			Expect(insertOldErr).NotTo(HaveOccurred())
+			_, insertNewErr := headerRepository.InternalInsertHeader(newHeader)
+			Expect(insertNewErr).NotTo(HaveOccurred())
+
+			_, err := repo.CreateReorg(core.Reorg{
+				CommonAncestor: blockNumber - 1,
+				OldHeaders:     []core.Header{oldHeader},
+				NewHeaders:     []core.Header{newHeader},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			var hashes []string
+			getErr := db.Select(&hashes, `SELECT hash FROM public.headers WHERE block_number = $1`, blockNumber)
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(hashes).To(ConsistOf(newHeader.Hash))
+		})
+	})
+})
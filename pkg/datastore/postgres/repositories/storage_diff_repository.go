@@ -19,6 +19,8 @@ package repositories
 import (
 	"database/sql"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lib/pq"
 	"github.com/makerdao/vulcanizedb/libraries/shared/storage"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
 )
@@ -46,3 +48,78 @@ func (repository StorageDiffRepository) CreateStorageDiff(rawDiff storage.RawDif
 	}
 	return storageDiffID, err
 }
+
+// GetLatestSlotValues returns the value of each of the contract's storage keys as of the block height, omitting keys
+// without a diff at or below it
+func (repository StorageDiffRepository) GetLatestSlotValues(hashedAddress common.Hash, keys []common.Hash, blockHeight int) (map[common.Hash]common.Hash, error) {
+	var slots []struct {
+		StorageKey   common.Hash `db:"storage_key"`
+		StorageValue common.Hash `db:"storage_value"`
+	}
+	err := repository.db.Select(&slots, `SELECT DISTINCT ON (storage_key) storage_key, storage_value
+		FROM public.storage_diff
+		WHERE hashed_address = $1 AND storage_key = ANY($2) AND block_height <= $3 AND NOT orphaned
+		ORDER BY storage_key, block_height DESC, id DESC`, hashedAddress.Bytes(), pq.Array(hashesToBytes(keys)), blockHeight)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[common.Hash]common.Hash, len(slots))
+	for _, slot := range slots {
+		values[slot.StorageKey] = slot.StorageValue
+	}
+	return values, nil
+}
+
+// GetLatestDiffID returns the ID of the last diff persisted for any of the contract's storage keys in the block
+func (repository StorageDiffRepository) GetLatestDiffID(hashedAddress, blockHash common.Hash, keys []common.Hash) (int64, error) {
+	var diffID sql.NullInt64
+	err := repository.db.Get(&diffID, `SELECT MAX(id) FROM public.storage_diff
+		WHERE hashed_address = $1 AND block_hash = $2 AND storage_key = ANY($3)`,
+		hashedAddress.Bytes(), blockHash.Bytes(), pq.Array(hashesToBytes(keys)))
+	return diffID.Int64, err
+}
+
+// HasDiffsAfter is true once a diff to any of the contract's storage keys from a block above the height has been
+// persisted, meaning every diff to those keys from the block at the height has been persisted too
+func (repository StorageDiffRepository) HasDiffsAfter(hashedAddress common.Hash, keys []common.Hash, blockHeight int) (bool, error) {
+	var exists bool
+	err := repository.db.Get(&exists, `SELECT EXISTS(SELECT 1 FROM public.storage_diff
+		WHERE hashed_address = $1 AND storage_key = ANY($2) AND block_height > $3)`,
+		hashedAddress.Bytes(), pq.Array(hashesToBytes(keys)), blockHeight)
+	return exists, err
+}
+
+// MarkOrphanedDiffs marks diffs at or below the final block height whose block hash doesn't match the header at their
+// height as orphaned, and removes them from the queue. Only queued diffs and diffs above afterBlockHeight are checked,
+// so heights already checked once final aren't scanned again. Returns the number of diffs newly orphaned.
+func (repository StorageDiffRepository) MarkOrphanedDiffs(afterBlockHeight, finalBlockHeight int64) (int64, error) {
+	var orphaned int64
+	err := repository.db.Get(&orphaned, `WITH candidates AS (
+			SELECT id FROM public.storage_diff
+			WHERE orphaned IS FALSE AND block_height > $3 AND block_height <= $1
+			UNION
+			SELECT diff_id FROM public.queued_storage
+		), orphaned AS (
+			UPDATE public.storage_diff SET orphaned = TRUE
+			FROM public.headers
+			WHERE storage_diff.id IN (SELECT id FROM candidates)
+				AND NOT storage_diff.orphaned
+				AND storage_diff.block_height <= $1
+				AND headers.block_number = storage_diff.block_height
+				AND headers.eth_node_id = $2
+				AND storage_diff.block_hash <> decode(regexp_replace(headers.hash, '^0x', ''), 'hex')
+			RETURNING storage_diff.id
+		), dequeued AS (
+			DELETE FROM public.queued_storage WHERE diff_id IN (SELECT id FROM orphaned)
+		)
+		SELECT COUNT(*) FROM orphaned`, finalBlockHeight, repository.db.NodeID, afterBlockHeight)
+	return orphaned, err
+}
+
+func hashesToBytes(hashes []common.Hash) [][]byte {
+	result := make([][]byte, len(hashes))
+	for i, hash := range hashes {
+		result[i] = hash.Bytes()
+	}
+	return result
+}
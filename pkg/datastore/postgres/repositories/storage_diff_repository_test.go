@@ -20,10 +20,12 @@ import (
 	"database/sql"
 	"math/rand"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/makerdao/vulcanizedb/libraries/shared/storage"
 	"github.com/makerdao/vulcanizedb/libraries/shared/test_data"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
 	"github.com/makerdao/vulcanizedb/test_config"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -80,4 +82,229 @@ var _ = Describe("Storage diffs repository", func() {
 			Expect(count).To(Equal(1))
 		})
 	})
+
+	Describe("GetLatestSlotValues", func() {
+		It("returns the latest value of each key at or below the block height", func() {
+			otherKey := test_data.FakeHash()
+			olderValue := test_data.FakeHash()
+			newerValue := test_data.FakeHash()
+			for _, diff := range []storage.RawDiff{
+				{HashedAddress: fakeStorageDiff.HashedAddress, BlockHeight: 1, StorageKey: fakeStorageDiff.StorageKey, StorageValue: olderValue},
+				{HashedAddress: fakeStorageDiff.HashedAddress, BlockHeight: 2, StorageKey: fakeStorageDiff.StorageKey, StorageValue: newerValue},
+				{HashedAddress: fakeStorageDiff.HashedAddress, BlockHeight: 3, StorageKey: fakeStorageDiff.StorageKey, StorageValue: test_data.FakeHash()},
+				{HashedAddress: test_data.FakeHash(), BlockHeight: 1, StorageKey: otherKey, StorageValue: test_data.FakeHash()},
+			} {
+				_, createErr := repo.CreateStorageDiff(diff)
+				Expect(createErr).NotTo(HaveOccurred())
+			}
+
+			values, err := repo.GetLatestSlotValues(fakeStorageDiff.HashedAddress, []common.Hash{fakeStorageDiff.StorageKey, otherKey}, 2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(values).To(Equal(map[common.Hash]common.Hash{fakeStorageDiff.StorageKey: newerValue}))
+		})
+
+		It("ignores orphaned diffs", func() {
+			canonicalValue := test_data.FakeHash()
+			_, createErr := repo.CreateStorageDiff(storage.RawDiff{HashedAddress: fakeStorageDiff.HashedAddress,
+				BlockHeight: 1, StorageKey: fakeStorageDiff.StorageKey, StorageValue: canonicalValue})
+			Expect(createErr).NotTo(HaveOccurred())
+			orphanedID, createOrphanedErr := repo.CreateStorageDiff(storage.RawDiff{HashedAddress: fakeStorageDiff.HashedAddress,
+				BlockHeight: 2, StorageKey: fakeStorageDiff.StorageKey, StorageValue: test_data.FakeHash()})
+			Expect(createOrphanedErr).NotTo(HaveOccurred())
+			db.MustExec(`UPDATE public.storage_diff SET orphaned = TRUE WHERE id = $1`, orphanedID)
+
+			values, err := repo.GetLatestSlotValues(fakeStorageDiff.HashedAddress, []common.Hash{fakeStorageDiff.StorageKey}, 2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(values).To(Equal(map[common.Hash]common.Hash{fakeStorageDiff.StorageKey: canonicalValue}))
+		})
+	})
+
+	Describe("GetLatestDiffID", func() {
+		It("returns the ID of the last diff to one of the keys in the block", func() {
+			otherDiff := fakeStorageDiff
+			otherDiff.StorageKey = test_data.FakeHash()
+			_, createErr := repo.CreateStorageDiff(fakeStorageDiff)
+			Expect(createErr).NotTo(HaveOccurred())
+			otherID, createOtherErr := repo.CreateStorageDiff(otherDiff)
+			Expect(createOtherErr).NotTo(HaveOccurred())
+
+			id, err := repo.GetLatestDiffID(fakeStorageDiff.HashedAddress, fakeStorageDiff.BlockHash,
+				[]common.Hash{fakeStorageDiff.StorageKey, otherDiff.StorageKey})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(Equal(otherID))
+		})
+
+		It("returns zero if none of the keys have a diff in the block", func() {
+			id, err := repo.GetLatestDiffID(fakeStorageDiff.HashedAddress, fakeStorageDiff.BlockHash,
+				[]common.Hash{fakeStorageDiff.StorageKey})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(BeZero())
+		})
+	})
+
+	Describe("HasDiffsAfter", func() {
+		var keys []common.Hash
+
+		BeforeEach(func() {
+			keys = []common.Hash{fakeStorageDiff.StorageKey}
+			_, createErr := repo.CreateStorageDiff(fakeStorageDiff)
+			Expect(createErr).NotTo(HaveOccurred())
+		})
+
+		It("is true if there is a diff to one of the keys from a later block", func() {
+			exists, err := repo.HasDiffsAfter(fakeStorageDiff.HashedAddress, keys, fakeStorageDiff.BlockHeight-1)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeTrue())
+		})
+
+		It("is false if there are only diffs to the keys at or below the block", func() {
+			exists, err := repo.HasDiffsAfter(fakeStorageDiff.HashedAddress, keys, fakeStorageDiff.BlockHeight)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+		})
+
+		It("ignores later diffs to other keys or contracts", func() {
+			exists, err := repo.HasDiffsAfter(fakeStorageDiff.HashedAddress, []common.Hash{test_data.FakeHash()},
+				fakeStorageDiff.BlockHeight-1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+
+			exists, err = repo.HasDiffsAfter(test_data.FakeHash(), keys, fakeStorageDiff.BlockHeight-1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+		})
+	})
+
+	Describe("MarkOrphanedDiffs", func() {
+		var (
+			canonicalHash common.Hash
+			queue         storage.StorageQueue
+		)
+
+		BeforeEach(func() {
+			queue = storage.NewStorageQueue(db)
+			header := fakes.GetFakeHeader(10)
+			canonicalHash = common.HexToHash(header.Hash)
+			_, insertHeaderErr := repositories.NewHeaderRepository(db).CreateOrUpdateHeader(header)
+			Expect(insertHeaderErr).NotTo(HaveOccurred())
+		})
+
+		createUnqueuedDiff := func(blockHeight int, blockHash common.Hash) int64 {
+			diffID, createErr := repo.CreateStorageDiff(storage.RawDiff{
+				HashedAddress: fakeStorageDiff.HashedAddress,
+				BlockHash:     blockHash,
+				BlockHeight:   blockHeight,
+				StorageKey:    test_data.FakeHash(),
+				StorageValue:  test_data.FakeHash(),
+			})
+			Expect(createErr).NotTo(HaveOccurred())
+			return diffID
+		}
+
+		createDiff := func(blockHeight int, blockHash common.Hash) int64 {
+			diffID := createUnqueuedDiff(blockHeight, blockHash)
+			queueErr := queue.Add(storage.PersistedDiff{ID: diffID})
+			Expect(queueErr).NotTo(HaveOccurred())
+			return diffID
+		}
+
+		isOrphaned := func(diffID int64) bool {
+			var orphaned bool
+			getErr := db.Get(&orphaned, `SELECT orphaned FROM public.storage_diff WHERE id = $1`, diffID)
+			Expect(getErr).NotTo(HaveOccurred())
+			return orphaned
+		}
+
+		It("marks diffs that don't match the header at their height as orphaned", func() {
+			canonicalDiffID := createDiff(10, canonicalHash)
+			orphanedDiffID := createDiff(10, test_data.FakeHash())
+
+			orphaned, err := repo.MarkOrphanedDiffs(0, 10)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orphaned).To(Equal(int64(1)))
+			Expect(isOrphaned(orphanedDiffID)).To(BeTrue())
+			Expect(isOrphaned(canonicalDiffID)).To(BeFalse())
+		})
+
+		It("removes orphaned diffs from the queue", func() {
+			canonicalDiffID := createDiff(10, canonicalHash)
+			createDiff(10, test_data.FakeHash())
+
+			_, err := repo.MarkOrphanedDiffs(0, 10)
+
+			Expect(err).NotTo(HaveOccurred())
+			queued, getErr := queue.GetAll()
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(len(queued)).To(Equal(1))
+			Expect(queued[0].ID).To(Equal(canonicalDiffID))
+		})
+
+		It("doesn't mark diffs above the final block height", func() {
+			diffID := createDiff(10, test_data.FakeHash())
+
+			orphaned, err := repo.MarkOrphanedDiffs(0, 9)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orphaned).To(BeZero())
+			Expect(isOrphaned(diffID)).To(BeFalse())
+		})
+
+		It("marks unqueued diffs above the checked height", func() {
+			diffID := createUnqueuedDiff(10, test_data.FakeHash())
+
+			orphaned, err := repo.MarkOrphanedDiffs(9, 10)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orphaned).To(Equal(int64(1)))
+			Expect(isOrphaned(diffID)).To(BeTrue())
+		})
+
+		It("doesn't check unqueued diffs at or below the checked height", func() {
+			diffID := createUnqueuedDiff(10, test_data.FakeHash())
+
+			orphaned, err := repo.MarkOrphanedDiffs(10, 10)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orphaned).To(BeZero())
+			Expect(isOrphaned(diffID)).To(BeFalse())
+		})
+
+		It("checks queued diffs at or below the checked height", func() {
+			diffID := createDiff(10, test_data.FakeHash())
+
+			orphaned, err := repo.MarkOrphanedDiffs(10, 10)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orphaned).To(Equal(int64(1)))
+			Expect(isOrphaned(diffID)).To(BeTrue())
+		})
+
+		It("doesn't mark diffs without a header at their height", func() {
+			diffID := createDiff(9, test_data.FakeHash())
+
+			orphaned, err := repo.MarkOrphanedDiffs(0, 10)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orphaned).To(BeZero())
+			Expect(isOrphaned(diffID)).To(BeFalse())
+		})
+
+		It("doesn't count diffs that were already orphaned", func() {
+			createDiff(10, test_data.FakeHash())
+			_, markErr := repo.MarkOrphanedDiffs(0, 10)
+			Expect(markErr).NotTo(HaveOccurred())
+
+			orphaned, err := repo.MarkOrphanedDiffs(0, 10)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orphaned).To(BeZero())
+		})
+	})
 })
@@ -0,0 +1,48 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repositories
+
+import (
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+)
+
+// Missing headers/blocks are the gaps between the lowest and highest synced block numbers for the node
+const getSyncStatusQuery = `SELECT
+	(SELECT COALESCE(MAX(block_number), 0) FROM public.headers WHERE eth_node_id = $1) AS highest_header_block_number,
+	(SELECT COALESCE(MAX(block_number) - MIN(block_number) + 1 - COUNT(DISTINCT block_number), 0)
+		FROM public.headers WHERE eth_node_id = $1) AS missing_headers,
+	(SELECT COUNT(*) FROM public.headers WHERE check_count = 0 AND eth_node_id = $1) AS unchecked_headers,
+	(SELECT COUNT(*) FROM public.header_sync_logs WHERE transformed = false) AS untransformed_logs,
+	(SELECT COUNT(*) FROM public.queued_storage) AS queued_storage_diffs,
+	(SELECT COALESCE(MAX(number), 0) FROM public.blocks WHERE eth_node_id = $1) AS highest_block_number,
+	(SELECT COALESCE(MAX(number) - MIN(number) + 1 - COUNT(*), 0)
+		FROM public.blocks WHERE eth_node_id = $1) AS missing_blocks`
+
+type SyncStatusRepository struct {
+	db *postgres.DB
+}
+
+func NewSyncStatusRepository(db *postgres.DB) SyncStatusRepository {
+	return SyncStatusRepository{db: db}
+}
+
+func (repo SyncStatusRepository) GetSyncStatus() (core.SyncStatus, error) {
+	var status core.SyncStatus
+	err := repo.db.Get(&status, getSyncStatusQuery, repo.db.NodeID)
+	return status, err
+}
@@ -0,0 +1,134 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repositories_test
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/makerdao/vulcanizedb/libraries/shared/storage"
+	"github.com/makerdao/vulcanizedb/libraries/shared/test_data"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/test_config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sync status repository", func() {
+	var (
+		db               *postgres.DB
+		repo             datastore.SyncStatusRepository
+		headerRepository repositories.HeaderRepository
+	)
+
+	BeforeEach(func() {
+		db = test_config.NewTestDB(test_config.NewTestNode())
+		test_config.CleanTestDB(db)
+		repo = repositories.NewSyncStatusRepository(db)
+		headerRepository = repositories.NewHeaderRepository(db)
+	})
+
+	AfterEach(func() {
+		closeErr := db.Close()
+		Expect(closeErr).NotTo(HaveOccurred())
+	})
+
+	Describe("GetSyncStatus", func() {
+		It("returns zero values for an empty database", func() {
+			status, err := repo.GetSyncStatus()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal(core.SyncStatus{}))
+		})
+
+		It("returns the highest synced header and the headers missing below it", func() {
+			for _, blockNumber := range []int64{3, 4, 7} {
+				_, insertErr := headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(blockNumber))
+				Expect(insertErr).NotTo(HaveOccurred())
+			}
+
+			status, err := repo.GetSyncStatus()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.HighestHeaderBlockNumber).To(Equal(int64(7)))
+			Expect(status.MissingHeaders).To(Equal(int64(2)))
+		})
+
+		It("returns the number of unchecked headers", func() {
+			checkedHeaderID, insertErr := headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(1))
+			Expect(insertErr).NotTo(HaveOccurred())
+			_, insertErr = headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(2))
+			Expect(insertErr).NotTo(HaveOccurred())
+			checkErr := repositories.NewCheckedHeadersRepository(db).MarkHeaderChecked(checkedHeaderID)
+			Expect(checkErr).NotTo(HaveOccurred())
+
+			status, err := repo.GetSyncStatus()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.UncheckedHeaders).To(Equal(int64(1)))
+		})
+
+		It("returns the number of untransformed logs", func() {
+			headerID, insertErr := headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(1))
+			Expect(insertErr).NotTo(HaveOccurred())
+			transformedLog := test_data.CreateTestLog(headerID, db)
+			test_data.CreateTestLog(headerID, db)
+			_, markErr := db.Exec(`UPDATE public.header_sync_logs SET transformed = true WHERE id = $1`, transformedLog.ID)
+			Expect(markErr).NotTo(HaveOccurred())
+
+			status, err := repo.GetSyncStatus()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.UntransformedLogs).To(Equal(int64(1)))
+		})
+
+		It("returns the number of queued storage diffs", func() {
+			rawDiff := storage.RawDiff{
+				HashedAddress: storage.HexToKeccak256Hash("0x123456"),
+				BlockHash:     common.HexToHash("0x678901"),
+				BlockHeight:   987,
+				StorageKey:    common.HexToHash("0x654321"),
+				StorageValue:  common.HexToHash("0x198765"),
+			}
+			diffID, insertDiffErr := repositories.NewStorageDiffRepository(db).CreateStorageDiff(rawDiff)
+			Expect(insertDiffErr).NotTo(HaveOccurred())
+			addErr := storage.NewStorageQueue(db).Add(storage.ToPersistedDiff(rawDiff, diffID))
+			Expect(addErr).NotTo(HaveOccurred())
+
+			status, err := repo.GetSyncStatus()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.QueuedStorageDiffs).To(Equal(int64(1)))
+		})
+
+		It("returns the highest synced block and the blocks missing below it", func() {
+			blockRepository := repositories.NewBlockRepository(db)
+			for _, blockNumber := range []int64{2, 5} {
+				_, insertErr := blockRepository.CreateOrUpdateBlock(core.Block{Number: blockNumber})
+				Expect(insertErr).NotTo(HaveOccurred())
+			}
+
+			status, err := repo.GetSyncStatus()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.HighestBlockNumber).To(Equal(int64(5)))
+			Expect(status.MissingBlocks).To(Equal(int64(2)))
+		})
+	})
+})
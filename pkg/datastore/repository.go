@@ -17,6 +17,7 @@
 package datastore
 
 import (
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/jmoiron/sqlx"
 	"github.com/makerdao/vulcanizedb/libraries/shared/storage"
@@ -36,14 +37,21 @@ type BlockRepository interface {
 }
 
 type CheckedHeadersRepository interface {
+	CheckedHeaders(startingBlockNumber, endingBlockNumber int64) ([]core.Header, error)
 	MarkHeaderChecked(headerID int64) error
-	MarkHeadersUnchecked(startingBlockNumber int64) error
+	MarkHeadersChecked(headerIDs []int64) error
+	MarkHeadersConfirmed(headerIDs []int64) error
+	MarkHeadersUnchecked(startingBlockNumber, endingBlockNumber int64) error
+	MarkHeadersUnconfirmed(headerIDs []int64) error
 	UncheckedHeaders(startingBlockNumber, endingBlockNumber, checkCount int64) ([]core.Header, error)
+	UnconfirmedHeaders(startingBlockNumber, endingBlockNumber int64) ([]core.Header, error)
 }
 
 type CheckedLogsRepository interface {
-	AlreadyWatchingLog(addresses []string, topic0 string) (bool, error)
-	MarkLogWatched(addresses []string, topic0 string) error
+	AlreadyWatchingLog(addresses []string, topic0, topicFilters string) (bool, error)
+	GetLogBackfills() ([]core.LogBackfill, error)
+	MarkLogWatched(addresses []string, topic0, topicFilters string, startingBlockNumber, endingBlockNumber int64) error
+	UpdateLogBackfill(addresses []string, topic0, topicFilters string, nextBlockNumber int64) error
 }
 
 type ContractRepository interface {
@@ -63,15 +71,25 @@ type FullSyncLogRepository interface {
 }
 
 type HeaderRepository interface {
+	BrokenLinkBlockNumbers(startingBlockNumber, endingBlockNumber int64) ([]int64, error)
+	CreateHeaders(headers []core.Header) error
 	CreateOrUpdateHeader(header core.Header) (int64, error)
+	CreateReceipts(headerID int64, receipts []core.Receipt) error
 	CreateTransactions(headerID int64, transactions []core.TransactionModel) error
 	GetHeader(blockNumber int64) (core.Header, error)
+	GetMostRecentHeaderBlockNumber() (int64, error)
 	MissingBlockNumbers(startingBlockNumber, endingBlockNumber int64) ([]int64, error)
 }
 
 type HeaderSyncLogRepository interface {
-	GetUntransformedHeaderSyncLogs() ([]core.HeaderSyncLog, error)
+	GetUntransformedHeaderSyncLogs(afterBlockNumber, afterID int64, limit int) ([]core.HeaderSyncLog, error)
 	CreateHeaderSyncLogs(headerID int64, logs []types.Log) error
+	CreateHeaderSyncLogTransformations(transformerName string, logIDs []int64) error
+	GetNewHeaderSyncLogTransformers(transformerNames []string) ([]string, error)
+	RegisterHeaderSyncLogTransformers(transformerNames []string) error
+	MarkHeaderSyncLogsTransformed(logIDs []int64) error
+	MarkHeaderSyncLogsUntransformed(logIDs []int64) error
+	MarkHeaderSyncLogsUntransformedBy(transformerName string, addresses []string, topic0 common.Hash, topicFilters [][]common.Hash) (int64, error)
 }
 
 type FullSyncReceiptRepository interface {
@@ -84,8 +102,22 @@ type HeaderSyncReceiptRepository interface {
 	CreateFullSyncReceiptInTx(blockId int64, receipt core.Receipt, tx *sqlx.Tx) (int64, error)
 }
 
+type ReorgRepository interface {
+	CreateReorg(reorg core.Reorg) (int64, error)
+	GetLatestReorgID() (int64, error)
+	GetReorgsAfter(reorgID int64) ([]core.Reorg, error)
+}
+
 type StorageDiffRepository interface {
 	CreateStorageDiff(rawDiff storage.RawDiff) (int64, error)
+	GetLatestSlotValues(hashedAddress common.Hash, keys []common.Hash, blockHeight int) (map[common.Hash]common.Hash, error)
+	GetLatestDiffID(hashedAddress, blockHash common.Hash, keys []common.Hash) (int64, error)
+	HasDiffsAfter(hashedAddress common.Hash, keys []common.Hash, blockHeight int) (bool, error)
+	MarkOrphanedDiffs(afterBlockHeight, finalBlockHeight int64) (int64, error)
+}
+
+type SyncStatusRepository interface {
+	GetSyncStatus() (core.SyncStatus, error)
 }
 
 type WatchedEventRepository interface {
@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"context"
 	"errors"
 	"github.com/ethereum/go-ethereum"
 	"math/big"
@@ -25,14 +26,16 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"golang.org/x/net/context"
 
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/eth/client"
 	vulcCommon "github.com/makerdao/vulcanizedb/pkg/eth/converters/common"
 )
 
-var ErrEmptyHeader = errors.New("empty header returned over RPC")
+var (
+	ErrEmptyHeader     = errors.New("empty header returned over RPC")
+	ErrReceiptNotFound = errors.New("transaction receipt not found over RPC")
+)
 
 const MAX_BATCH_SIZE = 100
 
@@ -56,37 +59,51 @@ func NewBlockChain(ethClient core.EthClient, rpcClient core.RpcClient, node core
 	}
 }
 
-func (blockChain *BlockChain) GetBlockByNumber(blockNumber int64) (block core.Block, err error) {
-	gethBlock, err := blockChain.ethClient.BlockByNumber(context.Background(), big.NewInt(blockNumber))
+func (blockChain *BlockChain) GetBlockByNumber(ctx context.Context, blockNumber int64) (block core.Block, err error) {
+	gethBlock, err := blockChain.ethClient.BlockByNumber(ctx, big.NewInt(blockNumber))
 	if err != nil {
 		return block, err
 	}
 	return blockChain.blockConverter.ToCoreBlock(gethBlock)
 }
 
-func (blockChain *BlockChain) GetEthLogsWithCustomQuery(query ethereum.FilterQuery) ([]types.Log, error) {
-	gethLogs, err := blockChain.ethClient.FilterLogs(context.Background(), query)
+func (blockChain *BlockChain) GetEthLogsWithCustomQuery(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	gethLogs, err := blockChain.ethClient.FilterLogs(ctx, query)
 	if err != nil {
 		return []types.Log{}, err
 	}
 	return gethLogs, nil
 }
 
-func (blockChain *BlockChain) GetHeaderByNumber(blockNumber int64) (header core.Header, err error) {
+func (blockChain *BlockChain) GetHeaderByNumber(ctx context.Context, blockNumber int64) (header core.Header, err error) {
 	if blockChain.node.NetworkID == core.KOVAN_NETWORK_ID {
-		return blockChain.getPOAHeader(blockNumber)
+		return blockChain.getPOAHeader(ctx, blockNumber)
 	}
-	return blockChain.getPOWHeader(blockNumber)
+	return blockChain.getPOWHeader(ctx, blockNumber)
 }
 
-func (blockChain *BlockChain) GetHeadersByNumbers(blockNumbers []int64) (header []core.Header, err error) {
-	if blockChain.node.NetworkID == core.KOVAN_NETWORK_ID {
-		return blockChain.getPOAHeaders(blockNumbers)
+// Fetches headers in batch calls of at most MAX_BATCH_SIZE block numbers each
+func (blockChain *BlockChain) GetHeadersByNumbers(ctx context.Context, blockNumbers []int64) (headers []core.Header, err error) {
+	for start := 0; start < len(blockNumbers); start += MAX_BATCH_SIZE {
+		end := start + MAX_BATCH_SIZE
+		if end > len(blockNumbers) {
+			end = len(blockNumbers)
+		}
+		var batchHeaders []core.Header
+		if blockChain.node.NetworkID == core.KOVAN_NETWORK_ID {
+			batchHeaders, err = blockChain.getPOAHeaders(ctx, blockNumbers[start:end])
+		} else {
+			batchHeaders, err = blockChain.getPOWHeaders(ctx, blockNumbers[start:end])
+		}
+		if err != nil {
+			return headers, err
+		}
+		headers = append(headers, batchHeaders...)
 	}
-	return blockChain.getPOWHeaders(blockNumbers)
+	return headers, nil
 }
 
-func (blockChain *BlockChain) GetFullSyncLogs(contract core.Contract, startingBlockNumber, endingBlockNumber *big.Int) ([]core.FullSyncLog, error) {
+func (blockChain *BlockChain) GetFullSyncLogs(ctx context.Context, contract core.Contract, startingBlockNumber, endingBlockNumber *big.Int) ([]core.FullSyncLog, error) {
 	if endingBlockNumber == nil {
 		endingBlockNumber = startingBlockNumber
 	}
@@ -97,7 +114,7 @@ func (blockChain *BlockChain) GetFullSyncLogs(contract core.Contract, startingBl
 		Addresses: []common.Address{contractAddress},
 		Topics:    nil,
 	}
-	gethLogs, err := blockChain.GetEthLogsWithCustomQuery(fc)
+	gethLogs, err := blockChain.GetEthLogsWithCustomQuery(ctx, fc)
 	if err != nil {
 		return []core.FullSyncLog{}, err
 	}
@@ -105,7 +122,7 @@ func (blockChain *BlockChain) GetFullSyncLogs(contract core.Contract, startingBl
 	return logs, nil
 }
 
-func (blockChain *BlockChain) GetTransactions(transactionHashes []common.Hash) ([]core.TransactionModel, error) {
+func (blockChain *BlockChain) GetTransactions(ctx context.Context, transactionHashes []common.Hash) ([]core.TransactionModel, error) {
 	numTransactions := len(transactionHashes)
 	var batch []client.BatchElem
 	transactions := make([]core.RpcTransaction, numTransactions)
@@ -119,7 +136,7 @@ func (blockChain *BlockChain) GetTransactions(transactionHashes []common.Hash) (
 		batch = append(batch, batchElem)
 	}
 
-	rpcErr := blockChain.rpcClient.BatchCall(batch)
+	rpcErr := blockChain.rpcClient.BatchCallContext(ctx, batch)
 	if rpcErr != nil {
 		return []core.TransactionModel{}, rpcErr
 	}
@@ -127,8 +144,45 @@ func (blockChain *BlockChain) GetTransactions(transactionHashes []common.Hash) (
 	return blockChain.transactionConverter.ConvertRpcTransactionsToModels(transactions)
 }
 
-func (blockChain *BlockChain) LastBlock() (*big.Int, error) {
-	block, err := blockChain.ethClient.HeaderByNumber(context.Background(), nil)
+// Receipts are fetched for transactions already included in a block, so a missing receipt means the node hasn't caught
+// up yet and is returned as ErrReceiptNotFound
+func (blockChain *BlockChain) GetTransactionReceipts(ctx context.Context, transactionHashes []common.Hash) ([]core.Receipt, error) {
+	var batch []client.BatchElem
+	gethReceipts := make([]*types.Receipt, len(transactionHashes))
+
+	for index, transactionHash := range transactionHashes {
+		batchElem := client.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Result: &gethReceipts[index],
+			Args:   []interface{}{transactionHash},
+		}
+		batch = append(batch, batchElem)
+	}
+
+	rpcErr := blockChain.rpcClient.BatchCallContext(ctx, batch)
+	if rpcErr != nil {
+		return []core.Receipt{}, rpcErr
+	}
+
+	var receipts []core.Receipt
+	for index, gethReceipt := range gethReceipts {
+		if batch[index].Error != nil {
+			return []core.Receipt{}, batch[index].Error
+		}
+		if gethReceipt == nil {
+			return []core.Receipt{}, ErrReceiptNotFound
+		}
+		receipt, convertErr := vulcCommon.ToCoreReceipt(gethReceipt)
+		if convertErr != nil {
+			return []core.Receipt{}, convertErr
+		}
+		receipts = append(receipts, receipt)
+	}
+	return receipts, nil
+}
+
+func (blockChain *BlockChain) LastBlock(ctx context.Context) (*big.Int, error) {
+	block, err := blockChain.ethClient.HeaderByNumber(ctx, nil)
 	if err != nil {
 		return big.NewInt(0), err
 	}
@@ -139,11 +193,16 @@ func (blockChain *BlockChain) Node() core.Node {
 	return blockChain.node
 }
 
-func (blockChain *BlockChain) getPOAHeader(blockNumber int64) (header core.Header, err error) {
+// RpcClient returns the client requests are made through, including any failover, middleware and cache layers
+func (blockChain *BlockChain) RpcClient() core.RpcClient {
+	return blockChain.rpcClient
+}
+
+func (blockChain *BlockChain) getPOAHeader(ctx context.Context, blockNumber int64) (header core.Header, err error) {
 	var POAHeader core.POAHeader
 	blockNumberArg := hexutil.EncodeBig(big.NewInt(blockNumber))
 	includeTransactions := false
-	err = blockChain.rpcClient.CallContext(context.Background(), &POAHeader, "eth_getBlockByNumber", blockNumberArg, includeTransactions)
+	err = blockChain.rpcClient.CallContext(ctx, &POAHeader, "eth_getBlockByNumber", blockNumberArg, includeTransactions)
 	if err != nil {
 		return header, err
 	}
@@ -167,7 +226,7 @@ func (blockChain *BlockChain) getPOAHeader(blockNumber int64) (header core.Heade
 	}, POAHeader.Hash.String()), nil
 }
 
-func (blockChain *BlockChain) getPOAHeaders(blockNumbers []int64) (headers []core.Header, err error) {
+func (blockChain *BlockChain) getPOAHeaders(ctx context.Context, blockNumbers []int64) (headers []core.Header, err error) {
 
 	var batch []client.BatchElem
 	var POAHeaders [MAX_BATCH_SIZE]core.POAHeader
@@ -190,7 +249,7 @@ func (blockChain *BlockChain) getPOAHeaders(blockNumbers []int64) (headers []cor
 		batch = append(batch, batchElem)
 	}
 
-	err = blockChain.rpcClient.BatchCall(batch)
+	err = blockChain.rpcClient.BatchCallContext(ctx, batch)
 	if err != nil {
 		return headers, err
 	}
@@ -222,15 +281,15 @@ func (blockChain *BlockChain) getPOAHeaders(blockNumbers []int64) (headers []cor
 	return headers, err
 }
 
-func (blockChain *BlockChain) getPOWHeader(blockNumber int64) (header core.Header, err error) {
-	gethHeader, err := blockChain.ethClient.HeaderByNumber(context.Background(), big.NewInt(blockNumber))
+func (blockChain *BlockChain) getPOWHeader(ctx context.Context, blockNumber int64) (header core.Header, err error) {
+	gethHeader, err := blockChain.ethClient.HeaderByNumber(ctx, big.NewInt(blockNumber))
 	if err != nil {
 		return header, err
 	}
 	return blockChain.headerConverter.Convert(gethHeader, gethHeader.Hash().String()), nil
 }
 
-func (blockChain *BlockChain) getPOWHeaders(blockNumbers []int64) (headers []core.Header, err error) {
+func (blockChain *BlockChain) getPOWHeaders(ctx context.Context, blockNumbers []int64) (headers []core.Header, err error) {
 	var batch []client.BatchElem
 	var POWHeaders [MAX_BATCH_SIZE]types.Header
 	includeTransactions := false
@@ -252,7 +311,7 @@ func (blockChain *BlockChain) getPOWHeaders(blockNumbers []int64) (headers []cor
 		batch = append(batch, batchElem)
 	}
 
-	err = blockChain.rpcClient.BatchCall(batch)
+	err = blockChain.rpcClient.BatchCallContext(ctx, batch)
 	if err != nil {
 		return headers, err
 	}
@@ -267,6 +326,6 @@ func (blockChain *BlockChain) getPOWHeaders(blockNumbers []int64) (headers []cor
 	return headers, err
 }
 
-func (blockChain *BlockChain) GetAccountBalance(address common.Address, blockNumber *big.Int) (*big.Int, error) {
-	return blockChain.ethClient.BalanceAt(context.Background(), address, blockNumber)
+func (blockChain *BlockChain) GetAccountBalance(ctx context.Context, address common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return blockChain.ethClient.BalanceAt(ctx, address, blockNumber)
 }
@@ -55,7 +55,7 @@ var _ = Describe("Geth blockchain", func() {
 			mockClient.SetBlockByNumberReturnBlock(types.NewBlockWithHeader(&types.Header{}))
 			blockNumber := int64(100)
 
-			_, err := blockChain.GetBlockByNumber(blockNumber)
+			_, err := blockChain.GetBlockByNumber(context.Background(), blockNumber)
 
 			Expect(err).NotTo(HaveOccurred())
 			mockClient.AssertBlockByNumberCalledWith(context.Background(), big.NewInt(blockNumber))
@@ -64,7 +64,7 @@ var _ = Describe("Geth blockchain", func() {
 		It("returns err if ethClient returns err", func() {
 			mockClient.SetBlockByNumberErr(fakes.FakeError)
 
-			_, err := blockChain.GetBlockByNumber(100)
+			_, err := blockChain.GetBlockByNumber(context.Background(), 100)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -77,7 +77,7 @@ var _ = Describe("Geth blockchain", func() {
 				blockNumber := int64(100)
 				mockClient.SetHeaderByNumberReturnHeader(&types.Header{Number: big.NewInt(blockNumber)})
 
-				_, err := blockChain.GetHeaderByNumber(blockNumber)
+				_, err := blockChain.GetHeaderByNumber(context.Background(), blockNumber)
 
 				Expect(err).NotTo(HaveOccurred())
 				mockClient.AssertHeaderByNumberCalledWith(context.Background(), big.NewInt(blockNumber))
@@ -86,18 +86,30 @@ var _ = Describe("Geth blockchain", func() {
 			It("returns err if ethClient returns err", func() {
 				mockClient.SetHeaderByNumberErr(fakes.FakeError)
 
-				_, err := blockChain.GetHeaderByNumber(100)
+				_, err := blockChain.GetHeaderByNumber(context.Background(), 100)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
 			})
 
 			It("fetches headers with multiple blocks", func() {
-				_, err := blockChain.GetHeadersByNumbers([]int64{100, 99})
+				_, err := blockChain.GetHeadersByNumbers(context.Background(), []int64{100, 99})
 
 				Expect(err).NotTo(HaveOccurred())
 				mockRpcClient.AssertBatchCalledWith("eth_getBlockByNumber", 2)
 			})
+
+			It("fetches headers in batches of at most the max batch size", func() {
+				var blockNumbers []int64
+				for i := int64(0); i < eth.MAX_BATCH_SIZE+50; i++ {
+					blockNumbers = append(blockNumbers, i)
+				}
+
+				_, err := blockChain.GetHeadersByNumbers(context.Background(), blockNumbers)
+
+				Expect(err).NotTo(HaveOccurred())
+				mockRpcClient.AssertBatchCalledWith("eth_getBlockByNumber", 50)
+			})
 		})
 
 		Describe("POA/Kovan", func() {
@@ -107,7 +119,7 @@ var _ = Describe("Geth blockchain", func() {
 				mockRpcClient.SetReturnPOAHeader(vulcCore.POAHeader{Number: &blockNumber})
 				blockChain = eth.NewBlockChain(mockClient, mockRpcClient, node, fakes.NewMockTransactionConverter())
 
-				_, err := blockChain.GetHeaderByNumber(100)
+				_, err := blockChain.GetHeaderByNumber(context.Background(), 100)
 
 				Expect(err).NotTo(HaveOccurred())
 				mockRpcClient.AssertCallContextCalledWith(context.Background(), &vulcCore.POAHeader{}, "eth_getBlockByNumber")
@@ -118,7 +130,7 @@ var _ = Describe("Geth blockchain", func() {
 				mockRpcClient.SetCallContextErr(fakes.FakeError)
 				blockChain = eth.NewBlockChain(mockClient, mockRpcClient, node, fakes.NewMockTransactionConverter())
 
-				_, err := blockChain.GetHeaderByNumber(100)
+				_, err := blockChain.GetHeaderByNumber(context.Background(), 100)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -128,7 +140,7 @@ var _ = Describe("Geth blockchain", func() {
 				node.NetworkID = vulcCore.KOVAN_NETWORK_ID
 				blockChain = eth.NewBlockChain(mockClient, mockRpcClient, node, fakes.NewMockTransactionConverter())
 
-				_, err := blockChain.GetHeaderByNumber(100)
+				_, err := blockChain.GetHeaderByNumber(context.Background(), 100)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(eth.ErrEmptyHeader))
@@ -139,7 +151,7 @@ var _ = Describe("Geth blockchain", func() {
 				blockNumber := hexutil.Big(*big.NewInt(100))
 				mockRpcClient.SetReturnPOAHeaders([]vulcCore.POAHeader{{Number: &blockNumber}})
 
-				_, err := blockChain.GetHeadersByNumbers([]int64{100, 99})
+				_, err := blockChain.GetHeadersByNumbers(context.Background(), []int64{100, 99})
 
 				Expect(err).NotTo(HaveOccurred())
 				mockRpcClient.AssertBatchCalledWith("eth_getBlockByNumber", 2)
@@ -154,7 +166,7 @@ var _ = Describe("Geth blockchain", func() {
 			startingBlockNumber := big.NewInt(1)
 			endingBlockNumber := big.NewInt(2)
 
-			_, err := blockChain.GetFullSyncLogs(contract, startingBlockNumber, endingBlockNumber)
+			_, err := blockChain.GetFullSyncLogs(context.Background(), contract, startingBlockNumber, endingBlockNumber)
 
 			Expect(err).NotTo(HaveOccurred())
 			expectedQuery := ethereum.FilterQuery{
@@ -171,7 +183,7 @@ var _ = Describe("Geth blockchain", func() {
 			startingBlockNumber := big.NewInt(1)
 			endingBlockNumber := big.NewInt(2)
 
-			_, err := blockChain.GetFullSyncLogs(contract, startingBlockNumber, endingBlockNumber)
+			_, err := blockChain.GetFullSyncLogs(context.Background(), contract, startingBlockNumber, endingBlockNumber)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -192,7 +204,7 @@ var _ = Describe("Geth blockchain", func() {
 				Topics:    [][]common.Hash{{topic}},
 			}
 
-			_, err := blockChain.GetEthLogsWithCustomQuery(query)
+			_, err := blockChain.GetEthLogsWithCustomQuery(context.Background(), query)
 
 			Expect(err).NotTo(HaveOccurred())
 			mockClient.AssertFilterLogsCalledWith(context.Background(), query)
@@ -210,7 +222,7 @@ var _ = Describe("Geth blockchain", func() {
 				Topics:    nil,
 			}
 
-			_, err := blockChain.GetEthLogsWithCustomQuery(query)
+			_, err := blockChain.GetEthLogsWithCustomQuery(context.Background(), query)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -219,26 +231,37 @@ var _ = Describe("Geth blockchain", func() {
 
 	Describe("getting transactions", func() {
 		It("fetches transaction for each hash", func() {
-			_, err := blockChain.GetTransactions([]common.Hash{{}, {}})
+			_, err := blockChain.GetTransactions(context.Background(), []common.Hash{{}, {}})
 
 			Expect(err).NotTo(HaveOccurred())
 			mockRpcClient.AssertBatchCalledWith("eth_getTransactionByHash", 2)
 		})
 
 		It("converts transaction indexes from hex to int", func() {
-			_, err := blockChain.GetTransactions([]common.Hash{{}, {}})
+			_, err := blockChain.GetTransactions(context.Background(), []common.Hash{{}, {}})
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(mockTransactionConverter.ConvertHeaderTransactionIndexToIntCalled).To(BeTrue())
 		})
 	})
 
+	Describe("getting transaction receipts", func() {
+		It("fetches receipt for each hash", func() {
+			receipts, err := blockChain.GetTransactionReceipts(context.Background(), []common.Hash{{}, {}})
+
+			Expect(err).NotTo(HaveOccurred())
+			mockRpcClient.AssertBatchCalledWith("eth_getTransactionReceipt", 2)
+			Expect(len(receipts)).To(Equal(2))
+			Expect(receipts[0].Status).To(Equal(1))
+		})
+	})
+
 	Describe("getting the most recent block number", func() {
 		It("fetches latest header from ethClient", func() {
 			blockNumber := int64(100)
 			mockClient.SetHeaderByNumberReturnHeader(&types.Header{Number: big.NewInt(blockNumber)})
 
-			result, err := blockChain.LastBlock()
+			result, err := blockChain.LastBlock(context.Background())
 			Expect(err).NotTo(HaveOccurred())
 
 			mockClient.AssertHeaderByNumberCalledWith(context.Background(), nil)
@@ -251,7 +274,7 @@ var _ = Describe("Geth blockchain", func() {
 			balance := big.NewInt(100000)
 			mockClient.SetBalanceAt(balance)
 
-			result, err := blockChain.GetAccountBalance(common.HexToAddress("0x40"), big.NewInt(100))
+			result, err := blockChain.GetAccountBalance(context.Background(), common.HexToAddress("0x40"), big.NewInt(100))
 			Expect(err).NotTo(HaveOccurred())
 
 			mockClient.AssertBalanceAtCalled(context.Background(), common.HexToAddress("0x40"), big.NewInt(100))
@@ -264,7 +287,7 @@ var _ = Describe("Geth blockchain", func() {
 			setErr := errors.New("testError")
 			mockClient.SetBalanceAtErr(setErr)
 
-			_, err := blockChain.GetAccountBalance(common.HexToAddress("0x40"), big.NewInt(100))
+			_, err := blockChain.GetAccountBalance(context.Background(), common.HexToAddress("0x40"), big.NewInt(100))
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(Equal(setErr))
 		})
@@ -0,0 +1,135 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cache
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// How long the chain head is assumed current when deciding whether a block is finalized
+const headRefreshInterval = 5 * time.Second
+
+// Config is read from the [cache] section of the config file
+type Config struct {
+	// Number of responses kept in memory
+	Size int
+	// Directory to also keep responses in on disk, so they're kept between runs; empty for memory only
+	Path string
+	// How far behind the chain head a block has to be for responses about it to be cached
+	Confirmations int64
+}
+
+// Cache stores responses for blocks at least confirmations blocks behind the chain head, which are assumed not to be
+// reorged. It's shared by the RPC and eth clients for a node. Keys are prefixed with namespace, so that responses for
+// different chains kept in the same store don't collide.
+type Cache struct {
+	store         Store
+	rpcClient     core.RpcClient
+	namespace     string
+	confirmations int64
+	mutex         sync.Mutex
+	head          int64
+	headFetched   time.Time
+}
+
+// NewCache creates a cache that reads the chain head with rpcClient
+func NewCache(store Store, rpcClient core.RpcClient, namespace string, confirmations int64) *Cache {
+	return &Cache{store: store, rpcClient: rpcClient, namespace: namespace, confirmations: confirmations}
+}
+
+// New creates the stores described by config, and a cache that reads the chain head with rpcClient. Responses are
+// namespaced by the genesis hash of the chain rpcClient is connected to.
+func New(config Config, rpcClient core.RpcClient) (*Cache, error) {
+	namespace, err := genesisHash(rpcClient)
+	if err != nil {
+		return nil, err
+	}
+	memory, err := NewMemoryStore(config.Size)
+	if err != nil {
+		return nil, err
+	}
+	if config.Path == "" {
+		return NewCache(memory, rpcClient, namespace, config.Confirmations), nil
+	}
+	disk, err := NewDiskStore(config.Path)
+	if err != nil {
+		return nil, err
+	}
+	return NewCache(NewTieredStore(memory, disk), rpcClient, namespace, config.Confirmations), nil
+}
+
+func genesisHash(rpcClient core.RpcClient) (string, error) {
+	var genesis struct {
+		Hash common.Hash `json:"hash"`
+	}
+	err := rpcClient.CallContext(context.Background(), &genesis, "eth_getBlockByNumber", "0x0", false)
+	if err != nil {
+		return "", err
+	}
+	return genesis.Hash.Hex(), nil
+}
+
+// Close closes the cache's store, if it needs closing
+func (cache *Cache) Close() error {
+	if closer, ok := cache.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (cache *Cache) get(method, key string) ([]byte, bool) {
+	value, ok := cache.store.Get(cache.namespace + ":" + key)
+	metrics.ObserveRPCCacheLookup(method, ok)
+	return value, ok
+}
+
+func (cache *Cache) put(key string, value []byte) {
+	cache.store.Put(cache.namespace+":"+key, value)
+}
+
+// Whether blockNumber is far enough behind the chain head to be cached. The head is only refreshed when a block
+// looks unfinalized, since the head only moves forward.
+func (cache *Cache) isFinalized(ctx context.Context, blockNumber int64) bool {
+	if blockNumber < 0 {
+		return false
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.head-blockNumber >= cache.confirmations {
+		return true
+	}
+	if time.Since(cache.headFetched) < headRefreshInterval {
+		return false
+	}
+	cache.headFetched = time.Now()
+	var head hexutil.Uint64
+	if err := cache.rpcClient.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+		logrus.Warnf("error getting chain head for RPC cache: %s", err.Error())
+		return false
+	}
+	cache.head = int64(head)
+	return cache.head-blockNumber >= cache.confirmations
+}
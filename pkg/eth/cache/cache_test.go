@@ -0,0 +1,333 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/makerdao/vulcanizedb/pkg/eth/cache"
+	"github.com/makerdao/vulcanizedb/pkg/eth/client"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+)
+
+// Answers calls with the responses set for each method, and counts the calls made for each method
+type nodeStub struct {
+	*fakes.MockRpcClient
+	head      uint64
+	responses map[string]interface{}
+	calls     map[string]int
+}
+
+func newNodeStub(head uint64) *nodeStub {
+	return &nodeStub{
+		MockRpcClient: fakes.NewMockRpcClient(),
+		head:          head,
+		responses:     map[string]interface{}{},
+		calls:         map[string]int{},
+	}
+}
+
+func (stub *nodeStub) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	stub.calls[method]++
+	response := stub.responses[method]
+	if method == "eth_blockNumber" {
+		response = hexutil.Uint64(stub.head)
+	}
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, result)
+}
+
+func (stub *nodeStub) BatchCallContext(ctx context.Context, batch []client.BatchElem) error {
+	for i, batchElem := range batch {
+		batch[i].Error = stub.CallContext(ctx, batchElem.Result, batchElem.Method, batchElem.Args...)
+	}
+	return nil
+}
+
+type ethStub struct {
+	*fakes.MockEthClient
+	header *types.Header
+	result []byte
+	calls  int
+}
+
+func (stub *ethStub) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	stub.calls++
+	return stub.header, nil
+}
+
+func (stub *ethStub) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	stub.calls++
+	return stub.result, nil
+}
+
+var _ = Describe("Cache", func() {
+	var (
+		ctx       context.Context
+		node      *nodeStub
+		store     *cache.MemoryStore
+		rpcClient cache.RpcClient
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		node = newNodeStub(100)
+		var err error
+		store, err = cache.NewMemoryStore(100)
+		Expect(err).NotTo(HaveOccurred())
+		rpcClient = cache.NewRpcClient(node, cache.NewCache(store, node, "0xgenesis", 10))
+	})
+
+	getBlock := func(number string) map[string]string {
+		var block map[string]string
+		Expect(rpcClient.CallContext(ctx, &block, "eth_getBlockByNumber", number, false)).To(Succeed())
+		return block
+	}
+
+	Describe("RpcClient", func() {
+		BeforeEach(func() {
+			node.responses["eth_getBlockByNumber"] = map[string]string{"hash": "0x1"}
+		})
+
+		It("answers repeated calls for finalized blocks from the cache", func() {
+			Expect(getBlock("0x5a")).To(Equal(map[string]string{"hash": "0x1"}))
+			Expect(getBlock("0x5a")).To(Equal(map[string]string{"hash": "0x1"}))
+
+			Expect(node.calls["eth_getBlockByNumber"]).To(Equal(1))
+		})
+
+		It("doesn't answer calls from responses cached for another chain", func() {
+			otherChain := cache.NewRpcClient(node, cache.NewCache(store, node, "0xother", 10))
+			var block map[string]string
+			Expect(otherChain.CallContext(ctx, &block, "eth_getBlockByNumber", "0x5a", false)).To(Succeed())
+
+			getBlock("0x5a")
+
+			Expect(node.calls["eth_getBlockByNumber"]).To(Equal(2))
+		})
+
+		It("doesn't cache blocks within the confirmation depth of the head", func() {
+			getBlock("0x5b")
+			getBlock("0x5b")
+
+			Expect(node.calls["eth_getBlockByNumber"]).To(Equal(2))
+		})
+
+		It("doesn't cache calls for block tags", func() {
+			getBlock("latest")
+			getBlock("latest")
+
+			Expect(node.calls["eth_getBlockByNumber"]).To(Equal(2))
+		})
+
+		It("doesn't cache null responses", func() {
+			node.responses["eth_getBlockByNumber"] = nil
+
+			Expect(getBlock("0x1")).To(BeNil())
+			Expect(getBlock("0x1")).To(BeNil())
+			Expect(node.calls["eth_getBlockByNumber"]).To(Equal(2))
+		})
+
+		It("passes methods that can't be cached through to the node", func() {
+			var head hexutil.Uint64
+			Expect(rpcClient.CallContext(ctx, &head, "eth_blockNumber")).To(Succeed())
+			Expect(rpcClient.CallContext(ctx, &head, "eth_blockNumber")).To(Succeed())
+
+			Expect(head).To(Equal(hexutil.Uint64(100)))
+			Expect(node.calls["eth_blockNumber"]).To(Equal(2))
+		})
+
+		It("caches transactions once the block they're included in is finalized", func() {
+			node.responses["eth_getTransactionByHash"] = map[string]string{"blockNumber": "0x5b"}
+			var transaction map[string]string
+			for i := 0; i < 2; i++ {
+				Expect(rpcClient.CallContext(ctx, &transaction, "eth_getTransactionByHash", common.HexToHash("0x2"))).
+					To(Succeed())
+			}
+			Expect(node.calls["eth_getTransactionByHash"]).To(Equal(2))
+
+			node.responses["eth_getTransactionByHash"] = map[string]string{"blockNumber": "0x5a"}
+			for i := 0; i < 2; i++ {
+				Expect(rpcClient.CallContext(ctx, &transaction, "eth_getTransactionByHash", common.HexToHash("0x3"))).
+					To(Succeed())
+			}
+			Expect(node.calls["eth_getTransactionByHash"]).To(Equal(3))
+			Expect(transaction).To(Equal(map[string]string{"blockNumber": "0x5a"}))
+		})
+
+		It("caches logs filtered by block hash", func() {
+			node.responses["eth_getLogs"] = []map[string]string{{"data": "0x"}}
+			filter := map[string]interface{}{"blockHash": common.HexToHash("0x4")}
+			var logs []map[string]string
+			for i := 0; i < 2; i++ {
+				Expect(rpcClient.CallContext(ctx, &logs, "eth_getLogs", filter)).To(Succeed())
+			}
+
+			Expect(logs).To(HaveLen(1))
+			Expect(node.calls["eth_getLogs"]).To(Equal(1))
+		})
+
+		It("answers batches from the cache and sends the rest to the node", func() {
+			getBlock("0x1")
+			results := make([]map[string]string, 2)
+			batch := []client.BatchElem{
+				{Method: "eth_getBlockByNumber", Args: []interface{}{"0x1", false}, Result: &results[0]},
+				{Method: "eth_getBlockByNumber", Args: []interface{}{"0x2", false}, Result: &results[1]},
+			}
+
+			Expect(rpcClient.BatchCallContext(ctx, batch)).To(Succeed())
+			Expect(rpcClient.BatchCallContext(ctx, batch)).To(Succeed())
+
+			Expect(results[0]).To(Equal(map[string]string{"hash": "0x1"}))
+			Expect(results[1]).To(Equal(map[string]string{"hash": "0x1"}))
+			Expect(node.calls["eth_getBlockByNumber"]).To(Equal(2))
+		})
+	})
+
+	Describe("EthClient", func() {
+		var (
+			eth       *ethStub
+			ethClient cache.EthClient
+		)
+
+		BeforeEach(func() {
+			eth = &ethStub{
+				MockEthClient: fakes.NewMockEthClient(),
+				header:        &types.Header{Number: big.NewInt(1), Extra: []byte{}},
+				result:        []byte{1, 2, 3},
+			}
+			ethClient = cache.NewEthClient(eth, cache.NewCache(store, node, "0xgenesis", 10))
+		})
+
+		It("caches headers for finalized blocks", func() {
+			for i := 0; i < 2; i++ {
+				header, err := ethClient.HeaderByNumber(ctx, big.NewInt(1))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(header.Hash()).To(Equal(eth.header.Hash()))
+			}
+
+			Expect(eth.calls).To(Equal(1))
+		})
+
+		It("doesn't cache the latest header", func() {
+			ethClient.HeaderByNumber(ctx, nil)
+			ethClient.HeaderByNumber(ctx, nil)
+
+			Expect(eth.calls).To(Equal(2))
+		})
+
+		It("caches contract calls at finalized blocks", func() {
+			msg := ethereum.CallMsg{Data: []byte{4}}
+			for i := 0; i < 2; i++ {
+				result, err := ethClient.CallContract(ctx, msg, big.NewInt(1))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal([]byte{1, 2, 3}))
+			}
+			Expect(eth.calls).To(Equal(1))
+
+			ethClient.CallContract(ctx, msg, big.NewInt(95))
+			ethClient.CallContract(ctx, msg, big.NewInt(95))
+			Expect(eth.calls).To(Equal(3))
+		})
+	})
+
+	Describe("stores", func() {
+		var path string
+
+		BeforeEach(func() {
+			var err error
+			path, err = ioutil.TempDir("", "rpc-cache")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(path)
+		})
+
+		It("evicts the least recently used responses from memory", func() {
+			memory, err := cache.NewMemoryStore(1)
+			Expect(err).NotTo(HaveOccurred())
+
+			memory.Put("a", []byte("1"))
+			memory.Put("b", []byte("2"))
+
+			_, ok := memory.Get("a")
+			Expect(ok).To(BeFalse())
+			value, ok := memory.Get("b")
+			Expect(ok).To(BeTrue())
+			Expect(value).To(Equal([]byte("2")))
+		})
+
+		It("keeps responses on disk between runs", func() {
+			disk, err := cache.NewDiskStore(path)
+			Expect(err).NotTo(HaveOccurred())
+			disk.Put("a", []byte("1"))
+			Expect(disk.Close()).To(Succeed())
+
+			disk, err = cache.NewDiskStore(path)
+			Expect(err).NotTo(HaveOccurred())
+			defer disk.Close()
+			value, ok := disk.Get("a")
+			Expect(ok).To(BeTrue())
+			Expect(value).To(Equal([]byte("1")))
+		})
+
+		It("closes the disk store when the cache is closed", func() {
+			disk, err := cache.NewDiskStore(path)
+			Expect(err).NotTo(HaveOccurred())
+			memory, err := cache.NewMemoryStore(1)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cache.NewCache(cache.NewTieredStore(memory, disk), node, "0xgenesis", 10).Close()).To(Succeed())
+
+			reopened, err := cache.NewDiskStore(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reopened.Close()).To(Succeed())
+		})
+
+		It("reads through memory to disk", func() {
+			disk, err := cache.NewDiskStore(path)
+			Expect(err).NotTo(HaveOccurred())
+			defer disk.Close()
+			disk.Put("a", []byte("1"))
+			memory, err := cache.NewMemoryStore(1)
+			Expect(err).NotTo(HaveOccurred())
+			tiered := cache.NewTieredStore(memory, disk)
+
+			value, ok := tiered.Get("a")
+			Expect(ok).To(BeTrue())
+			Expect(value).To(Equal([]byte("1")))
+			value, ok = memory.Get("a")
+			Expect(ok).To(BeTrue())
+			Expect(value).To(Equal([]byte("1")))
+		})
+	})
+})
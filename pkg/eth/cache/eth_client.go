@@ -0,0 +1,200 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+)
+
+// EthClient answers calls for finalized chain data from a Cache, and passes everything else to a core.EthClient.
+// Calls are labelled with the RPC method each one makes. Calls for the latest block are never cached.
+type EthClient struct {
+	client core.EthClient
+	cache  *Cache
+}
+
+func NewEthClient(ethClient core.EthClient, cache *Cache) EthClient {
+	return EthClient{client: ethClient, cache: cache}
+}
+
+func (ethClient EthClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	if number == nil {
+		return ethClient.client.BlockByNumber(ctx, number)
+	}
+	key := "eth:block:" + number.String()
+	if cached, ok := ethClient.cache.get("eth_getBlockByNumber", key); ok {
+		var block types.Block
+		if err := rlp.DecodeBytes(cached, &block); err == nil {
+			return &block, nil
+		}
+	}
+
+	block, err := ethClient.client.BlockByNumber(ctx, number)
+	if err != nil {
+		return block, err
+	}
+	if block != nil && ethClient.cache.isFinalized(ctx, number.Int64()) {
+		ethClient.putRLP(key, block)
+	}
+	return block, nil
+}
+
+func (ethClient EthClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if blockNumber == nil {
+		return ethClient.client.CallContract(ctx, msg, blockNumber)
+	}
+	encodedMsg, err := json.Marshal(msg)
+	if err != nil {
+		return ethClient.client.CallContract(ctx, msg, blockNumber)
+	}
+	key := "eth:call:" + blockNumber.String() + ":" + string(encodedMsg)
+	if cached, ok := ethClient.cache.get("eth_call", key); ok {
+		return cached, nil
+	}
+
+	result, err := ethClient.client.CallContract(ctx, msg, blockNumber)
+	if err != nil {
+		return result, err
+	}
+	if ethClient.cache.isFinalized(ctx, blockNumber.Int64()) {
+		ethClient.cache.put(key, result)
+	}
+	return result, nil
+}
+
+// FilterLogs caches logs filtered by block hash, or by a block range ending at a finalized block
+func (ethClient EthClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if q.BlockHash == nil && q.ToBlock == nil {
+		return ethClient.client.FilterLogs(ctx, q)
+	}
+	encodedQuery, err := json.Marshal(q)
+	if err != nil {
+		return ethClient.client.FilterLogs(ctx, q)
+	}
+	key := "eth:logs:" + string(encodedQuery)
+	if cached, ok := ethClient.cache.get("eth_getLogs", key); ok {
+		var logs []types.Log
+		if err := json.Unmarshal(cached, &logs); err == nil {
+			return logs, nil
+		}
+	}
+
+	logs, err := ethClient.client.FilterLogs(ctx, q)
+	if err != nil {
+		return logs, err
+	}
+	if q.BlockHash != nil || ethClient.cache.isFinalized(ctx, q.ToBlock.Int64()) {
+		ethClient.putJSON(key, logs)
+	}
+	return logs, nil
+}
+
+func (ethClient EthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		return ethClient.client.HeaderByNumber(ctx, number)
+	}
+	key := "eth:header:" + number.String()
+	if cached, ok := ethClient.cache.get("eth_getBlockByNumber", key); ok {
+		var header types.Header
+		if err := rlp.DecodeBytes(cached, &header); err == nil {
+			return &header, nil
+		}
+	}
+
+	header, err := ethClient.client.HeaderByNumber(ctx, number)
+	if err != nil {
+		return header, err
+	}
+	if header != nil && ethClient.cache.isFinalized(ctx, number.Int64()) {
+		ethClient.putRLP(key, header)
+	}
+	return header, nil
+}
+
+// TransactionSender is looked up by block hash, so it can always be cached
+func (ethClient EthClient) TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error) {
+	key := "eth:sender:" + block.Hex() + ":" + strconv.FormatUint(uint64(index), 10) + ":" + tx.Hash().Hex()
+	if cached, ok := ethClient.cache.get("eth_getTransactionByBlockHashAndIndex", key); ok {
+		return common.BytesToAddress(cached), nil
+	}
+
+	sender, err := ethClient.client.TransactionSender(ctx, tx, block, index)
+	if err != nil {
+		return sender, err
+	}
+	ethClient.cache.put(key, sender.Bytes())
+	return sender, nil
+}
+
+func (ethClient EthClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	key := "eth:receipt:" + txHash.Hex()
+	if cached, ok := ethClient.cache.get("eth_getTransactionReceipt", key); ok {
+		var receipt types.Receipt
+		if err := json.Unmarshal(cached, &receipt); err == nil {
+			return &receipt, nil
+		}
+	}
+
+	receipt, err := ethClient.client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return receipt, err
+	}
+	if receipt != nil && receipt.BlockNumber != nil && ethClient.cache.isFinalized(ctx, receipt.BlockNumber.Int64()) {
+		ethClient.putJSON(key, receipt)
+	}
+	return receipt, nil
+}
+
+func (ethClient EthClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	if blockNumber == nil {
+		return ethClient.client.BalanceAt(ctx, account, blockNumber)
+	}
+	key := "eth:balance:" + blockNumber.String() + ":" + account.Hex()
+	if cached, ok := ethClient.cache.get("eth_getBalance", key); ok {
+		return new(big.Int).SetBytes(cached), nil
+	}
+
+	balance, err := ethClient.client.BalanceAt(ctx, account, blockNumber)
+	if err != nil {
+		return balance, err
+	}
+	if balance != nil && ethClient.cache.isFinalized(ctx, blockNumber.Int64()) {
+		ethClient.cache.put(key, balance.Bytes())
+	}
+	return balance, nil
+}
+
+func (ethClient EthClient) putRLP(key string, value interface{}) {
+	if encoded, err := rlp.EncodeToBytes(value); err == nil {
+		ethClient.cache.put(key, encoded)
+	}
+}
+
+func (ethClient EthClient) putJSON(key string, value interface{}) {
+	if encoded, err := json.Marshal(value); err == nil {
+		ethClient.cache.put(key, encoded)
+	}
+}
@@ -0,0 +1,226 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/eth/client"
+)
+
+// A cacheRule decides whether the response to a call with args can be cached
+type cacheRule func(ctx context.Context, cache *Cache, args []interface{}, response json.RawMessage) bool
+
+// Rules for the RPC methods whose responses can be cached. Methods not listed here are never cached.
+var cacheRules = map[string]cacheRule{
+	"eth_getBlockByHash":                    always,
+	"eth_getTransactionByBlockHashAndIndex": always,
+	"eth_getUncleByBlockHashAndIndex":       always,
+	"eth_getBlockByNumber":                  blockArgFinalized(0),
+	"eth_getUncleByBlockNumberAndIndex":     blockArgFinalized(0),
+	"eth_call":                              blockArgFinalized(1),
+	"eth_getBalance":                        blockArgFinalized(1),
+	"eth_getCode":                           blockArgFinalized(1),
+	"eth_getStorageAt":                      blockArgFinalized(2),
+	"eth_getLogs":                           logFilterFinalized,
+	"eth_getTransactionByHash":              responseBlockFinalized,
+	"eth_getTransactionReceipt":             responseBlockFinalized,
+}
+
+// RpcClient answers calls for finalized chain data from a Cache, and passes everything else to a core.RpcClient
+type RpcClient struct {
+	client core.RpcClient
+	cache  *Cache
+}
+
+func NewRpcClient(rpcClient core.RpcClient, cache *Cache) RpcClient {
+	return RpcClient{client: rpcClient, cache: cache}
+}
+
+func (rpcClient RpcClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	rule, ok := cacheRules[method]
+	key := rpcKey(method, args)
+	if !ok || key == "" {
+		return rpcClient.client.CallContext(ctx, result, method, args...)
+	}
+	if cached, ok := rpcClient.cache.get(method, key); ok {
+		return unmarshalResult(cached, result)
+	}
+
+	var response json.RawMessage
+	err := rpcClient.client.CallContext(ctx, &response, method, args...)
+	if err != nil {
+		return err
+	}
+	if isCacheable(ctx, rpcClient.cache, rule, args, response) {
+		rpcClient.cache.put(key, response)
+	}
+	return unmarshalResult(response, result)
+}
+
+// BatchCallContext answers what it can from the cache and sends the rest of the batch to the node
+func (rpcClient RpcClient) BatchCallContext(ctx context.Context, batch []client.BatchElem) error {
+	var misses []int
+	var uncachedBatch []client.BatchElem
+	var keys = make([]string, len(batch))
+	var responses = make([]json.RawMessage, len(batch))
+	for i, batchElem := range batch {
+		if _, ok := cacheRules[batchElem.Method]; ok {
+			keys[i] = rpcKey(batchElem.Method, batchElem.Args)
+		}
+		if keys[i] != "" {
+			if cached, ok := rpcClient.cache.get(batchElem.Method, keys[i]); ok {
+				batch[i].Error = unmarshalResult(cached, batchElem.Result)
+				continue
+			}
+		}
+		misses = append(misses, i)
+		uncachedBatch = append(uncachedBatch, client.BatchElem{
+			Method: batchElem.Method,
+			Args:   batchElem.Args,
+			Result: &responses[i],
+		})
+	}
+	if len(uncachedBatch) == 0 {
+		return nil
+	}
+
+	err := rpcClient.client.BatchCallContext(ctx, uncachedBatch)
+	if err != nil {
+		return err
+	}
+	for j, i := range misses {
+		if uncachedBatch[j].Error != nil {
+			batch[i].Error = uncachedBatch[j].Error
+			continue
+		}
+		if keys[i] != "" && isCacheable(ctx, rpcClient.cache, cacheRules[batch[i].Method], batch[i].Args, responses[i]) {
+			rpcClient.cache.put(keys[i], responses[i])
+		}
+		batch[i].Error = unmarshalResult(responses[i], batch[i].Result)
+	}
+	return nil
+}
+
+func (rpcClient RpcClient) IpcPath() string {
+	return rpcClient.client.IpcPath()
+}
+
+func (rpcClient RpcClient) SupportedModules() (map[string]string, error) {
+	return rpcClient.client.SupportedModules()
+}
+
+func (rpcClient RpcClient) Subscribe(namespace string, payloadChan interface{}, args ...interface{}) (*rpc.ClientSubscription, error) {
+	return rpcClient.client.Subscribe(namespace, payloadChan, args...)
+}
+
+// Keys are empty for calls whose args can't be encoded, which are never cached
+func rpcKey(method string, args []interface{}) string {
+	encodedArgs, err := json.Marshal(args)
+	if err != nil {
+		return ""
+	}
+	return "rpc:" + method + ":" + string(encodedArgs)
+}
+
+// Null and missing responses are never cached, since the data may not have reached the node yet
+func isCacheable(ctx context.Context, cache *Cache, rule cacheRule, args []interface{}, response json.RawMessage) bool {
+	if len(response) == 0 || bytes.Equal(response, []byte("null")) {
+		return false
+	}
+	return rule(ctx, cache, args, response)
+}
+
+func unmarshalResult(response json.RawMessage, result interface{}) error {
+	if result == nil || len(response) == 0 {
+		return nil
+	}
+	return json.Unmarshal(response, result)
+}
+
+func always(context.Context, *Cache, []interface{}, json.RawMessage) bool {
+	return true
+}
+
+func blockArgFinalized(index int) cacheRule {
+	return func(ctx context.Context, cache *Cache, args []interface{}, _ json.RawMessage) bool {
+		if index >= len(args) {
+			return false
+		}
+		blockNumber, ok := toBlockNumber(args[index])
+		return ok && cache.isFinalized(ctx, blockNumber)
+	}
+}
+
+// Logs filtered by block hash are always cacheable, and logs filtered by block range are once the range ends at a
+// finalized block
+func logFilterFinalized(ctx context.Context, cache *Cache, args []interface{}, _ json.RawMessage) bool {
+	if len(args) != 1 {
+		return false
+	}
+	var filter struct {
+		BlockHash *common.Hash `json:"blockHash"`
+		ToBlock   interface{}  `json:"toBlock"`
+	}
+	if !remarshal(args[0], &filter) {
+		return false
+	}
+	if filter.BlockHash != nil {
+		return true
+	}
+	blockNumber, ok := toBlockNumber(filter.ToBlock)
+	return ok && cache.isFinalized(ctx, blockNumber)
+}
+
+// Transactions and receipts are looked up by transaction hash, so they're only cacheable once the block they were
+// included in is finalized
+func responseBlockFinalized(ctx context.Context, cache *Cache, _ []interface{}, response json.RawMessage) bool {
+	var included struct {
+		BlockNumber *hexutil.Big `json:"blockNumber"`
+	}
+	if err := json.Unmarshal(response, &included); err != nil || included.BlockNumber == nil {
+		return false
+	}
+	return cache.isFinalized(ctx, included.BlockNumber.ToInt().Int64())
+}
+
+// Block numbers are only accepted as hex quantities, since tags like "latest" refer to different blocks over time
+func toBlockNumber(arg interface{}) (int64, bool) {
+	var encoded string
+	if !remarshal(arg, &encoded) {
+		return 0, false
+	}
+	blockNumber, err := hexutil.DecodeUint64(encoded)
+	if err != nil {
+		return 0, false
+	}
+	return int64(blockNumber), true
+}
+
+func remarshal(value, target interface{}) bool {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(encoded, target) == nil
+}
@@ -0,0 +1,118 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cache
+
+import (
+	"github.com/hashicorp/golang-lru"
+	"github.com/sirupsen/logrus"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// Store holds cached responses by request key
+type Store interface {
+	Get(key string) ([]byte, bool)
+	Put(key string, value []byte)
+}
+
+// MemoryStore keeps the most recently used responses in memory
+type MemoryStore struct {
+	cache *lru.Cache
+}
+
+func NewMemoryStore(size int) (*MemoryStore, error) {
+	cache, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &MemoryStore{cache: cache}, nil
+}
+
+func (store *MemoryStore) Get(key string) ([]byte, bool) {
+	value, ok := store.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return value.([]byte), true
+}
+
+func (store *MemoryStore) Put(key string, value []byte) {
+	store.cache.Add(key, value)
+}
+
+// DiskStore keeps responses in a LevelDB database, so they're kept between runs
+type DiskStore struct {
+	db *leveldb.DB
+}
+
+func NewDiskStore(path string) (*DiskStore, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &DiskStore{db: db}, nil
+}
+
+func (store *DiskStore) Get(key string) ([]byte, bool) {
+	value, err := store.db.Get([]byte(key), nil)
+	if err != nil {
+		if err != leveldb.ErrNotFound {
+			logrus.Warnf("error reading RPC cache: %s", err.Error())
+		}
+		return nil, false
+	}
+	return value, true
+}
+
+func (store *DiskStore) Put(key string, value []byte) {
+	if err := store.db.Put([]byte(key), value, nil); err != nil {
+		logrus.Warnf("error writing RPC cache: %s", err.Error())
+	}
+}
+
+func (store *DiskStore) Close() error {
+	return store.db.Close()
+}
+
+// TieredStore checks a memory store before a disk store, and writes to both
+type TieredStore struct {
+	memory *MemoryStore
+	disk   *DiskStore
+}
+
+func NewTieredStore(memory *MemoryStore, disk *DiskStore) TieredStore {
+	return TieredStore{memory: memory, disk: disk}
+}
+
+func (store TieredStore) Get(key string) ([]byte, bool) {
+	if value, ok := store.memory.Get(key); ok {
+		return value, true
+	}
+	value, ok := store.disk.Get(key)
+	if ok {
+		store.memory.Put(key, value)
+	}
+	return value, ok
+}
+
+func (store TieredStore) Put(key string, value []byte) {
+	store.memory.Put(key, value)
+	store.disk.Put(key, value)
+}
+
+func (store TieredStore) Close() error {
+	return store.disk.Close()
+}
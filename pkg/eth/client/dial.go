@@ -0,0 +1,227 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+)
+
+// DialOptions configure how Dial connects to a node. Headers and TLSConfig are only used for HTTP and WebSocket
+// endpoints. Timeout bounds establishing a connection, and each request made over HTTP; zero means no timeout.
+type DialOptions struct {
+	Headers   map[string]string
+	Timeout   time.Duration
+	TLSConfig *tls.Config
+}
+
+// Dial connects to a node at an http(s):// or ws(s):// URL, or otherwise at an IPC path. ctx bounds connecting, and for
+// WebSocket endpoints also the lifetime of the connection, which must be cancelled for the client to close.
+func Dial(ctx context.Context, endpoint string, options DialOptions) (*rpc.Client, error) {
+	endpointURL, parseErr := url.Parse(endpoint)
+	if parseErr != nil {
+		return dialIPC(ctx, endpoint, options)
+	}
+	switch endpointURL.Scheme {
+	case "http", "https":
+		return dialHTTP(endpoint, options)
+	case "ws", "wss":
+		return dialWebsocket(ctx, endpoint, options)
+	default:
+		return dialIPC(ctx, endpoint, options)
+	}
+}
+
+func dialIPC(ctx context.Context, endpoint string, options DialOptions) (*rpc.Client, error) {
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+	return rpc.DialIPC(ctx, endpoint)
+}
+
+func dialHTTP(endpoint string, options DialOptions) (*rpc.Client, error) {
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: options.TLSConfig,
+	}
+	httpClient := &http.Client{
+		Timeout:   options.Timeout,
+		Transport: headerTransport{headers: options.Headers, base: transport},
+	}
+	return rpc.DialHTTPWithClient(endpoint, httpClient)
+}
+
+// headerTransport adds configured headers (e.g. Authorization) to every request
+type headerTransport struct {
+	headers map[string]string
+	base    http.RoundTripper
+}
+
+func (transport headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the request, so headers are set on a copy
+	withHeaders := new(http.Request)
+	*withHeaders = *req
+	withHeaders.Header = make(http.Header, len(req.Header)+len(transport.headers))
+	for key, values := range req.Header {
+		withHeaders.Header[key] = values
+	}
+	for key, value := range transport.headers {
+		withHeaders.Header.Set(key, value)
+	}
+	return transport.base.RoundTrip(withHeaders)
+}
+
+// The go-ethereum websocket client doesn't accept headers or TLS options, so the connection is established here and
+// handed to the RPC client as a stream of JSON messages.
+func dialWebsocket(ctx context.Context, endpoint string, options DialOptions) (*rpc.Client, error) {
+	header := make(http.Header)
+	for key, value := range options.Headers {
+		header.Set(key, value)
+	}
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: options.Timeout,
+		TLSClientConfig:  options.TLSConfig,
+	}
+	conn := &websocketConn{
+		ctx: ctx,
+		dial: func(ctx context.Context) (*websocket.Conn, error) {
+			wsConn, _, err := dialer.DialContext(ctx, endpoint, header)
+			return wsConn, err
+		},
+	}
+	// Connect up front so a bad endpoint is reported when dialing rather than on the first call
+	if _, err := conn.current(); err != nil {
+		return nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		conn.close()
+	}()
+	return rpc.DialIO(ctx, conn, conn)
+}
+
+var errWebsocketConnectionLost = errors.New("websocket connection lost")
+
+// websocketConn reads and writes JSON-RPC messages over a websocket connection, redialing after the connection fails.
+// The RPC client only reconnects (and restarts reading) after a write fails, so the first write after the connection is
+// lost fails, and the next one establishes a new connection.
+type websocketConn struct {
+	ctx    context.Context
+	dial   func(ctx context.Context) (*websocket.Conn, error)
+	mutex  sync.Mutex
+	conn   *websocket.Conn
+	lost   bool
+	reader io.Reader
+}
+
+func (wc *websocketConn) Read(p []byte) (int, error) {
+	for {
+		if wc.reader == nil {
+			conn, dialErr := wc.current()
+			if dialErr != nil {
+				return 0, dialErr
+			}
+			_, reader, readErr := conn.NextReader()
+			if readErr != nil {
+				wc.reset(conn, true)
+				return 0, readErr
+			}
+			wc.reader = reader
+		}
+		n, err := wc.reader.Read(p)
+		if err == io.EOF {
+			wc.reader = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (wc *websocketConn) Write(p []byte) (int, error) {
+	wc.mutex.Lock()
+	lost := wc.lost
+	wc.lost = false
+	wc.mutex.Unlock()
+	if lost {
+		return 0, errWebsocketConnectionLost
+	}
+
+	conn, dialErr := wc.current()
+	if dialErr != nil {
+		return 0, dialErr
+	}
+	wc.mutex.Lock()
+	defer wc.mutex.Unlock()
+	if writeErr := conn.WriteMessage(websocket.TextMessage, p); writeErr != nil {
+		wc.resetLocked(conn, false)
+		return 0, writeErr
+	}
+	return len(p), nil
+}
+
+func (wc *websocketConn) current() (*websocket.Conn, error) {
+	wc.mutex.Lock()
+	defer wc.mutex.Unlock()
+	if wc.ctx.Err() != nil {
+		return nil, wc.ctx.Err()
+	}
+	if wc.conn == nil {
+		conn, err := wc.dial(wc.ctx)
+		if err != nil {
+			return nil, err
+		}
+		wc.conn = conn
+	}
+	return wc.conn, nil
+}
+
+func (wc *websocketConn) close() {
+	wc.mutex.Lock()
+	defer wc.mutex.Unlock()
+	if wc.conn != nil {
+		wc.resetLocked(wc.conn, false)
+	}
+}
+
+func (wc *websocketConn) reset(conn *websocket.Conn, lost bool) {
+	wc.mutex.Lock()
+	defer wc.mutex.Unlock()
+	wc.resetLocked(conn, lost)
+}
+
+func (wc *websocketConn) resetLocked(conn *websocket.Conn, lost bool) {
+	if wc.conn == conn {
+		wc.conn.Close()
+		wc.conn = nil
+		wc.lost = lost
+	}
+}
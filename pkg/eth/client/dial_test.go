@@ -0,0 +1,146 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/makerdao/vulcanizedb/pkg/eth/client"
+)
+
+type web3API struct{}
+
+func (web3API) ClientVersion() string {
+	return "Geth/v1.9.0"
+}
+
+var _ = Describe("Dial", func() {
+	var (
+		ctx           context.Context
+		cancel        context.CancelFunc
+		rpcServer     *rpc.Server
+		handler       http.Handler
+		server        *httptest.Server
+		receivedAuth  chan string
+		hijackedConns chan net.Conn
+		authorization = "Bearer token"
+		options       = client.DialOptions{
+			Headers: map[string]string{"Authorization": authorization},
+			Timeout: time.Second,
+		}
+	)
+
+	newRPCServer := func() *rpc.Server {
+		rpcServer := rpc.NewServer()
+		Expect(rpcServer.RegisterName("web3", web3API{})).To(Succeed())
+		return rpcServer
+	}
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		rpcServer = newRPCServer()
+		receivedAuth = make(chan string, 10)
+		hijackedConns = make(chan net.Conn, 10)
+		server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			receivedAuth <- r.Header.Get("Authorization")
+			handler.ServeHTTP(w, r)
+		}))
+		server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+			if state == http.StateHijacked {
+				hijackedConns <- conn
+			}
+		}
+		server.Start()
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+		rpcServer.Stop()
+	})
+
+	wsURL := func() string {
+		return "ws" + strings.TrimPrefix(server.URL, "http")
+	}
+
+	It("calls HTTP endpoints with the configured headers", func() {
+		handler = rpcServer
+
+		rpcClient, err := client.Dial(ctx, server.URL, options)
+		Expect(err).NotTo(HaveOccurred())
+		defer rpcClient.Close()
+
+		var version string
+		Expect(rpcClient.CallContext(ctx, &version, "web3_clientVersion")).To(Succeed())
+		Expect(version).To(Equal("Geth/v1.9.0"))
+		Expect(<-receivedAuth).To(Equal(authorization))
+	})
+
+	It("calls WebSocket endpoints with the configured headers", func() {
+		handler = rpcServer.WebsocketHandler([]string{"*"})
+
+		rpcClient, err := client.Dial(ctx, wsURL(), options)
+		Expect(err).NotTo(HaveOccurred())
+		defer rpcClient.Close()
+		defer cancel()
+
+		var version string
+		Expect(rpcClient.CallContext(ctx, &version, "web3_clientVersion")).To(Succeed())
+		Expect(version).To(Equal("Geth/v1.9.0"))
+		Expect(<-receivedAuth).To(Equal(authorization))
+	})
+
+	It("reconnects to WebSocket endpoints after the connection is lost", func() {
+		handler = rpcServer.WebsocketHandler([]string{"*"})
+		rpcClient, err := client.Dial(ctx, wsURL(), options)
+		Expect(err).NotTo(HaveOccurred())
+		defer rpcClient.Close()
+		defer cancel()
+
+		Expect((<-hijackedConns).Close()).To(Succeed())
+
+		// A call written just before the lost connection is noticed never gets a response, so calls need a timeout
+		var version string
+		Eventually(func() error {
+			callCtx, cancelCall := context.WithTimeout(ctx, 100*time.Millisecond)
+			defer cancelCall()
+			return rpcClient.CallContext(callCtx, &version, "web3_clientVersion")
+		}, 2*time.Second).Should(Succeed())
+		Expect(version).To(Equal("Geth/v1.9.0"))
+	})
+
+	It("returns an error if the WebSocket handshake fails", func() {
+		handler = http.NotFoundHandler()
+
+		_, err := client.Dial(ctx, wsURL(), options)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an error if the IPC endpoint doesn't exist", func() {
+		_, err := client.Dial(ctx, "/nonexistent/geth.ipc", options)
+		Expect(err).To(HaveOccurred())
+	})
+})
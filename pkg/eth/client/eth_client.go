@@ -22,7 +22,9 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
 	"math/big"
+	"time"
 )
 
 type EthClient struct {
@@ -34,19 +36,31 @@ func NewEthClient(client *ethclient.Client) EthClient {
 }
 
 func (client EthClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
-	return client.client.BlockByNumber(ctx, number)
+	start := time.Now()
+	result, err := client.client.BlockByNumber(ctx, number)
+	metrics.ObserveRPCCall("eth_getBlockByNumber", start, err)
+	return result, err
 }
 
 func (client EthClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
-	return client.client.CallContract(ctx, msg, blockNumber)
+	start := time.Now()
+	result, err := client.client.CallContract(ctx, msg, blockNumber)
+	metrics.ObserveRPCCall("eth_call", start, err)
+	return result, err
 }
 
 func (client EthClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
-	return client.client.FilterLogs(ctx, q)
+	start := time.Now()
+	result, err := client.client.FilterLogs(ctx, q)
+	metrics.ObserveRPCCall("eth_getLogs", start, err)
+	return result, err
 }
 
 func (client EthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
-	return client.client.HeaderByNumber(ctx, number)
+	start := time.Now()
+	result, err := client.client.HeaderByNumber(ctx, number)
+	metrics.ObserveRPCCall("eth_getBlockByNumber", start, err)
+	return result, err
 }
 
 func (client EthClient) TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error) {
@@ -54,9 +68,15 @@ func (client EthClient) TransactionSender(ctx context.Context, tx *types.Transac
 }
 
 func (client EthClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
-	return client.client.TransactionReceipt(ctx, txHash)
+	start := time.Now()
+	result, err := client.client.TransactionReceipt(ctx, txHash)
+	metrics.ObserveRPCCall("eth_getTransactionReceipt", start, err)
+	return result, err
 }
 
 func (client EthClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
-	return client.client.BalanceAt(ctx, account, blockNumber)
+	start := time.Now()
+	result, err := client.client.BalanceAt(ctx, account, blockNumber)
+	metrics.ObserveRPCCall("eth_getBalance", start, err)
+	return result, err
 }
@@ -20,8 +20,10 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"time"
 
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
 )
 
 type RpcClient struct {
@@ -44,14 +46,18 @@ func NewRpcClient(client *rpc.Client, ipcPath string) RpcClient {
 }
 
 func (client RpcClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	start := time.Now()
+	var err error
 	//If an empty interface (or other nil object) is passed to CallContext, when the JSONRPC message is created the params will
 	//be interpreted as [null]. This seems to work fine for most of the ethereum clients (which presumably ignore a null parameter.
 	//Ganache however does not ignore it, and throws an 'Incorrect number of arguments' error.
 	if args == nil {
-		return client.client.CallContext(ctx, result, method)
+		err = client.client.CallContext(ctx, result, method)
 	} else {
-		return client.client.CallContext(ctx, result, method, args...)
+		err = client.client.CallContext(ctx, result, method, args...)
 	}
+	metrics.ObserveRPCCall(method, start, err)
+	return err
 }
 
 func (client RpcClient) IpcPath() string {
@@ -62,8 +68,9 @@ func (client RpcClient) SupportedModules() (map[string]string, error) {
 	return client.client.SupportedModules()
 }
 
-func (client RpcClient) BatchCall(batch []BatchElem) error {
+func (client RpcClient) BatchCallContext(ctx context.Context, batch []BatchElem) error {
 	var rpcBatch []rpc.BatchElem
+	var methods []string
 	for _, batchElem := range batch {
 		var newBatchElem = rpc.BatchElem{
 			Result: batchElem.Result,
@@ -73,8 +80,12 @@ func (client RpcClient) BatchCall(batch []BatchElem) error {
 		}
 
 		rpcBatch = append(rpcBatch, newBatchElem)
+		methods = append(methods, batchElem.Method)
 	}
-	return client.client.BatchCall(rpcBatch)
+	start := time.Now()
+	err := client.client.BatchCallContext(ctx, rpcBatch)
+	metrics.ObserveRPCBatch(methods, start, err)
+	return err
 }
 
 // Subscribe subscribes to an rpc "namespace_subscribe" subscription with the given channel
@@ -29,7 +29,7 @@ var (
 	ErrInvalidStateAttribute = errors.New("invalid state attribute")
 )
 
-func (blockChain *BlockChain) FetchContractData(abiJSON string, address string, method string, methodArgs []interface{}, result interface{}, blockNumber int64) error {
+func (blockChain *BlockChain) FetchContractData(ctx context.Context, abiJSON string, address string, method string, methodArgs []interface{}, result interface{}, blockNumber int64) error {
 	parsed, err := ParseAbi(abiJSON)
 	if err != nil {
 		return err
@@ -47,15 +47,15 @@ func (blockChain *BlockChain) FetchContractData(abiJSON string, address string,
 	if blockNumber > 0 {
 		bn = big.NewInt(blockNumber)
 	}
-	output, err := blockChain.callContract(address, input, bn)
+	output, err := blockChain.callContract(ctx, address, input, bn)
 	if err != nil {
 		return err
 	}
 	return parsed.Unpack(result, method, output)
 }
 
-func (blockChain *BlockChain) callContract(contractHash string, input []byte, blockNumber *big.Int) ([]byte, error) {
+func (blockChain *BlockChain) callContract(ctx context.Context, contractHash string, input []byte, blockNumber *big.Int) ([]byte, error) {
 	to := common.HexToAddress(contractHash)
 	msg := ethereum.CallMsg{To: &to, Data: input}
-	return blockChain.ethClient.CallContract(context.Background(), msg, blockNumber)
+	return blockChain.ethClient.CallContract(ctx, msg, blockNumber)
 }
@@ -49,6 +49,7 @@ var _ = Describe("Block header converter", func() {
 
 		Expect(coreHeader.BlockNumber).To(Equal(gethHeader.Number.Int64()))
 		Expect(coreHeader.Hash).To(Equal(hash))
+		Expect(coreHeader.ParentHash).To(Equal(gethHeader.ParentHash.Hex()))
 		Expect(coreHeader.Timestamp).To(Equal(strconv.FormatUint(gethHeader.Time, 10)))
 	})
 
@@ -0,0 +1,365 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/eth/client"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrNoEndpoints = errors.New("no RPC endpoints available")
+
+// Endpoint is a node that requests can be sent to. Name identifies it in logs, without any credentials in its URL.
+type Endpoint struct {
+	Name      string
+	RpcClient core.RpcClient
+	EthClient core.EthClient
+}
+
+func NewEndpoint(rawURL string, rpcClient core.RpcClient, ethClient core.EthClient) Endpoint {
+	name := rawURL
+	if endpointURL, err := url.Parse(rawURL); err == nil && endpointURL.Host != "" {
+		name = endpointURL.Scheme + "://" + endpointURL.Host
+	}
+	return Endpoint{Name: name, RpcClient: rpcClient, EthClient: ethClient}
+}
+
+type endpointState struct {
+	Endpoint
+	healthy  bool
+	verified bool
+	refused  bool
+	head     int64
+}
+
+// Client sends requests to one of several endpoints, implementing both core.RpcClient and core.EthClient. Requests go
+// to the first healthy endpoint (in the order given) and fail over to the next if the endpoint can't be reached; batch
+// calls are spread across healthy endpoints. Endpoints are healthy until CheckHealth finds otherwise, and are only used
+// while unhealthy if no endpoint is healthy. Endpoints on a different chain to the node are never used.
+type Client struct {
+	endpoints  []*endpointState
+	maxHeadLag int64
+	mutex      sync.RWMutex
+	nextBatch  uint64
+}
+
+func NewClient(endpoints []Endpoint, maxHeadLag int64) *Client {
+	var states []*endpointState
+	for _, endpoint := range endpoints {
+		states = append(states, &endpointState{Endpoint: endpoint, healthy: true})
+	}
+	return &Client{endpoints: states, maxHeadLag: maxHeadLag}
+}
+
+// MonitorHealth checks the endpoints' health every interval until ctx is cancelled
+func (client *Client) MonitorHealth(ctx context.Context, node core.Node, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			checkCtx, cancel := context.WithTimeout(ctx, interval)
+			client.CheckHealth(checkCtx, node)
+			cancel()
+		}
+	}
+}
+
+// CheckHealth marks endpoints unhealthy if they can't be reached or their chain head lags the highest head by more than
+// maxHeadLag blocks, and refuses endpoints whose genesis block or network ID don't match the node's.
+func (client *Client) CheckHealth(ctx context.Context, node core.Node) {
+	type result struct {
+		head     int64
+		verified bool
+		err      error
+	}
+	results := make([]result, len(client.endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range client.endpoints {
+		client.mutex.RLock()
+		refused, verified := endpoint.refused, endpoint.verified
+		client.mutex.RUnlock()
+		if refused {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, endpoint *endpointState) {
+			defer wg.Done()
+			if !verified {
+				if verifyErr := verifyChain(ctx, endpoint.RpcClient, node); verifyErr != nil {
+					results[i] = result{err: verifyErr}
+					return
+				}
+			}
+			head, headErr := getHead(ctx, endpoint.RpcClient)
+			results[i] = result{head: head, verified: true, err: headErr}
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	var maxHead int64
+	for _, r := range results {
+		if r.err == nil && r.head > maxHead {
+			maxHead = r.head
+		}
+	}
+
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	for i, endpoint := range client.endpoints {
+		if endpoint.refused {
+			continue
+		}
+		r := results[i]
+		endpoint.verified = endpoint.verified || r.verified
+		if chainErr, ok := r.err.(chainMismatchError); ok {
+			logrus.Errorf("refusing RPC endpoint %s: %s", endpoint.Name, chainErr.Error())
+			endpoint.refused = true
+			endpoint.healthy = false
+			continue
+		}
+		err := r.err
+		if err == nil && maxHead-r.head > client.maxHeadLag {
+			err = fmt.Errorf("chain head %d is more than %d blocks behind %d", r.head, client.maxHeadLag, maxHead)
+		}
+		endpoint.head = r.head
+		client.setHealthLocked(endpoint, err)
+	}
+}
+
+type chainMismatchError struct {
+	msg string
+}
+
+func (e chainMismatchError) Error() string {
+	return e.msg
+}
+
+func verifyChain(ctx context.Context, rpcClient core.RpcClient, node core.Node) error {
+	var version string
+	if err := rpcClient.CallContext(ctx, &version, "net_version"); err != nil {
+		return err
+	}
+	networkID, _ := strconv.ParseFloat(version, 64)
+	if networkID != node.NetworkID {
+		return chainMismatchError{fmt.Sprintf("network ID %s does not match %v", version, node.NetworkID)}
+	}
+	var genesis *types.Header
+	if err := rpcClient.CallContext(ctx, &genesis, "eth_getBlockByNumber", "0x0", false); err != nil {
+		return err
+	}
+	if genesis == nil {
+		return errors.New("genesis block not found")
+	}
+	if genesis.Hash().Hex() != node.GenesisBlock {
+		return chainMismatchError{fmt.Sprintf("genesis block %s does not match %s", genesis.Hash().Hex(), node.GenesisBlock)}
+	}
+	return nil
+}
+
+func getHead(ctx context.Context, rpcClient core.RpcClient) (int64, error) {
+	var head hexutil.Uint64
+	err := rpcClient.CallContext(ctx, &head, "eth_blockNumber")
+	return int64(head), err
+}
+
+func (client *Client) setHealthLocked(endpoint *endpointState, err error) {
+	if err != nil && endpoint.healthy {
+		logrus.Warnf("RPC endpoint %s is unhealthy: %s", endpoint.Name, err.Error())
+	}
+	if err == nil && !endpoint.healthy {
+		logrus.Infof("RPC endpoint %s is healthy", endpoint.Name)
+	}
+	endpoint.healthy = err == nil
+}
+
+// Returns the healthy endpoints in order starting from the one at offset, or the unhealthy ones if none are healthy
+func (client *Client) candidates(offset uint64) []*endpointState {
+	client.mutex.RLock()
+	defer client.mutex.RUnlock()
+	var healthy, unhealthy []*endpointState
+	for i := range client.endpoints {
+		endpoint := client.endpoints[(offset+uint64(i))%uint64(len(client.endpoints))]
+		if endpoint.refused {
+			continue
+		}
+		if endpoint.healthy {
+			healthy = append(healthy, endpoint)
+		} else {
+			unhealthy = append(unhealthy, endpoint)
+		}
+	}
+	if len(healthy) > 0 {
+		return healthy
+	}
+	return unhealthy
+}
+
+// Calls each candidate endpoint in turn until one can be reached. Errors returned by the node itself (e.g. a reverted
+// eth_call) don't cause a failover.
+func (client *Client) do(ctx context.Context, offset uint64, call func(endpoint *endpointState) error) error {
+	err := ErrNoEndpoints
+	for _, endpoint := range client.candidates(offset) {
+		err = call(endpoint)
+		if err == nil || !isEndpointError(ctx, err) {
+			return err
+		}
+		client.mutex.Lock()
+		client.setHealthLocked(endpoint, err)
+		client.mutex.Unlock()
+	}
+	return err
+}
+
+func isEndpointError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || err == ethereum.NotFound {
+		return false
+	}
+	_, isNodeError := err.(rpc.Error)
+	return !isNodeError
+}
+
+func (client *Client) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return client.do(ctx, 0, func(endpoint *endpointState) error {
+		return endpoint.RpcClient.CallContext(ctx, result, method, args...)
+	})
+}
+
+func (client *Client) BatchCallContext(ctx context.Context, batch []client.BatchElem) error {
+	offset := atomic.AddUint64(&client.nextBatch, 1) - 1
+	return client.do(ctx, offset, func(endpoint *endpointState) error {
+		return endpoint.RpcClient.BatchCallContext(ctx, batch)
+	})
+}
+
+// IpcPath returns the name of the endpoint requests are currently sent to
+func (client *Client) IpcPath() string {
+	candidates := client.candidates(0)
+	if len(candidates) == 0 {
+		return ""
+	}
+	return candidates[0].Name
+}
+
+func (client *Client) SupportedModules() (map[string]string, error) {
+	var modules map[string]string
+	err := client.do(context.Background(), 0, func(endpoint *endpointState) error {
+		var err error
+		modules, err = endpoint.RpcClient.SupportedModules()
+		return err
+	})
+	return modules, err
+}
+
+// Subscribe subscribes on the first endpoint that can be reached; the subscription isn't moved if that endpoint fails
+func (client *Client) Subscribe(namespace string, payloadChan interface{}, args ...interface{}) (*rpc.ClientSubscription, error) {
+	var subscription *rpc.ClientSubscription
+	err := client.do(context.Background(), 0, func(endpoint *endpointState) error {
+		var err error
+		subscription, err = endpoint.RpcClient.Subscribe(namespace, payloadChan, args...)
+		return err
+	})
+	return subscription, err
+}
+
+func (client *Client) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	var block *types.Block
+	err := client.do(ctx, 0, func(endpoint *endpointState) error {
+		var err error
+		block, err = endpoint.EthClient.BlockByNumber(ctx, number)
+		return err
+	})
+	return block, err
+}
+
+func (client *Client) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	var result []byte
+	err := client.do(ctx, 0, func(endpoint *endpointState) error {
+		var err error
+		result, err = endpoint.EthClient.CallContract(ctx, msg, blockNumber)
+		return err
+	})
+	return result, err
+}
+
+func (client *Client) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	err := client.do(ctx, 0, func(endpoint *endpointState) error {
+		var err error
+		logs, err = endpoint.EthClient.FilterLogs(ctx, q)
+		return err
+	})
+	return logs, err
+}
+
+func (client *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	var header *types.Header
+	err := client.do(ctx, 0, func(endpoint *endpointState) error {
+		var err error
+		header, err = endpoint.EthClient.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+func (client *Client) TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error) {
+	var sender common.Address
+	err := client.do(ctx, 0, func(endpoint *endpointState) error {
+		var err error
+		sender, err = endpoint.EthClient.TransactionSender(ctx, tx, block, index)
+		return err
+	})
+	return sender, err
+}
+
+func (client *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	var receipt *types.Receipt
+	err := client.do(ctx, 0, func(endpoint *endpointState) error {
+		var err error
+		receipt, err = endpoint.EthClient.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+func (client *Client) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	var balance *big.Int
+	err := client.do(ctx, 0, func(endpoint *endpointState) error {
+		var err error
+		balance, err = endpoint.EthClient.BalanceAt(ctx, account, blockNumber)
+		return err
+	})
+	return balance, err
+}
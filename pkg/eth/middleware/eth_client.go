@@ -0,0 +1,108 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package middleware
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+)
+
+// EthClient applies a Policy to calls made with a core.EthClient. Its calls are all reads, so they're all retried, and
+// they're timed out and rate limited by the RPC method each one makes.
+type EthClient struct {
+	client core.EthClient
+	policy *Policy
+}
+
+func NewEthClient(ethClient core.EthClient, policy *Policy) EthClient {
+	return EthClient{client: ethClient, policy: policy}
+}
+
+func (ethClient EthClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	var block *types.Block
+	err := ethClient.policy.do(ctx, "eth_getBlockByNumber", 1, true, func(ctx context.Context) error {
+		var err error
+		block, err = ethClient.client.BlockByNumber(ctx, number)
+		return err
+	})
+	return block, err
+}
+
+func (ethClient EthClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	var result []byte
+	err := ethClient.policy.do(ctx, "eth_call", 1, true, func(ctx context.Context) error {
+		var err error
+		result, err = ethClient.client.CallContract(ctx, msg, blockNumber)
+		return err
+	})
+	return result, err
+}
+
+func (ethClient EthClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	err := ethClient.policy.do(ctx, "eth_getLogs", 1, true, func(ctx context.Context) error {
+		var err error
+		logs, err = ethClient.client.FilterLogs(ctx, q)
+		return err
+	})
+	return logs, err
+}
+
+func (ethClient EthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	var header *types.Header
+	err := ethClient.policy.do(ctx, "eth_getBlockByNumber", 1, true, func(ctx context.Context) error {
+		var err error
+		header, err = ethClient.client.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+func (ethClient EthClient) TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error) {
+	var sender common.Address
+	err := ethClient.policy.do(ctx, "eth_getTransactionByBlockHashAndIndex", 1, true, func(ctx context.Context) error {
+		var err error
+		sender, err = ethClient.client.TransactionSender(ctx, tx, block, index)
+		return err
+	})
+	return sender, err
+}
+
+func (ethClient EthClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	var receipt *types.Receipt
+	err := ethClient.policy.do(ctx, "eth_getTransactionReceipt", 1, true, func(ctx context.Context) error {
+		var err error
+		receipt, err = ethClient.client.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+func (ethClient EthClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	var balance *big.Int
+	err := ethClient.policy.do(ctx, "eth_getBalance", 1, true, func(ctx context.Context) error {
+		var err error
+		balance, err = ethClient.client.BalanceAt(ctx, account, blockNumber)
+		return err
+	})
+	return balance, err
+}
@@ -0,0 +1,270 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/makerdao/vulcanizedb/pkg/eth/client"
+	"github.com/makerdao/vulcanizedb/pkg/eth/middleware"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+)
+
+var errTransient = errors.New("connection reset")
+
+type nodeError struct {
+	code    int
+	message string
+}
+
+func (e nodeError) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+	return "node error"
+}
+
+func (e nodeError) ErrorCode() int {
+	return e.code
+}
+
+// Fails with each of errs in turn, then succeeds. If block is set, the first call waits for its context to be done.
+type flakyRpcClient struct {
+	*fakes.MockRpcClient
+	errs      []error
+	block     bool
+	calls     int
+	deadlines []bool
+}
+
+func (c *flakyRpcClient) next(ctx context.Context) error {
+	c.calls++
+	_, hasDeadline := ctx.Deadline()
+	c.deadlines = append(c.deadlines, hasDeadline)
+	if c.block && c.calls == 1 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if c.calls <= len(c.errs) {
+		return c.errs[c.calls-1]
+	}
+	return nil
+}
+
+func (c *flakyRpcClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return c.next(ctx)
+}
+
+func (c *flakyRpcClient) BatchCallContext(ctx context.Context, batch []client.BatchElem) error {
+	return c.next(ctx)
+}
+
+type flakyEthClient struct {
+	*fakes.MockEthClient
+	errs  []error
+	calls int
+}
+
+func (c *flakyEthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.calls++
+	if c.calls <= len(c.errs) {
+		return nil, c.errs[c.calls-1]
+	}
+	return &types.Header{Number: number}, nil
+}
+
+var _ = Describe("Middleware", func() {
+	var (
+		ctx     context.Context
+		config  middleware.Config
+		flaky   *flakyRpcClient
+		ethStub *flakyEthClient
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		config = middleware.Config{MaxRetries: 3, RetryBackoff: time.Millisecond, MaxRetryBackoff: 2 * time.Millisecond}
+		flaky = &flakyRpcClient{MockRpcClient: fakes.NewMockRpcClient()}
+		ethStub = &flakyEthClient{MockEthClient: fakes.NewMockEthClient()}
+	})
+
+	call := func() error {
+		rpcClient := middleware.NewRpcClient(flaky, middleware.NewPolicy(config))
+		var result string
+		return rpcClient.CallContext(ctx, &result, "eth_blockNumber")
+	}
+
+	Describe("retries", func() {
+		It("retries transient errors", func() {
+			flaky.errs = []error{errTransient, errTransient}
+
+			Expect(call()).To(Succeed())
+			Expect(flaky.calls).To(Equal(3))
+		})
+
+		It("returns the last error after MaxRetries retries", func() {
+			flaky.errs = []error{errTransient, errTransient, errTransient, errTransient, errTransient}
+
+			Expect(call()).To(MatchError(errTransient))
+			Expect(flaky.calls).To(Equal(4))
+		})
+
+		It("doesn't retry errors returned by the node", func() {
+			flaky.errs = []error{nodeError{code: -32000}}
+
+			Expect(call()).To(MatchError(nodeError{code: -32000}))
+			Expect(flaky.calls).To(Equal(1))
+		})
+
+		It("retries rate limit errors returned by the node", func() {
+			flaky.errs = []error{nodeError{code: -32005}}
+
+			Expect(call()).To(Succeed())
+			Expect(flaky.calls).To(Equal(2))
+		})
+
+		It("doesn't retry log queries the node rejects for matching too many results", func() {
+			tooManyResultsErr := nodeError{code: -32005, message: "query returned more than 10000 results"}
+			flaky.errs = []error{tooManyResultsErr}
+
+			Expect(call()).To(MatchError(tooManyResultsErr))
+			Expect(flaky.calls).To(Equal(1))
+		})
+
+		It("doesn't retry non-idempotent methods", func() {
+			flaky.errs = []error{errTransient}
+			rpcClient := middleware.NewRpcClient(flaky, middleware.NewPolicy(config))
+
+			err := rpcClient.CallContext(ctx, nil, "eth_sendRawTransaction", "0x00")
+
+			Expect(err).To(MatchError(errTransient))
+			Expect(flaky.calls).To(Equal(1))
+		})
+
+		It("doesn't retry once the caller's context is done", func() {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithCancel(ctx)
+			cancel()
+			flaky.errs = []error{context.Canceled}
+
+			Expect(call()).To(MatchError(context.Canceled))
+			Expect(flaky.calls).To(Equal(1))
+		})
+
+		It("retries batches as a whole unless they contain non-idempotent calls", func() {
+			flaky.errs = []error{errTransient, errTransient}
+			rpcClient := middleware.NewRpcClient(flaky, middleware.NewPolicy(config))
+
+			Expect(rpcClient.BatchCallContext(ctx, []client.BatchElem{{Method: "eth_getBlockByNumber"}})).To(Succeed())
+			Expect(flaky.calls).To(Equal(3))
+
+			flaky.calls = 0
+			err := rpcClient.BatchCallContext(ctx, []client.BatchElem{{Method: "eth_getBlockByNumber"}, {Method: "eth_sendRawTransaction"}})
+			Expect(err).To(MatchError(errTransient))
+			Expect(flaky.calls).To(Equal(1))
+		})
+
+		It("retries eth client calls", func() {
+			ethStub.errs = []error{errTransient}
+			ethClient := middleware.NewEthClient(ethStub, middleware.NewPolicy(config))
+
+			header, err := ethClient.HeaderByNumber(ctx, big.NewInt(10))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(header.Number.Int64()).To(Equal(int64(10)))
+			Expect(ethStub.calls).To(Equal(2))
+		})
+
+		It("doesn't retry eth client calls for data that isn't found", func() {
+			ethStub.errs = []error{ethereum.NotFound}
+			ethClient := middleware.NewEthClient(ethStub, middleware.NewPolicy(config))
+
+			_, err := ethClient.HeaderByNumber(ctx, big.NewInt(10))
+
+			Expect(err).To(MatchError(ethereum.NotFound))
+			Expect(ethStub.calls).To(Equal(1))
+		})
+	})
+
+	Describe("timeouts", func() {
+		It("doesn't set a deadline if no timeout is configured", func() {
+			Expect(call()).To(Succeed())
+			Expect(flaky.deadlines).To(Equal([]bool{false}))
+		})
+
+		It("times out and retries attempts that take longer than the method's timeout", func() {
+			config.CallTimeout = time.Hour
+			config.CallTimeouts = map[string]time.Duration{"eth_blocknumber": 10 * time.Millisecond}
+			flaky.block = true
+
+			Expect(call()).To(Succeed())
+			Expect(flaky.calls).To(Equal(2))
+			Expect(flaky.deadlines).To(Equal([]bool{true, true}))
+		})
+	})
+
+	Describe("rate limiting", func() {
+		It("delays calls beyond the burst to keep to the rate", func() {
+			config.RateLimit = 100
+			config.RateLimitBurst = 2
+			rpcClient := middleware.NewRpcClient(flaky, middleware.NewPolicy(config))
+
+			start := time.Now()
+			for i := 0; i < 4; i++ {
+				Expect(rpcClient.CallContext(ctx, nil, "eth_blockNumber")).To(Succeed())
+			}
+
+			Expect(time.Since(start)).To(BeNumerically(">=", 15*time.Millisecond))
+			Expect(flaky.calls).To(Equal(4))
+		})
+
+		It("counts each call in a batch against the rate", func() {
+			config.RateLimit = 100
+			config.RateLimitBurst = 5
+			rpcClient := middleware.NewRpcClient(flaky, middleware.NewPolicy(config))
+			batch := make([]client.BatchElem, 5)
+
+			start := time.Now()
+			Expect(rpcClient.BatchCallContext(ctx, batch)).To(Succeed())
+			Expect(rpcClient.BatchCallContext(ctx, batch)).To(Succeed())
+
+			Expect(time.Since(start)).To(BeNumerically(">=", 40*time.Millisecond))
+		})
+
+		It("stops waiting when the caller's context is done", func() {
+			config.RateLimit = 0.001
+			config.RateLimitBurst = 1
+			rpcClient := middleware.NewRpcClient(flaky, middleware.NewPolicy(config))
+			Expect(rpcClient.CallContext(ctx, nil, "eth_blockNumber")).To(Succeed())
+
+			timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+			defer cancel()
+			err := rpcClient.CallContext(timeoutCtx, nil, "eth_blockNumber")
+
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+			Expect(flaky.calls).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,162 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package middleware
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// Code that rate limited providers (e.g. Infura) return in JSON-RPC errors, including for log queries that match
+// more results than they will serve
+const limitExceededErrorCode = -32005
+
+// Errors that nodes and providers return when a log query matches more results than they will serve
+var rangeTooLargeErrPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`query returned more than \d+ results`),
+	regexp.MustCompile(`log response size exceeded`),
+	regexp.MustCompile(`block range is too wide`),
+}
+
+// Methods that may have side effects if they're sent twice, so aren't retried
+var nonIdempotentMethods = map[string]bool{
+	"eth_sendRawTransaction":   true,
+	"eth_sendTransaction":      true,
+	"eth_subscribe":            true,
+	"personal_sendTransaction": true,
+}
+
+// Config controls how calls to the node are made. Zero values disable the corresponding feature.
+type Config struct {
+	// CallTimeout bounds each attempt of a call; CallTimeouts overrides it by method
+	CallTimeout  time.Duration
+	CallTimeouts map[string]time.Duration
+	// MaxRetries is how many times a failed idempotent call is retried, waiting RetryBackoff before the first retry and
+	// doubling the wait (up to MaxRetryBackoff) each time
+	MaxRetries      int
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// RateLimit is the sustained number of calls per second, with bursts of up to RateLimitBurst calls
+	RateLimit      float64
+	RateLimitBurst int
+}
+
+// Policy applies a Config to calls. It's shared by the RPC and eth clients for a node, so that they're rate limited
+// together.
+type Policy struct {
+	config  Config
+	limiter *rateLimiter
+}
+
+func NewPolicy(config Config) *Policy {
+	// Method names are matched case insensitively, since config keys are lowercased
+	callTimeouts := make(map[string]time.Duration)
+	for method, timeout := range config.CallTimeouts {
+		callTimeouts[strings.ToLower(method)] = timeout
+	}
+	config.CallTimeouts = callTimeouts
+	var limiter *rateLimiter
+	if config.RateLimit > 0 {
+		limiter = newRateLimiter(config.RateLimit, config.RateLimitBurst)
+	}
+	return &Policy{config: config, limiter: limiter}
+}
+
+// Calls call with a timeout and rate limiting, retrying if it fails with a transient error and idempotent is true.
+// requests is the number of requests the call makes (i.e. the size of a batch).
+func (policy *Policy) do(ctx context.Context, method string, requests int, idempotent bool, call func(ctx context.Context) error) error {
+	backoff := policy.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := policy.attempt(ctx, method, requests, call)
+		if err == nil || !idempotent || attempt >= policy.config.MaxRetries || !isTransient(ctx, err) {
+			return err
+		}
+		logrus.Debugf("retrying %s in %s after error: %s", method, backoff, err.Error())
+		metrics.ObserveRPCRetry(method)
+		if sleepErr := sleep(ctx, backoff); sleepErr != nil {
+			return err
+		}
+		backoff *= 2
+		if policy.config.MaxRetryBackoff > 0 && backoff > policy.config.MaxRetryBackoff {
+			backoff = policy.config.MaxRetryBackoff
+		}
+	}
+}
+
+func (policy *Policy) attempt(ctx context.Context, method string, requests int, call func(ctx context.Context) error) error {
+	if policy.limiter != nil {
+		wait, waitErr := policy.limiter.wait(ctx, requests)
+		if waitErr != nil {
+			return waitErr
+		}
+		if wait > 0 {
+			metrics.ObserveRPCThrottle(method, wait)
+		}
+	}
+	timeout := policy.config.CallTimeout
+	if methodTimeout, ok := policy.config.CallTimeouts[strings.ToLower(method)]; ok {
+		timeout = methodTimeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return call(ctx)
+}
+
+// Errors from reaching the node, timeouts of a single attempt and rate limit errors are transient. Other errors
+// returned by the node (including log queries matching too many results, which share the rate limit error code), and
+// cancellation of the caller's context, aren't.
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || err == ethereum.NotFound {
+		return false
+	}
+	if rpcErr, ok := err.(rpc.Error); ok {
+		return rpcErr.ErrorCode() == limitExceededErrorCode && !IsRangeTooLargeErr(err)
+	}
+	return true
+}
+
+// IsRangeTooLargeErr is true if the error is a node rejecting a log query for matching more results than it will serve
+func IsRangeTooLargeErr(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, pattern := range rangeTooLargeErrPatterns {
+		if pattern.MatchString(message) {
+			return true
+		}
+	}
+	return false
+}
+
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,73 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package middleware
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/eth/client"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
+)
+
+// RpcClient applies a Policy to calls made with a core.RpcClient
+type RpcClient struct {
+	client core.RpcClient
+	policy *Policy
+}
+
+func NewRpcClient(rpcClient core.RpcClient, policy *Policy) RpcClient {
+	return RpcClient{client: rpcClient, policy: policy}
+}
+
+func (rpcClient RpcClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return rpcClient.policy.do(ctx, method, 1, !nonIdempotentMethods[method], func(ctx context.Context) error {
+		return rpcClient.client.CallContext(ctx, result, method, args...)
+	})
+}
+
+// BatchCallContext retries the batch as a whole if the call fails, but not elements that fail individually. The batch
+// is only retried if every call in it is idempotent, and is timed out by the batch method's timeout.
+func (rpcClient RpcClient) BatchCallContext(ctx context.Context, batch []client.BatchElem) error {
+	idempotent := true
+	for _, batchElem := range batch {
+		idempotent = idempotent && !nonIdempotentMethods[batchElem.Method]
+	}
+	return rpcClient.policy.do(ctx, metrics.BatchMethod, len(batch), idempotent, func(ctx context.Context) error {
+		return rpcClient.client.BatchCallContext(ctx, batch)
+	})
+}
+
+func (rpcClient RpcClient) IpcPath() string {
+	return rpcClient.client.IpcPath()
+}
+
+func (rpcClient RpcClient) SupportedModules() (map[string]string, error) {
+	var modules map[string]string
+	err := rpcClient.policy.do(context.Background(), "rpc_modules", 1, true, func(ctx context.Context) error {
+		var err error
+		modules, err = rpcClient.client.SupportedModules()
+		return err
+	})
+	return modules, err
+}
+
+// Subscribe is passed straight through, since subscriptions are long-lived
+func (rpcClient RpcClient) Subscribe(namespace string, payloadChan interface{}, args ...interface{}) (*rpc.ClientSubscription, error) {
+	return rpcClient.client.Subscribe(namespace, payloadChan, args...)
+}
@@ -80,17 +80,29 @@ func makePropertiesReader(client core.RpcClient) IPropertiesReader {
 	}
 }
 
+// The node type is detected from web3_clientVersion, falling back to whether the admin API is exposed. Geth nodes that
+// don't expose it (e.g. Infura, or nodes behind a load balancer) can't report their node info, so they're treated like
+// Infura and recorded under a fixed ID.
 func getNodeType(client core.RpcClient) core.NodeType {
-	if strings.Contains(client.IpcPath(), "infura") {
-		return core.INFURA
+	var clientVersion string
+	versionErr := client.CallContext(context.Background(), &clientVersion, "web3_clientVersion")
+	if versionErr != nil {
+		logrus.Warnf("error getting client version: %s", versionErr.Error())
+	}
+	version := strings.ToLower(clientVersion)
+	if strings.HasPrefix(version, "parity") || strings.HasPrefix(version, "openethereum") {
+		return core.PARITY
 	}
-	if strings.Contains(client.IpcPath(), "127.0.0.1") || strings.Contains(client.IpcPath(), "localhost") {
+	if strings.HasPrefix(version, "ethereumjs testrpc") || strings.Contains(version, "ganache") {
 		return core.GANACHE
 	}
 	modules, _ := client.SupportedModules()
 	if _, ok := modules["admin"]; ok {
 		return core.GETH
 	}
+	if strings.HasPrefix(version, "geth") {
+		return core.INFURA
+	}
 	return core.PARITY
 }
 
@@ -96,24 +96,60 @@ var _ = Describe("Node Info", func() {
 		Expect(n.ClientName).To(Equal("Geth/v1.7"))
 	})
 
-	It("returns infura ID and client name for infura node", func() {
+	It("returns parity ID and client name when the client version is parity", func() {
 		client := fakes.NewMockRpcClient()
-		client.SetIpcPath("infura/path")
+		client.SetClientVersion("Parity-Ethereum//v2.5.13-stable-253ff3f-20191231/x86_64-linux-gnu/rustc1.40.0")
+		supportedModules := make(map[string]string)
+		supportedModules["admin"] = "ok"
+		client.SetSupporedModules(supportedModules)
+
+		n := node.MakeNode(client)
+		Expect(n.ID).To(Equal("ParityNode"))
+		Expect(n.ClientName).To(Equal("Parity/v1.2.3/"))
+	})
+
+	It("returns geth ID and client name when the client version is geth and the admin API is exposed", func() {
+		client := fakes.NewMockRpcClient()
+		client.SetClientVersion("Geth/v1.9.9-stable-01744997/linux-amd64/go1.13.5")
+		supportedModules := make(map[string]string)
+		supportedModules["admin"] = "ok"
+		client.SetSupporedModules(supportedModules)
+
+		n := node.MakeNode(client)
+		Expect(n.ID).To(Equal("enode://GethNode@172.17.0.1:30303"))
+		Expect(n.ClientName).To(Equal("Geth/v1.7"))
+	})
+
+	It("returns infura ID and client name for geth nodes without the admin API", func() {
+		client := fakes.NewMockRpcClient()
+		client.SetClientVersion("Geth/v1.9.9-omnibus-e320ae4c-20191206/linux-amd64/go1.13.4")
+
 		n := node.MakeNode(client)
 		Expect(n.ID).To(Equal("infura"))
 		Expect(n.ClientName).To(Equal("infura"))
 	})
 
-	It("returns local id and client name for Local node", func() {
+	It("returns ganache ID and client name for ganache nodes", func() {
 		client := fakes.NewMockRpcClient()
-		client.SetIpcPath("127.0.0.1")
+		client.SetClientVersion("EthereumJS TestRPC/v2.9.1/ethereum-js")
 		n := node.MakeNode(client)
 		Expect(n.ID).To(Equal("ganache"))
 		Expect(n.ClientName).To(Equal("ganache"))
 
-		client.SetIpcPath("localhost")
+		client.SetClientVersion("Ganache/v7.0.0/EthereumJS TestRPC/v7.0.0/ethereum-js")
 		n = node.MakeNode(client)
 		Expect(n.ID).To(Equal("ganache"))
 		Expect(n.ClientName).To(Equal("ganache"))
 	})
+
+	It("does not use the endpoint to detect the node type", func() {
+		client := fakes.NewMockRpcClient()
+		client.SetIpcPath("http://localhost:8545")
+		supportedModules := make(map[string]string)
+		supportedModules["admin"] = "ok"
+		client.SetSupporedModules(supportedModules)
+
+		n := node.MakeNode(client)
+		Expect(n.ClientName).To(Equal("Geth/v1.7"))
+	})
 })
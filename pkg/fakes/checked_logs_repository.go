@@ -16,24 +16,59 @@
 
 package fakes
 
+import "github.com/makerdao/vulcanizedb/pkg/core"
+
+type UpdateLogBackfillCall struct {
+	Addresses       []string
+	TopicZero       string
+	TopicFilters    string
+	NextBlockNumber int64
+}
+
 type MockCheckedLogsRepository struct {
-	AlreadyWatchingLogAddresses []string
-	AlreadyWatchingLogError     error
-	AlreadyWatchingLogReturn    bool
-	AlreadyWatchingLogTopicZero string
-	MarkLogWatchedAddresses     []string
-	MarkLogWatchedError         error
-	MarkLogWatchedTopicZero     string
+	AlreadyWatchingLogAddresses       []string
+	AlreadyWatchingLogError           error
+	AlreadyWatchingLogReturn          bool
+	AlreadyWatchingLogTopicFilters    string
+	AlreadyWatchingLogTopicZero       string
+	GetLogBackfillsError              error
+	GetLogBackfillsReturn             []core.LogBackfill
+	MarkLogWatchedAddresses           []string
+	MarkLogWatchedEndingBlockNumber   int64
+	MarkLogWatchedError               error
+	MarkLogWatchedStartingBlockNumber int64
+	MarkLogWatchedTopicFilters        string
+	MarkLogWatchedTopicZero           string
+	UpdateLogBackfillCalls            []UpdateLogBackfillCall
+	UpdateLogBackfillError            error
 }
 
-func (repository *MockCheckedLogsRepository) AlreadyWatchingLog(addresses []string, topic0 string) (bool, error) {
+func (repository *MockCheckedLogsRepository) AlreadyWatchingLog(addresses []string, topic0, topicFilters string) (bool, error) {
 	repository.AlreadyWatchingLogAddresses = addresses
 	repository.AlreadyWatchingLogTopicZero = topic0
+	repository.AlreadyWatchingLogTopicFilters = topicFilters
 	return repository.AlreadyWatchingLogReturn, repository.AlreadyWatchingLogError
 }
 
-func (repository *MockCheckedLogsRepository) MarkLogWatched(addresses []string, topic0 string) error {
+func (repository *MockCheckedLogsRepository) GetLogBackfills() ([]core.LogBackfill, error) {
+	return repository.GetLogBackfillsReturn, repository.GetLogBackfillsError
+}
+
+func (repository *MockCheckedLogsRepository) MarkLogWatched(addresses []string, topic0, topicFilters string, startingBlockNumber, endingBlockNumber int64) error {
 	repository.MarkLogWatchedAddresses = addresses
 	repository.MarkLogWatchedTopicZero = topic0
+	repository.MarkLogWatchedTopicFilters = topicFilters
+	repository.MarkLogWatchedStartingBlockNumber = startingBlockNumber
+	repository.MarkLogWatchedEndingBlockNumber = endingBlockNumber
 	return repository.MarkLogWatchedError
 }
+
+func (repository *MockCheckedLogsRepository) UpdateLogBackfill(addresses []string, topic0, topicFilters string, nextBlockNumber int64) error {
+	repository.UpdateLogBackfillCalls = append(repository.UpdateLogBackfillCalls, UpdateLogBackfillCall{
+		Addresses:       addresses,
+		TopicZero:       topic0,
+		TopicFilters:    topicFilters,
+		NextBlockNumber: nextBlockNumber,
+	})
+	return repository.UpdateLogBackfillError
+}
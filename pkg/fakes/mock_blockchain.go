@@ -17,8 +17,10 @@
 package fakes
 
 import (
+	"context"
 	"github.com/ethereum/go-ethereum/common"
 	"math/big"
+	"sync"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -36,14 +38,28 @@ type MockBlockChain struct {
 	fetchContractDataPassedResult      interface{}
 	fetchContractDataPassedBlockNumber int64
 	getBlockByNumberErr                error
+	getHeaderByNumberErr               error
+	getHeadersByNumbersErr             error
+	getHeadersByNumbersErrCount        int
+	getHeadersByNumbersMutex           sync.Mutex
+	GetHeadersByNumbersCallCount       int
+	getHeaderByNumberReturnHeaders     map[int64]core.Header
 	GetTransactionsCalled              bool
 	GetTransactionsError               error
 	GetTransactionsPassedHashes        []common.Hash
+	GetTransactionReceiptsCalled       bool
+	GetTransactionReceiptsError        error
+	GetTransactionReceiptsPassedHashes []common.Hash
 	logQuery                           ethereum.FilterQuery
 	logQueryErr                        error
+	logQueryRangeLimit                 int64
+	logQueryRangeLimitErr              error
 	logQueryReturnLogs                 []types.Log
+	PassedLogQueries                   []ethereum.FilterQuery
 	lastBlock                          *big.Int
+	lastBlockErr                       error
 	node                               core.Node
+	Receipts                           []core.Receipt
 	Transactions                       []core.TransactionModel
 	accountBalanceReturnValue          *big.Int
 	getAccountBalanceErr               error
@@ -63,19 +79,46 @@ func (chain *MockBlockChain) SetLastBlock(blockNumber *big.Int) {
 	chain.lastBlock = blockNumber
 }
 
+func (chain *MockBlockChain) SetLastBlockErr(err error) {
+	chain.lastBlockErr = err
+}
+
 func (chain *MockBlockChain) SetGetBlockByNumberErr(err error) {
 	chain.getBlockByNumberErr = err
 }
 
+func (chain *MockBlockChain) SetGetHeaderByNumberErr(err error) {
+	chain.getHeaderByNumberErr = err
+}
+
+// GetHeadersByNumbers returns err for the next count calls
+func (chain *MockBlockChain) SetGetHeadersByNumbersErr(err error, count int) {
+	chain.getHeadersByNumbersErr = err
+	chain.getHeadersByNumbersErrCount = count
+}
+
+func (chain *MockBlockChain) SetGetHeaderByNumberReturnHeaders(headers []core.Header) {
+	chain.getHeaderByNumberReturnHeaders = make(map[int64]core.Header)
+	for _, header := range headers {
+		chain.getHeaderByNumberReturnHeaders[header.BlockNumber] = header
+	}
+}
+
 func (chain *MockBlockChain) SetGetEthLogsWithCustomQueryErr(err error) {
 	chain.logQueryErr = err
 }
 
+// Queries spanning more than limit blocks return err, as a node does when a query matches too many logs
+func (chain *MockBlockChain) SetGetEthLogsWithCustomQueryRangeLimit(limit int64, err error) {
+	chain.logQueryRangeLimit = limit
+	chain.logQueryRangeLimitErr = err
+}
+
 func (chain *MockBlockChain) SetGetEthLogsWithCustomQueryReturnLogs(logs []types.Log) {
 	chain.logQueryReturnLogs = logs
 }
 
-func (blockChain *MockBlockChain) FetchContractData(abiJSON string, address string, method string, methodArgs []interface{}, result interface{}, blockNumber int64) error {
+func (blockChain *MockBlockChain) FetchContractData(ctx context.Context, abiJSON string, address string, method string, methodArgs []interface{}, result interface{}, blockNumber int64) error {
 	blockChain.fetchContractDataPassedAbi = abiJSON
 	blockChain.fetchContractDataPassedAddress = address
 	blockChain.fetchContractDataPassedMethod = method
@@ -85,44 +128,70 @@ func (blockChain *MockBlockChain) FetchContractData(abiJSON string, address stri
 	return blockChain.fetchContractDataErr
 }
 
-func (chain *MockBlockChain) GetBlockByNumber(blockNumber int64) (core.Block, error) {
+func (chain *MockBlockChain) GetBlockByNumber(ctx context.Context, blockNumber int64) (core.Block, error) {
 	return core.Block{Number: blockNumber}, chain.getBlockByNumberErr
 }
 
-func (blockChain *MockBlockChain) GetEthLogsWithCustomQuery(query ethereum.FilterQuery) ([]types.Log, error) {
+func (blockChain *MockBlockChain) GetEthLogsWithCustomQuery(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
 	blockChain.logQuery = query
+	blockChain.PassedLogQueries = append(blockChain.PassedLogQueries, query)
+	if blockChain.logQueryRangeLimit > 0 && query.FromBlock != nil && query.ToBlock != nil {
+		if query.ToBlock.Int64()-query.FromBlock.Int64()+1 > blockChain.logQueryRangeLimit {
+			return []types.Log{}, blockChain.logQueryRangeLimitErr
+		}
+	}
 	return blockChain.logQueryReturnLogs, blockChain.logQueryErr
 }
 
-func (chain *MockBlockChain) GetHeaderByNumber(blockNumber int64) (core.Header, error) {
-	return core.Header{BlockNumber: blockNumber}, nil
+func (chain *MockBlockChain) GetHeaderByNumber(ctx context.Context, blockNumber int64) (core.Header, error) {
+	if header, ok := chain.getHeaderByNumberReturnHeaders[blockNumber]; ok {
+		return header, chain.getHeaderByNumberErr
+	}
+	return core.Header{BlockNumber: blockNumber}, chain.getHeaderByNumberErr
 }
 
-func (chain *MockBlockChain) GetHeadersByNumbers(blockNumbers []int64) ([]core.Header, error) {
+func (chain *MockBlockChain) GetHeadersByNumbers(ctx context.Context, blockNumbers []int64) ([]core.Header, error) {
+	chain.getHeadersByNumbersMutex.Lock()
+	defer chain.getHeadersByNumbersMutex.Unlock()
+	chain.GetHeadersByNumbersCallCount++
+	if chain.getHeadersByNumbersErrCount > 0 {
+		chain.getHeadersByNumbersErrCount--
+		return []core.Header{}, chain.getHeadersByNumbersErr
+	}
 	var headers []core.Header
 	for _, blockNumber := range blockNumbers {
+		if header, ok := chain.getHeaderByNumberReturnHeaders[blockNumber]; ok {
+			headers = append(headers, header)
+			continue
+		}
 		var header = core.Header{BlockNumber: int64(blockNumber)}
 		headers = append(headers, header)
 	}
 	return headers, nil
 }
 
-func (chain *MockBlockChain) GetFullSyncLogs(contract core.Contract, startingBlockNumber, endingBlockNumber *big.Int) ([]core.FullSyncLog, error) {
+func (chain *MockBlockChain) GetFullSyncLogs(ctx context.Context, contract core.Contract, startingBlockNumber, endingBlockNumber *big.Int) ([]core.FullSyncLog, error) {
 	return []core.FullSyncLog{}, nil
 }
 
-func (chain *MockBlockChain) GetTransactions(transactionHashes []common.Hash) ([]core.TransactionModel, error) {
+func (chain *MockBlockChain) GetTransactions(ctx context.Context, transactionHashes []common.Hash) ([]core.TransactionModel, error) {
 	chain.GetTransactionsCalled = true
 	chain.GetTransactionsPassedHashes = transactionHashes
 	return chain.Transactions, chain.GetTransactionsError
 }
 
+func (chain *MockBlockChain) GetTransactionReceipts(ctx context.Context, transactionHashes []common.Hash) ([]core.Receipt, error) {
+	chain.GetTransactionReceiptsCalled = true
+	chain.GetTransactionReceiptsPassedHashes = transactionHashes
+	return chain.Receipts, chain.GetTransactionReceiptsError
+}
+
 func (chain *MockBlockChain) CallContract(contractHash string, input []byte, blockNumber *big.Int) ([]byte, error) {
 	return []byte{}, nil
 }
 
-func (chain *MockBlockChain) LastBlock() (*big.Int, error) {
-	return chain.lastBlock, nil
+func (chain *MockBlockChain) LastBlock(ctx context.Context) (*big.Int, error) {
+	return chain.lastBlock, chain.lastBlockErr
 }
 
 func (chain *MockBlockChain) Node() core.Node {
@@ -152,6 +221,6 @@ func (blockChain *MockBlockChain) SetGetAccountBalance(balance *big.Int) {
 	blockChain.accountBalanceReturnValue = balance
 }
 
-func (blockChain *MockBlockChain) GetAccountBalance(address common.Address, blockNumber *big.Int) (*big.Int, error) {
+func (blockChain *MockBlockChain) GetAccountBalance(ctx context.Context, address common.Address, blockNumber *big.Int) (*big.Int, error) {
 	return blockChain.accountBalanceReturnValue, blockChain.getAccountBalanceErr
 }
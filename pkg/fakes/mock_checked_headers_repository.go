@@ -20,33 +20,89 @@ import (
 	"github.com/makerdao/vulcanizedb/pkg/core"
 )
 
+type UncheckedHeadersCall struct {
+	StartingBlockNumber int64
+	EndingBlockNumber   int64
+	CheckCount          int64
+}
+
 type MockCheckedHeadersRepository struct {
+	CheckedHeadersEndingBlockNumber         int64
+	CheckedHeadersReturnError               error
+	CheckedHeadersReturnHeaders             []core.Header
+	CheckedHeadersStartingBlockNumber       int64
 	MarkHeaderCheckedHeaderID               int64
 	MarkHeaderCheckedReturnError            error
+	MarkHeadersCheckedHeaderIDs             []int64
+	MarkHeadersCheckedReturnError           error
+	MarkHeadersConfirmedHeaderIDs           []int64
+	MarkHeadersConfirmedReturnError         error
 	MarkHeadersUncheckedCalled              bool
+	MarkHeadersUncheckedEndingBlockNumber   int64
 	MarkHeadersUncheckedReturnError         error
 	MarkHeadersUncheckedStartingBlockNumber int64
+	MarkHeadersUnconfirmedHeaderIDs         []int64
+	MarkHeadersUnconfirmedReturnError       error
+	UncheckedHeadersCalls                   []UncheckedHeadersCall
 	UncheckedHeadersCheckCount              int64
 	UncheckedHeadersEndingBlockNumber       int64
 	UncheckedHeadersReturnError             error
 	UncheckedHeadersReturnHeaders           []core.Header
 	UncheckedHeadersStartingBlockNumber     int64
+	UnconfirmedHeadersCalls                 []UncheckedHeadersCall
+	UnconfirmedHeadersReturnError           error
+	UnconfirmedHeadersReturnHeaders         []core.Header
+}
+
+func (repository *MockCheckedHeadersRepository) CheckedHeaders(startingBlockNumber, endingBlockNumber int64) ([]core.Header, error) {
+	repository.CheckedHeadersStartingBlockNumber = startingBlockNumber
+	repository.CheckedHeadersEndingBlockNumber = endingBlockNumber
+	return repository.CheckedHeadersReturnHeaders, repository.CheckedHeadersReturnError
 }
 
-func (repository *MockCheckedHeadersRepository) MarkHeadersUnchecked(startingBlockNumber int64) error {
+func (repository *MockCheckedHeadersRepository) MarkHeadersUnchecked(startingBlockNumber, endingBlockNumber int64) error {
 	repository.MarkHeadersUncheckedCalled = true
 	repository.MarkHeadersUncheckedStartingBlockNumber = startingBlockNumber
+	repository.MarkHeadersUncheckedEndingBlockNumber = endingBlockNumber
 	return repository.MarkHeadersUncheckedReturnError
 }
 
+func (repository *MockCheckedHeadersRepository) MarkHeadersUnconfirmed(headerIDs []int64) error {
+	repository.MarkHeadersUnconfirmedHeaderIDs = append(repository.MarkHeadersUnconfirmedHeaderIDs, headerIDs...)
+	return repository.MarkHeadersUnconfirmedReturnError
+}
+
+func (repository *MockCheckedHeadersRepository) MarkHeadersConfirmed(headerIDs []int64) error {
+	repository.MarkHeadersConfirmedHeaderIDs = append(repository.MarkHeadersConfirmedHeaderIDs, headerIDs...)
+	return repository.MarkHeadersConfirmedReturnError
+}
+
 func (repository *MockCheckedHeadersRepository) MarkHeaderChecked(headerID int64) error {
 	repository.MarkHeaderCheckedHeaderID = headerID
 	return repository.MarkHeaderCheckedReturnError
 }
 
+func (repository *MockCheckedHeadersRepository) MarkHeadersChecked(headerIDs []int64) error {
+	repository.MarkHeadersCheckedHeaderIDs = append(repository.MarkHeadersCheckedHeaderIDs, headerIDs...)
+	return repository.MarkHeadersCheckedReturnError
+}
+
 func (repository *MockCheckedHeadersRepository) UncheckedHeaders(startingBlockNumber, endingBlockNumber, checkCount int64) ([]core.Header, error) {
 	repository.UncheckedHeadersStartingBlockNumber = startingBlockNumber
 	repository.UncheckedHeadersEndingBlockNumber = endingBlockNumber
 	repository.UncheckedHeadersCheckCount = checkCount
+	repository.UncheckedHeadersCalls = append(repository.UncheckedHeadersCalls, UncheckedHeadersCall{
+		StartingBlockNumber: startingBlockNumber,
+		EndingBlockNumber:   endingBlockNumber,
+		CheckCount:          checkCount,
+	})
 	return repository.UncheckedHeadersReturnHeaders, repository.UncheckedHeadersReturnError
 }
+
+func (repository *MockCheckedHeadersRepository) UnconfirmedHeaders(startingBlockNumber, endingBlockNumber int64) ([]core.Header, error) {
+	repository.UnconfirmedHeadersCalls = append(repository.UnconfirmedHeadersCalls, UncheckedHeadersCall{
+		StartingBlockNumber: startingBlockNumber,
+		EndingBlockNumber:   endingBlockNumber,
+	})
+	return repository.UnconfirmedHeadersReturnHeaders, repository.UnconfirmedHeadersReturnError
+}
@@ -17,23 +17,41 @@
 package fakes
 
 import (
+	"database/sql"
+	"sync"
+
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	. "github.com/onsi/gomega"
 )
 
 type MockHeaderRepository struct {
+	brokenLinkBlockNumbers                 []int64
+	brokenLinkCallCount                    int
+	BrokenLinkBlockNumbersError            error
+	BrokenLinkPassedStartingBlockNumber    int64
+	BrokenLinkPassedEndingBlockNumber      int64
+	CreateHeadersError                     error
+	createHeadersMutex                     sync.Mutex
+	createHeadersPassedBlockNumbers        []int64
 	createOrUpdateHeaderCallCount          int
 	createOrUpdateHeaderErr                error
 	createOrUpdateHeaderPassedBlockNumbers []int64
 	createOrUpdateHeaderReturnID           int64
+	CreateReceiptsCalled                   bool
+	CreateReceiptsError                    error
 	CreateTransactionsCalled               bool
 	CreateTransactionsError                error
 	GetHeaderError                         error
 	GetHeaderReturnHash                    string
 	GetHeaderReturnID                      int64
 	missingBlockNumbers                    []int64
+	remainingBrokenLinkBlockNumbers        []int64
+	remainingBrokenLinksSet                bool
 	headerExists                           bool
 	GetHeaderPassedBlockNumber             int64
+	MostRecentHeaderBlockNumber            int64
+	MostRecentHeaderBlockNumberError       error
+	storedHeaders                          map[int64]core.Header
 }
 
 func NewMockHeaderRepository() *MockHeaderRepository {
@@ -48,16 +66,58 @@ func (repository *MockHeaderRepository) SetCreateOrUpdateHeaderReturnErr(err err
 	repository.createOrUpdateHeaderErr = err
 }
 
+func (repository *MockHeaderRepository) SetBrokenLinkBlockNumbers(blockNumbers []int64) {
+	repository.brokenLinkBlockNumbers = blockNumbers
+}
+
+// Once set, BrokenLinkBlockNumbers returns the remaining block numbers after its first call, as if links were repaired
+func (repository *MockHeaderRepository) SetRemainingBrokenLinkBlockNumbers(blockNumbers []int64) {
+	repository.remainingBrokenLinkBlockNumbers = blockNumbers
+	repository.remainingBrokenLinksSet = true
+}
+
 func (repository *MockHeaderRepository) SetMissingBlockNumbers(blockNumbers []int64) {
 	repository.missingBlockNumbers = blockNumbers
 }
 
+// Once stored headers are set, GetHeader returns them by block number and sql.ErrNoRows for any other block
+func (repository *MockHeaderRepository) SetStoredHeaders(headers []core.Header) {
+	repository.storedHeaders = make(map[int64]core.Header)
+	for _, header := range headers {
+		repository.storedHeaders[header.BlockNumber] = header
+	}
+}
+
+func (repository *MockHeaderRepository) BrokenLinkBlockNumbers(startingBlockNumber, endingBlockNumber int64) ([]int64, error) {
+	repository.BrokenLinkPassedStartingBlockNumber = startingBlockNumber
+	repository.BrokenLinkPassedEndingBlockNumber = endingBlockNumber
+	repository.brokenLinkCallCount++
+	if repository.remainingBrokenLinksSet && repository.brokenLinkCallCount > 1 {
+		return repository.remainingBrokenLinkBlockNumbers, repository.BrokenLinkBlockNumbersError
+	}
+	return repository.brokenLinkBlockNumbers, repository.BrokenLinkBlockNumbersError
+}
+
+func (repository *MockHeaderRepository) CreateHeaders(headers []core.Header) error {
+	repository.createHeadersMutex.Lock()
+	defer repository.createHeadersMutex.Unlock()
+	for _, header := range headers {
+		repository.createHeadersPassedBlockNumbers = append(repository.createHeadersPassedBlockNumbers, header.BlockNumber)
+	}
+	return repository.CreateHeadersError
+}
+
 func (repository *MockHeaderRepository) CreateOrUpdateHeader(header core.Header) (int64, error) {
 	repository.createOrUpdateHeaderCallCount++
 	repository.createOrUpdateHeaderPassedBlockNumbers = append(repository.createOrUpdateHeaderPassedBlockNumbers, header.BlockNumber)
 	return repository.createOrUpdateHeaderReturnID, repository.createOrUpdateHeaderErr
 }
 
+func (repository *MockHeaderRepository) CreateReceipts(headerID int64, receipts []core.Receipt) error {
+	repository.CreateReceiptsCalled = true
+	return repository.CreateReceiptsError
+}
+
 func (repository *MockHeaderRepository) CreateTransactions(headerID int64, transactions []core.TransactionModel) error {
 	repository.CreateTransactionsCalled = true
 	return repository.CreateTransactionsError
@@ -65,6 +125,13 @@ func (repository *MockHeaderRepository) CreateTransactions(headerID int64, trans
 
 func (repository *MockHeaderRepository) GetHeader(blockNumber int64) (core.Header, error) {
 	repository.GetHeaderPassedBlockNumber = blockNumber
+	if repository.storedHeaders != nil {
+		header, ok := repository.storedHeaders[blockNumber]
+		if !ok {
+			return core.Header{}, sql.ErrNoRows
+		}
+		return header, repository.GetHeaderError
+	}
 	return core.Header{
 		Id:          repository.GetHeaderReturnID,
 		BlockNumber: blockNumber,
@@ -72,6 +139,10 @@ func (repository *MockHeaderRepository) GetHeader(blockNumber int64) (core.Heade
 	}, repository.GetHeaderError
 }
 
+func (repository *MockHeaderRepository) GetMostRecentHeaderBlockNumber() (int64, error) {
+	return repository.MostRecentHeaderBlockNumber, repository.MostRecentHeaderBlockNumberError
+}
+
 func (repository *MockHeaderRepository) MissingBlockNumbers(startingBlockNumber, endingBlockNumber int64) ([]int64, error) {
 	return repository.missingBlockNumbers, nil
 }
@@ -80,3 +151,7 @@ func (repository *MockHeaderRepository) AssertCreateOrUpdateHeaderCallCountAndPa
 	Expect(repository.createOrUpdateHeaderCallCount).To(Equal(times))
 	Expect(repository.createOrUpdateHeaderPassedBlockNumbers).To(Equal(blockNumbers))
 }
+
+func (repository *MockHeaderRepository) AssertCreateHeadersCalledWithBlockNumbers(blockNumbers []int64) {
+	Expect(repository.createHeadersPassedBlockNumbers).To(ConsistOf(blockNumbers))
+}
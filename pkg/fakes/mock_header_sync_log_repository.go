@@ -17,21 +17,40 @@
 package fakes
 
 import (
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 )
 
 type MockHeaderSyncLogRepository struct {
-	CreateError    error
-	GetCalled      bool
-	GetError       error
-	PassedHeaderID int64
-	PassedLogs     []types.Log
-	ReturnLogs     []core.HeaderSyncLog
+	CreateError                           error
+	CreateTransformationsError            error
+	GetCalled                             bool
+	GetNewTransformersError               error
+	GetError                              error
+	GetPassedAfterBlock                   int64
+	GetPassedAfterID                      int64
+	GetPassedLimit                        int
+	MarkTransformedError                  error
+	MarkUntransformedByError              error
+	MarkUntransformedError                error
+	MarkUntransformedByPassedNames        []string
+	MarkUntransformedByPassedTopicFilters [][][]common.Hash
+	PassedHeaderID                        int64
+	PassedLogs                            []types.Log
+	PassedTransformedLogIDs               []int64
+	PassedTransformerLogIDs               map[string][]int64
+	PassedUntransformedLogIDs             []int64
+	RegisterTransformersError             error
+	RegisteredTransformerNames            []string
+	ReturnLogs                            []core.HeaderSyncLog
 }
 
-func (repository *MockHeaderSyncLogRepository) GetUntransformedHeaderSyncLogs() ([]core.HeaderSyncLog, error) {
+func (repository *MockHeaderSyncLogRepository) GetUntransformedHeaderSyncLogs(afterBlockNumber, afterID int64, limit int) ([]core.HeaderSyncLog, error) {
 	repository.GetCalled = true
+	repository.GetPassedAfterBlock = afterBlockNumber
+	repository.GetPassedAfterID = afterID
+	repository.GetPassedLimit = limit
 	return repository.ReturnLogs, repository.GetError
 }
 
@@ -40,3 +59,46 @@ func (repository *MockHeaderSyncLogRepository) CreateHeaderSyncLogs(headerID int
 	repository.PassedLogs = logs
 	return repository.CreateError
 }
+
+func (repository *MockHeaderSyncLogRepository) CreateHeaderSyncLogTransformations(transformerName string, logIDs []int64) error {
+	if repository.PassedTransformerLogIDs == nil {
+		repository.PassedTransformerLogIDs = map[string][]int64{}
+	}
+	repository.PassedTransformerLogIDs[transformerName] = append(repository.PassedTransformerLogIDs[transformerName], logIDs...)
+	return repository.CreateTransformationsError
+}
+
+func (repository *MockHeaderSyncLogRepository) GetNewHeaderSyncLogTransformers(transformerNames []string) ([]string, error) {
+	var newNames []string
+	for _, name := range transformerNames {
+		isRegistered := false
+		for _, registeredName := range repository.RegisteredTransformerNames {
+			isRegistered = isRegistered || name == registeredName
+		}
+		if !isRegistered {
+			newNames = append(newNames, name)
+		}
+	}
+	return newNames, repository.GetNewTransformersError
+}
+
+func (repository *MockHeaderSyncLogRepository) RegisterHeaderSyncLogTransformers(transformerNames []string) error {
+	repository.RegisteredTransformerNames = append(repository.RegisteredTransformerNames, transformerNames...)
+	return repository.RegisterTransformersError
+}
+
+func (repository *MockHeaderSyncLogRepository) MarkHeaderSyncLogsTransformed(logIDs []int64) error {
+	repository.PassedTransformedLogIDs = append(repository.PassedTransformedLogIDs, logIDs...)
+	return repository.MarkTransformedError
+}
+
+func (repository *MockHeaderSyncLogRepository) MarkHeaderSyncLogsUntransformed(logIDs []int64) error {
+	repository.PassedUntransformedLogIDs = append(repository.PassedUntransformedLogIDs, logIDs...)
+	return repository.MarkUntransformedError
+}
+
+func (repository *MockHeaderSyncLogRepository) MarkHeaderSyncLogsUntransformedBy(transformerName string, addresses []string, topic0 common.Hash, topicFilters [][]common.Hash) (int64, error) {
+	repository.MarkUntransformedByPassedNames = append(repository.MarkUntransformedByPassedNames, transformerName)
+	repository.MarkUntransformedByPassedTopicFilters = append(repository.MarkUntransformedByPassedTopicFilters, topicFilters)
+	return 0, repository.MarkUntransformedByError
+}
@@ -17,6 +17,8 @@
 package fakes
 
 import (
+	"context"
+
 	"github.com/makerdao/vulcanizedb/pkg/contract_watcher/shared/contract"
 )
 
@@ -24,15 +26,15 @@ type MockPoller struct {
 	ContractName string
 }
 
-func (*MockPoller) PollContract(con contract.Contract, lastBlock int64) error {
+func (*MockPoller) PollContract(ctx context.Context, con contract.Contract, lastBlock int64) error {
 	panic("implement me")
 }
 
-func (*MockPoller) PollContractAt(con contract.Contract, blockNumber int64) error {
+func (*MockPoller) PollContractAt(ctx context.Context, con contract.Contract, blockNumber int64) error {
 	panic("implement me")
 }
 
-func (poller *MockPoller) FetchContractData(contractAbi, contractAddress, method string, methodArgs []interface{}, result interface{}, blockNumber int64) error {
+func (poller *MockPoller) FetchContractData(ctx context.Context, contractAbi, contractAddress, method string, methodArgs []interface{}, result interface{}, blockNumber int64) error {
 	if p, ok := result.(*string); ok {
 		*p = poller.ContractName
 	}
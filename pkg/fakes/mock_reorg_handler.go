@@ -0,0 +1,38 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package fakes
+
+type MockReorgHandler struct {
+	HandleReorgPassedBlockNumber int64
+	HandleReorgReturnError       error
+	HandleReorgReturnFound       bool
+	UpdateHeadersCallCount       int
+	UpdateHeadersPassedNumbers   []int64
+	UpdateHeadersReturnError     error
+	UpdateHeadersReturnReplaced  int
+}
+
+func (handler *MockReorgHandler) HandleReorg(headBlockNumber int64) (bool, error) {
+	handler.HandleReorgPassedBlockNumber = headBlockNumber
+	return handler.HandleReorgReturnFound, handler.HandleReorgReturnError
+}
+
+func (handler *MockReorgHandler) UpdateHeaders(blockNumbers []int64) (int, error) {
+	handler.UpdateHeadersCallCount++
+	handler.UpdateHeadersPassedNumbers = blockNumbers
+	return handler.UpdateHeadersReturnReplaced, handler.UpdateHeadersReturnError
+}
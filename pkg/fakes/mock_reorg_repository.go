@@ -0,0 +1,66 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package fakes
+
+import (
+	"sync"
+
+	"github.com/makerdao/vulcanizedb/pkg/core"
+)
+
+type MockReorgRepository struct {
+	CreateReorgCalled           bool
+	CreateReorgPassedReorg      core.Reorg
+	CreateReorgReturnError      error
+	CreateReorgReturnID         int64
+	GetLatestReorgIDReturnError error
+	GetLatestReorgIDReturnID    int64
+	GetReorgsAfterPassedIDs     []int64
+	GetReorgsAfterReturnError   error
+	GetReorgsAfterReturnReorgs  []core.Reorg
+	getReorgsAfterMutex         sync.Mutex
+}
+
+func (repository *MockReorgRepository) CreateReorg(reorg core.Reorg) (int64, error) {
+	repository.CreateReorgCalled = true
+	repository.CreateReorgPassedReorg = reorg
+	return repository.CreateReorgReturnID, repository.CreateReorgReturnError
+}
+
+func (repository *MockReorgRepository) GetLatestReorgID() (int64, error) {
+	return repository.GetLatestReorgIDReturnID, repository.GetLatestReorgIDReturnError
+}
+
+// Returns the reorgs set to be returned with IDs above reorgID
+func (repository *MockReorgRepository) GetReorgsAfter(reorgID int64) ([]core.Reorg, error) {
+	repository.getReorgsAfterMutex.Lock()
+	defer repository.getReorgsAfterMutex.Unlock()
+	repository.GetReorgsAfterPassedIDs = append(repository.GetReorgsAfterPassedIDs, reorgID)
+	var reorgs []core.Reorg
+	for _, reorg := range repository.GetReorgsAfterReturnReorgs {
+		if reorg.ID > reorgID {
+			reorgs = append(reorgs, reorg)
+		}
+	}
+	return reorgs, repository.GetReorgsAfterReturnError
+}
+
+func (repository *MockReorgRepository) PassedReorgIDs() []int64 {
+	repository.getReorgsAfterMutex.Lock()
+	defer repository.getReorgsAfterMutex.Unlock()
+	return append([]int64(nil), repository.GetReorgsAfterPassedIDs...)
+}
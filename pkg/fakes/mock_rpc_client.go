@@ -19,8 +19,8 @@ package fakes
 import (
 	"context"
 	"errors"
-	"github.com/ethereum/go-ethereum/statediff"
 	"math/big"
+	"reflect"
 
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/p2p"
@@ -33,6 +33,7 @@ import (
 
 type MockRpcClient struct {
 	callContextErr      error
+	clientVersion       string
 	ipcPath             string
 	nodeType            core.NodeType
 	passedContext       context.Context
@@ -40,7 +41,7 @@ type MockRpcClient struct {
 	passedResult        interface{}
 	passedBatch         []client.BatchElem
 	passedNamespace     string
-	passedPayloadChan   chan statediff.Payload
+	passedPayloadChan   interface{}
 	passedSubscribeArgs []interface{}
 	lengthOfBatch       int
 	returnPOAHeader     core.POAHeader
@@ -52,11 +53,10 @@ type MockRpcClient struct {
 func (client *MockRpcClient) Subscribe(namespace string, payloadChan interface{}, args ...interface{}) (*rpc.ClientSubscription, error) {
 	client.passedNamespace = namespace
 
-	passedPayloadChan, ok := payloadChan.(chan statediff.Payload)
-	if !ok {
-		return nil, errors.New("passed in channel is not of the correct type")
+	if reflect.TypeOf(payloadChan).Kind() != reflect.Chan {
+		return nil, errors.New("passed in payload is not a channel")
 	}
-	client.passedPayloadChan = passedPayloadChan
+	client.passedPayloadChan = payloadChan
 
 	for _, arg := range args {
 		client.passedSubscribeArgs = append(client.passedSubscribeArgs, arg)
@@ -66,7 +66,7 @@ func (client *MockRpcClient) Subscribe(namespace string, payloadChan interface{}
 	return &subscription, nil
 }
 
-func (client *MockRpcClient) AssertSubscribeCalledWith(namespace string, payloadChan chan statediff.Payload, args []interface{}) {
+func (client *MockRpcClient) AssertSubscribeCalledWith(namespace string, payloadChan interface{}, args []interface{}) {
 	Expect(client.passedNamespace).To(Equal(namespace))
 	Expect(client.passedPayloadChan).To(Equal(payloadChan))
 	Expect(client.passedSubscribeArgs).To(Equal(args))
@@ -80,13 +80,17 @@ func (client *MockRpcClient) SetIpcPath(ipcPath string) {
 	client.ipcPath = ipcPath
 }
 
-func (client *MockRpcClient) BatchCall(batch []client.BatchElem) error {
+func (client *MockRpcClient) SetClientVersion(clientVersion string) {
+	client.clientVersion = clientVersion
+}
+
+func (client *MockRpcClient) BatchCallContext(ctx context.Context, batch []client.BatchElem) error {
 	client.passedBatch = batch
 	client.passedMethod = batch[0].Method
 	client.lengthOfBatch = len(batch)
 
 	for _, batchElem := range batch {
-		client.passedContext = context.Background()
+		client.passedContext = ctx
 		client.passedResult = &batchElem.Result
 		client.passedMethod = batchElem.Method
 		if p, ok := batchElem.Result.(*types.Header); ok {
@@ -96,6 +100,9 @@ func (client *MockRpcClient) BatchCall(batch []client.BatchElem) error {
 
 			*p = client.returnPOAHeader
 		}
+		if p, ok := batchElem.Result.(**types.Receipt); ok {
+			*p = &types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{}}
+		}
 	}
 
 	return nil
@@ -142,6 +149,10 @@ func (client *MockRpcClient) CallContext(ctx context.Context, result interface{}
 		if p, ok := result.(*string); ok {
 			*p = "1234"
 		}
+	case "web3_clientVersion":
+		if p, ok := result.(*string); ok {
+			*p = client.clientVersion
+		}
 	}
 	return nil
 }
@@ -17,16 +17,61 @@
 package fakes
 
 import (
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/makerdao/vulcanizedb/libraries/shared/storage"
 )
 
 type MockStorageDiffRepository struct {
-	CreatePassedRawDiffs []storage.RawDiff
-	CreateReturnID       int64
-	CreateReturnError    error
+	CreatePassedRawDiffs           []storage.RawDiff
+	CreateReturnID                 int64
+	CreateReturnError              error
+	GetLatestDiffIDPassedKeys      []common.Hash
+	GetLatestDiffIDReturnID        int64
+	GetLatestDiffIDReturnError     error
+	GetLatestSlotValuesPassedKeys  [][]common.Hash
+	GetLatestSlotValuesReturnError error
+	HasDiffsAfterPassedAddress     common.Hash
+	HasDiffsAfterPassedHeight      int
+	HasDiffsAfterPassedKeys        []common.Hash
+	HasDiffsAfterReturnError       error
+	HasDiffsAfterReturnExists      bool
+	MarkOrphanedPassedAfterHeight  int64
+	MarkOrphanedPassedHeight       int64
+	MarkOrphanedReturnCount        int64
+	MarkOrphanedReturnError        error
+	SlotValues                     map[common.Hash]common.Hash
 }
 
 func (repository *MockStorageDiffRepository) CreateStorageDiff(rawDiff storage.RawDiff) (int64, error) {
 	repository.CreatePassedRawDiffs = append(repository.CreatePassedRawDiffs, rawDiff)
 	return repository.CreateReturnID, repository.CreateReturnError
 }
+
+func (repository *MockStorageDiffRepository) GetLatestSlotValues(hashedAddress common.Hash, keys []common.Hash, blockHeight int) (map[common.Hash]common.Hash, error) {
+	repository.GetLatestSlotValuesPassedKeys = append(repository.GetLatestSlotValuesPassedKeys, keys)
+	values := make(map[common.Hash]common.Hash)
+	for _, key := range keys {
+		if value, ok := repository.SlotValues[key]; ok {
+			values[key] = value
+		}
+	}
+	return values, repository.GetLatestSlotValuesReturnError
+}
+
+func (repository *MockStorageDiffRepository) GetLatestDiffID(hashedAddress, blockHash common.Hash, keys []common.Hash) (int64, error) {
+	repository.GetLatestDiffIDPassedKeys = keys
+	return repository.GetLatestDiffIDReturnID, repository.GetLatestDiffIDReturnError
+}
+
+func (repository *MockStorageDiffRepository) HasDiffsAfter(hashedAddress common.Hash, keys []common.Hash, blockHeight int) (bool, error) {
+	repository.HasDiffsAfterPassedAddress = hashedAddress
+	repository.HasDiffsAfterPassedKeys = keys
+	repository.HasDiffsAfterPassedHeight = blockHeight
+	return repository.HasDiffsAfterReturnExists, repository.HasDiffsAfterReturnError
+}
+
+func (repository *MockStorageDiffRepository) MarkOrphanedDiffs(afterBlockHeight, finalBlockHeight int64) (int64, error) {
+	repository.MarkOrphanedPassedAfterHeight = afterBlockHeight
+	repository.MarkOrphanedPassedHeight = finalBlockHeight
+	return repository.MarkOrphanedReturnCount, repository.MarkOrphanedReturnError
+}
@@ -16,14 +16,27 @@
 
 package fakes
 
-import "github.com/ethereum/go-ethereum/core/types"
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
 
 type MockTransactionSyncer struct {
 	SyncTransactionsCalled bool
 	SyncTransactionsError  error
+	SyncReceiptsCalled     bool
+	SyncReceiptsError      error
+	SyncReceiptsPassedLogs []types.Log
 }
 
-func (syncer *MockTransactionSyncer) SyncTransactions(headerID int64, logs []types.Log) error {
+func (syncer *MockTransactionSyncer) SyncTransactions(ctx context.Context, headerID int64, logs []types.Log) error {
 	syncer.SyncTransactionsCalled = true
 	return syncer.SyncTransactionsError
 }
+
+func (syncer *MockTransactionSyncer) SyncReceipts(ctx context.Context, headerID int64, logs []types.Log) error {
+	syncer.SyncReceiptsCalled = true
+	syncer.SyncReceiptsPassedLogs = append(syncer.SyncReceiptsPassedLogs, logs...)
+	return syncer.SyncReceiptsError
+}
@@ -0,0 +1,98 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+)
+
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Checks that the database accepts connections
+func DatabaseCheck(db Pinger) Check {
+	return Check{
+		Name: "database",
+		Run:  db.PingContext,
+	}
+}
+
+// Checks that the node returns its chain head and, unless maxHeaderLag is zero, that the most recent synced header is
+// no more than maxHeaderLag blocks behind it
+func NodeCheck(bc core.BlockChain, headerRepository datastore.HeaderRepository, maxHeaderLag int64) Check {
+	return Check{
+		Name: "node",
+		Run: func(ctx context.Context) error {
+			chainHead, chainHeadErr := bc.LastBlock(ctx)
+			if chainHeadErr != nil {
+				return fmt.Errorf("error getting chain head: %s", chainHeadErr.Error())
+			}
+			if maxHeaderLag == 0 {
+				return nil
+			}
+			mostRecentHeader, headerErr := headerRepository.GetMostRecentHeaderBlockNumber()
+			if headerErr != nil {
+				return fmt.Errorf("error getting most recent header: %s", headerErr.Error())
+			}
+			lag := chainHead.Int64() - mostRecentHeader
+			if lag > maxHeaderLag {
+				return fmt.Errorf("most recent header is %d blocks behind the chain head, more than the maximum of %d",
+					lag, maxHeaderLag)
+			}
+			return nil
+		},
+	}
+}
+
+// Checks that each component in maxStaleness that has reported progress to tracker last did so within its maximum
+// staleness. Components without a maximum staleness, or with a zero one, are not checked.
+func ProgressCheck(tracker *ProgressTracker, maxStaleness map[string]time.Duration) Check {
+	return Check{
+		Name: "progress",
+		Run: func(ctx context.Context) error {
+			var staleComponents []string
+			for component, lastProgress := range tracker.LastProgress() {
+				componentMaxStaleness := maxStaleness[component]
+				if componentMaxStaleness == 0 {
+					continue
+				}
+				if sinceProgress := time.Since(lastProgress); sinceProgress > componentMaxStaleness {
+					staleComponents = append(staleComponents, fmt.Sprintf("%s (%s ago)", component,
+						sinceProgress.Round(time.Second)))
+				}
+			}
+			if len(staleComponents) > 0 {
+				sort.Strings(staleComponents)
+				return fmt.Errorf("no recent progress from %s", strings.Join(staleComponents, ", "))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,153 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package health_test
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/pkg/health"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakePinger struct {
+	err error
+}
+
+func (pinger fakePinger) PingContext(ctx context.Context) error {
+	return pinger.err
+}
+
+var _ = Describe("Health checks", func() {
+	Describe("DatabaseCheck", func() {
+		It("passes if the database can be pinged", func() {
+			check := health.DatabaseCheck(fakePinger{})
+
+			Expect(check.Run(context.Background())).To(Succeed())
+		})
+
+		It("fails if pinging the database fails", func() {
+			check := health.DatabaseCheck(fakePinger{err: fakes.FakeError})
+
+			Expect(check.Run(context.Background())).To(MatchError(fakes.FakeError))
+		})
+	})
+
+	Describe("NodeCheck", func() {
+		var (
+			blockChain       *fakes.MockBlockChain
+			headerRepository *fakes.MockHeaderRepository
+		)
+
+		BeforeEach(func() {
+			blockChain = fakes.NewMockBlockChain()
+			blockChain.SetLastBlock(big.NewInt(100))
+			headerRepository = fakes.NewMockHeaderRepository()
+			headerRepository.MostRecentHeaderBlockNumber = 90
+		})
+
+		It("passes if the most recent header is within the maximum lag", func() {
+			check := health.NodeCheck(blockChain, headerRepository, 10)
+
+			Expect(check.Run(context.Background())).To(Succeed())
+		})
+
+		It("fails if the most recent header lags further behind the chain head", func() {
+			check := health.NodeCheck(blockChain, headerRepository, 9)
+
+			err := check.Run(context.Background())
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("10 blocks behind"))
+		})
+
+		It("does not check the lag if the maximum lag is zero", func() {
+			headerRepository.MostRecentHeaderBlockNumberError = fakes.FakeError
+			check := health.NodeCheck(blockChain, headerRepository, 0)
+
+			Expect(check.Run(context.Background())).To(Succeed())
+		})
+
+		It("fails if getting the chain head fails", func() {
+			blockChain.SetLastBlockErr(fakes.FakeError)
+			check := health.NodeCheck(blockChain, headerRepository, 0)
+
+			err := check.Run(context.Background())
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(fakes.FakeError.Error()))
+		})
+
+		It("fails if getting the most recent header fails", func() {
+			headerRepository.MostRecentHeaderBlockNumberError = fakes.FakeError
+			check := health.NodeCheck(blockChain, headerRepository, 10)
+
+			err := check.Run(context.Background())
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(fakes.FakeError.Error()))
+		})
+	})
+
+	Describe("ProgressCheck", func() {
+		var tracker *health.ProgressTracker
+
+		BeforeEach(func() {
+			tracker = health.NewProgressTracker()
+		})
+
+		It("passes if every component made progress within its maximum staleness", func() {
+			tracker.RecordProgress(health.ExtractLogs)
+			check := health.ProgressCheck(tracker, map[string]time.Duration{health.ExtractLogs: time.Hour})
+
+			Expect(check.Run(context.Background())).To(Succeed())
+		})
+
+		It("fails if a component has not made progress within its maximum staleness", func() {
+			tracker.RecordProgress(health.ExtractLogs)
+			tracker.RecordProgress(health.DelegateLogs)
+			check := health.ProgressCheck(tracker, map[string]time.Duration{
+				health.ExtractLogs:  time.Nanosecond,
+				health.DelegateLogs: time.Hour,
+			})
+			time.Sleep(time.Millisecond)
+
+			err := check.Run(context.Background())
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(health.ExtractLogs))
+			Expect(err.Error()).NotTo(ContainSubstring(health.DelegateLogs))
+		})
+
+		It("does not check components without a maximum staleness", func() {
+			tracker.RecordProgress(health.StorageDiffs)
+			check := health.ProgressCheck(tracker, map[string]time.Duration{health.StorageDiffs: 0})
+			time.Sleep(time.Millisecond)
+
+			Expect(check.Run(context.Background())).To(Succeed())
+		})
+
+		It("does not check components that have not reported progress", func() {
+			check := health.ProgressCheck(tracker, map[string]time.Duration{health.StorageDiffs: time.Nanosecond})
+
+			Expect(check.Run(context.Background())).To(Succeed())
+		})
+	})
+})
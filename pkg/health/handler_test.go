@@ -0,0 +1,53 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package health_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/makerdao/vulcanizedb/pkg/health"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var errCheckFailed = errors.New("check failed")
+
+var _ = Describe("Handler", func() {
+	passingCheck := health.Check{Name: "passing", Run: func(ctx context.Context) error { return nil }}
+	failingCheck := health.Check{Name: "failing", Run: func(ctx context.Context) error { return errCheckFailed }}
+
+	It("responds OK with each check's result if every check passes", func() {
+		recorder := httptest.NewRecorder()
+
+		health.Handler(passingCheck).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(Equal("passing: ok\n"))
+	})
+
+	It("responds service unavailable with each check's result if any check fails", func() {
+		recorder := httptest.NewRecorder()
+
+		health.Handler(passingCheck, failingCheck).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(recorder.Body.String()).To(Equal("passing: ok\nfailing: failed: check failed\n"))
+	})
+})
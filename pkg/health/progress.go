@@ -0,0 +1,64 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// Components that report their progress to the DefaultProgressTracker
+const (
+	DelegateLogs    = "delegate_logs"
+	ExtractLogs     = "extract_logs"
+	StorageDiffs    = "storage_diffs"
+	ValidateHeaders = "validate_headers"
+)
+
+// Tracks the last time each component made progress
+type ProgressTracker struct {
+	mutex        sync.RWMutex
+	lastProgress map[string]time.Time
+}
+
+var DefaultProgressTracker = NewProgressTracker()
+
+func NewProgressTracker() *ProgressTracker {
+	return &ProgressTracker{lastProgress: make(map[string]time.Time)}
+}
+
+func (tracker *ProgressTracker) RecordProgress(component string) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	tracker.lastProgress[component] = time.Now()
+}
+
+// Returns when each component that has reported progress last did so
+func (tracker *ProgressTracker) LastProgress() map[string]time.Time {
+	tracker.mutex.RLock()
+	defer tracker.mutex.RUnlock()
+	lastProgress := make(map[string]time.Time, len(tracker.lastProgress))
+	for component, timestamp := range tracker.lastProgress {
+		lastProgress[component] = timestamp
+	}
+	return lastProgress
+}
+
+// Records that the named component made progress, e.g. finished a unit of work, in the DefaultProgressTracker
+func RecordProgress(component string) {
+	DefaultProgressTracker.RecordProgress(component)
+}
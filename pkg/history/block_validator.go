@@ -17,6 +17,7 @@
 package history
 
 import (
+	"context"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore"
 	"github.com/sirupsen/logrus"
@@ -50,7 +51,7 @@ func (bv BlockValidator) ValidateBlocks() (ValidationWindow, error) {
 		return ValidationWindow{}, err
 	}
 
-	lastBlock, err := bv.blockchain.LastBlock()
+	lastBlock, err := bv.blockchain.LastBlock(context.Background())
 	if err != nil {
 		logrus.Error("ValidateBlocks: error getting last block: ", err)
 		return ValidationWindow{}, err
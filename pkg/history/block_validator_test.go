@@ -17,6 +17,7 @@
 package history_test
 
 import (
+	"context"
 	"math/big"
 
 	. "github.com/onsi/ginkgo"
@@ -44,7 +45,7 @@ var _ = Describe("Blocks validator", func() {
 	It("returns the number of largest block", func() {
 		blockChain := fakes.NewMockBlockChain()
 		blockChain.SetLastBlock(big.NewInt(3))
-		maxBlockNumber, _ := blockChain.LastBlock()
+		maxBlockNumber, _ := blockChain.LastBlock(context.Background())
 
 		Expect(maxBlockNumber.Int64()).To(Equal(int64(3)))
 	})
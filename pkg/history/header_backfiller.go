@@ -0,0 +1,208 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultBackfillMaxRetries    = 5
+	DefaultBackfillRetryInterval = time.Second
+	backfillProgressInterval     = 30 * time.Second
+)
+
+// Populates missing headers with a pool of workers, each fetching batches of block numbers over batch RPC calls and
+// persisting every batch in a single insert. Missing block numbers are read from the database on every run, so an
+// interrupted backfill resumes where it left off.
+type HeaderBackfiller struct {
+	BatchSize        int64
+	MaxRetries       int
+	RetryInterval    time.Duration
+	Workers          int
+	blockChain       core.BlockChain
+	headerRepository datastore.HeaderRepository
+}
+
+type backfillResult struct {
+	populated int
+	err       error
+}
+
+// Workers and batchSize are raised to at least one
+func NewHeaderBackfiller(blockChain core.BlockChain, headerRepository datastore.HeaderRepository, workers int, batchSize int64) HeaderBackfiller {
+	if workers < 1 {
+		workers = 1
+	}
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	return HeaderBackfiller{
+		BatchSize:        batchSize,
+		MaxRetries:       DefaultBackfillMaxRetries,
+		RetryInterval:    DefaultBackfillRetryInterval,
+		Workers:          workers,
+		blockChain:       blockChain,
+		headerRepository: headerRepository,
+	}
+}
+
+// Fetches and persists every header missing between startingBlockNumber and the chain head, returning how many were
+// populated. Stops dispatching batches after the first batch fails all of its retries or the context is cancelled.
+func (backfiller HeaderBackfiller) Backfill(ctx context.Context, startingBlockNumber int64) (int, error) {
+	lastBlock, lastBlockErr := backfiller.blockChain.LastBlock(ctx)
+	if lastBlockErr != nil {
+		logrus.Error("Backfill: Error getting last block: ", lastBlockErr)
+		return 0, lastBlockErr
+	}
+
+	blockNumbers, missingErr := backfiller.headerRepository.MissingBlockNumbers(startingBlockNumber, lastBlock.Int64())
+	if missingErr != nil {
+		logrus.Error("Backfill: Error getting missing block numbers: ", missingErr)
+		return 0, missingErr
+	}
+	if len(blockNumbers) == 0 {
+		return 0, nil
+	}
+
+	batches := chunkBlockNumbers(blockNumbers, backfiller.BatchSize)
+	batchesChan := make(chan []int64)
+	resultsChan := make(chan backfillResult)
+	quit := make(chan struct{})
+
+	go func() {
+		defer close(batchesChan)
+		for _, batch := range batches {
+			select {
+			case batchesChan <- batch:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < backfiller.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batchesChan {
+				populated, err := backfiller.backfillBatch(ctx, batch)
+				resultsChan <- backfillResult{populated: populated, err: err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(resultsChan)
+	}()
+
+	var populated int
+	var backfillErr error
+	startTime := time.Now()
+	lastProgressLog := startTime
+	for result := range resultsChan {
+		populated += result.populated
+		if result.err != nil && backfillErr == nil {
+			backfillErr = result.err
+			close(quit)
+		}
+		if time.Since(lastProgressLog) >= backfillProgressInterval {
+			logBackfillProgress(populated, len(blockNumbers), startTime)
+			lastProgressLog = time.Now()
+		}
+	}
+
+	if backfillErr != nil {
+		logrus.Error("Backfill: Error populating headers: ", backfillErr)
+		return populated, backfillErr
+	}
+	if len(batches) > 1 {
+		logBackfillProgress(populated, len(blockNumbers), startTime)
+	}
+	return populated, nil
+}
+
+func (backfiller HeaderBackfiller) backfillBatch(ctx context.Context, blockNumbers []int64) (int, error) {
+	headers, fetchErr := backfiller.fetchHeadersWithRetry(ctx, blockNumbers)
+	if fetchErr != nil {
+		return 0, fetchErr
+	}
+	createErr := backfiller.headerRepository.CreateHeaders(headers)
+	if createErr != nil {
+		return 0, createErr
+	}
+	return len(headers), nil
+}
+
+// Retries failed batch calls with exponential backoff, starting at RetryInterval, until the context is cancelled
+func (backfiller HeaderBackfiller) fetchHeadersWithRetry(ctx context.Context, blockNumbers []int64) ([]core.Header, error) {
+	for attempt := 0; ; attempt++ {
+		headers, err := backfiller.blockChain.GetHeadersByNumbers(ctx, blockNumbers)
+		if err == nil {
+			return headers, nil
+		}
+		if attempt >= backfiller.MaxRetries {
+			return nil, err
+		}
+		delay := backfiller.RetryInterval * time.Duration(1<<uint(attempt))
+		logrus.WithFields(logrus.Fields{
+			"startingBlock": blockNumbers[0],
+			"endingBlock":   blockNumbers[len(blockNumbers)-1],
+			"attempt":       attempt + 1,
+		}).Warnf("error fetching headers, retrying in %s: %s", delay, err.Error())
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func chunkBlockNumbers(blockNumbers []int64, batchSize int64) [][]int64 {
+	var batches [][]int64
+	for start := int64(0); start < int64(len(blockNumbers)); start += batchSize {
+		end := start + batchSize
+		if end > int64(len(blockNumbers)) {
+			end = int64(len(blockNumbers))
+		}
+		batches = append(batches, blockNumbers[start:end])
+	}
+	return batches
+}
+
+func logBackfillProgress(populated, total int, startTime time.Time) {
+	elapsed := time.Since(startTime)
+	rate := float64(populated) / elapsed.Seconds()
+	fields := logrus.Fields{
+		"populated":     populated,
+		"total":         total,
+		"headersPerSec": int64(rate),
+	}
+	if rate > 0 && populated < total {
+		fields["eta"] = (time.Duration(float64(total-populated)/rate) * time.Second).String()
+	}
+	logrus.WithFields(fields).Infof("backfilled %.1f%% of missing headers", float64(populated)/float64(total)*100)
+}
@@ -0,0 +1,123 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history_test
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/pkg/history"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Header backfiller", func() {
+	var (
+		blockChain       *fakes.MockBlockChain
+		headerRepository *fakes.MockHeaderRepository
+		backfiller       history.HeaderBackfiller
+	)
+
+	BeforeEach(func() {
+		blockChain = fakes.NewMockBlockChain()
+		blockChain.SetLastBlock(big.NewInt(10))
+		headerRepository = fakes.NewMockHeaderRepository()
+		backfiller = history.NewHeaderBackfiller(blockChain, headerRepository, 3, 2)
+		backfiller.RetryInterval = time.Millisecond
+	})
+
+	It("returns early if there are no missing headers", func() {
+		populated, err := backfiller.Backfill(context.Background(), 1)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(populated).To(Equal(0))
+		Expect(blockChain.GetHeadersByNumbersCallCount).To(Equal(0))
+	})
+
+	It("fetches missing headers in batches", func() {
+		headerRepository.SetMissingBlockNumbers([]int64{1, 2, 3, 5, 8})
+
+		populated, err := backfiller.Backfill(context.Background(), 1)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(populated).To(Equal(5))
+		Expect(blockChain.GetHeadersByNumbersCallCount).To(Equal(3))
+	})
+
+	It("persists every fetched header", func() {
+		headerRepository.SetMissingBlockNumbers([]int64{1, 2, 3, 5, 8})
+
+		_, err := backfiller.Backfill(context.Background(), 1)
+
+		Expect(err).NotTo(HaveOccurred())
+		headerRepository.AssertCreateHeadersCalledWithBlockNumbers([]int64{1, 2, 3, 5, 8})
+	})
+
+	It("retries batches that fail to fetch", func() {
+		headerRepository.SetMissingBlockNumbers([]int64{1, 2})
+		blockChain.SetGetHeadersByNumbersErr(fakes.FakeError, 2)
+
+		populated, err := backfiller.Backfill(context.Background(), 1)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(populated).To(Equal(2))
+		Expect(blockChain.GetHeadersByNumbersCallCount).To(Equal(3))
+	})
+
+	It("returns an error if a batch fails all retries", func() {
+		headerRepository.SetMissingBlockNumbers([]int64{1, 2})
+		backfiller.MaxRetries = 1
+		blockChain.SetGetHeadersByNumbersErr(fakes.FakeError, 2)
+
+		_, err := backfiller.Backfill(context.Background(), 1)
+
+		Expect(err).To(MatchError(fakes.FakeError))
+		headerRepository.AssertCreateHeadersCalledWithBlockNumbers([]int64{})
+	})
+
+	It("stops waiting to retry when the context is cancelled", func() {
+		headerRepository.SetMissingBlockNumbers([]int64{1, 2})
+		backfiller.RetryInterval = time.Hour
+		blockChain.SetGetHeadersByNumbersErr(fakes.FakeError, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := backfiller.Backfill(ctx, 1)
+
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(blockChain.GetHeadersByNumbersCallCount).To(Equal(1))
+	})
+
+	It("returns an error if persisting headers fails", func() {
+		headerRepository.SetMissingBlockNumbers([]int64{1, 2})
+		headerRepository.CreateHeadersError = fakes.FakeError
+
+		_, err := backfiller.Backfill(context.Background(), 1)
+
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+
+	It("returns an error if getting the last block fails", func() {
+		blockChain.SetLastBlockErr(fakes.FakeError)
+
+		_, err := backfiller.Backfill(context.Background(), 1)
+
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+})
@@ -0,0 +1,122 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"context"
+
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+// Re-fetches headers with broken parent links, replacing stale ones through the reorg handler so that the replacement
+// is recorded and reorg hooks run. Every link is checked on the first run and after headers have been backfilled,
+// since inserting a header can break links anywhere in the chain. Otherwise stored headers are only replaced near the
+// chain head, so only links from RecheckDepth blocks below the last clean run's head are checked.
+type HeaderLinkRepairer struct {
+	RecheckDepth     int64
+	blockChain       core.BlockChain
+	headerRepository datastore.HeaderRepository
+	reorgHandler     IReorgHandler
+	checkFrom        int64
+}
+
+func NewHeaderLinkRepairer(blockChain core.BlockChain, headerRepository datastore.HeaderRepository, reorgHandler IReorgHandler, recheckDepth int64) *HeaderLinkRepairer {
+	return &HeaderLinkRepairer{
+		RecheckDepth:     recheckDepth,
+		blockChain:       blockChain,
+		headerRepository: headerRepository,
+		reorgHandler:     reorgHandler,
+	}
+}
+
+// Repairs the broken links above startingBlockNumber, returning how many were repaired. backfilled is whether headers
+// have been inserted since the last run. Runs that repair links are checked again from the same block, since
+// re-fetched headers may break the links below them.
+func (repairer *HeaderLinkRepairer) Repair(startingBlockNumber int64, backfilled bool) (int, error) {
+	lastBlock, err := repairer.blockChain.LastBlock(context.Background())
+	if err != nil {
+		logrus.Error("Repair: Error getting last block: ", err)
+		return 0, err
+	}
+
+	from := startingBlockNumber + 1
+	if !backfilled && repairer.checkFrom > from {
+		from = repairer.checkFrom
+	}
+	repaired, err := repairer.repairBrokenLinks(from, lastBlock.Int64())
+	if err != nil {
+		return 0, err
+	}
+	if repaired == 0 {
+		repairer.checkFrom = lastBlock.Int64() - repairer.RecheckDepth
+	} else {
+		repairer.checkFrom = from
+	}
+	return repaired, nil
+}
+
+// Re-fetches both headers on either side of every broken link between the block numbers, since either one may be the
+// stale header. Returns the number of those links that are no longer broken.
+func (repairer *HeaderLinkRepairer) repairBrokenLinks(startingBlockNumber, endingBlockNumber int64) (int, error) {
+	brokenLinks, err := repairer.headerRepository.BrokenLinkBlockNumbers(startingBlockNumber, endingBlockNumber)
+	if err != nil {
+		logrus.Error("Repair: Error getting broken link block numbers: ", err)
+		return 0, err
+	} else if len(brokenLinks) == 0 {
+		return 0, nil
+	}
+
+	var blockNumbers []int64
+	seen := make(map[int64]bool)
+	for _, child := range brokenLinks {
+		for _, blockNumber := range []int64{child - 1, child} {
+			if !seen[blockNumber] {
+				seen[blockNumber] = true
+				blockNumbers = append(blockNumbers, blockNumber)
+			}
+		}
+	}
+
+	logrus.Warnf("Repair: re-fetching headers with broken parent links: %v", brokenLinks)
+	_, err = repairer.reorgHandler.UpdateHeaders(blockNumbers)
+	if err != nil {
+		logrus.Error("Repair: Error updating headers: ", err)
+		return 0, err
+	}
+
+	remainingLinks, err := repairer.headerRepository.BrokenLinkBlockNumbers(brokenLinks[0], brokenLinks[len(brokenLinks)-1])
+	if err != nil {
+		logrus.Error("Repair: Error getting remaining broken link block numbers: ", err)
+		return 0, err
+	}
+	stillBroken := make(map[int64]bool)
+	for _, child := range remainingLinks {
+		stillBroken[child] = true
+	}
+	repaired := 0
+	for _, child := range brokenLinks {
+		if !stillBroken[child] {
+			repaired++
+		}
+	}
+	if repaired < len(brokenLinks) {
+		logrus.Warnf("Repair: %d header links are still broken after re-fetching", len(brokenLinks)-repaired)
+	}
+	return repaired, nil
+}
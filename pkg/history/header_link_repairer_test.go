@@ -0,0 +1,143 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history_test
+
+import (
+	"math/big"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/pkg/history"
+)
+
+var _ = Describe("Header link repairer", func() {
+	var (
+		blockChain       *fakes.MockBlockChain
+		headerRepository *fakes.MockHeaderRepository
+		reorgHandler     *fakes.MockReorgHandler
+		repairer         *history.HeaderLinkRepairer
+	)
+
+	BeforeEach(func() {
+		blockChain = fakes.NewMockBlockChain()
+		blockChain.SetLastBlock(big.NewInt(100))
+		headerRepository = fakes.NewMockHeaderRepository()
+		reorgHandler = &fakes.MockReorgHandler{}
+		repairer = history.NewHeaderLinkRepairer(blockChain, headerRepository, reorgHandler, 10)
+	})
+
+	It("checks every link on the first run", func() {
+		_, err := repairer.Repair(1, false)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(headerRepository.BrokenLinkPassedStartingBlockNumber).To(Equal(int64(2)))
+		Expect(headerRepository.BrokenLinkPassedEndingBlockNumber).To(Equal(int64(100)))
+	})
+
+	It("only checks links near the head after a clean run", func() {
+		_, firstErr := repairer.Repair(1, false)
+		Expect(firstErr).NotTo(HaveOccurred())
+		blockChain.SetLastBlock(big.NewInt(105))
+
+		_, err := repairer.Repair(1, false)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(headerRepository.BrokenLinkPassedStartingBlockNumber).To(Equal(int64(90)))
+		Expect(headerRepository.BrokenLinkPassedEndingBlockNumber).To(Equal(int64(105)))
+	})
+
+	It("checks every link again after headers are backfilled", func() {
+		_, firstErr := repairer.Repair(1, false)
+		Expect(firstErr).NotTo(HaveOccurred())
+
+		_, err := repairer.Repair(1, true)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(headerRepository.BrokenLinkPassedStartingBlockNumber).To(Equal(int64(2)))
+	})
+
+	It("re-fetches the headers on both sides of each broken link through the reorg handler", func() {
+		headerRepository.SetBrokenLinkBlockNumbers([]int64{4, 5, 8})
+
+		_, err := repairer.Repair(1, false)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reorgHandler.UpdateHeadersPassedNumbers).To(Equal([]int64{3, 4, 5, 7, 8}))
+		headerRepository.AssertCreateOrUpdateHeaderCallCountAndPassedBlockNumbers(0, []int64(nil))
+	})
+
+	It("returns the number of links repaired", func() {
+		headerRepository.SetBrokenLinkBlockNumbers([]int64{4, 5, 8})
+		headerRepository.SetRemainingBrokenLinkBlockNumbers([]int64{8})
+
+		repaired, err := repairer.Repair(1, false)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repaired).To(Equal(2))
+		Expect(headerRepository.BrokenLinkPassedStartingBlockNumber).To(Equal(int64(4)))
+		Expect(headerRepository.BrokenLinkPassedEndingBlockNumber).To(Equal(int64(8)))
+	})
+
+	It("returns zero if re-fetching the headers repairs none of the links", func() {
+		headerRepository.SetBrokenLinkBlockNumbers([]int64{50})
+
+		repaired, err := repairer.Repair(1, false)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repaired).To(BeZero())
+	})
+
+	It("does not fetch headers if there are no broken links", func() {
+		repaired, err := repairer.Repair(1, false)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repaired).To(BeZero())
+		Expect(reorgHandler.UpdateHeadersCallCount).To(BeZero())
+	})
+
+	It("returns an error if updating headers fails", func() {
+		headerRepository.SetBrokenLinkBlockNumbers([]int64{50})
+		reorgHandler.UpdateHeadersReturnError = fakes.FakeError
+
+		_, err := repairer.Repair(1, false)
+
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+
+	It("checks the same links again after repairing some", func() {
+		headerRepository.SetBrokenLinkBlockNumbers([]int64{50})
+		headerRepository.SetRemainingBrokenLinkBlockNumbers(nil)
+		repaired, firstErr := repairer.Repair(1, false)
+		Expect(firstErr).NotTo(HaveOccurred())
+		Expect(repaired).To(Equal(1))
+
+		_, err := repairer.Repair(1, false)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(headerRepository.BrokenLinkPassedStartingBlockNumber).To(Equal(int64(2)))
+	})
+
+	It("returns an error if getting broken links fails", func() {
+		headerRepository.BrokenLinkBlockNumbersError = fakes.FakeError
+
+		_, err := repairer.Repair(1, false)
+
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+})
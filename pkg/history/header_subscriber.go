@@ -0,0 +1,135 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/makerdao/vulcanizedb/libraries/shared/streamer"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/makerdao/vulcanizedb/pkg/health"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrSubscriptionClosed = errors.New("new heads subscription closed")
+
+type HeaderSubscriber struct {
+	blockChain       core.BlockChain
+	headerRepository datastore.HeaderRepository
+	streamer         streamer.IHeadStreamer
+	validator        IHeaderValidator
+}
+
+func NewHeaderSubscriber(blockChain core.BlockChain, repository datastore.HeaderRepository, streamer streamer.IHeadStreamer, validator IHeaderValidator) HeaderSubscriber {
+	return HeaderSubscriber{
+		blockChain:       blockChain,
+		headerRepository: repository,
+		streamer:         streamer,
+		validator:        validator,
+	}
+}
+
+// Inserts headers as the node announces them until the context is cancelled or the subscription drops.
+// A head that doesn't extend the stored chain (a gap or a reorg) triggers a full validation of the window below the head.
+func (subscriber HeaderSubscriber) Subscribe(ctx context.Context) error {
+	heads := make(chan streamer.NewHead)
+	subscription, err := subscriber.streamer.Stream(heads)
+	if err != nil {
+		return err
+	}
+	defer subscription.Unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-subscription.Err():
+			if err == nil {
+				return ErrSubscriptionClosed
+			}
+			return err
+		case head := <-heads:
+			err := subscriber.syncNewHead(ctx, head)
+			if err != nil {
+				logrus.Error("HeaderSubscriber: error syncing new head: ", err)
+				continue
+			}
+			health.RecordProgress(health.ValidateHeaders)
+		}
+	}
+}
+
+func (subscriber HeaderSubscriber) syncNewHead(ctx context.Context, head streamer.NewHead) error {
+	if head.Number == nil {
+		return subscriber.validate()
+	}
+	blockNumber := head.Number.ToInt().Int64()
+	extendsChain, err := subscriber.extendsStoredChain(blockNumber, head)
+	if err != nil {
+		return err
+	}
+	if !extendsChain {
+		logrus.Infof("HeaderSubscriber: head %d does not extend stored chain, validating headers", blockNumber)
+		return subscriber.validate()
+	}
+
+	header, err := subscriber.blockChain.GetHeaderByNumber(ctx, blockNumber)
+	if err != nil {
+		return err
+	}
+	_, err = subscriber.headerRepository.CreateOrUpdateHeader(header)
+	if err != nil && err != repositories.ErrValidHeaderExists {
+		return err
+	}
+	return nil
+}
+
+// A head extends the stored chain if its parent is stored and no other header is stored at its height
+func (subscriber HeaderSubscriber) extendsStoredChain(blockNumber int64, head streamer.NewHead) (bool, error) {
+	parent, err := subscriber.headerRepository.GetHeader(blockNumber - 1)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if parent.Hash != head.ParentHash.Hex() {
+		return false, nil
+	}
+
+	existing, err := subscriber.headerRepository.GetHeader(blockNumber)
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return existing.Hash == head.Hash.Hex(), nil
+}
+
+func (subscriber HeaderSubscriber) validate() error {
+	window, err := subscriber.validator.ValidateHeaders()
+	if err != nil {
+		return err
+	}
+	logrus.Debug(window.GetString())
+	return nil
+}
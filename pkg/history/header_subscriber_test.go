@@ -0,0 +1,166 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history_test
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/makerdao/vulcanizedb/libraries/shared/mocks"
+	"github.com/makerdao/vulcanizedb/libraries/shared/streamer"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/pkg/history"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Header subscriber", func() {
+	var (
+		blockChain       *fakes.MockBlockChain
+		headerRepository *fakes.MockHeaderRepository
+		headStreamer     *mocks.MockHeadStreamer
+		validator        *fakes.MockHeaderValidator
+		subscriber       history.HeaderSubscriber
+		parentHash       = common.HexToHash("0x1")
+		headHash         = common.HexToHash("0x2")
+	)
+
+	BeforeEach(func() {
+		blockChain = fakes.NewMockBlockChain()
+		headerRepository = fakes.NewMockHeaderRepository()
+		headStreamer = mocks.NewMockHeadStreamer()
+		headStreamer.ErrToReturn = fakes.FakeError
+		validator = &fakes.MockHeaderValidator{}
+		subscriber = history.NewHeaderSubscriber(blockChain, headerRepository, headStreamer, validator)
+	})
+
+	newHead := func(number int64, hash, parent common.Hash) streamer.NewHead {
+		return streamer.NewHead{Number: (*hexutil.Big)(big.NewInt(number)), Hash: hash, ParentHash: parent}
+	}
+
+	It("returns an error if subscribing fails", func() {
+		headStreamer.StreamErr = fakes.FakeError
+
+		err := subscriber.Subscribe(context.Background())
+
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+
+	It("returns the subscription error and unsubscribes when the subscription drops", func() {
+		err := subscriber.Subscribe(context.Background())
+
+		Expect(err).To(MatchError(fakes.FakeError))
+		Expect(headStreamer.Subscription.UnsubscribeCalled).To(BeTrue())
+	})
+
+	It("returns an error if the subscription closes without an error", func() {
+		headStreamer.ErrToReturn = nil
+
+		err := subscriber.Subscribe(context.Background())
+
+		Expect(err).To(MatchError(history.ErrSubscriptionClosed))
+	})
+
+	It("returns without an error when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		headStreamer.KeepSubscription = true
+
+		err := subscriber.Subscribe(ctx)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(headStreamer.Subscription.UnsubscribeCalled).To(BeTrue())
+	})
+
+	It("inserts a head that extends the stored chain", func() {
+		headerRepository.SetStoredHeaders([]core.Header{{BlockNumber: 9, Hash: parentHash.Hex()}})
+		headStreamer.HeadsToStream = []streamer.NewHead{newHead(10, headHash, parentHash)}
+
+		err := subscriber.Subscribe(context.Background())
+
+		Expect(err).To(MatchError(fakes.FakeError))
+		headerRepository.AssertCreateOrUpdateHeaderCallCountAndPassedBlockNumbers(1, []int64{10})
+		Expect(validator.ValidateHeadersCallCount).To(BeZero())
+	})
+
+	It("ignores a head that is already stored", func() {
+		headerRepository.SetStoredHeaders([]core.Header{
+			{BlockNumber: 9, Hash: parentHash.Hex()},
+			{BlockNumber: 10, Hash: headHash.Hex()},
+		})
+		headerRepository.SetCreateOrUpdateHeaderReturnErr(repositories.ErrValidHeaderExists)
+		headStreamer.HeadsToStream = []streamer.NewHead{newHead(10, headHash, parentHash)}
+
+		err := subscriber.Subscribe(context.Background())
+
+		Expect(err).To(MatchError(fakes.FakeError))
+		Expect(validator.ValidateHeadersCallCount).To(BeZero())
+	})
+
+	It("validates headers when the parent of a head isn't stored", func() {
+		headerRepository.SetStoredHeaders([]core.Header{{BlockNumber: 8, Hash: parentHash.Hex()}})
+		headStreamer.HeadsToStream = []streamer.NewHead{newHead(10, headHash, parentHash)}
+
+		err := subscriber.Subscribe(context.Background())
+
+		Expect(err).To(MatchError(fakes.FakeError))
+		headerRepository.AssertCreateOrUpdateHeaderCallCountAndPassedBlockNumbers(0, nil)
+		Expect(validator.ValidateHeadersCallCount).To(Equal(1))
+	})
+
+	It("validates headers when the parent hash of a head doesn't match the stored parent", func() {
+		headerRepository.SetStoredHeaders([]core.Header{{BlockNumber: 9, Hash: common.HexToHash("0x3").Hex()}})
+		headStreamer.HeadsToStream = []streamer.NewHead{newHead(10, headHash, parentHash)}
+
+		err := subscriber.Subscribe(context.Background())
+
+		Expect(err).To(MatchError(fakes.FakeError))
+		headerRepository.AssertCreateOrUpdateHeaderCallCountAndPassedBlockNumbers(0, nil)
+		Expect(validator.ValidateHeadersCallCount).To(Equal(1))
+	})
+
+	It("validates headers when a different header is stored at the height of a head", func() {
+		headerRepository.SetStoredHeaders([]core.Header{
+			{BlockNumber: 9, Hash: parentHash.Hex()},
+			{BlockNumber: 10, Hash: common.HexToHash("0x3").Hex()},
+		})
+		headStreamer.HeadsToStream = []streamer.NewHead{newHead(10, headHash, parentHash)}
+
+		err := subscriber.Subscribe(context.Background())
+
+		Expect(err).To(MatchError(fakes.FakeError))
+		Expect(validator.ValidateHeadersCallCount).To(Equal(1))
+	})
+
+	It("keeps streaming after failing to sync a head", func() {
+		headerRepository.SetStoredHeaders([]core.Header{{BlockNumber: 9, Hash: parentHash.Hex()}})
+		blockChain.SetGetHeaderByNumberErr(fakes.FakeError)
+		headStreamer.HeadsToStream = []streamer.NewHead{
+			newHead(10, headHash, parentHash),
+			newHead(12, headHash, parentHash),
+		}
+
+		err := subscriber.Subscribe(context.Background())
+
+		Expect(err).To(MatchError(fakes.FakeError))
+		Expect(validator.ValidateHeadersCallCount).To(Equal(1))
+	})
+})
@@ -22,30 +22,43 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+type IHeaderValidator interface {
+	ValidateHeaders() (ValidationWindow, error)
+}
+
 type HeaderValidator struct {
 	blockChain       core.BlockChain
 	headerRepository datastore.HeaderRepository
+	reorgHandler     IReorgHandler
 	windowSize       int
 }
 
-func NewHeaderValidator(blockChain core.BlockChain, repository datastore.HeaderRepository, windowSize int) HeaderValidator {
+func NewHeaderValidator(blockChain core.BlockChain, repository datastore.HeaderRepository, reorgHandler IReorgHandler, windowSize int) HeaderValidator {
 	return HeaderValidator{
 		blockChain:       blockChain,
 		headerRepository: repository,
+		reorgHandler:     reorgHandler,
 		windowSize:       windowSize,
 	}
 }
 
+// Handles any reorg from the head of the validation window, then fetches every header in the window through the reorg
+// handler, so that stored headers the walk back from the head didn't reach are replaced as a reorg as well
 func (validator HeaderValidator) ValidateHeaders() (ValidationWindow, error) {
 	window, err := MakeValidationWindow(validator.blockChain, validator.windowSize)
 	if err != nil {
 		logrus.Error("ValidateHeaders: error creating validation window: ", err)
 		return ValidationWindow{}, err
 	}
+	_, err = validator.reorgHandler.HandleReorg(window.UpperBound)
+	if err != nil {
+		logrus.Error("ValidateHeaders: error handling reorg: ", err)
+		return ValidationWindow{}, err
+	}
 	blockNumbers := MakeRange(window.LowerBound, window.UpperBound)
-	_, err = RetrieveAndUpdateHeaders(validator.blockChain, validator.headerRepository, blockNumbers)
+	_, err = validator.reorgHandler.UpdateHeaders(blockNumbers)
 	if err != nil {
-		logrus.Error("ValidateHeaders: error getting/updating headers: ", err)
+		logrus.Error("ValidateHeaders: error updating headers: ", err)
 		return ValidationWindow{}, err
 	}
 	return window, nil
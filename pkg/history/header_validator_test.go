@@ -31,31 +31,53 @@ var _ = Describe("Header validator", func() {
 	var (
 		headerRepository *fakes.MockHeaderRepository
 		blockChain       *fakes.MockBlockChain
+		reorgHandler     *fakes.MockReorgHandler
 	)
 
 	BeforeEach(func() {
 		headerRepository = fakes.NewMockHeaderRepository()
 		blockChain = fakes.NewMockBlockChain()
+		reorgHandler = &fakes.MockReorgHandler{}
 	})
 
-	It("attempts to create every header in the validation window", func() {
-		headerRepository.SetMissingBlockNumbers([]int64{})
+	It("updates every header in the validation window through the reorg handler", func() {
 		blockChain.SetLastBlock(big.NewInt(3))
-		validator := history.NewHeaderValidator(blockChain, headerRepository, 2)
+		validator := history.NewHeaderValidator(blockChain, headerRepository, reorgHandler, 2)
 
 		_, err := validator.ValidateHeaders()
 		Expect(err).NotTo(HaveOccurred())
 
-		headerRepository.AssertCreateOrUpdateHeaderCallCountAndPassedBlockNumbers(3, []int64{1, 2, 3})
+		Expect(reorgHandler.UpdateHeadersPassedNumbers).To(Equal([]int64{1, 2, 3}))
+		headerRepository.AssertCreateOrUpdateHeaderCallCountAndPassedBlockNumbers(0, []int64(nil))
 	})
 
-	It("propagates header repository errors", func() {
+	It("propagates errors updating headers", func() {
 		blockChain.SetLastBlock(big.NewInt(3))
-		headerRepositoryError := errors.New("CreateOrUpdate")
-		headerRepository.SetCreateOrUpdateHeaderReturnErr(headerRepositoryError)
-		validator := history.NewHeaderValidator(blockChain, headerRepository, 2)
+		updateError := errors.New("UpdateHeaders")
+		reorgHandler.UpdateHeadersReturnError = updateError
+		validator := history.NewHeaderValidator(blockChain, headerRepository, reorgHandler, 2)
 
 		_, err := validator.ValidateHeaders()
-		Expect(err).To(MatchError(headerRepositoryError))
+		Expect(err).To(MatchError(updateError))
+	})
+
+	It("handles reorgs from the head of the validation window", func() {
+		blockChain.SetLastBlock(big.NewInt(3))
+		validator := history.NewHeaderValidator(blockChain, headerRepository, reorgHandler, 2)
+
+		_, err := validator.ValidateHeaders()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(reorgHandler.HandleReorgPassedBlockNumber).To(Equal(int64(3)))
+	})
+
+	It("propagates reorg handler errors", func() {
+		blockChain.SetLastBlock(big.NewInt(3))
+		reorgHandler.HandleReorgReturnError = fakes.FakeError
+		validator := history.NewHeaderValidator(blockChain, headerRepository, reorgHandler, 2)
+
+		_, err := validator.ValidateHeaders()
+		Expect(err).To(MatchError(fakes.FakeError))
+		Expect(reorgHandler.UpdateHeadersCallCount).To(BeZero())
 	})
 })
@@ -17,6 +17,7 @@
 package history
 
 import (
+	"context"
 	"fmt"
 	log "github.com/sirupsen/logrus"
 
@@ -25,7 +26,7 @@ import (
 )
 
 func PopulateMissingBlocks(blockchain core.BlockChain, blockRepository datastore.BlockRepository, startingBlockNumber int64) (int, error) {
-	lastBlock, err := blockchain.LastBlock()
+	lastBlock, err := blockchain.LastBlock(context.Background())
 	if err != nil {
 		log.Error("PopulateMissingBlocks: error getting last block: ", err)
 		return 0, err
@@ -47,7 +48,7 @@ func PopulateMissingBlocks(blockchain core.BlockChain, blockRepository datastore
 
 func RetrieveAndUpdateBlocks(blockchain core.BlockChain, blockRepository datastore.BlockRepository, blockNumbers []int64) (int, error) {
 	for _, blockNumber := range blockNumbers {
-		block, err := blockchain.GetBlockByNumber(blockNumber)
+		block, err := blockchain.GetBlockByNumber(context.Background(), blockNumber)
 		if err != nil {
 			log.Error("RetrieveAndUpdateBlocks: error getting block: ", err)
 			return 0, err
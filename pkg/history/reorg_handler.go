@@ -0,0 +1,177 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrChainChangedDuringWalk = errors.New("parent hash mismatch while walking back to common ancestor")
+	ErrReorgTooDeep           = errors.New("reorg exceeds maximum depth")
+)
+
+// ReorgHook is invoked after a reorg has been persisted, so that dependent processes can react to orphaned headers
+type ReorgHook func(reorg core.Reorg) error
+
+type IReorgHandler interface {
+	HandleReorg(headBlockNumber int64) (bool, error)
+	UpdateHeaders(blockNumbers []int64) (int, error)
+}
+
+type ReorgHandler struct {
+	blockChain       core.BlockChain
+	headerRepository datastore.HeaderRepository
+	reorgRepository  datastore.ReorgRepository
+	maxDepth         int64
+	hooks            []ReorgHook
+}
+
+func NewReorgHandler(blockChain core.BlockChain, headerRepository datastore.HeaderRepository, reorgRepository datastore.ReorgRepository, maxDepth int64) *ReorgHandler {
+	return &ReorgHandler{
+		blockChain:       blockChain,
+		headerRepository: headerRepository,
+		reorgRepository:  reorgRepository,
+		maxDepth:         maxDepth,
+	}
+}
+
+// Registers a hook to be called with every reorg the handler persists
+func (handler *ReorgHandler) AddHook(hook ReorgHook) {
+	handler.hooks = append(handler.hooks, hook)
+}
+
+// Walks parent hashes back from the chain head until reaching a header matching the one in the db. If any stored
+// headers were orphaned along the way, they are replaced and the reorg is recorded before hooks are invoked.
+func (handler *ReorgHandler) HandleReorg(headBlockNumber int64) (bool, error) {
+	reorg, detectErr := handler.detectReorg(headBlockNumber)
+	if detectErr != nil {
+		logrus.Errorf("HandleReorg: error detecting reorg: %s", detectErr.Error())
+		return false, detectErr
+	}
+	if reorg.Depth() == 0 {
+		return false, nil
+	}
+
+	return true, handler.recordReorg(reorg)
+}
+
+// Fetches the headers at the block numbers, inserting those that aren't stored yet. Stored headers whose hash no longer
+// matches the chain's are replaced as a reorg below the lowest of them, which is recorded before hooks are invoked.
+// Returns the number of headers replaced.
+func (handler *ReorgHandler) UpdateHeaders(blockNumbers []int64) (int, error) {
+	headers, fetchErr := handler.blockChain.GetHeadersByNumbers(context.Background(), blockNumbers)
+	if fetchErr != nil {
+		return 0, fetchErr
+	}
+	sort.Slice(headers, func(i, j int) bool { return headers[i].BlockNumber > headers[j].BlockNumber })
+
+	var reorg core.Reorg
+	for _, header := range headers {
+		stored, getStoredErr := handler.headerRepository.GetHeader(header.BlockNumber)
+		if getStoredErr == sql.ErrNoRows {
+			_, createErr := handler.headerRepository.CreateOrUpdateHeader(header)
+			if createErr != nil {
+				return 0, createErr
+			}
+			continue
+		}
+		if getStoredErr != nil {
+			return 0, getStoredErr
+		}
+		if stored.Hash != header.Hash {
+			reorg.OldHeaders = append(reorg.OldHeaders, stored)
+			reorg.NewHeaders = append(reorg.NewHeaders, header)
+			reorg.CommonAncestor = header.BlockNumber - 1
+		}
+	}
+	if reorg.Depth() == 0 {
+		return 0, nil
+	}
+	return int(reorg.Depth()), handler.recordReorg(reorg)
+}
+
+// Persists the reorg, replacing its orphaned headers, and invokes hooks with it
+func (handler *ReorgHandler) recordReorg(reorg core.Reorg) error {
+	logrus.WithFields(logrus.Fields{
+		"commonAncestor": reorg.CommonAncestor,
+		"depth":          reorg.Depth(),
+	}).Warn("chain reorganization detected")
+
+	reorgID, createErr := handler.reorgRepository.CreateReorg(reorg)
+	if createErr != nil {
+		logrus.Errorf("error persisting reorg: %s", createErr.Error())
+		return createErr
+	}
+	reorg.ID = reorgID
+
+	for _, hook := range handler.hooks {
+		hookErr := hook(reorg)
+		if hookErr != nil {
+			logrus.Errorf("error executing reorg hook: %s", hookErr.Error())
+			return hookErr
+		}
+	}
+	return nil
+}
+
+func (handler *ReorgHandler) detectReorg(headBlockNumber int64) (core.Reorg, error) {
+	var reorg core.Reorg
+	current, getHeadErr := handler.blockChain.GetHeaderByNumber(context.Background(), headBlockNumber)
+	if getHeadErr != nil {
+		return core.Reorg{}, getHeadErr
+	}
+
+	for steps := int64(0); ; steps++ {
+		stored, getStoredErr := handler.headerRepository.GetHeader(current.BlockNumber)
+		if getStoredErr != nil && getStoredErr != sql.ErrNoRows {
+			return core.Reorg{}, getStoredErr
+		}
+		if getStoredErr == nil {
+			if stored.Hash == current.Hash {
+				reorg.CommonAncestor = current.BlockNumber
+				return reorg, nil
+			}
+			reorg.OldHeaders = append(reorg.OldHeaders, stored)
+			reorg.NewHeaders = append(reorg.NewHeaders, current)
+		}
+
+		if steps >= handler.maxDepth || current.BlockNumber == 0 {
+			if reorg.Depth() > 0 {
+				return core.Reorg{}, ErrReorgTooDeep
+			}
+			// no stored headers within reach of the head, so nothing can have been orphaned
+			return core.Reorg{}, nil
+		}
+
+		parent, getParentErr := handler.blockChain.GetHeaderByNumber(context.Background(), current.BlockNumber-1)
+		if getParentErr != nil {
+			return core.Reorg{}, getParentErr
+		}
+		if parent.Hash != current.ParentHash {
+			return core.Reorg{}, ErrChainChangedDuringWalk
+		}
+		current = parent
+	}
+}
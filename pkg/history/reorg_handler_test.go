@@ -0,0 +1,245 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history_test
+
+import (
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/pkg/history"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reorg handler", func() {
+	var (
+		blockChain       *fakes.MockBlockChain
+		headerRepository *fakes.MockHeaderRepository
+		reorgRepository  *fakes.MockReorgRepository
+		handler          *history.ReorgHandler
+		canonicalHeaders []core.Header
+	)
+
+	BeforeEach(func() {
+		blockChain = fakes.NewMockBlockChain()
+		headerRepository = fakes.NewMockHeaderRepository()
+		reorgRepository = &fakes.MockReorgRepository{}
+		handler = history.NewReorgHandler(blockChain, headerRepository, reorgRepository, 10)
+		canonicalHeaders = []core.Header{
+			{BlockNumber: 1, Hash: "0x1", ParentHash: "0x0"},
+			{BlockNumber: 2, Hash: "0x2", ParentHash: "0x1"},
+			{BlockNumber: 3, Hash: "0x3", ParentHash: "0x2"},
+			{BlockNumber: 4, Hash: "0x4", ParentHash: "0x3"},
+		}
+		blockChain.SetGetHeaderByNumberReturnHeaders(canonicalHeaders)
+	})
+
+	It("does not record a reorg if the stored head matches the chain", func() {
+		headerRepository.SetStoredHeaders(canonicalHeaders)
+
+		found, err := handler.HandleReorg(4)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeFalse())
+		Expect(reorgRepository.CreateReorgCalled).To(BeFalse())
+	})
+
+	It("records orphaned and replacement headers back to the common ancestor", func() {
+		orphanedHeaders := []core.Header{
+			{BlockNumber: 3, Hash: "0x3a", ParentHash: "0x2"},
+			{BlockNumber: 4, Hash: "0x4a", ParentHash: "0x3a"},
+		}
+		headerRepository.SetStoredHeaders(append(canonicalHeaders[:2:2], orphanedHeaders...))
+
+		found, err := handler.HandleReorg(4)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(reorgRepository.CreateReorgCalled).To(BeTrue())
+		passedReorg := reorgRepository.CreateReorgPassedReorg
+		Expect(passedReorg.CommonAncestor).To(Equal(int64(2)))
+		Expect(passedReorg.OldHashes()).To(ConsistOf("0x3a", "0x4a"))
+		Expect(passedReorg.NewHashes()).To(ConsistOf("0x3", "0x4"))
+	})
+
+	It("skips blocks that have not been stored yet", func() {
+		headerRepository.SetStoredHeaders([]core.Header{
+			canonicalHeaders[0],
+			{BlockNumber: 2, Hash: "0x2a", ParentHash: "0x1"},
+		})
+
+		found, err := handler.HandleReorg(4)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		passedReorg := reorgRepository.CreateReorgPassedReorg
+		Expect(passedReorg.CommonAncestor).To(Equal(int64(1)))
+		Expect(passedReorg.OldHashes()).To(ConsistOf("0x2a"))
+		Expect(passedReorg.NewHashes()).To(ConsistOf("0x2"))
+	})
+
+	It("does not record a reorg if no headers near the head are stored", func() {
+		handler = history.NewReorgHandler(blockChain, headerRepository, reorgRepository, 2)
+		headerRepository.SetStoredHeaders([]core.Header{})
+
+		found, err := handler.HandleReorg(4)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeFalse())
+		Expect(reorgRepository.CreateReorgCalled).To(BeFalse())
+	})
+
+	It("returns an error if the reorg is deeper than the max depth", func() {
+		handler = history.NewReorgHandler(blockChain, headerRepository, reorgRepository, 1)
+		headerRepository.SetStoredHeaders([]core.Header{
+			canonicalHeaders[0],
+			{BlockNumber: 2, Hash: "0x2a", ParentHash: "0x1"},
+			{BlockNumber: 3, Hash: "0x3a", ParentHash: "0x2a"},
+			{BlockNumber: 4, Hash: "0x4a", ParentHash: "0x3a"},
+		})
+
+		_, err := handler.HandleReorg(4)
+
+		Expect(err).To(MatchError(history.ErrReorgTooDeep))
+		Expect(reorgRepository.CreateReorgCalled).To(BeFalse())
+	})
+
+	It("returns an error if the chain changes while walking back", func() {
+		blockChain.SetGetHeaderByNumberReturnHeaders([]core.Header{
+			canonicalHeaders[0],
+			canonicalHeaders[1],
+			{BlockNumber: 3, Hash: "0x3b", ParentHash: "0x2"},
+			canonicalHeaders[3],
+		})
+		headerRepository.SetStoredHeaders([]core.Header{
+			canonicalHeaders[0],
+			canonicalHeaders[1],
+			{BlockNumber: 3, Hash: "0x3a", ParentHash: "0x2"},
+			{BlockNumber: 4, Hash: "0x4a", ParentHash: "0x3a"},
+		})
+
+		_, err := handler.HandleReorg(4)
+
+		Expect(err).To(MatchError(history.ErrChainChangedDuringWalk))
+		Expect(reorgRepository.CreateReorgCalled).To(BeFalse())
+	})
+
+	It("returns an error if persisting the reorg fails", func() {
+		headerRepository.SetStoredHeaders([]core.Header{
+			canonicalHeaders[0],
+			{BlockNumber: 2, Hash: "0x2a", ParentHash: "0x1"},
+		})
+		reorgRepository.CreateReorgReturnError = fakes.FakeError
+
+		_, err := handler.HandleReorg(2)
+
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+
+	Describe("hooks", func() {
+		BeforeEach(func() {
+			headerRepository.SetStoredHeaders([]core.Header{
+				canonicalHeaders[0],
+				{BlockNumber: 2, Hash: "0x2a", ParentHash: "0x1"},
+			})
+			reorgRepository.CreateReorgReturnID = 123
+		})
+
+		It("calls hooks with the persisted reorg", func() {
+			var hookReorg core.Reorg
+			handler.AddHook(func(reorg core.Reorg) error {
+				hookReorg = reorg
+				return nil
+			})
+
+			_, err := handler.HandleReorg(2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hookReorg.ID).To(Equal(int64(123)))
+			Expect(hookReorg.CommonAncestor).To(Equal(int64(1)))
+		})
+
+		It("returns an error if a hook fails", func() {
+			handler.AddHook(func(reorg core.Reorg) error {
+				return fakes.FakeError
+			})
+
+			found, err := handler.HandleReorg(2)
+
+			Expect(found).To(BeTrue())
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+	})
+
+	Describe("UpdateHeaders", func() {
+		It("replaces stored headers that no longer match the chain as a reorg", func() {
+			orphanedHeader := core.Header{BlockNumber: 3, Hash: "0x3a", ParentHash: "0x2"}
+			headerRepository.SetStoredHeaders([]core.Header{canonicalHeaders[1], orphanedHeader, canonicalHeaders[3]})
+
+			replaced, err := handler.UpdateHeaders([]int64{2, 3, 4})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(replaced).To(Equal(1))
+			Expect(reorgRepository.CreateReorgCalled).To(BeTrue())
+			passedReorg := reorgRepository.CreateReorgPassedReorg
+			Expect(passedReorg.CommonAncestor).To(Equal(int64(2)))
+			Expect(passedReorg.OldHashes()).To(ConsistOf("0x3a"))
+			Expect(passedReorg.NewHashes()).To(ConsistOf("0x3"))
+		})
+
+		It("invokes hooks with the reorg", func() {
+			headerRepository.SetStoredHeaders([]core.Header{{BlockNumber: 3, Hash: "0x3a", ParentHash: "0x2"}})
+			var hookedReorg core.Reorg
+			handler.AddHook(func(reorg core.Reorg) error {
+				hookedReorg = reorg
+				return nil
+			})
+
+			_, err := handler.UpdateHeaders([]int64{3})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hookedReorg.NewHashes()).To(ConsistOf("0x3"))
+		})
+
+		It("inserts headers that have not been stored yet without recording a reorg", func() {
+			headerRepository.SetStoredHeaders([]core.Header{canonicalHeaders[1]})
+
+			replaced, err := handler.UpdateHeaders([]int64{2, 3})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(replaced).To(BeZero())
+			Expect(reorgRepository.CreateReorgCalled).To(BeFalse())
+			headerRepository.AssertCreateOrUpdateHeaderCallCountAndPassedBlockNumbers(1, []int64{3})
+		})
+
+		It("returns an error if fetching headers fails", func() {
+			blockChain.SetGetHeadersByNumbersErr(fakes.FakeError, 1)
+
+			_, err := handler.UpdateHeaders([]int64{3})
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+
+		It("returns an error if persisting the reorg fails", func() {
+			headerRepository.SetStoredHeaders([]core.Header{{BlockNumber: 3, Hash: "0x3a", ParentHash: "0x2"}})
+			reorgRepository.CreateReorgReturnError = fakes.FakeError
+
+			_, err := handler.UpdateHeaders([]int64{3})
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+	})
+})
@@ -17,11 +17,16 @@
 package history
 
 import (
+	"context"
 	"fmt"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	log "github.com/sirupsen/logrus"
 )
 
+// Blocks are validated this many blocks past the finality depth, so that blocks that became final since the previous
+// poll are validated once more after reaching it
+const FinalityDepthMargin = 10
+
 type ValidationWindow struct {
 	LowerBound int64
 	UpperBound int64
@@ -32,7 +37,7 @@ func (window ValidationWindow) Size() int {
 }
 
 func MakeValidationWindow(blockchain core.BlockChain, windowSize int) (ValidationWindow, error) {
-	upperBound, err := blockchain.LastBlock()
+	upperBound, err := blockchain.LastBlock(context.Background())
 	if err != nil {
 		log.Error("MakeValidationWindow: error getting LastBlock: ", err)
 		return ValidationWindow{}, err
@@ -41,6 +46,12 @@ func MakeValidationWindow(blockchain core.BlockChain, windowSize int) (Validatio
 	return ValidationWindow{lowerBound, upperBound.Int64()}, nil
 }
 
+// Returns the size of a window validating every block that could still be reorged on a chain whose blocks are final
+// once they're finalityDepth blocks below the head
+func WindowSizeForFinalityDepth(finalityDepth int64) int {
+	return int(finalityDepth) + FinalityDepthMargin
+}
+
 func MakeRange(min, max int64) []int64 {
 	a := make([]int64, max-min+1)
 	for i := range a {
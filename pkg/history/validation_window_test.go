@@ -37,6 +37,12 @@ var _ = Describe("Validation window", func() {
 		Expect(validationWindow.UpperBound).To(Equal(int64(5)))
 	})
 
+	It("covers the finality depth and a margin for blocks finalized between polls", func() {
+		windowSize := history.WindowSizeForFinalityDepth(64)
+
+		Expect(windowSize).To(Equal(64 + history.FinalityDepthMargin))
+	})
+
 	It("returns the window size", func() {
 		window := history.ValidationWindow{LowerBound: 1, UpperBound: 3}
 
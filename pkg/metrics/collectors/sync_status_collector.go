@@ -0,0 +1,129 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package collectors
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	chainHeadTimeout = 5 * time.Second
+	// How long the sync status is reported from memory before it's read from the database again
+	DefaultSyncStatusMaxAge = time.Minute
+)
+
+var (
+	chainHeadDesc = prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, "", "chain_head_block_number"),
+		"Block number of the ethereum node's chain head.", nil, nil)
+	highestHeaderDesc = prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, "", "highest_synced_header_block_number"),
+		"Block number of the highest synced header.", nil, nil)
+	missingHeadersDesc = prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, "", "missing_headers"),
+		"Number of headers missing between the lowest and highest synced headers.", nil, nil)
+	uncheckedHeadersDesc = prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, "", "unchecked_headers"),
+		"Number of synced headers that have not been checked for watched event logs.", nil, nil)
+	untransformedLogsDesc = prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, "", "untransformed_logs"),
+		"Number of persisted event logs waiting to be transformed.", nil, nil)
+	queuedStorageDesc = prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, "", "queued_storage_diffs"),
+		"Number of storage diffs queued for another transformation attempt.", nil, nil)
+	highestBlockDesc = prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, "", "highest_synced_block_number"),
+		"Block number of the highest fully synced block.", nil, nil)
+	missingBlocksDesc = prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, "", "missing_blocks"),
+		"Number of blocks missing between the lowest and highest fully synced blocks.", nil, nil)
+)
+
+// Reports the chain head and the state of the synced data each time metrics are scraped. The sync status counts whole
+// tables, so it's only read from the database once it's older than StatusMaxAge.
+type SyncStatusCollector struct {
+	BlockChain           core.BlockChain
+	StatusMaxAge         time.Duration
+	SyncStatusRepository datastore.SyncStatusRepository
+	mutex                sync.Mutex
+	status               core.SyncStatus
+	statusRead           time.Time
+}
+
+func NewSyncStatusCollector(bc core.BlockChain, repository datastore.SyncStatusRepository) *SyncStatusCollector {
+	return &SyncStatusCollector{
+		BlockChain:           bc,
+		StatusMaxAge:         DefaultSyncStatusMaxAge,
+		SyncStatusRepository: repository,
+	}
+}
+
+func (collector *SyncStatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- chainHeadDesc
+	ch <- highestHeaderDesc
+	ch <- missingHeadersDesc
+	ch <- uncheckedHeadersDesc
+	ch <- untransformedLogsDesc
+	ch <- queuedStorageDesc
+	ch <- highestBlockDesc
+	ch <- missingBlocksDesc
+}
+
+// Metrics that can't be read are left out of the scrape, so that the others are still reported
+func (collector *SyncStatusCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), chainHeadTimeout)
+	defer cancel()
+	chainHead, chainHeadErr := collector.BlockChain.LastBlock(ctx)
+	if chainHeadErr != nil {
+		logrus.Warnf("error getting chain head for metrics: %s", chainHeadErr.Error())
+	} else {
+		ch <- gauge(chainHeadDesc, chainHead.Int64())
+	}
+
+	status, statusErr := collector.getSyncStatus()
+	if statusErr != nil {
+		logrus.Warnf("error getting sync status for metrics: %s", statusErr.Error())
+		return
+	}
+	ch <- gauge(highestHeaderDesc, status.HighestHeaderBlockNumber)
+	ch <- gauge(missingHeadersDesc, status.MissingHeaders)
+	ch <- gauge(uncheckedHeadersDesc, status.UncheckedHeaders)
+	ch <- gauge(untransformedLogsDesc, status.UntransformedLogs)
+	ch <- gauge(queuedStorageDesc, status.QueuedStorageDiffs)
+	ch <- gauge(highestBlockDesc, status.HighestBlockNumber)
+	ch <- gauge(missingBlocksDesc, status.MissingBlocks)
+}
+
+// Errors aren't kept, so a failed read is retried on the next scrape
+func (collector *SyncStatusCollector) getSyncStatus() (core.SyncStatus, error) {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+	if !collector.statusRead.IsZero() && time.Since(collector.statusRead) < collector.StatusMaxAge {
+		return collector.status, nil
+	}
+	status, err := collector.SyncStatusRepository.GetSyncStatus()
+	if err != nil {
+		return status, err
+	}
+	collector.status = status
+	collector.statusRead = time.Now()
+	return status, nil
+}
+
+func gauge(desc *prometheus.Desc, value int64) prometheus.Metric {
+	return prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(value))
+}
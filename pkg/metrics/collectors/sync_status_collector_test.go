@@ -0,0 +1,138 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package collectors_test
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/pkg/metrics/collectors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("Sync status collector", func() {
+	var (
+		blockChain *fakes.MockBlockChain
+		repository *fakes.MockSyncStatusRepository
+		collector  *collectors.SyncStatusCollector
+	)
+
+	BeforeEach(func() {
+		blockChain = fakes.NewMockBlockChain()
+		blockChain.SetLastBlock(big.NewInt(120))
+		repository = &fakes.MockSyncStatusRepository{}
+		repository.GetSyncStatusReturn = core.SyncStatus{
+			HighestHeaderBlockNumber: 110,
+			MissingHeaders:           3,
+			UncheckedHeaders:         4,
+			UntransformedLogs:        5,
+			QueuedStorageDiffs:       6,
+			HighestBlockNumber:       100,
+			MissingBlocks:            7,
+		}
+		collector = collectors.NewSyncStatusCollector(blockChain, repository)
+	})
+
+	It("reports the chain head and sync status", func() {
+		expected := `
+# HELP vulcanizedb_chain_head_block_number Block number of the ethereum node's chain head.
+# TYPE vulcanizedb_chain_head_block_number gauge
+vulcanizedb_chain_head_block_number 120
+# HELP vulcanizedb_highest_synced_block_number Block number of the highest fully synced block.
+# TYPE vulcanizedb_highest_synced_block_number gauge
+vulcanizedb_highest_synced_block_number 100
+# HELP vulcanizedb_highest_synced_header_block_number Block number of the highest synced header.
+# TYPE vulcanizedb_highest_synced_header_block_number gauge
+vulcanizedb_highest_synced_header_block_number 110
+# HELP vulcanizedb_missing_blocks Number of blocks missing between the lowest and highest fully synced blocks.
+# TYPE vulcanizedb_missing_blocks gauge
+vulcanizedb_missing_blocks 7
+# HELP vulcanizedb_missing_headers Number of headers missing between the lowest and highest synced headers.
+# TYPE vulcanizedb_missing_headers gauge
+vulcanizedb_missing_headers 3
+# HELP vulcanizedb_queued_storage_diffs Number of storage diffs queued for another transformation attempt.
+# TYPE vulcanizedb_queued_storage_diffs gauge
+vulcanizedb_queued_storage_diffs 6
+# HELP vulcanizedb_unchecked_headers Number of synced headers that have not been checked for watched event logs.
+# TYPE vulcanizedb_unchecked_headers gauge
+vulcanizedb_unchecked_headers 4
+# HELP vulcanizedb_untransformed_logs Number of persisted event logs waiting to be transformed.
+# TYPE vulcanizedb_untransformed_logs gauge
+vulcanizedb_untransformed_logs 5
+`
+		err := testutil.CollectAndCompare(collector, strings.NewReader(expected))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repository.GetSyncStatusCalled).To(BeTrue())
+	})
+
+	It("reads the sync status from the database once it's older than the max age", func() {
+		collect(collector)
+		collect(collector)
+		Expect(repository.GetSyncStatusCallCount).To(Equal(1))
+
+		collector.StatusMaxAge = 0
+		collect(collector)
+
+		Expect(repository.GetSyncStatusCallCount).To(Equal(2))
+	})
+
+	It("reads the sync status again after a failed read", func() {
+		repository.GetSyncStatusReturnError = fakes.FakeError
+		collect(collector)
+		repository.GetSyncStatusReturnError = nil
+
+		collect(collector)
+
+		Expect(repository.GetSyncStatusCallCount).To(Equal(2))
+	})
+
+	It("still reports the sync status if getting the chain head fails", func() {
+		blockChain.SetLastBlockErr(fakes.FakeError)
+		expected := `
+# HELP vulcanizedb_untransformed_logs Number of persisted event logs waiting to be transformed.
+# TYPE vulcanizedb_untransformed_logs gauge
+vulcanizedb_untransformed_logs 5
+`
+		err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+			"vulcanizedb_chain_head_block_number", "vulcanizedb_untransformed_logs")
+
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("still reports the chain head if getting the sync status fails", func() {
+		repository.GetSyncStatusReturnError = fakes.FakeError
+		expected := `
+# HELP vulcanizedb_chain_head_block_number Block number of the ethereum node's chain head.
+# TYPE vulcanizedb_chain_head_block_number gauge
+vulcanizedb_chain_head_block_number 120
+`
+		err := testutil.CollectAndCompare(collector, strings.NewReader(expected))
+
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
+func collect(collector prometheus.Collector) {
+	ch := make(chan prometheus.Metric, 10)
+	collector.Collect(ch)
+}
@@ -0,0 +1,173 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prefix of every metric vulcanizeDB exports
+const Namespace = "vulcanizedb"
+
+var (
+	rpcCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "rpc",
+		Name:      "calls_total",
+		Help:      "Number of RPC calls made to the ethereum node, by method.",
+	}, []string{"method"})
+	rpcErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "rpc",
+		Name:      "errors_total",
+		Help:      "Number of RPC calls to the ethereum node that returned an error, by method.",
+	}, []string{"method"})
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "rpc",
+		Name:      "call_duration_seconds",
+		Help:      "Latency of RPC calls to the ethereum node, by method. Batched calls are timed as a whole under the batch method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+	rpcThrottled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "rpc",
+		Name:      "throttled_total",
+		Help:      "Number of RPC calls delayed by the rate limiter, by method.",
+	}, []string{"method"})
+	rpcThrottledDuration = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "rpc",
+		Name:      "throttled_seconds_total",
+		Help:      "Time RPC calls spent waiting for the rate limiter, by method.",
+	}, []string{"method"})
+	rpcRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "rpc",
+		Name:      "retries_total",
+		Help:      "Number of times failed RPC calls were retried, by method.",
+	}, []string{"method"})
+	rpcCacheHits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "rpc",
+		Name:      "cache_hits_total",
+		Help:      "Number of RPC calls answered from the response cache, by method.",
+	}, []string{"method"})
+	rpcCacheMisses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "rpc",
+		Name:      "cache_misses_total",
+		Help:      "Number of cacheable RPC calls not found in the response cache, by method.",
+	}, []string{"method"})
+
+	transformerExecutions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "transformer",
+		Name:      "executions_total",
+		Help:      "Number of transformer executions, by transformer.",
+	}, []string{"transformer"})
+	transformerErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "transformer",
+		Name:      "errors_total",
+		Help:      "Number of transformer executions that returned an error, by transformer.",
+	}, []string{"transformer"})
+	transformerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "transformer",
+		Name:      "execution_duration_seconds",
+		Help:      "Latency of transformer executions, by transformer.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"transformer"})
+
+	reorgs = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "reorgs_total",
+		Help:      "Number of chain reorganizations handled by headerSync.",
+	})
+	reorgDepth = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "reorg_depth_blocks",
+		Help:      "Number of orphaned headers replaced in each chain reorganization.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 8),
+	})
+)
+
+// BatchMethod labels the latency of batched RPC calls, which cannot be attributed to the individual methods
+const BatchMethod = "batch"
+
+func init() {
+	prometheus.MustRegister(rpcCalls, rpcErrors, rpcDuration, rpcThrottled, rpcThrottledDuration, rpcRetries,
+		rpcCacheHits, rpcCacheMisses, transformerExecutions, transformerErrors, transformerDuration, reorgs, reorgDepth)
+}
+
+// Records an RPC call to method that started at start and returned err
+func ObserveRPCCall(method string, start time.Time, err error) {
+	rpcCalls.WithLabelValues(method).Inc()
+	if err != nil {
+		rpcErrors.WithLabelValues(method).Inc()
+	}
+	rpcDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// Records a batch of RPC calls to methods that started at start and returned err
+func ObserveRPCBatch(methods []string, start time.Time, err error) {
+	for _, method := range methods {
+		rpcCalls.WithLabelValues(method).Inc()
+		if err != nil {
+			rpcErrors.WithLabelValues(method).Inc()
+		}
+	}
+	rpcDuration.WithLabelValues(BatchMethod).Observe(time.Since(start).Seconds())
+}
+
+// Records that a call to method was delayed by the rate limiter for wait
+func ObserveRPCThrottle(method string, wait time.Duration) {
+	rpcThrottled.WithLabelValues(method).Inc()
+	rpcThrottledDuration.WithLabelValues(method).Add(wait.Seconds())
+}
+
+// Records that a failed call to method is being retried
+func ObserveRPCRetry(method string) {
+	rpcRetries.WithLabelValues(method).Inc()
+}
+
+// Records whether a cacheable call to method was found in the response cache
+func ObserveRPCCacheLookup(method string, hit bool) {
+	if hit {
+		rpcCacheHits.WithLabelValues(method).Inc()
+	} else {
+		rpcCacheMisses.WithLabelValues(method).Inc()
+	}
+}
+
+// Records an execution of the named transformer that started at start and returned err
+func ObserveTransformerExecution(transformerName string, start time.Time, err error) {
+	transformerExecutions.WithLabelValues(transformerName).Inc()
+	if err != nil {
+		transformerErrors.WithLabelValues(transformerName).Inc()
+	}
+	transformerDuration.WithLabelValues(transformerName).Observe(time.Since(start).Seconds())
+}
+
+// Records a chain reorganization that orphaned depth headers
+func ObserveReorg(depth int64) {
+	reorgs.Inc()
+	reorgDepth.Observe(float64(depth))
+}
@@ -0,0 +1,152 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metrics_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var _ = Describe("Metrics", func() {
+	Describe("ObserveRPCCall", func() {
+		It("counts and times calls by method", func() {
+			metrics.ObserveRPCCall("test_successfulCall", time.Now(), nil)
+
+			Expect(counterValue("vulcanizedb_rpc_calls_total", "method", "test_successfulCall")).To(Equal(float64(1)))
+			Expect(counterValue("vulcanizedb_rpc_errors_total", "method", "test_successfulCall")).To(BeZero())
+			Expect(histogramCount("vulcanizedb_rpc_call_duration_seconds", "method", "test_successfulCall")).To(Equal(uint64(1)))
+		})
+
+		It("counts calls that return an error", func() {
+			metrics.ObserveRPCCall("test_failingCall", time.Now(), errors.New("rpc failed"))
+
+			Expect(counterValue("vulcanizedb_rpc_errors_total", "method", "test_failingCall")).To(Equal(float64(1)))
+		})
+	})
+
+	Describe("ObserveRPCBatch", func() {
+		It("counts each batched call by method and times the batch as a whole", func() {
+			batchCountBefore := histogramCount("vulcanizedb_rpc_call_duration_seconds", "method", metrics.BatchMethod)
+
+			metrics.ObserveRPCBatch([]string{"test_batchedCall", "test_batchedCall"}, time.Now(), nil)
+
+			Expect(counterValue("vulcanizedb_rpc_calls_total", "method", "test_batchedCall")).To(Equal(float64(2)))
+			Expect(histogramCount("vulcanizedb_rpc_call_duration_seconds", "method", metrics.BatchMethod)).
+				To(Equal(batchCountBefore + 1))
+		})
+	})
+
+	Describe("ObserveRPCThrottle", func() {
+		It("counts throttled calls and the time they waited by method", func() {
+			metrics.ObserveRPCThrottle("test_throttledCall", 500*time.Millisecond)
+			metrics.ObserveRPCThrottle("test_throttledCall", time.Second)
+
+			Expect(counterValue("vulcanizedb_rpc_throttled_total", "method", "test_throttledCall")).To(Equal(float64(2)))
+			Expect(counterValue("vulcanizedb_rpc_throttled_seconds_total", "method", "test_throttledCall")).
+				To(Equal(1.5))
+		})
+	})
+
+	Describe("ObserveRPCRetry", func() {
+		It("counts retries by method", func() {
+			metrics.ObserveRPCRetry("test_retriedCall")
+
+			Expect(counterValue("vulcanizedb_rpc_retries_total", "method", "test_retriedCall")).To(Equal(float64(1)))
+		})
+	})
+
+	Describe("ObserveRPCCacheLookup", func() {
+		It("counts cache hits and misses by method", func() {
+			metrics.ObserveRPCCacheLookup("test_cachedCall", true)
+			metrics.ObserveRPCCacheLookup("test_cachedCall", true)
+			metrics.ObserveRPCCacheLookup("test_cachedCall", false)
+
+			Expect(counterValue("vulcanizedb_rpc_cache_hits_total", "method", "test_cachedCall")).To(Equal(float64(2)))
+			Expect(counterValue("vulcanizedb_rpc_cache_misses_total", "method", "test_cachedCall")).To(Equal(float64(1)))
+		})
+	})
+
+	Describe("ObserveTransformerExecution", func() {
+		It("counts and times executions by transformer", func() {
+			metrics.ObserveTransformerExecution("test_transformer", time.Now(), nil)
+			metrics.ObserveTransformerExecution("test_transformer", time.Now(), errors.New("execution failed"))
+
+			Expect(counterValue("vulcanizedb_transformer_executions_total", "transformer", "test_transformer")).
+				To(Equal(float64(2)))
+			Expect(counterValue("vulcanizedb_transformer_errors_total", "transformer", "test_transformer")).
+				To(Equal(float64(1)))
+			Expect(histogramCount("vulcanizedb_transformer_execution_duration_seconds", "transformer", "test_transformer")).
+				To(Equal(uint64(2)))
+		})
+	})
+
+	Describe("ObserveReorg", func() {
+		It("counts reorgs and records their depth", func() {
+			reorgsBefore := counterValue("vulcanizedb_reorgs_total", "", "")
+			depthsBefore := histogramCount("vulcanizedb_reorg_depth_blocks", "", "")
+
+			metrics.ObserveReorg(3)
+
+			Expect(counterValue("vulcanizedb_reorgs_total", "", "")).To(Equal(reorgsBefore + 1))
+			Expect(histogramCount("vulcanizedb_reorg_depth_blocks", "", "")).To(Equal(depthsBefore + 1))
+		})
+	})
+})
+
+func counterValue(name, labelName, labelValue string) float64 {
+	metric := getMetric(name, labelName, labelValue)
+	if metric == nil {
+		return 0
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func histogramCount(name, labelName, labelValue string) uint64 {
+	metric := getMetric(name, labelName, labelValue)
+	if metric == nil {
+		return 0
+	}
+	return metric.GetHistogram().GetSampleCount()
+}
+
+// Unlabelled metrics are looked up with an empty labelName
+func getMetric(name, labelName, labelValue string) *dto.Metric {
+	families, err := prometheus.DefaultGatherer.Gather()
+	Expect(err).NotTo(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if labelName == "" {
+				return metric
+			}
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == labelName && label.GetValue() == labelValue {
+					return metric
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -95,12 +95,15 @@ func CleanTestDB(db *postgres.DB) {
 	db.MustExec("DELETE FROM full_sync_receipts")
 	db.MustExec("DELETE FROM full_sync_transactions")
 	db.MustExec("DELETE FROM goose_db_version")
+	db.MustExec("DELETE FROM header_sync_log_transformations")
+	db.MustExec("DELETE FROM header_sync_log_transformers")
 	db.MustExec("DELETE FROM header_sync_logs")
 	db.MustExec("DELETE FROM header_sync_receipts")
 	db.MustExec("DELETE FROM header_sync_transactions")
 	db.MustExec("DELETE FROM headers")
 	db.MustExec("DELETE FROM log_filters")
 	db.MustExec("DELETE FROM queued_storage")
+	db.MustExec("DELETE FROM reorgs")
 	db.MustExec("DELETE FROM storage_diff")
 	db.MustExec("DELETE FROM watched_contracts")
 	db.MustExec("DELETE FROM watched_logs")